@@ -0,0 +1,163 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// APIKeySecretKey is the key of the Secret data holding the API key, as expected by Authorino
+	APIKeySecretKey = "api_key"
+
+	// APIKeySecretLabel labels the Secrets of the API keys with the name of the APIKey.
+	// The operator only caches the Secrets with this label.
+	APIKeySecretLabel = "kuadrant.io/apikey"
+
+	// APIKeyPlanAnnotation annotates the Secret of the API key with the rate limit plan of the key
+	APIKeyPlanAnnotation = "kuadrant.io/plan"
+
+	// APIKeyLastRotationAnnotation annotates the Secret of the API key with the time the key was generated, in RFC 3339
+	APIKeyLastRotationAnnotation = "kuadrant.io/apikey-last-rotation"
+
+	// APIKeyReadyConditionType signals the Secret of the API key is in place and selected by the AuthPolicy
+	APIKeyReadyConditionType = "Ready"
+
+	// APIKeyExpiredConditionType signals the API key expired and its Secret was deleted
+	APIKeyExpiredConditionType = "Expired"
+)
+
+// APIKeySpec defines the desired state of APIKey
+type APIKeySpec struct {
+	// AuthPolicyRef is the AuthPolicy, in the same namespace as the APIKey, that authenticates the key.
+	// The Secret of the key is labeled as selected by the `apiKey` authentication rules of the policy.
+	// +kubebuilder:validation:Required
+	// +required
+	AuthPolicyRef corev1.LocalObjectReference `json:"authPolicyRef"`
+
+	// AuthenticationRule is the name of the `apiKey` authentication rule of the AuthPolicy that selects the key.
+	// If omitted, the Secret is labeled for every `apiKey` authentication rule of the policy.
+	// +optional
+	AuthenticationRule string `json:"authenticationRule,omitempty"`
+
+	// Rotation regenerates the key periodically.
+	// If omitted, the key is never rotated.
+	// +optional
+	Rotation *APIKeyRotation `json:"rotation,omitempty"`
+
+	// ExpiresAt is the time after which the key is revoked, i.e. its Secret deleted.
+	// If omitted, the key never expires.
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+
+	// Plan is the rate limit plan of the key.
+	// It is set as the `kuadrant.io/plan` annotation of the Secret, so it can be exposed by the AuthPolicy
+	// (e.g. `auth.identity.metadata.annotations.kuadrant\.io/plan`) and used as counter key in RateLimitPolicies.
+	// +optional
+	Plan string `json:"plan,omitempty"`
+}
+
+// APIKeyRotation defines how often the key is regenerated
+type APIKeyRotation struct {
+	// Interval after which the key is regenerated.
+	// Value must be in units accepted by Go time.ParseDuration https://golang.org/pkg/time/#ParseDuration
+	// +kubebuilder:validation:Required
+	// +required
+	Interval metav1.Duration `json:"interval"`
+
+	// GracePeriod during which the previous key remains valid after a rotation, so clients can switch to the new key.
+	// The previous key is kept in the Secret `apikey-<name>-previous` until the grace period elapses.
+	// If omitted, defaults to 5m. Zero revokes the previous key right away.
+	// Value must be in units accepted by Go time.ParseDuration https://golang.org/pkg/time/#ParseDuration
+	// +optional
+	GracePeriod *metav1.Duration `json:"gracePeriod,omitempty"`
+}
+
+// APIKeyStatus defines the observed state of APIKey
+type APIKeyStatus struct {
+	// ObservedGeneration reflects the generation of the most recently observed spec.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Represents the observations of the APIKey's current state.
+	// Known .status.conditions.type are: "Ready", "Expired"
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	// SecretName is the name of the Secret holding the key, in the same namespace as the APIKey.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// LastRotationTime is the time the key was last generated.
+	// +optional
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
+
+	// AcceptedBy lists the AuthPolicies, as namespace/name, whose `apiKey` authentication rules select the key.
+	// +optional
+	AcceptedBy []string `json:"acceptedBy,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="AuthPolicy",type="string",JSONPath=".spec.authPolicyRef.name",description="AuthPolicy that authenticates the key"
+// +kubebuilder:printcolumn:name="Plan",type="string",JSONPath=".spec.plan",description="Rate limit plan of the key",priority=2
+// +kubebuilder:printcolumn:name="Secret",type="string",JSONPath=".status.secretName",description="Secret holding the key"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=`.status.conditions[?(@.type=="Ready")].status`,description="APIKey Ready"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// APIKey is the Schema for the apikeys API
+type APIKey struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   APIKeySpec   `json:"spec,omitempty"`
+	Status APIKeyStatus `json:"status,omitempty"`
+}
+
+// SecretName returns the name of the Secret holding the key
+func (k *APIKey) SecretName() string {
+	return fmt.Sprintf("apikey-%s", k.Name)
+}
+
+// PreviousSecretName returns the name of the Secret holding the previous key during the grace period of a rotation
+func (k *APIKey) PreviousSecretName() string {
+	return fmt.Sprintf("%s-previous", k.SecretName())
+}
+
+// IsExpired returns true if the key has an expiry time that is not after the given time
+func (k *APIKey) IsExpired(now metav1.Time) bool {
+	return k.Spec.ExpiresAt != nil && !now.Before(k.Spec.ExpiresAt)
+}
+
+//+kubebuilder:object:root=true
+
+// APIKeyList contains a list of APIKey
+type APIKeyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []APIKey `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&APIKey{}, &APIKeyList{})
+}
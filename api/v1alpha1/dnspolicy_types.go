@@ -128,10 +128,18 @@ type DNSPolicyStatus struct {
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 
 	HealthCheck *HealthCheckStatus `json:"healthCheck,omitempty"`
+
+	// Ancestors is a list of the gateways (and listeners) affected by the policy,
+	// with the status of the policy with respect to each of them.
+	// See https://gateway-api.sigs.k8s.io/geps/gep-713/#standard-status-struct
+	// +optional
+	// +kubebuilder:validation:MaxItems=16
+	Ancestors []gatewayapiv1alpha2.PolicyAncestorStatus `json:"ancestors,omitempty"`
 }
 
 var _ kuadrant.Policy = &DNSPolicy{}
 var _ kuadrant.Referrer = &DNSPolicy{}
+var _ kuadrant.AncestorsGetter = &DNSPolicy{}
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
@@ -164,6 +172,10 @@ func (p *DNSPolicy) GetTargetRef() gatewayapiv1alpha2.PolicyTargetReference {
 
 func (p *DNSPolicy) Kind() string { return p.TypeMeta.Kind }
 
+func (p *DNSPolicy) GetAncestors() []gatewayapiv1alpha2.PolicyAncestorStatus {
+	return p.Status.Ancestors
+}
+
 func (p *DNSPolicy) BackReferenceAnnotationName() string {
 	return DNSPolicyBackReferenceAnnotationName
 }
@@ -114,10 +114,18 @@ type TLSPolicyStatus struct {
 	// recorded in the status condition
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Ancestors is a list of the gateways (and listeners) affected by the policy,
+	// with the status of the policy with respect to each of them.
+	// See https://gateway-api.sigs.k8s.io/geps/gep-713/#standard-status-struct
+	// +optional
+	// +kubebuilder:validation:MaxItems=16
+	Ancestors []gatewayapiv1alpha2.PolicyAncestorStatus `json:"ancestors,omitempty"`
 }
 
 var _ kuadrant.Policy = &TLSPolicy{}
 var _ kuadrant.Referrer = &TLSPolicy{}
+var _ kuadrant.AncestorsGetter = &TLSPolicy{}
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
@@ -150,6 +158,10 @@ func (p *TLSPolicy) GetTargetRef() gatewayapiv1alpha2.PolicyTargetReference {
 	return p.Spec.TargetRef
 }
 
+func (p *TLSPolicy) GetAncestors() []gatewayapiv1alpha2.PolicyAncestorStatus {
+	return p.Status.Ancestors
+}
+
 func (p *TLSPolicy) BackReferenceAnnotationName() string {
 	return TLSPolicyBackReferenceAnnotationName
 }
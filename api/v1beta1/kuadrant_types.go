@@ -31,10 +31,26 @@ import (
 
 // KuadrantSpec defines the desired state of Kuadrant
 type KuadrantSpec struct {
+	// +optional
+	Authorino *AuthorinoSpec `json:"authorino,omitempty"`
+
 	// +optional
 	Limitador *LimitadorSpec `json:"limitador,omitempty"`
 }
 
+type AuthorinoSpec struct {
+	// DedicatedPerGateway deploys a dedicated Authorino instance for each gateway managed by Kuadrant,
+	// so the AuthConfigs of the policies of one gateway do not affect the latency of the other gateways.
+	// Gateways can also opt in individually with the annotation `kuadrant.io/dedicated-authorino: "true"`.
+	// +optional
+	DedicatedPerGateway bool `json:"dedicatedPerGateway,omitempty"`
+}
+
+// DedicatedAuthorinoPerGateway returns true if every gateway gets a dedicated Authorino instance
+func (k *Kuadrant) DedicatedAuthorinoPerGateway() bool {
+	return k.Spec.Authorino != nil && k.Spec.Authorino.DedicatedPerGateway
+}
+
 type LimitadorSpec struct {
 
 	// +optional
@@ -27,6 +27,21 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuthorinoSpec) DeepCopyInto(out *AuthorinoSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthorinoSpec.
+func (in *AuthorinoSpec) DeepCopy() *AuthorinoSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AuthorinoSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Kuadrant) DeepCopyInto(out *Kuadrant) {
 	*out = *in
@@ -89,6 +104,11 @@ func (in *KuadrantList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KuadrantSpec) DeepCopyInto(out *KuadrantSpec) {
 	*out = *in
+	if in.Authorino != nil {
+		in, out := &in.Authorino, &out.Authorino
+		*out = new(AuthorinoSpec)
+		**out = **in
+	}
 	if in.Limitador != nil {
 		in, out := &in.Limitador, &out.Limitador
 		*out = new(LimitadorSpec)
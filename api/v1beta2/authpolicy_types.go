@@ -6,6 +6,7 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
 	authorinoapi "github.com/kuadrant/authorino/api/v1beta2"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
@@ -18,6 +19,24 @@ import (
 const (
 	AuthPolicyBackReferenceAnnotationName   = "kuadrant.io/authpolicies"
 	AuthPolicyDirectReferenceAnnotationName = "kuadrant.io/authpolicy"
+
+	// AuthPolicyNativeAuthAnnotation opts an AuthPolicy in to be enforced by the native authentication of the
+	// gateway provider instead of Authorino, whenever the auth scheme of the policy can be expressed natively.
+	AuthPolicyNativeAuthAnnotation = "kuadrant.io/native-auth"
+
+	// AuthPolicyConditionNativeAuth reports whether an AuthPolicy opted in to native auth is enforced natively
+	// by the gateway provider or falls back to Authorino
+	AuthPolicyConditionNativeAuth = "NativeAuth"
+
+	AuthPolicyReasonNativeAuthEnvoyGateway = "EnvoyGateway"
+	AuthPolicyReasonNativeAuthAuthorino    = "Authorino"
+
+	// AuthPolicyConditionAuthSchemeAccepted reports whether Authorino accepted the auth scheme of the AuthConfig of the
+	// policy, with the reason reported by Authorino (e.g. "Reconciled", "Invalid", "CachingError")
+	AuthPolicyConditionAuthSchemeAccepted = "AuthSchemeAccepted"
+
+	// AuthPolicyConditionHostsLinked reports whether Authorino linked all the hosts of the AuthConfig of the policy
+	AuthPolicyConditionHostsLinked = "HostsLinked"
 )
 
 type AuthSchemeSpec struct {
@@ -146,6 +165,9 @@ type CallbackSpec struct {
 // +kubebuilder:validation:XValidation:rule="self.targetRef.kind != 'Gateway' || !has(self.defaults) || !has(self.defaults.rules) || !has(self.defaults.rules.callbacks) || !self.defaults.rules.callbacks.exists(x, has(self.defaults.rules.callbacks[x].routeSelectors))",message="route selectors not supported when targeting a Gateway"
 // Mutual Exclusivity Validation
 // +kubebuilder:validation:XValidation:rule="!(has(self.defaults) && (has(self.routeSelectors) || has(self.patterns) || has(self.when) || has(self.rules)))",message="Implicit and explicit defaults are mutually exclusive"
+// Disabled Validation
+// +kubebuilder:validation:XValidation:rule="!has(self.disabled) || !self.disabled || self.targetRef.kind == 'HTTPRoute'",message="disabled is only supported when targeting a HTTPRoute"
+// +kubebuilder:validation:XValidation:rule="!has(self.disabled) || !self.disabled || !(has(self.defaults) || has(self.routeSelectors) || has(self.patterns) || has(self.when) || has(self.rules))",message="disabled is mutually exclusive with the rest of the auth settings"
 type AuthPolicySpec struct {
 	// TargetRef identifies an API object to apply policy to.
 	// +kubebuilder:validation:XValidation:rule="self.group == 'gateway.networking.k8s.io'",message="Invalid targetRef.group. The only supported value is 'gateway.networking.k8s.io'"
@@ -157,6 +179,12 @@ type AuthPolicySpec struct {
 	// +optional
 	Defaults *AuthPolicyCommonSpec `json:"defaults,omitempty"`
 
+	// Disabled exempts the targeted HTTPRoute from auth, including from the AuthPolicy targeting its gateways,
+	// e.g. to expose public endpoints behind a gateway protected by a deny-by-default AuthPolicy.
+	// Only supported when targeting a HTTPRoute, and mutually exclusive with the rest of the auth settings.
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+
 	// AuthPolicyCommonSpec defines implicit default values for this policy and for policies inheriting this policy.
 	// AuthPolicyCommonSpec is mutually exclusive with explicit defaults defined by Defaults.
 	AuthPolicyCommonSpec `json:""`
@@ -205,6 +233,68 @@ type AuthPolicyStatus struct {
 	// +listType=map
 	// +listMapKey=type
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+
+	// Ancestors is a list of the gateways (and listeners) affected by the policy,
+	// with the status of the policy with respect to each of them.
+	// See https://gateway-api.sigs.k8s.io/geps/gep-713/#standard-status-struct
+	// +optional
+	// +kubebuilder:validation:MaxItems=16
+	Ancestors []gatewayapiv1alpha2.PolicyAncestorStatus `json:"ancestors,omitempty"`
+
+	// AuthConfig summarizes the status of the Authorino AuthConfig that enforces the policy.
+	// Not set for policies enforced natively by the gateway provider.
+	// +optional
+	AuthConfig *AuthConfigSummary `json:"authConfig,omitempty"`
+}
+
+// AuthConfigSummary summarizes the status of an Authorino AuthConfig
+type AuthConfigSummary struct {
+	// Name of the AuthConfig, in the namespace of the policy.
+	Name string `json:"name"`
+
+	// Ready tells whether Authorino accepted the auth scheme of the AuthConfig and linked all its hosts.
+	Ready bool `json:"ready"`
+
+	// Reason of the Ready condition of the AuthConfig reported by Authorino.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Message of the Ready condition of the AuthConfig reported by Authorino,
+	// e.g. the failure to resolve a Secret or an OIDC issuer.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// HostsReady lists the hosts of the AuthConfig linked by Authorino.
+	// +optional
+	HostsReady []string `json:"hostsReady,omitempty"`
+
+	// HostsNotReady lists the hosts of the AuthConfig not linked by Authorino,
+	// e.g. because they are linked to the AuthConfig of another policy.
+	// +optional
+	HostsNotReady []string `json:"hostsNotReady,omitempty"`
+
+	// Authentication rules of the AuthConfig by evaluation state.
+	// +optional
+	Authentication AuthRulesSummary `json:"authentication,omitempty"`
+
+	// Metadata rules of the AuthConfig by evaluation state.
+	// +optional
+	Metadata AuthRulesSummary `json:"metadata,omitempty"`
+
+	// Authorization rules of the AuthConfig by evaluation state.
+	// +optional
+	Authorization AuthRulesSummary `json:"authorization,omitempty"`
+}
+
+// AuthRulesSummary lists the names of the rules of a phase of an auth scheme by evaluation state
+type AuthRulesSummary struct {
+	// Accepted lists the rules loaded by Authorino.
+	// +optional
+	Accepted []string `json:"accepted,omitempty"`
+
+	// Failed lists the rules whose references (e.g. Secrets, OIDC issuers, external endpoints) Authorino failed to resolve.
+	// +optional
+	Failed []string `json:"failed,omitempty"`
 }
 
 func (s *AuthPolicyStatus) Equals(other *AuthPolicyStatus, logger logr.Logger) bool {
@@ -223,11 +313,28 @@ func (s *AuthPolicyStatus) Equals(other *AuthPolicyStatus, logger logr.Logger) b
 		return false
 	}
 
+	if !equality.Semantic.DeepEqual(s.Ancestors, other.Ancestors) {
+		if logger.V(1).Enabled() {
+			diff := cmp.Diff(s.Ancestors, other.Ancestors)
+			logger.V(1).Info("Ancestors not equal", "difference", diff)
+		}
+		return false
+	}
+
+	if !equality.Semantic.DeepEqual(s.AuthConfig, other.AuthConfig) {
+		if logger.V(1).Enabled() {
+			diff := cmp.Diff(s.AuthConfig, other.AuthConfig)
+			logger.V(1).Info("AuthConfig not equal", "difference", diff)
+		}
+		return false
+	}
+
 	return true
 }
 
 var _ kuadrant.Policy = &AuthPolicy{}
 var _ kuadrant.Referrer = &AuthPolicy{}
+var _ kuadrant.AncestorsGetter = &AuthPolicy{}
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
@@ -259,11 +366,25 @@ func (ap *AuthPolicy) TargetKey() client.ObjectKey {
 	}
 }
 
+// NativeAuthRequested returns true if the policy opted in to native auth
+func (ap *AuthPolicy) NativeAuthRequested() bool {
+	return ap.GetAnnotations()[AuthPolicyNativeAuthAnnotation] == "true"
+}
+
 func (ap *AuthPolicy) Validate() error {
 	if ap.Spec.TargetRef.Namespace != nil && string(*ap.Spec.TargetRef.Namespace) != ap.Namespace {
 		return fmt.Errorf("invalid targetRef.Namespace %s. Currently only supporting references to the same namespace", *ap.Spec.TargetRef.Namespace)
 	}
 
+	if ap.Spec.Disabled {
+		if ap.Spec.TargetRef.Kind != "HTTPRoute" {
+			return fmt.Errorf("disabled is only supported when targeting a HTTPRoute")
+		}
+		if ap.Spec.Defaults != nil || !equality.Semantic.DeepEqual(ap.Spec.AuthPolicyCommonSpec, AuthPolicyCommonSpec{}) {
+			return fmt.Errorf("disabled is mutually exclusive with the rest of the auth settings")
+		}
+	}
+
 	return nil
 }
 
@@ -322,6 +443,10 @@ func (ap *AuthPolicy) Kind() string {
 	return ap.TypeMeta.Kind
 }
 
+func (ap *AuthPolicy) GetAncestors() []gatewayapiv1alpha2.PolicyAncestorStatus {
+	return ap.Status.Ancestors
+}
+
 func (ap *AuthPolicy) BackReferenceAnnotationName() string {
 	return AuthPolicyBackReferenceAnnotationName
 }
@@ -293,6 +293,42 @@ func TestAuthPolicyValidate(t *testing.T) {
 			},
 			message: "invalid targetRef.Namespace other-namespace. Currently only supporting references to the same namespace",
 		},
+		{
+			name: "disabled route policy",
+			policy: &AuthPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-policy", Namespace: "my-namespace"},
+				Spec: AuthPolicySpec{
+					TargetRef: gatewayapiv1alpha2.PolicyTargetReference{Group: gatewayapiv1.GroupName, Kind: "HTTPRoute", Name: "my-route"},
+					Disabled:  true,
+				},
+			},
+			valid: true,
+		},
+		{
+			name: "disabled gateway policy",
+			policy: &AuthPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-policy", Namespace: "my-namespace"},
+				Spec: AuthPolicySpec{
+					TargetRef: gatewayapiv1alpha2.PolicyTargetReference{Group: gatewayapiv1.GroupName, Kind: "Gateway", Name: "my-gateway"},
+					Disabled:  true,
+				},
+			},
+			message: "disabled is only supported when targeting a HTTPRoute",
+		},
+		{
+			name: "disabled policy with rules",
+			policy: &AuthPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-policy", Namespace: "my-namespace"},
+				Spec: AuthPolicySpec{
+					TargetRef: gatewayapiv1alpha2.PolicyTargetReference{Group: gatewayapiv1.GroupName, Kind: "HTTPRoute", Name: "my-route"},
+					Disabled:  true,
+					AuthPolicyCommonSpec: AuthPolicyCommonSpec{
+						Conditions: []authorinoapi.PatternExpressionOrRef{{PatternExpression: authorinoapi.PatternExpression{Selector: "request.path", Operator: "eq", Value: "/healthz"}}},
+					},
+				},
+			},
+			message: "disabled is mutually exclusive with the rest of the auth settings",
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -17,10 +17,19 @@ limitations under the License.
 package v1beta2
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
+	"golang.org/x/net/http/httpguts"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
@@ -59,39 +68,292 @@ const (
 	RateLimitPolicyDirectReferenceAnnotationName = "kuadrant.io/ratelimitpolicy"
 )
 
-// +kubebuilder:validation:Enum:=second;minute;hour;day
+// +kubebuilder:validation:Enum:=second;minute;hour;day;week;month
 type TimeUnit string
 
+// TimeUnitSeconds maps the time units to their length in seconds. A month is 30 days long.
+var TimeUnitSeconds = map[TimeUnit]int{
+	TimeUnit("second"): 1,
+	TimeUnit("minute"): 60,
+	TimeUnit("hour"):   60 * 60,
+	TimeUnit("day"):    60 * 60 * 24,
+	TimeUnit("week"):   60 * 60 * 24 * 7,
+	TimeUnit("month"):  60 * 60 * 24 * 30,
+}
+
+// iso8601DurationRegexp matches ISO 8601 durations, e.g. P1M, P1W, PT90S
+var iso8601DurationRegexp = regexp.MustCompile(`^P(?:(\d+)Y)?(?:(\d+)M)?(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// iso8601DurationUnits holds the length of each group of iso8601DurationRegexp.
+// Years are 365 days long and months are 30 days long.
+var iso8601DurationUnits = []time.Duration{
+	365 * 24 * time.Hour,
+	30 * 24 * time.Hour,
+	7 * 24 * time.Hour,
+	24 * time.Hour,
+	time.Hour,
+	time.Minute,
+	time.Second,
+}
+
+// ParseRateWindow parses a rate window expressed either as a Go duration string (e.g. "90s", "1h30m")
+// or as an ISO 8601 duration (e.g. "PT90S", "P1W", "P1M"). The window must be a positive number of whole seconds.
+func ParseRateWindow(window string) (time.Duration, error) {
+	var duration time.Duration
+
+	if strings.HasPrefix(window, "P") {
+		matches := iso8601DurationRegexp.FindStringSubmatch(window)
+		if matches == nil || window == "P" || strings.HasSuffix(window, "T") {
+			return 0, fmt.Errorf("invalid window %q: not a valid ISO 8601 duration", window)
+		}
+		for idx, match := range matches[1:] {
+			if match == "" {
+				continue
+			}
+			value, err := strconv.Atoi(match)
+			if err != nil {
+				return 0, fmt.Errorf("invalid window %q: %w", window, err)
+			}
+			duration += time.Duration(value) * iso8601DurationUnits[idx]
+		}
+	} else {
+		var err error
+		if duration, err = time.ParseDuration(window); err != nil {
+			return 0, fmt.Errorf("invalid window %q: %w", window, err)
+		}
+	}
+
+	if duration < time.Second {
+		return 0, fmt.Errorf("invalid window %q: must be at least 1 second", window)
+	}
+	if duration%time.Second != 0 {
+		return 0, fmt.Errorf("invalid window %q: must be a whole number of seconds", window)
+	}
+
+	return duration, nil
+}
+
+// LimitMode defines whether a limit is enforced or only reported
+// +kubebuilder:validation:Enum:=enforce;report
+type LimitMode string
+
+const (
+	// EnforceLimitMode rejects requests that exceed the limit
+	EnforceLimitMode LimitMode = "enforce"
+	// ReportLimitMode counts requests against the limit but never rejects them
+	ReportLimitMode LimitMode = "report"
+)
+
+// CounterScope defines how the counters of the limits are shared across the gateways that enforce the policy
+// +kubebuilder:validation:Enum:=policy;gateway
+type CounterScope string
+
+const (
+	// PolicyCounterScope shares the counters of the limits across all the gateways that enforce the policy
+	PolicyCounterScope CounterScope = "policy"
+	// GatewayCounterScope keeps separate counters of the limits for each gateway that enforces the policy
+	GatewayCounterScope CounterScope = "gateway"
+)
+
 // Rate defines the actual rate limit that will be used when there is a match
+// +kubebuilder:validation:XValidation:rule="has(self.window) != (has(self.duration) || has(self.unit))",message="window is mutually exclusive with duration and unit"
+// +kubebuilder:validation:XValidation:rule="has(self.duration) == has(self.unit)",message="duration and unit must be set together"
 type Rate struct {
 	// Limit defines the max value allowed for a given period of time
+	// +kubebuilder:validation:Minimum=0
 	Limit int `json:"limit"`
 
 	// Duration defines the time period for which the Limit specified above applies.
-	Duration int `json:"duration"`
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Duration int `json:"duration,omitempty"`
 
 	// Duration defines the time uni
-	// Possible values are: "second", "minute", "hour", "day"
-	Unit TimeUnit `json:"unit"`
+	// Possible values are: "second", "minute", "hour", "day", "week", "month" (30 days)
+	// +optional
+	Unit TimeUnit `json:"unit,omitempty"`
+
+	// Window defines the time period for which the Limit specified above applies, as a duration string.
+	// Both Go duration strings (e.g. "90s", "1h30m") and ISO 8601 durations (e.g. "PT90S", "P1W", "P1M") are supported.
+	// Mutually exclusive with duration and unit.
+	// +optional
+	Window string `json:"window,omitempty"`
+}
+
+// WindowSeconds returns the length in seconds of the time period for which the rate applies
+func (r Rate) WindowSeconds() (int, error) {
+	if r.Window != "" {
+		if r.Duration != 0 || r.Unit != "" {
+			return 0, errors.New("window is mutually exclusive with duration and unit")
+		}
+		window, err := ParseRateWindow(r.Window)
+		if err != nil {
+			return 0, err
+		}
+		return int(window / time.Second), nil
+	}
+
+	unitSeconds, ok := TimeUnitSeconds[r.Unit]
+	if !ok {
+		return 0, fmt.Errorf("invalid unit %q", r.Unit)
+	}
+	if r.Duration <= 0 {
+		return 0, fmt.Errorf("invalid duration %d: must be a positive integer", r.Duration)
+	}
+	return r.Duration * unitSeconds, nil
 }
 
-// RouteSelector defines semantics for matching an HTTP request based on conditions
-// https://gateway-api.sigs.k8s.io/reference/spec/#gateway.networking.k8s.io/v1.HTTPRouteSpec
+// Validate checks the limit and the time window of the rate, so invalid rates are rejected rather than normalised
+func (r Rate) Validate() error {
+	if r.Limit < 0 {
+		return fmt.Errorf("invalid limit %d: must not be negative", r.Limit)
+	}
+	_, err := r.WindowSeconds()
+	return err
+}
+
+// WhenCondition defines a condition for a limit to be enforced.
+// A condition is either a pattern expression (selector, operator and value), a reference to a named pattern of the
+// policy (patternRef), or a group of those that must all match (allOf) or of which at least one must match (anyOf).
+// +kubebuilder:validation:XValidation:rule="[has(self.selector), has(self.patternRef), has(self.anyOf), has(self.allOf)].filter(x, x).size() == 1",message="exactly one of selector, patternRef, anyOf or allOf must be set"
 type WhenCondition struct {
 	// Selector defines one item from the well known selectors
 	// TODO Document properly "Well-known selector" https://github.com/Kuadrant/architecture/blob/main/rfcs/0001-rlp-v2.md#well-known-selectors
-	Selector ContextSelector `json:"selector"`
+	// +optional
+	Selector ContextSelector `json:"selector,omitempty"`
 
 	// The binary operator to be applied to the content fetched from the selector
 	// Possible values are: "eq" (equal to), "neq" (not equal to)
+	// +optional
+	Operator WhenConditionOperator `json:"operator,omitempty"`
+
+	// The value of reference for the comparison.
+	// +optional
+	Value string `json:"value,omitempty"`
+
+	// PatternRef is the name of a named pattern of the policy whose expressions must all match.
+	// +optional
+	PatternRef string `json:"patternRef,omitempty"`
+
+	// AnyOf holds a group of patterns of which at least one must match.
+	// +optional
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=10
+	AnyOf []WhenPattern `json:"anyOf,omitempty"`
+
+	// AllOf holds a group of patterns that must all match.
+	// +optional
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=10
+	AllOf []WhenPattern `json:"allOf,omitempty"`
+}
+
+// WhenPattern is either a pattern expression (selector, operator and value) or a reference to a named pattern of the policy
+// +kubebuilder:validation:XValidation:rule="has(self.selector) != has(self.patternRef)",message="exactly one of selector or patternRef must be set"
+type WhenPattern struct {
+	// Selector defines one item from the well known selectors
+	// +optional
+	Selector ContextSelector `json:"selector,omitempty"`
+
+	// The binary operator to be applied to the content fetched from the selector
+	// +optional
+	Operator WhenConditionOperator `json:"operator,omitempty"`
+
+	// The value of reference for the comparison.
+	// +optional
+	Value string `json:"value,omitempty"`
+
+	// PatternRef is the name of a named pattern of the policy whose expressions must all match.
+	// +optional
+	PatternRef string `json:"patternRef,omitempty"`
+}
+
+// PatternExpression compares the value fetched from a well known selector with a value of reference
+type PatternExpression struct {
+	// Selector defines one item from the well known selectors
+	Selector ContextSelector `json:"selector"`
+
+	// The binary operator to be applied to the content fetched from the selector
 	Operator WhenConditionOperator `json:"operator"`
 
 	// The value of reference for the comparison.
 	Value string `json:"value"`
 }
 
+// PatternExpressions is a list of pattern expressions that must all match
+type PatternExpressions []PatternExpression
+
+// alternatives returns the lists of pattern expressions of which at least one must match for the condition to match
+func (c WhenCondition) alternatives(namedPatterns map[string]PatternExpressions) ([]PatternExpressions, error) {
+	set := 0
+	for _, isSet := range []bool{c.Selector != "", c.PatternRef != "", len(c.AnyOf) > 0, len(c.AllOf) > 0} {
+		if isSet {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, errors.New("exactly one of selector, patternRef, anyOf or allOf must be set")
+	}
+
+	switch {
+	case len(c.AnyOf) > 0:
+		alternatives := make([]PatternExpressions, 0, len(c.AnyOf))
+		for idx, pattern := range c.AnyOf {
+			expressions, err := pattern.expressions(namedPatterns)
+			if err != nil {
+				return nil, fmt.Errorf("invalid anyOf pattern %d: %w", idx, err)
+			}
+			alternatives = append(alternatives, expressions)
+		}
+		return alternatives, nil
+	case len(c.AllOf) > 0:
+		var all PatternExpressions
+		for idx, pattern := range c.AllOf {
+			expressions, err := pattern.expressions(namedPatterns)
+			if err != nil {
+				return nil, fmt.Errorf("invalid allOf pattern %d: %w", idx, err)
+			}
+			all = append(all, expressions...)
+		}
+		return []PatternExpressions{all}, nil
+	default:
+		pattern := WhenPattern{Selector: c.Selector, Operator: c.Operator, Value: c.Value, PatternRef: c.PatternRef}
+		expressions, err := pattern.expressions(namedPatterns)
+		if err != nil {
+			return nil, err
+		}
+		return []PatternExpressions{expressions}, nil
+	}
+}
+
+// expressions returns the pattern expressions of the pattern, resolving the reference to a named pattern
+func (p WhenPattern) expressions(namedPatterns map[string]PatternExpressions) (PatternExpressions, error) {
+	if (p.Selector != "") == (p.PatternRef != "") {
+		return nil, errors.New("exactly one of selector or patternRef must be set")
+	}
+	if p.PatternRef != "" {
+		pattern, ok := namedPatterns[p.PatternRef]
+		if !ok {
+			return nil, fmt.Errorf("unknown named pattern %q", p.PatternRef)
+		}
+		return pattern, nil
+	}
+	if p.Operator == "" {
+		return nil, fmt.Errorf("missing operator for selector %q", p.Selector)
+	}
+	return PatternExpressions{{Selector: p.Selector, Operator: p.Operator, Value: p.Value}}, nil
+}
+
 // Limit represents a complete rate limit configuration
 type Limit struct {
+	// ID is a stable identifier of the limit in the rate limit service.
+	// If not set, the identifier is derived from the name of the limit, so renaming the limit resets its counters.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_]+$`
+	ID string `json:"id,omitempty"`
+
 	// RouteSelectors defines semantics for matching an HTTP request based on conditions
 	// +optional
 	// +kubebuilder:validation:MaxItems=15
@@ -99,35 +361,362 @@ type Limit struct {
 
 	// When holds the list of conditions for the policy to be enforced.
 	// Called also "soft" conditions as route selectors must also match
+	// All the conditions must match, whereas `anyOf` groups conditions of which at least one must match.
+	// The conditions expand to at most 64 alternatives, i.e. the product of the sizes of the `anyOf` groups.
 	// +optional
+	// +kubebuilder:validation:MaxItems=15
 	When []WhenCondition `json:"when,omitempty"`
 
 	// Counters defines additional rate limit counters based on context qualifiers and well known selectors
 	// TODO Document properly "Well-known selector" https://github.com/Kuadrant/architecture/blob/main/rfcs/0001-rlp-v2.md#well-known-selectors
+	// Each counter is either a well known selector (string) or an object with selector, key and default fields.
 	// +optional
-	Counters []ContextSelector `json:"counters,omitempty"`
+	Counters []Counter `json:"counters,omitempty"`
 
 	// Rates holds the list of limit rates
 	// +optional
 	Rates []Rate `json:"rates,omitempty"`
+
+	// Mode defines whether the limit is enforced or only reported.
+	// In report mode, counters are incremented but requests are never rejected. Instead, the outcome
+	// of the rate limit check is exposed by the data plane.
+	// Possible values are: "enforce" (default), "report"
+	// +optional
+	// +kubebuilder:default:=enforce
+	Mode LimitMode `json:"mode,omitempty"`
+
+	// Response overrides the response returned to clients whose requests exceed the limit.
+	// If not set, the response defined at the level of the policy applies.
+	// +optional
+	Response *RateLimitedResponse `json:"response,omitempty"`
+
+	// Cost defines how much each request adds to the counters of the limit (hits addend).
+	// If not set, each request counts as 1.
+	// +optional
+	Cost *Cost `json:"cost,omitempty"`
+}
+
+// Cost defines the amount to add to the counters of a limit for each request, either as a static value
+// or resolved from a well known selector, e.g. a request header or a response attribute.
+// Precisely one of "value", "selector" must be set.
+// +kubebuilder:validation:XValidation:rule="has(self.value) != has(self.selector)",message="exactly one of value or selector must be set"
+type Cost struct {
+	// Value is a static amount to add to the counters
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Value *int `json:"value,omitempty"`
+
+	// Selector of an attribute whose resolved value is the amount to add to the counters
+	// +optional
+	Selector ContextSelector `json:"selector,omitempty"`
+}
+
+func (c *Cost) validate() error {
+	if c == nil {
+		return nil
+	}
+	if (c.Value != nil) == (c.Selector != "") {
+		return errors.New("invalid cost: exactly one of value or selector must be set")
+	}
+	if c.Value != nil && *c.Value <= 0 {
+		return fmt.Errorf("invalid cost value %d: must be a positive integer", *c.Value)
+	}
+	return nil
+}
+
+// RateLimitedResponse defines the response returned to clients whose requests are rate limited.
+// Header values and body can include templated placeholders in the form `{{selector}}`, where selector is a
+// well known selector resolved by the data plane, e.g. "Retry-After: {{ratelimit.reset}}".
+type RateLimitedResponse struct {
+	// Code is the HTTP status code of the response.
+	// +optional
+	// +kubebuilder:default:=429
+	// +kubebuilder:validation:Minimum=400
+	// +kubebuilder:validation:Maximum=599
+	Code int32 `json:"code,omitempty"`
+
+	// Headers to add to the response
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Body of the response
+	// +optional
+	Body string `json:"body,omitempty"`
+}
+
+func (r *RateLimitedResponse) validate() error {
+	if r == nil {
+		return nil
+	}
+	if r.Code != 0 && (r.Code < 400 || r.Code > 599) {
+		return fmt.Errorf("invalid response code %d: must be a 4xx or 5xx status code", r.Code)
+	}
+	for name, value := range r.Headers {
+		if !httpguts.ValidHeaderFieldName(name) {
+			return fmt.Errorf("invalid response header name %q", name)
+		}
+		if err := validateResponseTemplate(value); err != nil {
+			return fmt.Errorf("invalid response header %s: %w", name, err)
+		}
+	}
+	if err := validateResponseTemplate(r.Body); err != nil {
+		return fmt.Errorf("invalid response body: %w", err)
+	}
+	return nil
+}
+
+// validateResponseTemplate checks that all the placeholders of a template are closed and not empty
+func validateResponseTemplate(template string) error {
+	for rest := template; ; {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			return nil
+		}
+		end := strings.Index(rest[start:], "}}")
+		if end < 0 {
+			return fmt.Errorf("unclosed placeholder in %q", template)
+		}
+		if strings.TrimSpace(rest[start+2:start+end]) == "" {
+			return fmt.Errorf("empty placeholder in %q", template)
+		}
+		rest = rest[start+end+2:]
+	}
+}
+
+// IsReportMode returns true if the limit is configured to only report, and not enforce, the rate limit
+func (l Limit) IsReportMode() bool {
+	return l.Mode == ReportLimitMode
 }
 
+// MaxWhenPatterns is the maximum number of alternatives the `when` conditions of a limit can expand to, each one
+// becoming a separate condition of the limit in the wasm configuration
+const MaxWhenPatterns = 64
+
+// WhenPatterns returns the `when` conditions of the limit in disjunctive normal form, i.e. the lists of pattern
+// expressions of which at least one must match entirely for the limit to be enforced, with the references to named
+// patterns resolved. It returns nil if the limit has no `when` conditions, and an error if the conditions expand to
+// more than MaxWhenPatterns alternatives.
+func (l Limit) WhenPatterns(namedPatterns map[string]PatternExpressions) ([]PatternExpressions, error) {
+	if len(l.When) == 0 {
+		return nil, nil
+	}
+	patterns := []PatternExpressions{{}}
+	for idx, condition := range l.When {
+		alternatives, err := condition.alternatives(namedPatterns)
+		if err != nil {
+			return nil, fmt.Errorf("invalid when condition %d: %w", idx, err)
+		}
+		if len(patterns)*len(alternatives) > MaxWhenPatterns {
+			return nil, fmt.Errorf("when conditions expand to more than %d alternatives", MaxWhenPatterns)
+		}
+		// cross product of the alternatives so far with the ones of the condition
+		next := make([]PatternExpressions, 0, len(patterns)*len(alternatives))
+		for _, pattern := range patterns {
+			for _, alternative := range alternatives {
+				next = append(next, append(slices.Clone(pattern), alternative...))
+			}
+		}
+		patterns = next
+	}
+	return patterns, nil
+}
+
+// CountersAsStringList returns the descriptor keys of the counters of the limit
 func (l Limit) CountersAsStringList() []string {
 	if len(l.Counters) == 0 {
 		return nil
 	}
-	return utils.Map(l.Counters, func(counter ContextSelector) string { return string(counter) })
+	return utils.Map(l.Counters, func(counter Counter) string { return counter.DescriptorKey() })
+}
+
+// The schema of the counter is untyped, so the length bounds only apply to the string form and the required
+// selector and the bounded number of properties only apply to the object form. Untyped schemas are not visible
+// to CEL validation rules, hence unknown fields of the object form are rejected by the validating webhook.
+
+// Counter defines a rate limit counter qualified by the value of a well known selector.
+// For backward compatibility, a counter can also be expressed as a plain selector string.
+// +kubebuilder:validation:Schemaless
+// +kubebuilder:validation:Type=""
+// +kubebuilder:validation:XPreserveUnknownFields
+// +kubebuilder:validation:MinLength=1
+// +kubebuilder:validation:MaxLength=253
+// +kubebuilder:validation:MaxProperties=3
+type Counter struct {
+	// Selector of the attribute whose value qualifies the counter
+	Selector ContextSelector `json:"selector"`
+
+	// Key is the name of the descriptor entry generated for the counter.
+	// If not set, it defaults to the selector.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	// +optional
+	Key *string `json:"key,omitempty"`
+
+	// Default is the value to use when the selector is not found in the request context.
+	// If not set and the selector is not found, the limit does not apply to the request.
+	// +optional
+	Default *string `json:"default,omitempty"`
+}
+
+// DescriptorKey returns the key of the descriptor entry generated for the counter
+func (c Counter) DescriptorKey() string {
+	if c.Key != nil {
+		return *c.Key
+	}
+	return string(c.Selector)
+}
+
+// UnmarshalJSON accepts both the plain selector string and the object forms
+func (c *Counter) UnmarshalJSON(data []byte) error {
+	var selector string
+	if err := json.Unmarshal(data, &selector); err == nil {
+		*c = Counter{Selector: ContextSelector(selector)}
+		return nil
+	}
+
+	type counter Counter
+	var obj counter
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return fmt.Errorf("counter must be either a selector string or an object: %w", err)
+	}
+	*c = Counter(obj)
+	return nil
+}
+
+// CounterFields are the fields of the object form of a counter
+var CounterFields = []string{"selector", "key", "default"}
+
+// MarshalJSON serializes counters with only a selector in the plain string form
+func (c Counter) MarshalJSON() ([]byte, error) {
+	if c.Key == nil && c.Default == nil {
+		return json.Marshal(string(c.Selector))
+	}
+	type counter Counter
+	return json.Marshal(counter(c))
+}
+
+func (c Counter) validate() error {
+	if len(c.Selector) == 0 || len(c.Selector) > 253 {
+		return fmt.Errorf("invalid counter selector %q: must be between 1 and 253 characters", c.Selector)
+	}
+	if c.Key != nil && (len(*c.Key) == 0 || len(*c.Key) > 253) {
+		return fmt.Errorf("invalid counter key %q: must be between 1 and 253 characters", *c.Key)
+	}
+	return nil
+}
+
+func (l Limit) validate() error {
+	keys := make(map[string]struct{}, len(l.Counters))
+	for _, counter := range l.Counters {
+		if err := counter.validate(); err != nil {
+			return err
+		}
+		key := counter.DescriptorKey()
+		if _, ok := keys[key]; ok {
+			return fmt.Errorf("duplicate counter key %q", key)
+		}
+		keys[key] = struct{}{}
+	}
+	for idx, rate := range l.Rates {
+		if err := rate.Validate(); err != nil {
+			return fmt.Errorf("invalid rate %d: %w", idx, err)
+		}
+	}
+	if err := l.Cost.validate(); err != nil {
+		return err
+	}
+	return l.Response.validate()
+}
+
+// PlanLimitNamePrefix prefixes the names of the limits expanded from the plans of a policy
+const PlanLimitNamePrefix = "plans."
+
+// Plans defines rate limits per plan (tier) of the identities, e.g. free, silver and gold.
+// The plan of a request is the value of an identity attribute, typically exposed by the AuthPolicy in the
+// success response or dynamic metadata. Requests whose plan does not match any of the tiers are not limited by the plans.
+type Plans struct {
+	// Selector of the attribute whose value is the plan of the request,
+	// e.g. "metadata.filter_metadata.envoy\.filters\.http\.ext_authz.identity.plan"
+	Selector ContextSelector `json:"selector"`
+
+	// Counters qualify the counters of all the plans, e.g. by the identity so each identity gets its own quota.
+	// If not set, all the requests of a plan share the same counters.
+	// +optional
+	Counters []Counter `json:"counters,omitempty"`
+
+	// Tiers holds the rates of each plan, indexed by the value of the selector that identifies the plan
+	// +kubebuilder:validation:MinProperties=1
+	// +kubebuilder:validation:MaxProperties=14
+	Tiers map[string]Plan `json:"tiers"`
+}
+
+// Plan defines the rates of a plan
+type Plan struct {
+	// Rates holds the list of rates of the plan
+	// +kubebuilder:validation:MinItems=1
+	Rates []Rate `json:"rates"`
+}
+
+// PlanLimitName returns the name of the limit expanded from a tier of the plans
+func PlanLimitName(tier string) string {
+	return PlanLimitNamePrefix + tier
+}
+
+// Limits expands the plans into one limit per tier, conditioned to the value of the selector of the plans
+func (p *Plans) Limits() map[string]Limit {
+	if p == nil {
+		return nil
+	}
+	limits := make(map[string]Limit, len(p.Tiers))
+	for tier, plan := range p.Tiers {
+		limits[PlanLimitName(tier)] = Limit{
+			When:     []WhenCondition{{Selector: p.Selector, Operator: EqualOperator, Value: tier}},
+			Counters: p.Counters,
+			Rates:    plan.Rates,
+		}
+	}
+	return limits
+}
+
+func (p *Plans) validate() error {
+	if p == nil {
+		return nil
+	}
+	if len(p.Selector) == 0 || len(p.Selector) > 253 {
+		return fmt.Errorf("invalid selector %q: must be between 1 and 253 characters", p.Selector)
+	}
+	if len(p.Tiers) == 0 {
+		return errors.New("at least one tier must be defined")
+	}
+	tiers := make([]string, 0, len(p.Tiers))
+	for tier := range p.Tiers {
+		tiers = append(tiers, tier)
+	}
+	slices.Sort(tiers)
+	for _, tier := range tiers {
+		if len(p.Tiers[tier].Rates) == 0 {
+			return fmt.Errorf("invalid tier %s: at least one rate must be defined", tier)
+		}
+	}
+	return nil
 }
 
 // RateLimitPolicySpec defines the desired state of RateLimitPolicy
 // +kubebuilder:validation:XValidation:rule="self.targetRef.kind != 'Gateway' || !has(self.limits) || !self.limits.exists(x, has(self.limits[x].routeSelectors))",message="route selectors not supported when targeting a Gateway"
-// +kubebuilder:validation:XValidation:rule="!(has(self.defaults) && has(self.limits))",message="Implicit and explicit defaults are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!(has(self.defaults) && (has(self.limits) || has(self.response) || has(self.plans) || has(self.patterns)))",message="Implicit and explicit defaults are mutually exclusive"
 type RateLimitPolicySpec struct {
 	// TargetRef identifies an API object to apply policy to.
 	// +kubebuilder:validation:XValidation:rule="self.group == 'gateway.networking.k8s.io'",message="Invalid targetRef.group. The only supported value is 'gateway.networking.k8s.io'"
 	// +kubebuilder:validation:XValidation:rule="self.kind == 'HTTPRoute' || self.kind == 'Gateway'",message="Invalid targetRef.kind. The only supported values are 'HTTPRoute' and 'Gateway'"
 	TargetRef gatewayapiv1alpha2.PolicyTargetReference `json:"targetRef"`
 
+	// CounterScope defines whether the counters of the limits are shared across all the gateways that enforce the policy
+	// or kept separate for each gateway, e.g. for a route attached to an internal and an external gateway.
+	// Possible values are: "policy" (default), "gateway"
+	// +optional
+	// +kubebuilder:default:=policy
+	CounterScope CounterScope `json:"counterScope,omitempty"`
+
 	// Defaults define explicit default values for this policy and for policies inheriting this policy.
 	// Defaults are mutually exclusive with implicit defaults defined by RateLimitPolicyCommonSpec.
 	// +optional
@@ -144,6 +733,35 @@ type RateLimitPolicyCommonSpec struct {
 	// +optional
 	// +kubebuilder:validation:MaxProperties=14
 	Limits map[string]Limit `json:"limits,omitempty"`
+
+	// Named sets of patterns that can be referred in the `when` conditions of the limits.
+	// +optional
+	NamedPatterns map[string]PatternExpressions `json:"patterns,omitempty"`
+
+	// Plans defines rate limits per plan (tier) of the identities.
+	// Each tier is expanded into a limit named after the tier with the "plans." prefix.
+	// +optional
+	Plans *Plans `json:"plans,omitempty"`
+
+	// Response defines the response returned to clients whose requests are rate limited by any of the limits.
+	// If not set, the data plane returns 429 Too Many Requests.
+	// +optional
+	Response *RateLimitedResponse `json:"response,omitempty"`
+}
+
+// LimitsWithPlans returns the limits of the policy, including the limits expanded from the plans
+func (c *RateLimitPolicyCommonSpec) LimitsWithPlans() map[string]Limit {
+	if c.Plans == nil {
+		return c.Limits
+	}
+	limits := make(map[string]Limit, len(c.Limits)+len(c.Plans.Tiers))
+	for name, limit := range c.Limits {
+		limits[name] = limit
+	}
+	for name, limit := range c.Plans.Limits() {
+		limits[name] = limit
+	}
+	return limits
 }
 
 // RateLimitPolicyStatus defines the observed state of RateLimitPolicy
@@ -159,6 +777,55 @@ type RateLimitPolicyStatus struct {
 	// +listType=map
 	// +listMapKey=type
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+
+	// Ancestors is a list of the gateways (and listeners) affected by the policy,
+	// with the status of the policy with respect to each of them.
+	// See https://gateway-api.sigs.k8s.io/geps/gep-713/#standard-status-struct
+	// +optional
+	// +kubebuilder:validation:MaxItems=16
+	Ancestors []gatewayapiv1alpha2.PolicyAncestorStatus `json:"ancestors,omitempty"`
+
+	// ReportModeLimits is the sorted list of names of the limits of the policy in report mode,
+	// i.e. limits whose counters are incremented but that never reject requests.
+	// +optional
+	ReportModeLimits []string `json:"reportModeLimits,omitempty"`
+
+	// Usage reports the counters with the highest usage of each limit, as observed in the rate limit service.
+	// Only the 14 limits with the highest usage are reported.
+	// Only populated when the usage poller is enabled, which is the only writer of the field.
+	// +optional
+	// +kubebuilder:validation:MaxItems=14
+	Usage []LimitUsage `json:"usage,omitempty"`
+}
+
+// LimitUsage reports the usage of the counters of a limit
+type LimitUsage struct {
+	// Name of the limit
+	Name string `json:"name"`
+
+	// Counters of the limit with the highest usage, sorted by usage in descending order
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	Counters []CounterUsage `json:"counters,omitempty"`
+}
+
+// CounterUsage reports the usage of a counter of a limit in the current time window
+type CounterUsage struct {
+	// Namespace of the counter in the rate limit service
+	Namespace string `json:"namespace"`
+
+	// Variables holds the values of the counter qualifiers
+	// +optional
+	Variables map[string]string `json:"variables,omitempty"`
+
+	// MaxValue is the max value allowed within the time window
+	MaxValue int `json:"maxValue"`
+
+	// Seconds is the duration of the time window
+	Seconds int `json:"seconds"`
+
+	// Remaining is the remaining quota within the current time window
+	Remaining int `json:"remaining"`
 }
 
 func (s *RateLimitPolicyStatus) Equals(other *RateLimitPolicyStatus, logger logr.Logger) bool {
@@ -179,11 +846,26 @@ func (s *RateLimitPolicyStatus) Equals(other *RateLimitPolicyStatus, logger logr
 		return false
 	}
 
+	if !equality.Semantic.DeepEqual(s.Ancestors, other.Ancestors) {
+		if logger.V(1).Enabled() {
+			diff := cmp.Diff(s.Ancestors, other.Ancestors)
+			logger.V(1).Info("Ancestors not equal", "difference", diff)
+		}
+		return false
+	}
+
+	if !slices.Equal(s.ReportModeLimits, other.ReportModeLimits) {
+		diff := cmp.Diff(s.ReportModeLimits, other.ReportModeLimits)
+		logger.V(1).Info("ReportModeLimits not equal", "difference", diff)
+		return false
+	}
+
 	return true
 }
 
 var _ kuadrant.Policy = &RateLimitPolicy{}
 var _ kuadrant.Referrer = &RateLimitPolicy{}
+var _ kuadrant.AncestorsGetter = &RateLimitPolicy{}
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
@@ -210,9 +892,68 @@ func (r *RateLimitPolicy) Validate() error {
 		return fmt.Errorf("invalid targetRef.Namespace %s. Currently only supporting references to the same namespace", *r.Spec.TargetRef.Namespace)
 	}
 
+	if err := r.Spec.CommonSpec().Response.validate(); err != nil {
+		return err
+	}
+
+	if err := r.Spec.CommonSpec().Plans.validate(); err != nil {
+		return fmt.Errorf("invalid plans: %w", err)
+	}
+	for name := range r.Spec.CommonSpec().Plans.Limits() {
+		if _, ok := r.Spec.CommonSpec().Limits[name]; ok {
+			return fmt.Errorf("invalid limit %s: name reserved for the limit of a plan", name)
+		}
+	}
+
+	namedPatterns := r.Spec.CommonSpec().NamedPatterns
+	for name, pattern := range namedPatterns {
+		if len(pattern) == 0 {
+			return fmt.Errorf("invalid named pattern %s: at least one pattern expression must be defined", name)
+		}
+	}
+
+	limits := r.Spec.CommonSpec().LimitsWithPlans()
+	limitNames := make([]string, 0, len(limits))
+	for name := range limits {
+		limitNames = append(limitNames, name)
+	}
+	slices.Sort(limitNames)
+	limitIDs := make(map[string]string, len(limits))
+	for _, name := range limitNames {
+		limit := limits[name]
+		if err := limit.validate(); err != nil {
+			return fmt.Errorf("invalid limit %s: %w", name, err)
+		}
+		if _, err := limit.WhenPatterns(namedPatterns); err != nil {
+			return fmt.Errorf("invalid limit %s: %w", name, err)
+		}
+		if limit.ID == "" {
+			continue
+		}
+		if other, ok := limitIDs[limit.ID]; ok {
+			return fmt.Errorf("invalid limit %s: id %q already used by limit %s", name, limit.ID, other)
+		}
+		limitIDs[limit.ID] = name
+	}
+
 	return nil
 }
 
+// ReportModeLimits returns the sorted names of the limits of the policy configured in report mode
+func (r *RateLimitPolicy) ReportModeLimits() []string {
+	names := make([]string, 0)
+	for name, limit := range r.Spec.CommonSpec().Limits {
+		if limit.IsReportMode() {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	slices.Sort(names)
+	return names
+}
+
 func (r *RateLimitPolicy) TargetKey() client.ObjectKey {
 	tmpNS := r.Namespace
 	if r.Spec.TargetRef.Namespace != nil {
@@ -269,6 +1010,10 @@ func (r *RateLimitPolicy) Kind() string {
 	return r.TypeMeta.Kind
 }
 
+func (r *RateLimitPolicy) GetAncestors() []gatewayapiv1alpha2.PolicyAncestorStatus {
+	return r.Status.Ancestors
+}
+
 func (r *RateLimitPolicy) BackReferenceAnnotationName() string {
 	return RateLimitPolicyBackReferenceAnnotationName
 }
@@ -3,11 +3,13 @@
 package v1beta2
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 
 	"gotest.tools/assert"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
 	gatewayapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 
@@ -62,6 +64,279 @@ func TestRateLimitPolicyValidation(t *testing.T) {
 			subT.Fatalf(`rlp.Validate() did not return expected error. Instead: %v`, err)
 		}
 	})
+
+	t.Run("Invalid - Duplicate counter key", func(subT *testing.T) {
+		rlp := testBuildBasicHTTPRouteRLP(name, func(policy *RateLimitPolicy) {
+			policy.Spec.Limits = map[string]Limit{
+				"l1": {
+					Counters: []Counter{
+						{Selector: "auth.identity.userid", Key: ptr.To("user")},
+						{Selector: "request.headers.x-user", Key: ptr.To("user")},
+					},
+				},
+			}
+		})
+		err := rlp.Validate()
+		assert.ErrorContains(subT, err, `invalid limit l1: duplicate counter key "user"`)
+	})
+
+	t.Run("Invalid - Empty counter key", func(subT *testing.T) {
+		rlp := testBuildBasicHTTPRouteRLP(name, func(policy *RateLimitPolicy) {
+			policy.Spec.Limits = map[string]Limit{
+				"l1": {Counters: []Counter{{Selector: "auth.identity.userid", Key: ptr.To("")}}},
+			}
+		})
+		assert.ErrorContains(subT, rlp.Validate(), "invalid counter key")
+	})
+
+	t.Run("Invalid - Response code", func(subT *testing.T) {
+		rlp := testBuildBasicHTTPRouteRLP(name, func(policy *RateLimitPolicy) {
+			policy.Spec.Response = &RateLimitedResponse{Code: 302}
+		})
+		assert.ErrorContains(subT, rlp.Validate(), "invalid response code 302: must be a 4xx or 5xx status code")
+	})
+
+	t.Run("Invalid - Limit response code", func(subT *testing.T) {
+		rlp := testBuildBasicHTTPRouteRLP(name, func(policy *RateLimitPolicy) {
+			policy.Spec.Limits = map[string]Limit{"l1": {Response: &RateLimitedResponse{Code: 200}}}
+		})
+		assert.ErrorContains(subT, rlp.Validate(), "invalid limit l1: invalid response code 200")
+	})
+
+	t.Run("Invalid - Response header template", func(subT *testing.T) {
+		rlp := testBuildBasicHTTPRouteRLP(name, func(policy *RateLimitPolicy) {
+			policy.Spec.Response = &RateLimitedResponse{Headers: map[string]string{"Retry-After": "{{ratelimit.reset"}}
+		})
+		assert.ErrorContains(subT, rlp.Validate(), "invalid response header Retry-After: unclosed placeholder")
+	})
+
+	t.Run("Invalid - Response header name", func(subT *testing.T) {
+		rlp := testBuildBasicHTTPRouteRLP(name, func(policy *RateLimitPolicy) {
+			policy.Spec.Response = &RateLimitedResponse{Headers: map[string]string{"Retry After": "60"}}
+		})
+		assert.ErrorContains(subT, rlp.Validate(), `invalid response header name "Retry After"`)
+	})
+
+	t.Run("Valid - Response", func(subT *testing.T) {
+		rlp := testBuildBasicHTTPRouteRLP(name, func(policy *RateLimitPolicy) {
+			policy.Spec.Response = &RateLimitedResponse{
+				Code:    429,
+				Headers: map[string]string{"Content-Type": "application/problem+json", "Retry-After": "{{ ratelimit.reset }}"},
+				Body:    `{"title":"Too Many Requests","status":429}`,
+			}
+			policy.Spec.Limits = map[string]Limit{"l1": {Response: &RateLimitedResponse{Code: 503}}}
+		})
+		assert.NilError(subT, rlp.Validate())
+	})
+
+	t.Run("Invalid - Cost value", func(subT *testing.T) {
+		rlp := testBuildBasicHTTPRouteRLP(name, func(policy *RateLimitPolicy) {
+			policy.Spec.Limits = map[string]Limit{"l1": {Cost: &Cost{Value: ptr.To(0)}}}
+		})
+		assert.ErrorContains(subT, rlp.Validate(), "invalid limit l1: invalid cost value 0: must be a positive integer")
+	})
+
+	t.Run("Invalid - Cost with both value and selector", func(subT *testing.T) {
+		rlp := testBuildBasicHTTPRouteRLP(name, func(policy *RateLimitPolicy) {
+			policy.Spec.Limits = map[string]Limit{"l1": {Cost: &Cost{Value: ptr.To(2), Selector: "request.size"}}}
+		})
+		assert.ErrorContains(subT, rlp.Validate(), "exactly one of value or selector must be set")
+	})
+
+	t.Run("Invalid - Empty cost", func(subT *testing.T) {
+		rlp := testBuildBasicHTTPRouteRLP(name, func(policy *RateLimitPolicy) {
+			policy.Spec.Limits = map[string]Limit{"l1": {Cost: &Cost{}}}
+		})
+		assert.ErrorContains(subT, rlp.Validate(), "exactly one of value or selector must be set")
+	})
+
+	t.Run("Valid - Cost", func(subT *testing.T) {
+		rlp := testBuildBasicHTTPRouteRLP(name, func(policy *RateLimitPolicy) {
+			policy.Spec.Limits = map[string]Limit{
+				"static":   {Cost: &Cost{Value: ptr.To(5)}},
+				"selector": {Cost: &Cost{Selector: "request.headers.x-tokens"}},
+			}
+		})
+		assert.NilError(subT, rlp.Validate())
+	})
+
+	t.Run("Invalid - Duplicate limit id", func(subT *testing.T) {
+		rlp := testBuildBasicHTTPRouteRLP(name, func(policy *RateLimitPolicy) {
+			policy.Spec.Limits = map[string]Limit{
+				"a": {ID: "per_user"},
+				"b": {ID: "per_user"},
+			}
+		})
+		assert.ErrorContains(subT, rlp.Validate(), `invalid limit b: id "per_user" already used by limit a`)
+	})
+
+	t.Run("Invalid - Negative rate limit", func(subT *testing.T) {
+		rlp := testBuildBasicHTTPRouteRLP(name, func(policy *RateLimitPolicy) {
+			policy.Spec.Limits = map[string]Limit{"l1": {Rates: []Rate{{Limit: -1, Window: "1m"}}}}
+		})
+		assert.ErrorContains(subT, rlp.Validate(), "invalid limit l1: invalid rate 0: invalid limit -1: must not be negative")
+	})
+
+	t.Run("Invalid - Rate window", func(subT *testing.T) {
+		rlp := testBuildBasicHTTPRouteRLP(name, func(policy *RateLimitPolicy) {
+			policy.Spec.Limits = map[string]Limit{"l1": {Rates: []Rate{{Limit: 1, Window: "forever"}}}}
+		})
+		assert.ErrorContains(subT, rlp.Validate(), `invalid limit l1: invalid rate 0: invalid window "forever"`)
+	})
+
+	t.Run("Invalid - Plan without rates", func(subT *testing.T) {
+		rlp := testBuildBasicHTTPRouteRLP(name, func(policy *RateLimitPolicy) {
+			policy.Spec.Plans = &Plans{
+				Selector: "auth.identity.plan",
+				Tiers: map[string]Plan{
+					"free": {Rates: []Rate{{Limit: 10, Window: "1m"}}},
+					"gold": {},
+				},
+			}
+		})
+		assert.ErrorContains(subT, rlp.Validate(), "invalid plans: invalid tier gold: at least one rate must be defined")
+	})
+
+	t.Run("Invalid - Plans without tiers", func(subT *testing.T) {
+		rlp := testBuildBasicHTTPRouteRLP(name, func(policy *RateLimitPolicy) {
+			policy.Spec.Plans = &Plans{Selector: "auth.identity.plan"}
+		})
+		assert.ErrorContains(subT, rlp.Validate(), "invalid plans: at least one tier must be defined")
+	})
+
+	t.Run("Invalid - Limit named as a plan", func(subT *testing.T) {
+		rlp := testBuildBasicHTTPRouteRLP(name, func(policy *RateLimitPolicy) {
+			policy.Spec.Limits = map[string]Limit{"plans.free": {Rates: []Rate{{Limit: 1, Window: "1m"}}}}
+			policy.Spec.Plans = &Plans{
+				Selector: "auth.identity.plan",
+				Tiers:    map[string]Plan{"free": {Rates: []Rate{{Limit: 10, Window: "1m"}}}},
+			}
+		})
+		assert.ErrorContains(subT, rlp.Validate(), "invalid limit plans.free: name reserved for the limit of a plan")
+	})
+
+	t.Run("Invalid - Plan rate window", func(subT *testing.T) {
+		rlp := testBuildBasicHTTPRouteRLP(name, func(policy *RateLimitPolicy) {
+			policy.Spec.Plans = &Plans{
+				Selector: "auth.identity.plan",
+				Tiers:    map[string]Plan{"free": {Rates: []Rate{{Limit: 10, Window: "forever"}}}},
+			}
+		})
+		assert.ErrorContains(subT, rlp.Validate(), `invalid limit plans.free: invalid rate 0: invalid window "forever"`)
+	})
+
+	t.Run("Valid - Plans", func(subT *testing.T) {
+		rlp := testBuildBasicHTTPRouteRLP(name, func(policy *RateLimitPolicy) {
+			policy.Spec.Plans = &Plans{
+				Selector: "auth.identity.plan",
+				Counters: []Counter{{Selector: "auth.identity.userid"}},
+				Tiers: map[string]Plan{
+					"free": {Rates: []Rate{{Limit: 10, Window: "1m"}}},
+					"gold": {Rates: []Rate{{Limit: 100, Window: "1m"}, {Limit: 10000, Window: "P1D"}}},
+				},
+			}
+		})
+		assert.NilError(subT, rlp.Validate())
+	})
+
+	t.Run("Invalid - Unknown named pattern", func(subT *testing.T) {
+		rlp := testBuildBasicHTTPRouteRLP(name, func(policy *RateLimitPolicy) {
+			policy.Spec.Limits = map[string]Limit{"l1": {When: []WhenCondition{{AnyOf: []WhenPattern{{PatternRef: "tenants"}}}}}}
+		})
+		assert.ErrorContains(subT, rlp.Validate(), `invalid limit l1: invalid when condition 0: invalid anyOf pattern 0: unknown named pattern "tenants"`)
+	})
+
+	t.Run("Invalid - Ambiguous when condition", func(subT *testing.T) {
+		rlp := testBuildBasicHTTPRouteRLP(name, func(policy *RateLimitPolicy) {
+			policy.Spec.Limits = map[string]Limit{
+				"l1": {When: []WhenCondition{{Selector: "auth.identity.tenant", Operator: EqualOperator, Value: "a", AnyOf: []WhenPattern{{Selector: "auth.identity.tenant", Operator: EqualOperator, Value: "b"}}}}},
+			}
+		})
+		assert.ErrorContains(subT, rlp.Validate(), "invalid limit l1: invalid when condition 0: exactly one of selector, patternRef, anyOf or allOf must be set")
+	})
+
+	t.Run("Invalid - Too many when alternatives", func(subT *testing.T) {
+		rlp := testBuildBasicHTTPRouteRLP(name, func(policy *RateLimitPolicy) {
+			anyOf := WhenCondition{AnyOf: []WhenPattern{
+				{Selector: "auth.identity.tenant", Operator: EqualOperator, Value: "a"},
+				{Selector: "auth.identity.tenant", Operator: EqualOperator, Value: "b"},
+			}}
+			var when []WhenCondition
+			for i := 0; i < 7; i++ {
+				when = append(when, anyOf)
+			}
+			policy.Spec.Limits = map[string]Limit{"l1": {When: when}}
+		})
+		assert.ErrorContains(subT, rlp.Validate(), "invalid limit l1: when conditions expand to more than 64 alternatives")
+	})
+
+	t.Run("Invalid - Empty named pattern", func(subT *testing.T) {
+		rlp := testBuildBasicHTTPRouteRLP(name, func(policy *RateLimitPolicy) {
+			policy.Spec.NamedPatterns = map[string]PatternExpressions{"tenants": {}}
+		})
+		assert.ErrorContains(subT, rlp.Validate(), "invalid named pattern tenants: at least one pattern expression must be defined")
+	})
+
+	t.Run("Valid - Named patterns", func(subT *testing.T) {
+		rlp := testBuildBasicHTTPRouteRLP(name, func(policy *RateLimitPolicy) {
+			policy.Spec.NamedPatterns = map[string]PatternExpressions{
+				"internal": {{Selector: "source.address", Operator: MatchesOperator, Value: "^10\\."}},
+			}
+			policy.Spec.Limits = map[string]Limit{
+				"l1": {When: []WhenCondition{{PatternRef: "internal"}}},
+				"l2": {When: []WhenCondition{{AnyOf: []WhenPattern{{PatternRef: "internal"}, {Selector: "auth.identity.tenant", Operator: EqualOperator, Value: "a"}}}}},
+			}
+		})
+		assert.NilError(subT, rlp.Validate())
+	})
+
+	t.Run("Valid - Counters", func(subT *testing.T) {
+		rlp := testBuildBasicHTTPRouteRLP(name, func(policy *RateLimitPolicy) {
+			policy.Spec.Limits = map[string]Limit{
+				"l1": {
+					Counters: []Counter{
+						{Selector: "auth.identity.userid", Key: ptr.To("user"), Default: ptr.To("anonymous")},
+						{Selector: "request.path"},
+					},
+				},
+			}
+		})
+		assert.NilError(subT, rlp.Validate())
+	})
+}
+
+func TestCounterJSON(t *testing.T) {
+	t.Run("string form", func(subT *testing.T) {
+		var counter Counter
+		assert.NilError(subT, json.Unmarshal([]byte(`"request.path"`), &counter))
+		assert.DeepEqual(subT, counter, Counter{Selector: "request.path"})
+
+		data, err := json.Marshal(counter)
+		assert.NilError(subT, err)
+		assert.Equal(subT, string(data), `"request.path"`)
+	})
+
+	t.Run("object form", func(subT *testing.T) {
+		var counter Counter
+		assert.NilError(subT, json.Unmarshal([]byte(`{"selector":"auth.identity.userid","key":"user","default":"anonymous"}`), &counter))
+		assert.DeepEqual(subT, counter, Counter{Selector: "auth.identity.userid", Key: ptr.To("user"), Default: ptr.To("anonymous")})
+		assert.Equal(subT, counter.DescriptorKey(), "user")
+
+		data, err := json.Marshal(counter)
+		assert.NilError(subT, err)
+		assert.Equal(subT, string(data), `{"selector":"auth.identity.userid","key":"user","default":"anonymous"}`)
+	})
+
+	t.Run("mixed list", func(subT *testing.T) {
+		var limit Limit
+		assert.NilError(subT, json.Unmarshal([]byte(`{"counters":["request.path",{"selector":"auth.identity.userid","key":"user"}]}`), &limit))
+		assert.DeepEqual(subT, limit.CountersAsStringList(), []string{"request.path", "user"})
+	})
+
+	t.Run("invalid", func(subT *testing.T) {
+		var counter Counter
+		assert.ErrorContains(subT, json.Unmarshal([]byte(`42`), &counter), "counter must be either a selector string or an object")
+	})
 }
 
 func TestRateLimitPolicyListGetItems(t *testing.T) {
@@ -124,3 +399,163 @@ func TestRateLimitPolicy_GetLimits(t *testing.T) {
 		assert.DeepEqual(subT, r.Spec.CommonSpec().Limits, defaultLimits)
 	})
 }
+
+func TestRateLimitPolicy_ReportModeLimits(t *testing.T) {
+	t.Run("No limits in report mode", func(subT *testing.T) {
+		r := testBuildBasicHTTPRouteRLP("policy", func(policy *RateLimitPolicy) {
+			policy.Spec.Limits = map[string]Limit{
+				"a": {Mode: EnforceLimitMode},
+				"b": {},
+			}
+		})
+		assert.Assert(subT, r.ReportModeLimits() == nil)
+	})
+
+	t.Run("Limits in report mode", func(subT *testing.T) {
+		r := testBuildBasicHTTPRouteRLP("policy", func(policy *RateLimitPolicy) {
+			policy.Spec.Defaults = &RateLimitPolicyCommonSpec{
+				Limits: map[string]Limit{
+					"c": {Mode: ReportLimitMode},
+					"b": {Mode: EnforceLimitMode},
+					"a": {Mode: ReportLimitMode},
+				},
+			}
+		})
+		assert.DeepEqual(subT, r.ReportModeLimits(), []string{"a", "c"})
+	})
+}
+
+func TestRateWindowSeconds(t *testing.T) {
+	testCases := []struct {
+		name            string
+		rate            Rate
+		expectedSeconds int
+		expectedErr     string
+	}{
+		{name: "duration and unit", rate: Rate{Limit: 1, Duration: 2, Unit: "minute"}, expectedSeconds: 120},
+		{name: "week", rate: Rate{Limit: 1, Duration: 1, Unit: "week"}, expectedSeconds: 7 * 24 * 3600},
+		{name: "month", rate: Rate{Limit: 1, Duration: 1, Unit: "month"}, expectedSeconds: 30 * 24 * 3600},
+		{name: "go duration", rate: Rate{Limit: 1, Window: "90s"}, expectedSeconds: 90},
+		{name: "compound go duration", rate: Rate{Limit: 1, Window: "1h30m"}, expectedSeconds: 5400},
+		{name: "iso 8601 seconds", rate: Rate{Limit: 1, Window: "PT90S"}, expectedSeconds: 90},
+		{name: "iso 8601 week", rate: Rate{Limit: 1, Window: "P1W"}, expectedSeconds: 7 * 24 * 3600},
+		{name: "iso 8601 month", rate: Rate{Limit: 1, Window: "P1M"}, expectedSeconds: 30 * 24 * 3600},
+		{name: "iso 8601 compound", rate: Rate{Limit: 1, Window: "P1DT12H"}, expectedSeconds: 36 * 3600},
+		{name: "negative duration", rate: Rate{Limit: 1, Duration: -2, Unit: "second"}, expectedErr: "invalid duration -2"},
+		{name: "unknown unit", rate: Rate{Limit: 1, Duration: 2, Unit: "fortnight"}, expectedErr: `invalid unit "fortnight"`},
+		{name: "window and duration", rate: Rate{Limit: 1, Duration: 2, Unit: "second", Window: "2s"}, expectedErr: "window is mutually exclusive with duration and unit"},
+		{name: "sub-second window", rate: Rate{Limit: 1, Window: "500ms"}, expectedErr: "must be at least 1 second"},
+		{name: "fractional window", rate: Rate{Limit: 1, Window: "1.5s"}, expectedErr: "must be a whole number of seconds"},
+		{name: "negative window", rate: Rate{Limit: 1, Window: "-1m"}, expectedErr: "must be at least 1 second"},
+		{name: "invalid go duration", rate: Rate{Limit: 1, Window: "1 minute"}, expectedErr: `invalid window "1 minute"`},
+		{name: "invalid iso 8601 duration", rate: Rate{Limit: 1, Window: "PT"}, expectedErr: "not a valid ISO 8601 duration"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(subT *testing.T) {
+			seconds, err := tc.rate.WindowSeconds()
+			if tc.expectedErr != "" {
+				assert.ErrorContains(subT, err, tc.expectedErr)
+				return
+			}
+			assert.NilError(subT, err)
+			assert.Equal(subT, seconds, tc.expectedSeconds)
+		})
+	}
+}
+
+func TestRateLimitPolicyCommonSpec_LimitsWithPlans(t *testing.T) {
+	spec := &RateLimitPolicyCommonSpec{
+		Limits: map[string]Limit{"global": {Rates: []Rate{{Limit: 1000, Window: "1s"}}}},
+		Plans: &Plans{
+			Selector: "auth.identity.plan",
+			Counters: []Counter{{Selector: "auth.identity.userid"}},
+			Tiers: map[string]Plan{
+				"free": {Rates: []Rate{{Limit: 10, Window: "1m"}}},
+				"gold": {Rates: []Rate{{Limit: 100, Window: "1m"}}},
+			},
+		},
+	}
+
+	assert.DeepEqual(t, spec.LimitsWithPlans(), map[string]Limit{
+		"global": {Rates: []Rate{{Limit: 1000, Window: "1s"}}},
+		"plans.free": {
+			When:     []WhenCondition{{Selector: "auth.identity.plan", Operator: EqualOperator, Value: "free"}},
+			Counters: []Counter{{Selector: "auth.identity.userid"}},
+			Rates:    []Rate{{Limit: 10, Window: "1m"}},
+		},
+		"plans.gold": {
+			When:     []WhenCondition{{Selector: "auth.identity.plan", Operator: EqualOperator, Value: "gold"}},
+			Counters: []Counter{{Selector: "auth.identity.userid"}},
+			Rates:    []Rate{{Limit: 100, Window: "1m"}},
+		},
+	})
+
+	spec.Plans = nil
+	assert.Equal(t, len(spec.LimitsWithPlans()), 1)
+}
+
+func TestLimitWhenPatterns(t *testing.T) {
+	tenantA := PatternExpression{Selector: "auth.identity.tenant", Operator: EqualOperator, Value: "a"}
+	tenantB := PatternExpression{Selector: "auth.identity.tenant", Operator: EqualOperator, Value: "b"}
+	get := PatternExpression{Selector: "request.method", Operator: EqualOperator, Value: "GET"}
+	internal := PatternExpression{Selector: "source.address", Operator: MatchesOperator, Value: "^10\\."}
+	namedPatterns := map[string]PatternExpressions{"internal-get": {internal, get}}
+
+	whenPattern := func(p PatternExpression) WhenPattern {
+		return WhenPattern{Selector: p.Selector, Operator: p.Operator, Value: p.Value}
+	}
+	whenCondition := func(p PatternExpression) WhenCondition {
+		return WhenCondition{Selector: p.Selector, Operator: p.Operator, Value: p.Value}
+	}
+
+	testCases := []struct {
+		name     string
+		when     []WhenCondition
+		expected []PatternExpressions
+	}{
+		{
+			name: "no conditions",
+		},
+		{
+			name:     "conditions are ANDed",
+			when:     []WhenCondition{whenCondition(tenantA), whenCondition(get)},
+			expected: []PatternExpressions{{tenantA, get}},
+		},
+		{
+			name:     "anyOf",
+			when:     []WhenCondition{{AnyOf: []WhenPattern{whenPattern(tenantA), whenPattern(tenantB)}}, whenCondition(get)},
+			expected: []PatternExpressions{{tenantA, get}, {tenantB, get}},
+		},
+		{
+			name:     "allOf",
+			when:     []WhenCondition{{AllOf: []WhenPattern{whenPattern(tenantA), whenPattern(get)}}},
+			expected: []PatternExpressions{{tenantA, get}},
+		},
+		{
+			name:     "named pattern",
+			when:     []WhenCondition{{PatternRef: "internal-get"}},
+			expected: []PatternExpressions{{internal, get}},
+		},
+		{
+			name:     "anyOf with named pattern",
+			when:     []WhenCondition{{AnyOf: []WhenPattern{{PatternRef: "internal-get"}, whenPattern(tenantA)}}},
+			expected: []PatternExpressions{{internal, get}, {tenantA}},
+		},
+		{
+			name: "cross product of anyOf groups",
+			when: []WhenCondition{
+				{AnyOf: []WhenPattern{whenPattern(tenantA), whenPattern(tenantB)}},
+				{AnyOf: []WhenPattern{whenPattern(get), whenPattern(internal)}},
+			},
+			expected: []PatternExpressions{{tenantA, get}, {tenantA, internal}, {tenantB, get}, {tenantB, internal}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(subT *testing.T) {
+			patterns, err := Limit{When: tc.when}.WhenPatterns(namedPatterns)
+			assert.NilError(subT, err)
+			assert.DeepEqual(subT, patterns, tc.expected)
+		})
+	}
+}
@@ -25,8 +25,37 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	apisv1 "sigs.k8s.io/gateway-api/apis/v1"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuthConfigSummary) DeepCopyInto(out *AuthConfigSummary) {
+	*out = *in
+	if in.HostsReady != nil {
+		in, out := &in.HostsReady, &out.HostsReady
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.HostsNotReady != nil {
+		in, out := &in.HostsNotReady, &out.HostsNotReady
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Authentication.DeepCopyInto(&out.Authentication)
+	in.Metadata.DeepCopyInto(&out.Metadata)
+	in.Authorization.DeepCopyInto(&out.Authorization)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthConfigSummary.
+func (in *AuthConfigSummary) DeepCopy() *AuthConfigSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(AuthConfigSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AuthPolicy) DeepCopyInto(out *AuthPolicy) {
 	*out = *in
@@ -168,6 +197,18 @@ func (in *AuthPolicyStatus) DeepCopyInto(out *AuthPolicyStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Ancestors != nil {
+		in, out := &in.Ancestors, &out.Ancestors
+		*out = make([]v1alpha2.PolicyAncestorStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AuthConfig != nil {
+		in, out := &in.AuthConfig, &out.AuthConfig
+		*out = new(AuthConfigSummary)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthPolicyStatus.
@@ -180,6 +221,31 @@ func (in *AuthPolicyStatus) DeepCopy() *AuthPolicyStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuthRulesSummary) DeepCopyInto(out *AuthRulesSummary) {
+	*out = *in
+	if in.Accepted != nil {
+		in, out := &in.Accepted, &out.Accepted
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Failed != nil {
+		in, out := &in.Failed, &out.Failed
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthRulesSummary.
+func (in *AuthRulesSummary) DeepCopy() *AuthRulesSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(AuthRulesSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AuthSchemeSpec) DeepCopyInto(out *AuthSchemeSpec) {
 	*out = *in
@@ -301,6 +367,73 @@ func (in *CommonAuthRuleSpec) DeepCopy() *CommonAuthRuleSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Cost) DeepCopyInto(out *Cost) {
+	*out = *in
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Cost.
+func (in *Cost) DeepCopy() *Cost {
+	if in == nil {
+		return nil
+	}
+	out := new(Cost)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Counter) DeepCopyInto(out *Counter) {
+	*out = *in
+	if in.Key != nil {
+		in, out := &in.Key, &out.Key
+		*out = new(string)
+		**out = **in
+	}
+	if in.Default != nil {
+		in, out := &in.Default, &out.Default
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Counter.
+func (in *Counter) DeepCopy() *Counter {
+	if in == nil {
+		return nil
+	}
+	out := new(Counter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CounterUsage) DeepCopyInto(out *CounterUsage) {
+	*out = *in
+	if in.Variables != nil {
+		in, out := &in.Variables, &out.Variables
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CounterUsage.
+func (in *CounterUsage) DeepCopy() *CounterUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(CounterUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HeaderSuccessResponseSpec) DeepCopyInto(out *HeaderSuccessResponseSpec) {
 	*out = *in
@@ -330,18 +463,32 @@ func (in *Limit) DeepCopyInto(out *Limit) {
 	if in.When != nil {
 		in, out := &in.When, &out.When
 		*out = make([]WhenCondition, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Counters != nil {
 		in, out := &in.Counters, &out.Counters
-		*out = make([]ContextSelector, len(*in))
-		copy(*out, *in)
+		*out = make([]Counter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Rates != nil {
 		in, out := &in.Rates, &out.Rates
 		*out = make([]Rate, len(*in))
 		copy(*out, *in)
 	}
+	if in.Response != nil {
+		in, out := &in.Response, &out.Response
+		*out = new(RateLimitedResponse)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Cost != nil {
+		in, out := &in.Cost, &out.Cost
+		*out = new(Cost)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Limit.
@@ -354,6 +501,28 @@ func (in *Limit) DeepCopy() *Limit {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LimitUsage) DeepCopyInto(out *LimitUsage) {
+	*out = *in
+	if in.Counters != nil {
+		in, out := &in.Counters, &out.Counters
+		*out = make([]CounterUsage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LimitUsage.
+func (in *LimitUsage) DeepCopy() *LimitUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(LimitUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MetadataSpec) DeepCopyInto(out *MetadataSpec) {
 	*out = *in
@@ -371,6 +540,89 @@ func (in *MetadataSpec) DeepCopy() *MetadataSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PatternExpression) DeepCopyInto(out *PatternExpression) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatternExpression.
+func (in *PatternExpression) DeepCopy() *PatternExpression {
+	if in == nil {
+		return nil
+	}
+	out := new(PatternExpression)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in PatternExpressions) DeepCopyInto(out *PatternExpressions) {
+	{
+		in := &in
+		*out = make(PatternExpressions, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatternExpressions.
+func (in PatternExpressions) DeepCopy() PatternExpressions {
+	if in == nil {
+		return nil
+	}
+	out := new(PatternExpressions)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Plan) DeepCopyInto(out *Plan) {
+	*out = *in
+	if in.Rates != nil {
+		in, out := &in.Rates, &out.Rates
+		*out = make([]Rate, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Plan.
+func (in *Plan) DeepCopy() *Plan {
+	if in == nil {
+		return nil
+	}
+	out := new(Plan)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Plans) DeepCopyInto(out *Plans) {
+	*out = *in
+	if in.Counters != nil {
+		in, out := &in.Counters, &out.Counters
+		*out = make([]Counter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Tiers != nil {
+		in, out := &in.Tiers, &out.Tiers
+		*out = make(map[string]Plan, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Plans.
+func (in *Plans) DeepCopy() *Plans {
+	if in == nil {
+		return nil
+	}
+	out := new(Plans)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Rate) DeepCopyInto(out *Rate) {
 	*out = *in
@@ -423,6 +675,32 @@ func (in *RateLimitPolicyCommonSpec) DeepCopyInto(out *RateLimitPolicyCommonSpec
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.NamedPatterns != nil {
+		in, out := &in.NamedPatterns, &out.NamedPatterns
+		*out = make(map[string]PatternExpressions, len(*in))
+		for key, val := range *in {
+			var outVal []PatternExpression
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make(PatternExpressions, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.Plans != nil {
+		in, out := &in.Plans, &out.Plans
+		*out = new(Plans)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Response != nil {
+		in, out := &in.Response, &out.Response
+		*out = new(RateLimitedResponse)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateLimitPolicyCommonSpec.
@@ -499,6 +777,25 @@ func (in *RateLimitPolicyStatus) DeepCopyInto(out *RateLimitPolicyStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Ancestors != nil {
+		in, out := &in.Ancestors, &out.Ancestors
+		*out = make([]v1alpha2.PolicyAncestorStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ReportModeLimits != nil {
+		in, out := &in.ReportModeLimits, &out.ReportModeLimits
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Usage != nil {
+		in, out := &in.Usage, &out.Usage
+		*out = make([]LimitUsage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateLimitPolicyStatus.
@@ -511,6 +808,28 @@ func (in *RateLimitPolicyStatus) DeepCopy() *RateLimitPolicyStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RateLimitedResponse) DeepCopyInto(out *RateLimitedResponse) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateLimitedResponse.
+func (in *RateLimitedResponse) DeepCopy() *RateLimitedResponse {
+	if in == nil {
+		return nil
+	}
+	out := new(RateLimitedResponse)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResponseSpec) DeepCopyInto(out *ResponseSpec) {
 	*out = *in
@@ -584,6 +903,16 @@ func (in *SuccessResponseSpec) DeepCopy() *SuccessResponseSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WhenCondition) DeepCopyInto(out *WhenCondition) {
 	*out = *in
+	if in.AnyOf != nil {
+		in, out := &in.AnyOf, &out.AnyOf
+		*out = make([]WhenPattern, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllOf != nil {
+		in, out := &in.AllOf, &out.AllOf
+		*out = make([]WhenPattern, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WhenCondition.
@@ -596,6 +925,21 @@ func (in *WhenCondition) DeepCopy() *WhenCondition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WhenPattern) DeepCopyInto(out *WhenPattern) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WhenPattern.
+func (in *WhenPattern) DeepCopy() *WhenPattern {
+	if in == nil {
+		return nil
+	}
+	out := new(WhenPattern)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WrappedSuccessResponseSpec) DeepCopyInto(out *WrappedSuccessResponseSpec) {
 	*out = *in
@@ -0,0 +1,576 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/kuadrant/kuadrant-operator/api/v1alpha1"
+	api "github.com/kuadrant/kuadrant-operator/api/v1beta2"
+	"github.com/kuadrant/kuadrant-operator/pkg/library/reconcilers"
+	"github.com/kuadrant/kuadrant-operator/pkg/library/utils"
+)
+
+const (
+	// authorinoSecretLabel is the label of the Secrets watched by Authorino by default
+	authorinoSecretLabel      = "authorino.kuadrant.io/managed-by"
+	authorinoSecretLabelValue = "authorino"
+
+	apiKeyLength = 32
+
+	// defaultAPIKeyRotationGracePeriod is how long the previous key remains valid after a rotation, if not specified
+	defaultAPIKeyRotationGracePeriod = 5 * time.Minute
+)
+
+// errAPIKeySecretConflict signals a Secret with the name of the Secret of a key exists and is not owned by the APIKey
+var errAPIKeySecretConflict = errors.New("secret not owned by the apikey")
+
+// APIKeyReconciler reconciles an APIKey object
+type APIKeyReconciler struct {
+	*reconcilers.BaseReconciler
+}
+
+//+kubebuilder:rbac:groups=kuadrant.io,resources=apikeys,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=kuadrant.io,resources=apikeys/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=kuadrant.io,resources=authpolicies,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+
+func (r *APIKeyReconciler) Reconcile(eventCtx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := r.Logger().WithValues("apikey", req.NamespacedName)
+	logger.Info("Reconciling APIKey")
+	ctx := crlog.IntoContext(eventCtx, logger)
+
+	apiKey := &v1alpha1.APIKey{}
+	if err := r.Client().Get(ctx, req.NamespacedName, apiKey); err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Info("no apikey found")
+			return ctrl.Result{}, nil
+		}
+		logger.Error(err, "failed to get apikey")
+		return ctrl.Result{}, err
+	}
+
+	if apiKey.GetDeletionTimestamp() != nil {
+		// the secret is garbage collected with its owner
+		return ctrl.Result{}, nil
+	}
+
+	now := metav1.Now()
+
+	secret, lastRotation, specErr := r.reconcileSecret(ctx, apiKey, now)
+
+	if err := r.reconcileStatus(ctx, apiKey, secret, lastRotation, now, specErr); err != nil {
+		if apierrors.IsConflict(err) {
+			return ctrl.Result{Requeue: true}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if specErr != nil {
+		return ctrl.Result{}, specErr
+	}
+
+	if requeueAfter := apiKeyRequeueAfter(apiKey, lastRotation, now); requeueAfter > 0 {
+		logger.V(1).Info("requeueing for rotation or expiry", "after", requeueAfter)
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+
+	logger.Info("APIKey reconciled successfully")
+	return ctrl.Result{}, nil
+}
+
+// reconcileSecret generates, rotates and revokes the Secret holding the key.
+// It returns the reconciled Secret, nil if the key expired, and the time the key was last generated.
+func (r *APIKeyReconciler) reconcileSecret(ctx context.Context, apiKey *v1alpha1.APIKey, now metav1.Time) (*corev1.Secret, *metav1.Time, error) {
+	desired := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      apiKey.SecretName(),
+			Namespace: apiKey.Namespace,
+		},
+		Type: corev1.SecretTypeOpaque,
+	}
+
+	if apiKey.IsExpired(now) {
+		for _, name := range []string{apiKey.SecretName(), apiKey.PreviousSecretName()} {
+			secret := desired.DeepCopy()
+			secret.Name = name
+			utils.TagObjectToDelete(secret)
+			if err := r.reconcileAPIKeySecret(ctx, apiKey, secret); err != nil {
+				return nil, apiKey.Status.LastRotationTime, err
+			}
+		}
+		return nil, apiKey.Status.LastRotationTime, nil
+	}
+
+	authPolicy := &api.AuthPolicy{}
+	if err := r.Client().Get(ctx, client.ObjectKey{Name: apiKey.Spec.AuthPolicyRef.Name, Namespace: apiKey.Namespace}, authPolicy); err != nil {
+		return nil, apiKey.Status.LastRotationTime, err
+	}
+
+	labels, err := apiKeySecretLabels(authPolicy, apiKey.Spec.AuthenticationRule)
+	if err != nil {
+		return nil, apiKey.Status.LastRotationTime, err
+	}
+	labels[v1alpha1.APIKeySecretLabel] = apiKey.Name
+	desired.Labels = labels
+
+	if err := r.SetOwnerReference(apiKey, desired); err != nil {
+		return nil, apiKey.Status.LastRotationTime, err
+	}
+
+	existing, err := r.getAPIKeySecret(ctx, apiKey, client.ObjectKeyFromObject(desired))
+	if err != nil {
+		return nil, apiKey.Status.LastRotationTime, err
+	}
+
+	var key []byte
+	lastRotation := apiKey.Status.LastRotationTime
+	if existing != nil {
+		key = existing.Data[v1alpha1.APIKeySecretKey]
+		lastRotation = apiKeySecretLastRotation(existing, lastRotation)
+	}
+	var previousKey []byte
+	if len(key) == 0 || apiKeyRotationDue(apiKey, lastRotation, now) {
+		previousKey = key
+		if key, err = generateAPIKey(); err != nil {
+			return nil, lastRotation, err
+		}
+		// the time of the rotation is recorded along with the key, so it is not rotated again if the status is not updated
+		lastRotation = ptr.To(now.Rfc3339Copy())
+	}
+	desired.Data = map[string][]byte{v1alpha1.APIKeySecretKey: key}
+	desired.Annotations = map[string]string{v1alpha1.APIKeyLastRotationAnnotation: lastRotation.UTC().Format(time.RFC3339)}
+	if apiKey.Spec.Plan != "" {
+		desired.Annotations[v1alpha1.APIKeyPlanAnnotation] = apiKey.Spec.Plan
+	}
+
+	// the previous key is in place before it is replaced, so it is never revoked ahead of its grace period
+	if err := r.reconcilePreviousSecret(ctx, apiKey, desired, previousKey, lastRotation, now); err != nil {
+		return nil, apiKey.Status.LastRotationTime, err
+	}
+
+	if err := r.reconcileAPIKeySecret(ctx, apiKey, desired); err != nil {
+		return nil, apiKey.Status.LastRotationTime, err
+	}
+
+	return desired, lastRotation, nil
+}
+
+// reconcilePreviousSecret keeps the key replaced by a rotation valid until the grace period elapses, in a Secret labeled
+// as the one of the current key. The previous key is read from the existing Secret, unless the key has just been rotated.
+func (r *APIKeyReconciler) reconcilePreviousSecret(ctx context.Context, apiKey *v1alpha1.APIKey, current *corev1.Secret, previousKey []byte, lastRotation *metav1.Time, now metav1.Time) error {
+	desired := current.DeepCopy()
+	desired.Name = apiKey.PreviousSecretName()
+	desired.Data = nil
+
+	if previousKey == nil {
+		existing, err := r.getAPIKeySecret(ctx, apiKey, client.ObjectKeyFromObject(desired))
+		if err != nil || existing == nil {
+			return err
+		}
+		previousKey = existing.Data[v1alpha1.APIKeySecretKey]
+	}
+
+	if len(previousKey) == 0 || lastRotation == nil || !now.Time.Before(lastRotation.Add(apiKeyRotationGracePeriod(apiKey))) {
+		utils.TagObjectToDelete(desired)
+	} else {
+		desired.Data = map[string][]byte{v1alpha1.APIKeySecretKey: previousKey}
+	}
+
+	return r.reconcileAPIKeySecret(ctx, apiKey, desired)
+}
+
+// getAPIKeySecret returns the Secret of a key, or nil if it does not exist.
+// Secrets without the APIKey label are not cached, so they are read from the API server instead. Those are only
+// returned if owned by the APIKey (e.g. if the label was removed), to be adopted; any other Secret is a conflict.
+func (r *APIKeyReconciler) getAPIKeySecret(ctx context.Context, apiKey *v1alpha1.APIKey, key client.ObjectKey) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	err := r.Client().Get(ctx, key, secret)
+	if err == nil {
+		return secret, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	if err := r.APIClientReader().Get(ctx, key, secret); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	if !metav1.IsControlledBy(secret, apiKey) {
+		return nil, fmt.Errorf("%w: %s", errAPIKeySecretConflict, key)
+	}
+	return secret, nil
+}
+
+// reconcileAPIKeySecret creates, updates or deletes a Secret of a key.
+// Secrets owned by the APIKey that are not cached are updated or deleted directly, labeling them back into the cache.
+func (r *APIKeyReconciler) reconcileAPIKeySecret(ctx context.Context, apiKey *v1alpha1.APIKey, desired *corev1.Secret) error {
+	existing, err := r.getAPIKeySecret(ctx, apiKey, client.ObjectKeyFromObject(desired))
+	if err != nil {
+		return err
+	}
+	if existing == nil || existing.Labels[v1alpha1.APIKeySecretLabel] != "" {
+		return r.ReconcileResource(ctx, &corev1.Secret{}, desired, apiKeySecretMutator)
+	}
+
+	if utils.IsObjectTaggedToDelete(desired) {
+		return r.DeleteResource(ctx, existing)
+	}
+	if _, err := apiKeySecretMutator(existing, desired); err != nil {
+		return err
+	}
+	return r.UpdateResource(ctx, existing)
+}
+
+// apiKeySecretLastRotation returns the time the key of the Secret was generated, as recorded in its annotation.
+// Otherwise, it falls back to the given time, or to the creation of the Secret if nil.
+func apiKeySecretLastRotation(secret *corev1.Secret, fallback *metav1.Time) *metav1.Time {
+	if value, ok := secret.GetAnnotations()[v1alpha1.APIKeyLastRotationAnnotation]; ok {
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return ptr.To(metav1.NewTime(t))
+		}
+	}
+	if fallback != nil {
+		return fallback
+	}
+	return secret.CreationTimestamp.DeepCopy()
+}
+
+func (r *APIKeyReconciler) reconcileStatus(ctx context.Context, apiKey *v1alpha1.APIKey, secret *corev1.Secret, lastRotation *metav1.Time, now metav1.Time, specErr error) error {
+	logger, _ := logr.FromContext(ctx)
+
+	newStatus := &v1alpha1.APIKeyStatus{
+		// Copy initial conditions. Otherwise, status will always be updated
+		Conditions:         slices.Clone(apiKey.Status.Conditions),
+		ObservedGeneration: apiKey.Generation,
+		LastRotationTime:   lastRotation,
+	}
+
+	if secret != nil {
+		newStatus.SecretName = secret.Name
+		acceptedBy, err := r.acceptedBy(ctx, secret)
+		if err != nil {
+			return err
+		}
+		newStatus.AcceptedBy = acceptedBy
+	}
+
+	expired := apiKey.IsExpired(now)
+	meta.SetStatusCondition(&newStatus.Conditions, apiKeyReadyCondition(expired, newStatus.AcceptedBy, specErr))
+	meta.SetStatusCondition(&newStatus.Conditions, apiKeyExpiredCondition(expired))
+
+	if equality.Semantic.DeepEqual(newStatus, &apiKey.Status) {
+		logger.V(1).Info("status up-to-date. No changes required.")
+		return nil
+	}
+
+	apiKey.Status = *newStatus
+	return r.Client().Status().Update(ctx, apiKey)
+}
+
+// acceptedBy returns the AuthPolicies, as namespace/name, whose apiKey authentication rules select the Secret
+func (r *APIKeyReconciler) acceptedBy(ctx context.Context, secret *corev1.Secret) ([]string, error) {
+	authPolicies := &api.AuthPolicyList{}
+	if err := r.Client().List(ctx, authPolicies); err != nil {
+		return nil, err
+	}
+
+	var acceptedBy []string
+	for i := range authPolicies.Items {
+		ap := &authPolicies.Items[i]
+		if authPolicyAcceptsSecret(ap, secret) {
+			acceptedBy = append(acceptedBy, client.ObjectKeyFromObject(ap).String())
+		}
+	}
+	slices.Sort(acceptedBy)
+	return acceptedBy, nil
+}
+
+// apiKeyAuthenticationRules returns the apiKey authentication rules of the AuthPolicy by name
+func apiKeyAuthenticationRules(ap *api.AuthPolicy) map[string]*api.AuthenticationSpec {
+	rules := map[string]*api.AuthenticationSpec{}
+	authScheme := ap.Spec.CommonSpec().AuthScheme
+	if authScheme == nil {
+		return rules
+	}
+	for name := range authScheme.Authentication {
+		rule := authScheme.Authentication[name]
+		if rule.ApiKey != nil && rule.ApiKey.Selector != nil {
+			rules[name] = &rule
+		}
+	}
+	return rules
+}
+
+// apiKeySecretLabels returns the labels that make the Secret of a key be selected by the apiKey authentication rules
+// of the AuthPolicy, either all of them or only the named one
+func apiKeySecretLabels(ap *api.AuthPolicy, ruleName string) (map[string]string, error) {
+	rules := apiKeyAuthenticationRules(ap)
+	if ruleName != "" {
+		rule, ok := rules[ruleName]
+		if !ok {
+			return nil, fmt.Errorf("authpolicy %s has no apiKey authentication rule %s", client.ObjectKeyFromObject(ap), ruleName)
+		}
+		rules = map[string]*api.AuthenticationSpec{ruleName: rule}
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("authpolicy %s has no apiKey authentication rules", client.ObjectKeyFromObject(ap))
+	}
+
+	labels := map[string]string{authorinoSecretLabel: authorinoSecretLabelValue}
+	for _, name := range sortedMapKeys(rules) {
+		for key, value := range rules[name].ApiKey.Selector.MatchLabels {
+			if current, ok := labels[key]; ok && current != value && key != authorinoSecretLabel {
+				return nil, fmt.Errorf("apiKey authentication rules of authpolicy %s select conflicting values for label %s", client.ObjectKeyFromObject(ap), key)
+			}
+			labels[key] = value
+		}
+	}
+
+	for _, name := range sortedMapKeys(rules) {
+		selector, err := metav1.LabelSelectorAsSelector(rules[name].ApiKey.Selector)
+		if err != nil {
+			return nil, err
+		}
+		if !selector.Matches(k8slabels.Set(labels)) {
+			return nil, fmt.Errorf("the selector of apiKey authentication rule %s of authpolicy %s cannot be satisfied with matchLabels only", name, client.ObjectKeyFromObject(ap))
+		}
+	}
+
+	return labels, nil
+}
+
+// authPolicyAcceptsSecret returns true if any of the apiKey authentication rules of the AuthPolicy selects the Secret
+func authPolicyAcceptsSecret(ap *api.AuthPolicy, secret *corev1.Secret) bool {
+	for _, rule := range apiKeyAuthenticationRules(ap) {
+		if !rule.ApiKey.AllNamespaces && ap.Namespace != secret.Namespace {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(rule.ApiKey.Selector)
+		if err != nil {
+			continue
+		}
+		if selector.Matches(k8slabels.Set(secret.Labels)) {
+			return true
+		}
+	}
+	return false
+}
+
+// apiKeyRotationDue returns true if the rotation interval of the key elapsed since it was last generated
+func apiKeyRotationDue(apiKey *v1alpha1.APIKey, lastRotation *metav1.Time, now metav1.Time) bool {
+	rotation := apiKey.Spec.Rotation
+	if rotation == nil || rotation.Interval.Duration <= 0 || lastRotation == nil {
+		return false
+	}
+	return !now.Time.Before(lastRotation.Add(rotation.Interval.Duration))
+}
+
+// apiKeyRotationGracePeriod returns how long the previous key remains valid after a rotation
+func apiKeyRotationGracePeriod(apiKey *v1alpha1.APIKey) time.Duration {
+	rotation := apiKey.Spec.Rotation
+	if rotation == nil || rotation.GracePeriod == nil {
+		return defaultAPIKeyRotationGracePeriod
+	}
+	return rotation.GracePeriod.Duration
+}
+
+// apiKeyRequeueAfter returns the time until the next rotation, the end of the grace period of the previous key or the
+// expiry of the key, whichever comes first.
+// Zero means nothing is scheduled.
+func apiKeyRequeueAfter(apiKey *v1alpha1.APIKey, lastRotation *metav1.Time, now metav1.Time) time.Duration {
+	var after time.Duration
+	schedule := func(at time.Time) {
+		if d := at.Sub(now.Time); d > 0 && (after == 0 || d < after) {
+			after = d
+		}
+	}
+	if apiKey.IsExpired(now) {
+		return 0
+	}
+	if apiKey.Spec.ExpiresAt != nil {
+		schedule(apiKey.Spec.ExpiresAt.Time)
+	}
+	if rotation := apiKey.Spec.Rotation; rotation != nil && rotation.Interval.Duration > 0 && lastRotation != nil {
+		schedule(lastRotation.Add(rotation.Interval.Duration))
+		schedule(lastRotation.Add(apiKeyRotationGracePeriod(apiKey)))
+	}
+	return after
+}
+
+func apiKeyReadyCondition(expired bool, acceptedBy []string, specErr error) metav1.Condition {
+	cond := metav1.Condition{
+		Type:    v1alpha1.APIKeyReadyConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Ready",
+		Message: fmt.Sprintf("APIKey is accepted by AuthPolicies %s", strings.Join(acceptedBy, ", ")),
+	}
+
+	switch {
+	case specErr != nil && errors.Is(specErr, errAPIKeySecretConflict):
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "SecretConflict"
+		cond.Message = specErr.Error()
+	case specErr != nil && apierrors.IsNotFound(specErr):
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "AuthPolicyNotFound"
+		cond.Message = specErr.Error()
+	case specErr != nil:
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "ReconciliationError"
+		cond.Message = specErr.Error()
+	case expired:
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "Expired"
+		cond.Message = "APIKey expired"
+	case len(acceptedBy) == 0:
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "NotAccepted"
+		cond.Message = "APIKey is not accepted by any AuthPolicy"
+	}
+
+	return cond
+}
+
+func apiKeyExpiredCondition(expired bool) metav1.Condition {
+	if expired {
+		return metav1.Condition{
+			Type:    v1alpha1.APIKeyExpiredConditionType,
+			Status:  metav1.ConditionTrue,
+			Reason:  "Expired",
+			Message: "APIKey expired and its secrets were deleted",
+		}
+	}
+	return metav1.Condition{
+		Type:    v1alpha1.APIKeyExpiredConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  "NotExpired",
+		Message: "APIKey has not expired",
+	}
+}
+
+// generateAPIKey returns a random key encoded in base64 (url-safe, unpadded)
+func generateAPIKey() ([]byte, error) {
+	b := make([]byte, apiKeyLength)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return []byte(base64.RawURLEncoding.EncodeToString(b)), nil
+}
+
+func apiKeySecretMutator(existingObj, desiredObj client.Object) (bool, error) {
+	update := false
+	existing, ok := existingObj.(*corev1.Secret)
+	if !ok {
+		return false, fmt.Errorf("existingObj %T is not a *corev1.Secret", existingObj)
+	}
+	desired, ok := desiredObj.(*corev1.Secret)
+	if !ok {
+		return false, fmt.Errorf("desiredObj %T is not a *corev1.Secret", desiredObj)
+	}
+
+	if !reflect.DeepEqual(existing.OwnerReferences, desired.OwnerReferences) {
+		update = true
+		existing.OwnerReferences = desired.OwnerReferences
+	}
+
+	if !reflect.DeepEqual(existing.Labels, desired.Labels) {
+		update = true
+		existing.Labels = desired.Labels
+	}
+
+	if !reflect.DeepEqual(existing.Annotations, desired.Annotations) {
+		update = true
+		existing.Annotations = desired.Annotations
+	}
+
+	if !reflect.DeepEqual(existing.Data, desired.Data) {
+		update = true
+		existing.Data = desired.Data
+	}
+
+	return update, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *APIKeyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.APIKey{}).
+		Owns(&corev1.Secret{}).
+		Watches(
+			&api.AuthPolicy{},
+			handler.EnqueueRequestsFromMapFunc(r.authPolicyToAPIKeys),
+		).
+		Complete(r)
+}
+
+// authPolicyToAPIKeys enqueues the APIKeys that refer to the AuthPolicy.
+// AuthPolicies that accept keys from all namespaces may accept any APIKey, so all of them are enqueued.
+func (r *APIKeyReconciler) authPolicyToAPIKeys(ctx context.Context, obj client.Object) []reconcile.Request {
+	logger := r.Logger().WithValues("authpolicy", client.ObjectKeyFromObject(obj))
+
+	ap, ok := obj.(*api.AuthPolicy)
+	if !ok {
+		logger.Info("cannot map authpolicy event to apikeys", "error", fmt.Sprintf("%T is not an *AuthPolicy", obj))
+		return nil
+	}
+
+	allNamespaces := false
+	for _, rule := range apiKeyAuthenticationRules(ap) {
+		allNamespaces = allNamespaces || rule.ApiKey.AllNamespaces
+	}
+
+	apiKeys := &v1alpha1.APIKeyList{}
+	if err := r.Client().List(ctx, apiKeys); err != nil {
+		logger.Error(err, "failed to list apikeys")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range apiKeys.Items {
+		if allNamespaces || apiKeys.Items[i].Namespace == ap.Namespace {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&apiKeys.Items[i])})
+		}
+	}
+	return requests
+}
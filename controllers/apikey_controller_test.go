@@ -0,0 +1,364 @@
+//go:build unit
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	authorinoapi "github.com/kuadrant/authorino/api/v1beta2"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/kuadrant/kuadrant-operator/api/v1alpha1"
+	api "github.com/kuadrant/kuadrant-operator/api/v1beta2"
+	"github.com/kuadrant/kuadrant-operator/pkg/library/reconcilers"
+)
+
+func testAPIKeyAuthPolicy(allNamespaces bool, selectors map[string]*metav1.LabelSelector) *api.AuthPolicy {
+	authentication := map[string]api.AuthenticationSpec{
+		"jwt": {
+			AuthenticationSpec: authorinoapi.AuthenticationSpec{
+				AuthenticationMethodSpec: authorinoapi.AuthenticationMethodSpec{Jwt: &authorinoapi.JwtAuthenticationSpec{}},
+			},
+		},
+	}
+	for name, selector := range selectors {
+		authentication[name] = api.AuthenticationSpec{
+			AuthenticationSpec: authorinoapi.AuthenticationSpec{
+				AuthenticationMethodSpec: authorinoapi.AuthenticationMethodSpec{
+					ApiKey: &authorinoapi.ApiKeyAuthenticationSpec{Selector: selector, AllNamespaces: allNamespaces},
+				},
+			},
+		}
+	}
+	return &api.AuthPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "ap", Namespace: "testNS"},
+		Spec: api.AuthPolicySpec{
+			AuthPolicyCommonSpec: api.AuthPolicyCommonSpec{
+				AuthScheme: &api.AuthSchemeSpec{Authentication: authentication},
+			},
+		},
+	}
+}
+
+func TestAPIKeySecretLabels(t *testing.T) {
+	consumers := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "toystore"}}
+	admins := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "toystore", "group": "admins"}}
+
+	testCases := []struct {
+		name      string
+		ap        *api.AuthPolicy
+		ruleName  string
+		expected  map[string]string
+		expectErr bool
+	}{
+		{
+			name: "all apiKey rules",
+			ap:   testAPIKeyAuthPolicy(false, map[string]*metav1.LabelSelector{"consumers": consumers, "admins": admins}),
+			expected: map[string]string{
+				authorinoSecretLabel: authorinoSecretLabelValue,
+				"app":                "toystore",
+				"group":              "admins",
+			},
+		},
+		{
+			name:     "named apiKey rule",
+			ap:       testAPIKeyAuthPolicy(false, map[string]*metav1.LabelSelector{"consumers": consumers, "admins": admins}),
+			ruleName: "consumers",
+			expected: map[string]string{authorinoSecretLabel: authorinoSecretLabelValue, "app": "toystore"},
+		},
+		{
+			name:      "named rule is not an apiKey rule",
+			ap:        testAPIKeyAuthPolicy(false, map[string]*metav1.LabelSelector{"consumers": consumers}),
+			ruleName:  "jwt",
+			expectErr: true,
+		},
+		{
+			name:      "no apiKey rules",
+			ap:        testAPIKeyAuthPolicy(false, nil),
+			expectErr: true,
+		},
+		{
+			name: "conflicting labels",
+			ap: testAPIKeyAuthPolicy(false, map[string]*metav1.LabelSelector{
+				"consumers": consumers,
+				"others":    {MatchLabels: map[string]string{"app": "other"}},
+			}),
+			expectErr: true,
+		},
+		{
+			name: "selector with match expressions",
+			ap: testAPIKeyAuthPolicy(false, map[string]*metav1.LabelSelector{
+				"consumers": {MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: metav1.LabelSelectorOpExists}}},
+			}),
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(subT *testing.T) {
+			labels, err := apiKeySecretLabels(tc.ap, tc.ruleName)
+			if (err != nil) != tc.expectErr {
+				subT.Fatalf("unexpected error: %v", err)
+			}
+			if !tc.expectErr && !reflect.DeepEqual(labels, tc.expected) {
+				subT.Errorf("expected %v, got %v", tc.expected, labels)
+			}
+		})
+	}
+}
+
+func TestAuthPolicyAcceptsSecret(t *testing.T) {
+	selectors := map[string]*metav1.LabelSelector{"consumers": {MatchLabels: map[string]string{"app": "toystore"}}}
+	secret := func(namespace string, labels map[string]string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "apikey-test", Namespace: namespace, Labels: labels}}
+	}
+
+	testCases := []struct {
+		name     string
+		ap       *api.AuthPolicy
+		secret   *corev1.Secret
+		expected bool
+	}{
+		{name: "selected", ap: testAPIKeyAuthPolicy(false, selectors), secret: secret("testNS", map[string]string{"app": "toystore"}), expected: true},
+		{name: "not selected", ap: testAPIKeyAuthPolicy(false, selectors), secret: secret("testNS", map[string]string{"app": "other"})},
+		{name: "other namespace", ap: testAPIKeyAuthPolicy(false, selectors), secret: secret("otherNS", map[string]string{"app": "toystore"})},
+		{name: "all namespaces", ap: testAPIKeyAuthPolicy(true, selectors), secret: secret("otherNS", map[string]string{"app": "toystore"}), expected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(subT *testing.T) {
+			if got := authPolicyAcceptsSecret(tc.ap, tc.secret); got != tc.expected {
+				subT.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func withGracePeriod(apiKey *v1alpha1.APIKey, gracePeriod time.Duration) *v1alpha1.APIKey {
+	apiKey.Spec.Rotation.GracePeriod = &metav1.Duration{Duration: gracePeriod}
+	return apiKey
+}
+
+func TestAPIKeyRotationAndExpiry(t *testing.T) {
+	now := metav1.NewTime(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	at := func(d time.Duration) *metav1.Time {
+		t := metav1.NewTime(now.Add(d))
+		return &t
+	}
+	apiKey := func(rotation time.Duration, expiresAt *metav1.Time) *v1alpha1.APIKey {
+		apiKey := &v1alpha1.APIKey{Spec: v1alpha1.APIKeySpec{ExpiresAt: expiresAt}}
+		if rotation > 0 {
+			apiKey.Spec.Rotation = &v1alpha1.APIKeyRotation{Interval: metav1.Duration{Duration: rotation}}
+		}
+		return apiKey
+	}
+
+	testCases := []struct {
+		name         string
+		apiKey       *v1alpha1.APIKey
+		lastRotation *metav1.Time
+		rotationDue  bool
+		requeueAfter time.Duration
+	}{
+		{name: "no rotation nor expiry", apiKey: apiKey(0, nil), lastRotation: at(-time.Hour)},
+		{name: "rotation pending", apiKey: apiKey(2*time.Hour, nil), lastRotation: at(-time.Hour), requeueAfter: time.Hour},
+		{name: "grace period of the previous key pending", apiKey: apiKey(2*time.Hour, nil), lastRotation: at(-time.Minute), requeueAfter: 4 * time.Minute},
+		{name: "no grace period", apiKey: withGracePeriod(apiKey(2*time.Hour, nil), 0), lastRotation: at(-time.Minute), requeueAfter: 2*time.Hour - time.Minute},
+		{name: "rotation due", apiKey: apiKey(time.Hour, nil), lastRotation: at(-time.Hour), rotationDue: true},
+		{name: "expiry before rotation", apiKey: apiKey(2*time.Hour, at(30*time.Minute)), lastRotation: at(-time.Hour), requeueAfter: 30 * time.Minute},
+		{name: "expired", apiKey: apiKey(0, at(-time.Minute)), lastRotation: at(-time.Hour)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(subT *testing.T) {
+			if got := apiKeyRotationDue(tc.apiKey, tc.lastRotation, now); got != tc.rotationDue {
+				subT.Errorf("expected rotation due %v, got %v", tc.rotationDue, got)
+			}
+			if got := apiKeyRequeueAfter(tc.apiKey, tc.lastRotation, now); got != tc.requeueAfter {
+				subT.Errorf("expected requeue after %v, got %v", tc.requeueAfter, got)
+			}
+		})
+	}
+}
+
+func TestReconcilePreviousSecret(t *testing.T) {
+	now := metav1.NewTime(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	at := func(d time.Duration) *metav1.Time {
+		t := metav1.NewTime(now.Add(d))
+		return &t
+	}
+	apiKey := &v1alpha1.APIKey{
+		ObjectMeta: metav1.ObjectMeta{Name: "bob", Namespace: "testNS"},
+		Spec: v1alpha1.APIKeySpec{
+			Rotation: &v1alpha1.APIKeyRotation{Interval: metav1.Duration{Duration: time.Hour}},
+		},
+	}
+	current := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      apiKey.SecretName(),
+			Namespace: apiKey.Namespace,
+			Labels:    map[string]string{authorinoSecretLabel: authorinoSecretLabelValue, v1alpha1.APIKeySecretLabel: apiKey.Name},
+		},
+		Data: map[string][]byte{v1alpha1.APIKeySecretKey: []byte("new")},
+	}
+	previous := func(key string) *corev1.Secret {
+		secret := current.DeepCopy()
+		secret.Name = apiKey.PreviousSecretName()
+		secret.Data = map[string][]byte{v1alpha1.APIKeySecretKey: []byte(key)}
+		return secret
+	}
+
+	testCases := []struct {
+		name         string
+		apiKey       *v1alpha1.APIKey
+		existing     []client.Object
+		previousKey  []byte
+		lastRotation *metav1.Time
+		expectedKey  string
+	}{
+		{name: "just rotated", apiKey: apiKey, previousKey: []byte("old"), lastRotation: &now, expectedKey: "old"},
+		{name: "just rotated without grace period", apiKey: withGracePeriod(apiKey.DeepCopy(), 0), previousKey: []byte("old"), lastRotation: &now},
+		{name: "first key", apiKey: apiKey, lastRotation: &now},
+		{name: "within the grace period", apiKey: apiKey, existing: []client.Object{previous("old")}, lastRotation: at(-time.Minute), expectedKey: "old"},
+		{name: "grace period elapsed", apiKey: apiKey, existing: []client.Object{previous("old")}, lastRotation: at(-10 * time.Minute)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(subT *testing.T) {
+			scheme := runtime.NewScheme()
+			assert.NilError(subT, corev1.AddToScheme(scheme))
+			cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.existing...).Build()
+			r := &APIKeyReconciler{BaseReconciler: reconcilers.NewBaseReconciler(cl, scheme, cl, logr.Discard(), nil)}
+
+			assert.NilError(subT, r.reconcilePreviousSecret(context.Background(), tc.apiKey, current, tc.previousKey, tc.lastRotation, now))
+
+			secret := &corev1.Secret{}
+			err := cl.Get(context.Background(), client.ObjectKey{Namespace: apiKey.Namespace, Name: apiKey.PreviousSecretName()}, secret)
+			if tc.expectedKey == "" {
+				assert.Assert(subT, apierrors.IsNotFound(err), "expected the previous secret to be deleted, got %v", err)
+				return
+			}
+			assert.NilError(subT, err)
+			assert.Equal(subT, string(secret.Data[v1alpha1.APIKeySecretKey]), tc.expectedKey)
+			assert.DeepEqual(subT, secret.Labels, current.Labels)
+		})
+	}
+}
+
+func TestReconcileSecret(t *testing.T) {
+	now := metav1.NewTime(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	consumers := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "toystore"}}
+	ap := testAPIKeyAuthPolicy(false, map[string]*metav1.LabelSelector{"api-key": consumers})
+	apiKey := &v1alpha1.APIKey{
+		ObjectMeta: metav1.ObjectMeta{Name: "bob", Namespace: ap.Namespace, UID: "bob-uid"},
+		Spec: v1alpha1.APIKeySpec{
+			AuthPolicyRef: corev1.LocalObjectReference{Name: ap.Name},
+			Rotation:      &v1alpha1.APIKeyRotation{Interval: metav1.Duration{Duration: time.Hour}},
+		},
+	}
+	secretKey := client.ObjectKey{Namespace: apiKey.Namespace, Name: apiKey.SecretName()}
+
+	scheme := runtime.NewScheme()
+	assert.NilError(t, corev1.AddToScheme(scheme))
+	assert.NilError(t, api.AddToScheme(scheme))
+	assert.NilError(t, v1alpha1.AddToScheme(scheme))
+
+	// the cached client only reads the secrets labeled as the ones of the api keys, as configured in the manager
+	newReconciler := func(objs ...client.Object) (*APIKeyReconciler, client.Client) {
+		apiReader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(objs, ap)...).Build()
+		cached := interceptor.NewClient(apiReader.(client.WithWatch), interceptor.Funcs{
+			Get: func(ctx context.Context, cl client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				if err := cl.Get(ctx, key, obj, opts...); err != nil {
+					return err
+				}
+				if _, ok := obj.(*corev1.Secret); ok && obj.GetLabels()[v1alpha1.APIKeySecretLabel] == "" {
+					return apierrors.NewNotFound(corev1.Resource("secrets"), key.Name)
+				}
+				return nil
+			},
+		})
+		return &APIKeyReconciler{BaseReconciler: reconcilers.NewBaseReconciler(cached, scheme, apiReader, logr.Discard(), nil)}, apiReader
+	}
+
+	t.Run("rotation time recorded on the secret", func(subT *testing.T) {
+		r, cl := newReconciler()
+
+		secret, lastRotation, err := r.reconcileSecret(context.Background(), apiKey, now)
+		assert.NilError(subT, err)
+		assert.Equal(subT, secret.Annotations[v1alpha1.APIKeyLastRotationAnnotation], "2024-05-01T12:00:00Z")
+		assert.Assert(subT, lastRotation.Equal(&now))
+		key := secret.Data[v1alpha1.APIKeySecretKey]
+
+		// the status was not updated, e.g. due to a conflict, so the secret is the only record of the rotation
+		later := metav1.NewTime(now.Add(time.Minute))
+		secret, lastRotation, err = r.reconcileSecret(context.Background(), apiKey, later)
+		assert.NilError(subT, err)
+		assert.Assert(subT, lastRotation.Equal(&now))
+		assert.DeepEqual(subT, secret.Data[v1alpha1.APIKeySecretKey], key)
+
+		existing := &corev1.Secret{}
+		assert.NilError(subT, cl.Get(context.Background(), secretKey, existing))
+		assert.DeepEqual(subT, existing.Data[v1alpha1.APIKeySecretKey], key)
+	})
+
+	t.Run("unlabeled secret not owned by the apikey", func(subT *testing.T) {
+		r, cl := newReconciler(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: secretKey.Namespace, Name: secretKey.Name},
+			Data:       map[string][]byte{"password": []byte("secret")},
+		})
+
+		_, _, err := r.reconcileSecret(context.Background(), apiKey, now)
+		assert.Assert(subT, errors.Is(err, errAPIKeySecretConflict), "expected a conflict, got %v", err)
+		assert.Equal(subT, apiKeyReadyCondition(false, nil, err).Reason, "SecretConflict")
+
+		existing := &corev1.Secret{}
+		assert.NilError(subT, cl.Get(context.Background(), secretKey, existing))
+		assert.DeepEqual(subT, existing.Data, map[string][]byte{"password": []byte("secret")})
+	})
+
+	t.Run("unlabeled secret owned by the apikey", func(subT *testing.T) {
+		owned := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   secretKey.Namespace,
+				Name:        secretKey.Name,
+				Annotations: map[string]string{v1alpha1.APIKeyLastRotationAnnotation: "2024-05-01T11:30:00Z"},
+			},
+			Data: map[string][]byte{v1alpha1.APIKeySecretKey: []byte("key")},
+		}
+		r, cl := newReconciler(owned)
+		assert.NilError(subT, r.SetOwnerReference(apiKey, owned))
+		assert.NilError(subT, cl.Update(context.Background(), owned))
+
+		secret, _, err := r.reconcileSecret(context.Background(), apiKey, now)
+		assert.NilError(subT, err)
+		assert.DeepEqual(subT, secret.Data[v1alpha1.APIKeySecretKey], []byte("key"))
+
+		existing := &corev1.Secret{}
+		assert.NilError(subT, cl.Get(context.Background(), secretKey, existing))
+		assert.Equal(subT, existing.Labels[v1alpha1.APIKeySecretLabel], apiKey.Name)
+		assert.DeepEqual(subT, existing.Data[v1alpha1.APIKeySecretKey], []byte("key"))
+	})
+}
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, err := generateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := generateAPIKey()
+	if len(key) != 43 || reflect.DeepEqual(key, other) {
+		t.Errorf("unexpected keys %s, %s", key, other)
+	}
+}
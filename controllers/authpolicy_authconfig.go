@@ -16,11 +16,12 @@ import (
 
 	api "github.com/kuadrant/kuadrant-operator/api/v1beta2"
 	"github.com/kuadrant/kuadrant-operator/pkg/common"
+	"github.com/kuadrant/kuadrant-operator/pkg/kuadranttools"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/kuadrant"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/utils"
 )
 
-func (r *AuthPolicyReconciler) reconcileAuthConfigs(ctx context.Context, ap *api.AuthPolicy, targetNetworkObject client.Object) error {
+func (r *AuthPolicyReconciler) reconcileAuthConfigs(ctx context.Context, ap *api.AuthPolicy, targetNetworkObject client.Object, native *nativeAuth, authorinoName string) error {
 	logger, err := logr.FromContext(ctx)
 	if err != nil {
 		return err
@@ -31,11 +32,19 @@ func (r *AuthPolicyReconciler) reconcileAuthConfigs(ctx context.Context, ap *api
 		return err
 	}
 
+	// label the authconfig for the authorino instance that serves the gateways of the policy
+	authConfig.Labels = kuadranttools.AuthorinoAuthConfigLabels(authorinoName)
+
 	err = r.SetOwnerReference(ap, authConfig)
 	if err != nil {
 		return err
 	}
 
+	// neither the authpolicy enforced natively by the gateway provider nor the one that disables auth need an authconfig
+	if native.InUse() || ap.Spec.Disabled {
+		utils.TagObjectToDelete(authConfig)
+	}
+
 	err = r.ReconcileResource(ctx, &authorinoapi.AuthConfig{}, authConfig, authConfigBasicMutator)
 	if err != nil && !apierrors.IsAlreadyExists(err) {
 		logger.Error(err, "ReconcileResource failed to create/update AuthConfig resource")
@@ -94,7 +103,6 @@ func (r *AuthPolicyReconciler) desiredAuthConfig(ctx context.Context, ap *api.Au
 		if len(rules) == 0 {
 			logger.V(1).Info("no httproutes attached to the targeted gateway, skipping authorino authconfig for the gateway authpolicy")
 			utils.TagObjectToDelete(authConfig)
-			r.OverriddenPolicyMap.SetOverriddenPolicy(ap)
 			return authConfig, nil
 		}
 		route = &gatewayapiv1.HTTPRoute{
@@ -105,9 +113,6 @@ func (r *AuthPolicyReconciler) desiredAuthConfig(ctx context.Context, ap *api.Au
 		}
 	}
 
-	// AuthPolicy is not overridden if we still need to create an AuthConfig for it
-	r.OverriddenPolicyMap.RemoveOverriddenPolicy(ap)
-
 	// hosts
 	authConfig.Spec.Hosts = hosts
 
@@ -521,11 +526,24 @@ func authConfigBasicMutator(existingObj, desiredObj client.Object) (bool, error)
 		return false, fmt.Errorf("%T is not an *authorinoapi.AuthConfig", desiredObj)
 	}
 
-	if reflect.DeepEqual(existing.Spec, desired.Spec) {
-		return false, nil
+	var update bool
+
+	if existing.Labels[common.AuthorinoInstanceLabel] != desired.Labels[common.AuthorinoInstanceLabel] {
+		update = true
+		if desiredInstance, ok := desired.Labels[common.AuthorinoInstanceLabel]; ok {
+			if existing.Labels == nil {
+				existing.Labels = map[string]string{}
+			}
+			existing.Labels[common.AuthorinoInstanceLabel] = desiredInstance
+		} else {
+			delete(existing.Labels, common.AuthorinoInstanceLabel)
+		}
 	}
 
-	existing.Spec = desired.Spec
+	if !reflect.DeepEqual(existing.Spec, desired.Spec) {
+		update = true
+		existing.Spec = desired.Spec
+	}
 
-	return true, nil
+	return update, nil
 }
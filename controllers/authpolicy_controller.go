@@ -3,18 +3,29 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
 
+	egapi "github.com/envoyproxy/gateway/api/v1alpha1"
 	"github.com/go-logr/logr"
 	authorinoapi "github.com/kuadrant/authorino/api/v1beta2"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
 
+	kuadrantv1beta1 "github.com/kuadrant/kuadrant-operator/api/v1beta1"
 	api "github.com/kuadrant/kuadrant-operator/api/v1beta2"
+	"github.com/kuadrant/kuadrant-operator/pkg/common"
+	"github.com/kuadrant/kuadrant-operator/pkg/kuadranttools"
+	kuadrantgatewayapi "github.com/kuadrant/kuadrant-operator/pkg/library/gatewayapi"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/kuadrant"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/mappers"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/reconcilers"
@@ -26,8 +37,14 @@ const authPolicyFinalizer = "authpolicy.kuadrant.io/finalizer"
 type AuthPolicyReconciler struct {
 	*reconcilers.BaseReconciler
 	TargetRefReconciler reconcilers.TargetRefReconciler
-	// OverriddenPolicyMap tracks the overridden policies to report their status.
-	OverriddenPolicyMap *kuadrant.OverriddenPolicyMap
+	// TopologyCache provides the topology of gateways, routes and policies used to tell the overridden policies apart.
+	TopologyCache *kuadrantgatewayapi.TopologyCache
+	// HTTPClient fetches the OpenID Connect discovery documents of the policies enforced natively by the gateway provider.
+	// Defaults to a client with a 5s timeout.
+	HTTPClient *http.Client
+
+	jwksURIsOnce sync.Once
+	jwksURIs     *jwksURICache
 }
 
 //+kubebuilder:rbac:groups=kuadrant.io,resources=authpolicies,verbs=get;list;watch;create;update;patch;delete
@@ -74,7 +91,7 @@ func (r *AuthPolicyReconciler) Reconcile(eventCtx context.Context, req ctrl.Requ
 				if delResErr == nil {
 					delResErr = err
 				}
-				return r.reconcileStatus(ctx, ap, targetNetworkObject, kuadrant.NewErrTargetNotFound(ap.Kind(), ap.GetTargetRef(), delResErr))
+				return r.reconcileStatus(ctx, ap, targetNetworkObject, nil, kuadrant.NewErrTargetNotFound(ap.Kind(), ap.GetTargetRef(), delResErr))
 			}
 			return ctrl.Result{}, err
 		}
@@ -106,11 +123,14 @@ func (r *AuthPolicyReconciler) Reconcile(eventCtx context.Context, req ctrl.Requ
 		}
 	}
 
+	// translate the authpolicy to the native auth of the gateway provider, if requested
+	native := r.nativeAuth(ctx, ap, targetNetworkObject)
+
 	// reconcile the authpolicy spec
-	specErr := r.reconcileResources(ctx, ap, targetNetworkObject)
+	specErr := r.reconcileResources(ctx, ap, targetNetworkObject, native)
 
 	// reconcile authpolicy status
-	statusResult, statusErr := r.reconcileStatus(ctx, ap, targetNetworkObject, specErr)
+	statusResult, statusErr := r.reconcileStatus(ctx, ap, targetNetworkObject, native, specErr)
 
 	if specErr != nil {
 		return ctrl.Result{}, specErr
@@ -125,14 +145,6 @@ func (r *AuthPolicyReconciler) Reconcile(eventCtx context.Context, req ctrl.Requ
 		return statusResult, nil
 	}
 
-	// trigger concurrent reconciliations of possibly affected gateway policies
-	switch route := targetNetworkObject.(type) {
-	case *gatewayapiv1.HTTPRoute:
-		if err := r.reconcileRouteParentGatewayPolicies(ctx, route); err != nil {
-			return ctrl.Result{}, err
-		}
-	}
-
 	logger.Info("AuthPolicy reconciled successfully")
 	return ctrl.Result{}, nil
 }
@@ -150,41 +162,41 @@ func (r *AuthPolicyReconciler) validate(ap *api.AuthPolicy, targetNetworkObject
 	return nil
 }
 
-func (r *AuthPolicyReconciler) reconcileResources(ctx context.Context, ap *api.AuthPolicy, targetNetworkObject client.Object) error {
+func (r *AuthPolicyReconciler) reconcileResources(ctx context.Context, ap *api.AuthPolicy, targetNetworkObject client.Object, native *nativeAuth) error {
 	if err := r.validate(ap, targetNetworkObject); err != nil {
 		return err
 	}
 
 	// reconcile based on gateway diffs
-	gatewayDiffObj, err := reconcilers.ComputeGatewayDiffs(ctx, r.Client(), ap, targetNetworkObject)
+	gatewayDiffObj, err := reconcilers.ComputeGatewayDiffs(ctx, r.Client(), r.TargetRefReconciler.BackReferenceIndex, ap, targetNetworkObject)
 	if err != nil {
 		return err
 	}
 
-	// if err := r.reconcileIstioAuthorizationPolicies(ctx, ap, targetNetworkObject, gatewayDiffObj); err != nil {
+	authorinoName, err := r.authorinoName(ctx, gatewayDiffObj)
+	if err != nil {
+		return err
+	}
+
+	// if err := r.reconcileIstioAuthorizationPolicies(ctx, ap, targetNetworkObject, gatewayDiffObj, authorinoName); err != nil {
 	// 	return err
 	// }
 
-	if err := r.reconcileEnvoySecurityPolicies(ctx, ap, targetNetworkObject, gatewayDiffObj); err != nil {
+	if err := r.reconcileEnvoySecurityPolicies(ctx, ap, targetNetworkObject, gatewayDiffObj, native, authorinoName); err != nil {
 		return err
 	}
 
-	if err := r.reconcileAuthConfigs(ctx, ap, targetNetworkObject); err != nil {
+	if err := r.reconcileAuthConfigs(ctx, ap, targetNetworkObject, native, authorinoName); err != nil {
 		return err
 	}
 
 	// set direct back ref - i.e. claim the target network object as taken asap
-	if err := r.reconcileNetworkResourceDirectBackReference(ctx, ap, targetNetworkObject); err != nil {
-		return err
-	}
-
-	// set annotation of policies affecting the gateway - should be the last step, only when all the reconciliation steps succeed
-	return r.TargetRefReconciler.ReconcileGatewayPolicyReferences(ctx, ap, gatewayDiffObj)
+	return r.reconcileNetworkResourceDirectBackReference(ctx, ap, targetNetworkObject)
 }
 
 func (r *AuthPolicyReconciler) deleteResources(ctx context.Context, ap *api.AuthPolicy, targetNetworkObject client.Object) error {
 	// delete based on gateway diffs
-	gatewayDiffObj, err := reconcilers.ComputeGatewayDiffs(ctx, r.Client(), ap, targetNetworkObject)
+	gatewayDiffObj, err := reconcilers.ComputeGatewayDiffs(ctx, r.Client(), r.TargetRefReconciler.BackReferenceIndex, ap, targetNetworkObject)
 	if err != nil {
 		return err
 	}
@@ -192,19 +204,16 @@ func (r *AuthPolicyReconciler) deleteResources(ctx context.Context, ap *api.Auth
 	// if err := r.deleteIstioAuthorizationPolicies(ctx, ap, gatewayDiffObj); err != nil {
 	// 	return err
 	// }
-	if err := r.reconcileEnvoySecurityPolicies(ctx, ap, targetNetworkObject, gatewayDiffObj); err != nil {
+	if err := r.reconcileEnvoySecurityPolicies(ctx, ap, targetNetworkObject, gatewayDiffObj, nil, common.AuthorinoName); err != nil {
 		return err
 	}
 
 	// remove direct back ref
 	if targetNetworkObject != nil {
-		if err := r.deleteNetworkResourceDirectBackReference(ctx, targetNetworkObject, ap); err != nil {
-			return err
-		}
+		return r.deleteNetworkResourceDirectBackReference(ctx, targetNetworkObject, ap)
 	}
 
-	// update annotation of policies affecting the gateway
-	return r.TargetRefReconciler.ReconcileGatewayPolicyReferences(ctx, ap, gatewayDiffObj)
+	return nil
 }
 
 // Ensures only one RLP targets the network resource
@@ -216,28 +225,58 @@ func (r *AuthPolicyReconciler) deleteNetworkResourceDirectBackReference(ctx cont
 	return r.TargetRefReconciler.DeleteTargetBackReference(ctx, targetNetworkObject, ap.DirectReferenceAnnotationName())
 }
 
-// reconcileRouteParentGatewayPolicies triggers the concurrent reconciliation of all policies that target gateways that are parents of a route
-func (r *AuthPolicyReconciler) reconcileRouteParentGatewayPolicies(ctx context.Context, route *gatewayapiv1.HTTPRoute) error {
-	logger, err := logr.FromContext(ctx)
-	if err != nil {
-		return err
+// authorinoName returns the name of the authorino instance that serves the auth policy, i.e. the instance dedicated to
+// the gateways of the policy, or the shared one if the gateways are not served by the same dedicated instance
+func (r *AuthPolicyReconciler) authorinoName(ctx context.Context, gwDiffObj *reconcilers.GatewayDiffs) (string, error) {
+	logger, _ := logr.FromContext(ctx)
+
+	names := make(map[string]struct{})
+	for _, gw := range append(gwDiffObj.GatewaysWithValidPolicyRef, gwDiffObj.GatewaysMissingPolicyRef...) {
+		kObj, err := kuadranttools.KuadrantFromGateway(ctx, r.Client(), gw.Gateway)
+		if err != nil {
+			return "", err
+		}
+		names[kuadranttools.AuthorinoNameForGateway(gw.Gateway, kObj)] = struct{}{}
 	}
-	mapper := HTTPRouteParentRefsEventMapper{
-		Logger: logger,
-		Client: r.Client(),
+
+	if len(names) != 1 {
+		if len(names) > 1 {
+			logger.V(1).Info("gateways of the policy are served by different authorino instances, falling back to the shared one")
+		}
+		return common.AuthorinoName, nil
 	}
-	requests := mapper.MapToAuthPolicy(route)
-	for i := range requests {
-		request := requests[i]
-		go r.Reconcile(context.Background(), request)
+	for name := range names {
+		return name, nil
 	}
-	return nil
+	return common.AuthorinoName, nil
+}
+
+// topologyIndexes returns the indexes of the current topology narrowed down to the auth policies.
+// The indexes are built once per topology snapshot.
+func (r *AuthPolicyReconciler) topologyIndexes() (*kuadrantgatewayapi.TopologyIndexes, error) {
+	topology, err := r.TopologyCache.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	return topology.Memoize("controllers.AuthPolicyTopologyIndexes", func() any {
+		return kuadrantgatewayapi.NewTopologyIndexes(topology.WithPolicyFilter(isAuthPolicy))
+	}).(*kuadrantgatewayapi.TopologyIndexes), nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *AuthPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	httpRouteEventMapper := mappers.NewHTTPRouteEventMapper(mappers.WithLogger(r.Logger().WithName("httpRouteEventMapper")))
-	gatewayEventMapper := mappers.NewGatewayEventMapper(mappers.WithLogger(r.Logger().WithName("gatewayEventMapper")))
+	httpRouteEventMapper := mappers.NewHTTPRouteEventMapper(
+		mappers.WithLogger(r.Logger().WithName("httpRouteEventMapper")),
+		mappers.WithBackReferenceIndex(r.TargetRefReconciler.BackReferenceIndex),
+	)
+	gatewayEventMapper := mappers.NewGatewayEventMapper(
+		mappers.WithLogger(r.Logger().WithName("gatewayEventMapper")),
+		mappers.WithBackReferenceIndex(r.TargetRefReconciler.BackReferenceIndex),
+	)
+	topologyToParentGatewaysEventMapper := mappers.NewTopologyToParentGatewaysEventMapper(
+		mappers.WithLogger(r.Logger().WithName("topologyToParentGatewaysEventMapper")),
+		mappers.WithClient(r.Client()),
+	)
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&api.AuthPolicy{}).
@@ -245,13 +284,84 @@ func (r *AuthPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Watches(
 			&gatewayapiv1.HTTPRoute{},
 			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, object client.Object) []reconcile.Request {
-				return httpRouteEventMapper.MapToPolicy(object, &api.AuthPolicy{})
+				return httpRouteEventMapper.MapToPolicy(ctx, object, &api.AuthPolicy{})
 			}),
 		).
 		Watches(&gatewayapiv1.Gateway{},
 			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, object client.Object) []reconcile.Request {
-				return gatewayEventMapper.MapToPolicy(object, &api.AuthPolicy{})
+				return gatewayEventMapper.MapToPolicy(ctx, object, &api.AuthPolicy{})
+			}),
+		).
+		// The kuadrant instance decides which authorino instance serves the gateways it manages. Changes of the
+		// annotations of the gateways are covered by the watch of the gateways.
+		Watches(
+			&kuadrantv1beta1.Kuadrant{},
+			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, object client.Object) []reconcile.Request {
+				return r.kuadrantToAuthPolicies(ctx, object, gatewayEventMapper)
 			}),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
+		// The status of the security policies tells whether the auth policies enforced natively are enforced
+		Watches(
+			&egapi.SecurityPolicy{},
+			handler.EnqueueRequestsFromMapFunc(securityPolicyToAuthPolicy),
+		).
+		// Events of routes and auth policies are received after the topology cache has been updated,
+		// so the overridden state of the gateway policies is computed out of a snapshot that includes the triggering change.
+		// Changes of the route policies also trigger the reconciliation of the gateway policies through the workqueue.
+		WatchesRawSource(
+			r.TopologyCache.Source(),
+			handler.EnqueueRequestsFromMapFunc(topologyToGatewayPolicies(r.Client(), r.Logger(), topologyToParentGatewaysEventMapper, func() client.ObjectList { return &api.AuthPolicyList{} })),
+			builder.WithPredicates(predicate.NewPredicateFuncs(isAuthPolicyRouteTopologyObject)),
 		).
 		Complete(r)
 }
+
+// kuadrantToAuthPolicies maps events of a kuadrant instance to events of the auth policies affecting the gateways
+// managed by the instance, whose authorino instance may have changed
+func (r *AuthPolicyReconciler) kuadrantToAuthPolicies(ctx context.Context, obj client.Object, gatewayEventMapper mappers.EventMapper) []reconcile.Request {
+	gwList := &gatewayapiv1.GatewayList{}
+	if err := r.Client().List(ctx, gwList); err != nil {
+		r.Logger().V(1).Info("cannot map kuadrant event to auth policies", "error", err.Error())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0)
+	for idx := range gwList.Items {
+		gw := &gwList.Items[idx]
+		if gw.GetAnnotations()[kuadrant.KuadrantNamespaceAnnotation] != obj.GetNamespace() {
+			continue
+		}
+		for _, request := range gatewayEventMapper.MapToPolicy(ctx, gw, &api.AuthPolicy{}) {
+			if !slices.Contains(requests, request) {
+				requests = append(requests, request)
+			}
+		}
+	}
+	return requests
+}
+
+// securityPolicyToAuthPolicy maps events of the envoy security policies to events of the auth policies they were created for
+func securityPolicyToAuthPolicy(_ context.Context, obj client.Object) []reconcile.Request {
+	labels := obj.GetLabels()
+	name, ok := labels[common.AuthPolicyBackRefAnnotation]
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: client.ObjectKey{Name: name, Namespace: labels[fmt.Sprintf("%s-namespace", common.AuthPolicyBackRefAnnotation)]}}}
+}
+
+func isAuthPolicy(p kuadrantgatewayapi.Policy) bool {
+	_, ok := p.(*api.AuthPolicy)
+	return ok
+}
+
+// isAuthPolicyRouteTopologyObject filters the events of the topology down to the ones of routes and auth policies
+func isAuthPolicyRouteTopologyObject(obj client.Object) bool {
+	switch obj.(type) {
+	case *gatewayapiv1.HTTPRoute, *api.AuthPolicy:
+		return true
+	default:
+		return false
+	}
+}
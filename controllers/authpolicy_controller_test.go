@@ -714,22 +714,20 @@ var _ = Describe("AuthPolicy controller", func() {
 				logf.Log.V(1).Info("Fetching Istio's AuthorizationPolicy", "key", iapKey.String(), "error", err)
 				return err == nil
 			}, 2*time.Minute, 5*time.Second).Should(BeTrue())
-			Expect(iap.Spec.Rules).To(HaveLen(3))
-			Expect(iap.Spec.Rules[0].To).To(HaveLen(1))
+			Expect(iap.Spec.Rules).To(HaveLen(1))
+			Expect(iap.Spec.Rules[0].To).To(HaveLen(3))
 			Expect(iap.Spec.Rules[0].To[0].Operation).ShouldNot(BeNil())
 			Expect(iap.Spec.Rules[0].To[0].Operation.Hosts).To(Equal([]string{"*.toystore.com", "*.admin.toystore.com"}))
 			Expect(iap.Spec.Rules[0].To[0].Operation.Methods).To(Equal([]string{"POST"}))
 			Expect(iap.Spec.Rules[0].To[0].Operation.Paths).To(Equal([]string{"/admin*"}))
-			Expect(iap.Spec.Rules[1].To).To(HaveLen(1))
-			Expect(iap.Spec.Rules[1].To[0].Operation).ShouldNot(BeNil())
-			Expect(iap.Spec.Rules[1].To[0].Operation.Hosts).To(Equal([]string{"*.toystore.com", "*.admin.toystore.com"}))
-			Expect(iap.Spec.Rules[1].To[0].Operation.Methods).To(Equal([]string{"DELETE"}))
-			Expect(iap.Spec.Rules[1].To[0].Operation.Paths).To(Equal([]string{"/admin*"}))
-			Expect(iap.Spec.Rules[2].To).To(HaveLen(1))
-			Expect(iap.Spec.Rules[2].To[0].Operation).ShouldNot(BeNil())
-			Expect(iap.Spec.Rules[2].To[0].Operation.Hosts).To(Equal([]string{"*.toystore.com", "*.admin.toystore.com"}))
-			Expect(iap.Spec.Rules[2].To[0].Operation.Methods).To(Equal([]string{"GET"}))
-			Expect(iap.Spec.Rules[2].To[0].Operation.Paths).To(Equal([]string{"/private*"}))
+			Expect(iap.Spec.Rules[0].To[1].Operation).ShouldNot(BeNil())
+			Expect(iap.Spec.Rules[0].To[1].Operation.Hosts).To(Equal([]string{"*.toystore.com", "*.admin.toystore.com"}))
+			Expect(iap.Spec.Rules[0].To[1].Operation.Methods).To(Equal([]string{"DELETE"}))
+			Expect(iap.Spec.Rules[0].To[1].Operation.Paths).To(Equal([]string{"/admin*"}))
+			Expect(iap.Spec.Rules[0].To[2].Operation).ShouldNot(BeNil())
+			Expect(iap.Spec.Rules[0].To[2].Operation.Hosts).To(Equal([]string{"*.toystore.com", "*.admin.toystore.com"}))
+			Expect(iap.Spec.Rules[0].To[2].Operation.Methods).To(Equal([]string{"GET"}))
+			Expect(iap.Spec.Rules[0].To[2].Operation.Paths).To(Equal([]string{"/private*"}))
 
 			// check authorino authconfig
 			authConfigKey := types.NamespacedName{Name: authConfigName(client.ObjectKeyFromObject(policy)), Namespace: testNamespace}
@@ -810,25 +808,23 @@ var _ = Describe("AuthPolicy controller", func() {
 				logf.Log.V(1).Info("Fetching Istio's AuthorizationPolicy", "key", iapKey.String(), "error", err)
 				return err == nil
 			}, 2*time.Minute, 5*time.Second).Should(BeTrue())
-			Expect(iap.Spec.Rules).To(HaveLen(3))
+			Expect(iap.Spec.Rules).To(HaveLen(1))
+			Expect(iap.Spec.Rules[0].To).To(HaveLen(3))
 			// POST *.admin.toystore.com/admin*
-			Expect(iap.Spec.Rules[0].To).To(HaveLen(1))
 			Expect(iap.Spec.Rules[0].To[0].Operation).ShouldNot(BeNil())
 			Expect(iap.Spec.Rules[0].To[0].Operation.Hosts).To(Equal([]string{"*.admin.toystore.com"}))
 			Expect(iap.Spec.Rules[0].To[0].Operation.Methods).To(Equal([]string{"POST"}))
 			Expect(iap.Spec.Rules[0].To[0].Operation.Paths).To(Equal([]string{"/admin*"}))
 			// DELETE *.admin.toystore.com/admin*
-			Expect(iap.Spec.Rules[1].To).To(HaveLen(1))
-			Expect(iap.Spec.Rules[1].To[0].Operation).ShouldNot(BeNil())
-			Expect(iap.Spec.Rules[1].To[0].Operation.Hosts).To(Equal([]string{"*.admin.toystore.com"}))
-			Expect(iap.Spec.Rules[1].To[0].Operation.Methods).To(Equal([]string{"DELETE"}))
-			Expect(iap.Spec.Rules[1].To[0].Operation.Paths).To(Equal([]string{"/admin*"}))
+			Expect(iap.Spec.Rules[0].To[1].Operation).ShouldNot(BeNil())
+			Expect(iap.Spec.Rules[0].To[1].Operation.Hosts).To(Equal([]string{"*.admin.toystore.com"}))
+			Expect(iap.Spec.Rules[0].To[1].Operation.Methods).To(Equal([]string{"DELETE"}))
+			Expect(iap.Spec.Rules[0].To[1].Operation.Paths).To(Equal([]string{"/admin*"}))
 			// GET (*.toystore.com|*.admin.toystore.com)/private*
-			Expect(iap.Spec.Rules[2].To).To(HaveLen(1))
-			Expect(iap.Spec.Rules[2].To[0].Operation).ShouldNot(BeNil())
-			Expect(iap.Spec.Rules[2].To[0].Operation.Hosts).To(Equal([]string{"*.toystore.com", "*.admin.toystore.com"}))
-			Expect(iap.Spec.Rules[2].To[0].Operation.Methods).To(Equal([]string{"GET"}))
-			Expect(iap.Spec.Rules[2].To[0].Operation.Paths).To(Equal([]string{"/private*"}))
+			Expect(iap.Spec.Rules[0].To[2].Operation).ShouldNot(BeNil())
+			Expect(iap.Spec.Rules[0].To[2].Operation.Hosts).To(Equal([]string{"*.toystore.com", "*.admin.toystore.com"}))
+			Expect(iap.Spec.Rules[0].To[2].Operation.Methods).To(Equal([]string{"GET"}))
+			Expect(iap.Spec.Rules[0].To[2].Operation.Paths).To(Equal([]string{"/private*"}))
 
 			// check authorino authconfig
 			authConfigKey := types.NamespacedName{Name: authConfigName(client.ObjectKeyFromObject(policy)), Namespace: testNamespace}
@@ -907,25 +903,23 @@ var _ = Describe("AuthPolicy controller", func() {
 				logf.Log.V(1).Info("Fetching Istio's AuthorizationPolicy", "key", iapKey.String(), "error", err)
 				return err == nil
 			}, 2*time.Minute, 5*time.Second).Should(BeTrue())
-			Expect(iap.Spec.Rules).To(HaveLen(3))
-			// POST *.admin.toystore.com/admin*
-			Expect(iap.Spec.Rules[0].To).To(HaveLen(1))
+			Expect(iap.Spec.Rules).To(HaveLen(1))
+			Expect(iap.Spec.Rules[0].To).To(HaveLen(3))
+			// POST (*.toystore.com|*.admin.toystore.com)/admin*
 			Expect(iap.Spec.Rules[0].To[0].Operation).ShouldNot(BeNil())
-			Expect(iap.Spec.Rules[2].To[0].Operation.Hosts).To(Equal([]string{"*.toystore.com", "*.admin.toystore.com"}))
+			Expect(iap.Spec.Rules[0].To[0].Operation.Hosts).To(Equal([]string{"*.toystore.com", "*.admin.toystore.com"}))
 			Expect(iap.Spec.Rules[0].To[0].Operation.Methods).To(Equal([]string{"POST"}))
 			Expect(iap.Spec.Rules[0].To[0].Operation.Paths).To(Equal([]string{"/admin*"}))
-			// DELETE *.admin.toystore.com/admin*
-			Expect(iap.Spec.Rules[1].To).To(HaveLen(1))
-			Expect(iap.Spec.Rules[1].To[0].Operation).ShouldNot(BeNil())
-			Expect(iap.Spec.Rules[2].To[0].Operation.Hosts).To(Equal([]string{"*.toystore.com", "*.admin.toystore.com"}))
-			Expect(iap.Spec.Rules[1].To[0].Operation.Methods).To(Equal([]string{"DELETE"}))
-			Expect(iap.Spec.Rules[1].To[0].Operation.Paths).To(Equal([]string{"/admin*"}))
+			// DELETE (*.toystore.com|*.admin.toystore.com)/admin*
+			Expect(iap.Spec.Rules[0].To[1].Operation).ShouldNot(BeNil())
+			Expect(iap.Spec.Rules[0].To[1].Operation.Hosts).To(Equal([]string{"*.toystore.com", "*.admin.toystore.com"}))
+			Expect(iap.Spec.Rules[0].To[1].Operation.Methods).To(Equal([]string{"DELETE"}))
+			Expect(iap.Spec.Rules[0].To[1].Operation.Paths).To(Equal([]string{"/admin*"}))
 			// GET (*.toystore.com|*.admin.toystore.com)/private*
-			Expect(iap.Spec.Rules[2].To).To(HaveLen(1))
-			Expect(iap.Spec.Rules[2].To[0].Operation).ShouldNot(BeNil())
-			Expect(iap.Spec.Rules[2].To[0].Operation.Hosts).To(Equal([]string{"*.toystore.com", "*.admin.toystore.com"}))
-			Expect(iap.Spec.Rules[2].To[0].Operation.Methods).To(Equal([]string{"GET"}))
-			Expect(iap.Spec.Rules[2].To[0].Operation.Paths).To(Equal([]string{"/private*"}))
+			Expect(iap.Spec.Rules[0].To[2].Operation).ShouldNot(BeNil())
+			Expect(iap.Spec.Rules[0].To[2].Operation.Hosts).To(Equal([]string{"*.toystore.com", "*.admin.toystore.com"}))
+			Expect(iap.Spec.Rules[0].To[2].Operation.Methods).To(Equal([]string{"GET"}))
+			Expect(iap.Spec.Rules[0].To[2].Operation.Paths).To(Equal([]string{"/private*"}))
 
 			// check authorino authconfig
 			authConfigKey := types.NamespacedName{Name: authConfigName(client.ObjectKeyFromObject(policy)), Namespace: testNamespace}
@@ -0,0 +1,351 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	egapi "github.com/envoyproxy/gateway/api/v1alpha1"
+	authorinoapi "github.com/kuadrant/authorino/api/v1beta2"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	api "github.com/kuadrant/kuadrant-operator/api/v1beta2"
+)
+
+const (
+	oidcDiscoveryPath    = "/.well-known/openid-configuration"
+	oidcDiscoveryTimeout = 5 * time.Second
+	// jwksURITTL is how long a discovered JWKS URI is used before the discovery document of the issuer is fetched again
+	jwksURITTL = time.Hour
+	// jwksURIRetryInterval is how long a failed discovery is remembered before the issuer is tried again
+	jwksURIRetryInterval = time.Minute
+
+	// audienceSelector is the only selector of authorization patterns that can be enforced natively
+	audienceSelector = "auth.identity.aud"
+)
+
+// nativeAuth is the outcome of the translation of an AuthPolicy opted in to native auth
+type nativeAuth struct {
+	// JWT is the native JWT authentication of the Envoy Gateway SecurityPolicy.
+	// Nil if the policy falls back to Authorino.
+	JWT *egapi.JWT
+	// Err is the reason why the policy falls back to Authorino
+	Err error
+}
+
+// InUse returns true if the policy is enforced natively by the gateway provider
+func (n *nativeAuth) InUse() bool {
+	return n != nil && n.JWT != nil
+}
+
+// nativeAuth translates the AuthPolicy into the native JWT authentication of Envoy Gateway.
+// It returns nil if the policy did not opt in to native auth.
+func (r *AuthPolicyReconciler) nativeAuth(ctx context.Context, ap *api.AuthPolicy, targetNetworkObject client.Object) *nativeAuth {
+	if !ap.NativeAuthRequested() || ap.Spec.Disabled {
+		return nil
+	}
+
+	if _, ok := targetNetworkObject.(*gatewayapiv1.HTTPRoute); !ok {
+		return &nativeAuth{Err: errors.New("native auth is only supported for policies targeting HTTPRoutes")}
+	}
+
+	jwt, err := envoyGatewayJWTFromAuthPolicy(ctx, r.jwksURICache(), ap)
+	if err != nil {
+		return &nativeAuth{Err: err}
+	}
+	return &nativeAuth{JWT: jwt}
+}
+
+func (r *AuthPolicyReconciler) jwksURICache() *jwksURICache {
+	r.jwksURIsOnce.Do(func() {
+		httpClient := r.HTTPClient
+		if httpClient == nil {
+			httpClient = &http.Client{Timeout: oidcDiscoveryTimeout}
+		}
+		r.jwksURIs = newJWKSURICache(httpClient)
+	})
+	return r.jwksURIs
+}
+
+// jwksURICache caches the JWKS URIs discovered out of the OpenID Connect discovery documents of the issuers, so the
+// documents are not fetched on every reconciliation of the policies. When the discovery fails, the last URI discovered
+// for the issuer is kept, so the policies enforced natively do not fall back to Authorino on transient errors.
+type jwksURICache struct {
+	httpClient *http.Client
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]jwksURICacheEntry
+}
+
+type jwksURICacheEntry struct {
+	uri       string
+	err       error
+	expiresAt time.Time
+}
+
+func newJWKSURICache(httpClient *http.Client) *jwksURICache {
+	return &jwksURICache{
+		httpClient: httpClient,
+		now:        time.Now,
+		entries:    make(map[string]jwksURICacheEntry),
+	}
+}
+
+// Get returns the JWKS URI of the issuer, discovering it if it is not cached or has expired
+func (c *jwksURICache) Get(ctx context.Context, issuer string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, found := c.entries[issuer]
+	if found && c.now().Before(entry.expiresAt) {
+		return entry.uri, entry.err
+	}
+
+	uri, err := discoverJWKSURI(ctx, c.httpClient, issuer)
+	switch {
+	case err == nil:
+		entry = jwksURICacheEntry{uri: uri, expiresAt: c.now().Add(jwksURITTL)}
+	case entry.uri != "":
+		// keep the last good uri and try again later
+		entry = jwksURICacheEntry{uri: entry.uri, expiresAt: c.now().Add(jwksURIRetryInterval)}
+	default:
+		entry = jwksURICacheEntry{err: err, expiresAt: c.now().Add(jwksURIRetryInterval)}
+	}
+	c.entries[issuer] = entry
+	return entry.uri, entry.err
+}
+
+// envoyGatewayJWTFromAuthPolicy translates an auth scheme made only of JWT authentication rules, and optionally
+// authorization rules that check a single audience, into the native JWT authentication of Envoy Gateway.
+// It returns an error describing the first feature that cannot be expressed natively.
+func envoyGatewayJWTFromAuthPolicy(ctx context.Context, jwksURIs *jwksURICache, ap *api.AuthPolicy) (*egapi.JWT, error) {
+	spec := ap.Spec.CommonSpec()
+
+	if len(spec.RouteSelectors) > 0 {
+		return nil, errors.New("top-level route selectors are not supported natively")
+	}
+	if len(spec.Conditions) > 0 {
+		return nil, errors.New("top-level conditions are not supported natively")
+	}
+
+	scheme := spec.AuthScheme
+	if scheme == nil || len(scheme.Authentication) == 0 {
+		return nil, errors.New("no authentication rules")
+	}
+	if len(scheme.Metadata) > 0 {
+		return nil, errors.New("metadata rules are not supported natively")
+	}
+	if scheme.Response != nil {
+		return nil, errors.New("response rules are not supported natively")
+	}
+	if len(scheme.Callbacks) > 0 {
+		return nil, errors.New("callbacks are not supported natively")
+	}
+
+	audiences, err := audiencesFromAuthorizationRules(scheme.Authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	providers := make([]egapi.JWTProvider, 0, len(scheme.Authentication))
+	for _, name := range sortedMapKeys(scheme.Authentication) {
+		rule := scheme.Authentication[name]
+		if err := validateNativeRule(rule.RouteSelectors, rule.CommonEvaluatorSpec); err != nil {
+			return nil, fmt.Errorf("authentication rule %s: %w", name, err)
+		}
+		if rule.GetMethod() != authorinoapi.JwtAuthentication {
+			return nil, fmt.Errorf("authentication rule %s: only jwt authentication is supported natively", name)
+		}
+		if len(rule.Overrides) > 0 || len(rule.Defaults) > 0 {
+			return nil, fmt.Errorf("authentication rule %s: overrides and defaults are not supported natively", name)
+		}
+
+		extractor, err := jwtExtractorFromCredentials(rule.Credentials)
+		if err != nil {
+			return nil, fmt.Errorf("authentication rule %s: %w", name, err)
+		}
+
+		issuer := rule.Jwt.IssuerUrl
+		jwksURI, err := jwksURIs.Get(ctx, issuer)
+		if err != nil {
+			return nil, fmt.Errorf("authentication rule %s: %w", name, err)
+		}
+
+		providers = append(providers, egapi.JWTProvider{
+			Name:        name,
+			Issuer:      issuer,
+			Audiences:   audiences,
+			RemoteJWKS:  egapi.RemoteJWKS{URI: jwksURI},
+			ExtractFrom: extractor,
+		})
+	}
+
+	return &egapi.JWT{Providers: providers}, nil
+}
+
+// audiencesFromAuthorizationRules returns the audience required by the pattern-matching authorization rules.
+// Envoy Gateway accepts a token if it has any of the audiences of the provider, thus only one audience can be required.
+func audiencesFromAuthorizationRules(rules map[string]api.AuthorizationSpec) ([]string, error) {
+	var audiences []string
+	for _, name := range sortedMapKeys(rules) {
+		rule := rules[name]
+		if err := validateNativeRule(rule.RouteSelectors, rule.CommonEvaluatorSpec); err != nil {
+			return nil, fmt.Errorf("authorization rule %s: %w", name, err)
+		}
+		if rule.PatternMatching == nil {
+			return nil, fmt.Errorf("authorization rule %s: only pattern-matching authorization is supported natively", name)
+		}
+		for _, pattern := range rule.PatternMatching.Patterns {
+			if pattern.PatternRef.Name != "" || len(pattern.All) > 0 || len(pattern.Any) > 0 ||
+				pattern.Selector != audienceSelector ||
+				(pattern.Operator != "eq" && pattern.Operator != "incl") {
+				return nil, fmt.Errorf("authorization rule %s: only patterns checking the audience (%s) with the eq or incl operators are supported natively", name, audienceSelector)
+			}
+			if !slices.Contains(audiences, pattern.Value) {
+				audiences = append(audiences, pattern.Value)
+			}
+		}
+	}
+	if len(audiences) > 1 {
+		return nil, errors.New("requiring more than one audience is not supported natively")
+	}
+	return audiences, nil
+}
+
+func validateNativeRule(routeSelectors []api.RouteSelector, evaluator authorinoapi.CommonEvaluatorSpec) error {
+	if len(routeSelectors) > 0 {
+		return errors.New("route selectors are not supported natively")
+	}
+	if len(evaluator.Conditions) > 0 {
+		return errors.New("conditions are not supported natively")
+	}
+	if evaluator.Cache != nil {
+		return errors.New("caching is not supported natively")
+	}
+	if evaluator.Metrics {
+		return errors.New("metrics are not supported natively")
+	}
+	return nil
+}
+
+// jwtExtractorFromCredentials returns where Envoy Gateway extracts the token from.
+// It returns nil for the default location, i.e. the Authorization header with the Bearer prefix.
+func jwtExtractorFromCredentials(credentials authorinoapi.Credentials) (*egapi.JWTExtractor, error) {
+	switch credentials.GetType() {
+	case authorinoapi.UnknownCredentialsType:
+		return nil, nil
+	case authorinoapi.AuthorizationHeaderCredentials:
+		prefix := credentials.AuthorizationHeader.Prefix
+		if prefix == "" || prefix == "Bearer" {
+			return nil, nil
+		}
+		return &egapi.JWTExtractor{
+			Headers: []egapi.JWTHeaderExtractor{{Name: "Authorization", ValuePrefix: ptr.To(prefix + " ")}},
+		}, nil
+	case authorinoapi.CustomHeaderCredentials:
+		return &egapi.JWTExtractor{
+			Headers: []egapi.JWTHeaderExtractor{{Name: credentials.CustomHeader.Name}},
+		}, nil
+	case authorinoapi.QueryStringCredentials:
+		return &egapi.JWTExtractor{Params: []string{credentials.QueryString.Name}}, nil
+	case authorinoapi.CookieCredentials:
+		return &egapi.JWTExtractor{Cookies: []string{credentials.Cookie.Name}}, nil
+	default:
+		return nil, errors.New("unsupported credentials")
+	}
+}
+
+// discoverJWKSURI reads the JWKS URI from the OpenID Connect discovery document of the issuer,
+// the same way Authorino does for jwt authentication
+func discoverJWKSURI(ctx context.Context, httpClient *http.Client, issuer string) (string, error) {
+	if issuer == "" {
+		return "", errors.New("missing issuer url")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(issuer, "/")+oidcDiscoveryPath, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to discover the openid configuration of %s: %w", issuer, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to discover the openid configuration of %s: unexpected status code %d", issuer, resp.StatusCode)
+	}
+
+	config := struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&config); err != nil {
+		return "", fmt.Errorf("failed to decode the openid configuration of %s: %w", issuer, err)
+	}
+	if config.Issuer != issuer {
+		return "", fmt.Errorf("issuer %s of the openid configuration does not match %s", config.Issuer, issuer)
+	}
+	if config.JWKSURI == "" {
+		return "", fmt.Errorf("missing jwks_uri in the openid configuration of %s", issuer)
+	}
+	return config.JWKSURI, nil
+}
+
+// nativeAuthCondition reports the auth backend in use for the policies opted in to native auth
+func nativeAuthCondition(native *nativeAuth) *metav1.Condition {
+	if native.InUse() {
+		return &metav1.Condition{
+			Type:    api.AuthPolicyConditionNativeAuth,
+			Status:  metav1.ConditionTrue,
+			Reason:  api.AuthPolicyReasonNativeAuthEnvoyGateway,
+			Message: "AuthPolicy is enforced by the native JWT authentication of Envoy Gateway",
+		}
+	}
+	return &metav1.Condition{
+		Type:    api.AuthPolicyConditionNativeAuth,
+		Status:  metav1.ConditionFalse,
+		Reason:  api.AuthPolicyReasonNativeAuthAuthorino,
+		Message: fmt.Sprintf("AuthPolicy falls back to Authorino: %v", native.Err),
+	}
+}
+
+// isSecurityPolicyAccepted checks if the Envoy Gateway SecurityPolicy of the policy enforced natively is accepted
+// by all the gateways the targeted route is attached to
+func (r *AuthPolicyReconciler) isSecurityPolicyAccepted(ctx context.Context, targetNetworkObject client.Object) (bool, error) {
+	esp := &egapi.SecurityPolicy{}
+	key := client.ObjectKey{Name: envoySecurityPolicyName(targetNetworkObject), Namespace: targetNetworkObject.GetNamespace()}
+	if err := r.Client().Get(ctx, key, esp); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	if len(esp.Status.Ancestors) == 0 {
+		return false, nil
+	}
+	for _, ancestor := range esp.Status.Ancestors {
+		if !meta.IsStatusConditionTrue(ancestor.Conditions, string(gatewayapiv1alpha2.PolicyConditionAccepted)) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func sortedMapKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
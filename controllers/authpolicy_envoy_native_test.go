@@ -0,0 +1,239 @@
+//go:build unit
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	egapi "github.com/envoyproxy/gateway/api/v1alpha1"
+	authorinoapi "github.com/kuadrant/authorino/api/v1beta2"
+	"gotest.tools/assert"
+	"k8s.io/utils/ptr"
+
+	api "github.com/kuadrant/kuadrant-operator/api/v1beta2"
+)
+
+func testOIDCServer(t *testing.T) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/realm"+oidcDiscoveryPath {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		assert.NilError(t, json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   server.URL + "/realm",
+			"jwks_uri": server.URL + "/realm/certs",
+		}))
+	}))
+	return server
+}
+
+func testJWTAuthenticationSpec(issuer string, credentials authorinoapi.Credentials) api.AuthenticationSpec {
+	return api.AuthenticationSpec{
+		AuthenticationSpec: authorinoapi.AuthenticationSpec{
+			AuthenticationMethodSpec: authorinoapi.AuthenticationMethodSpec{
+				Jwt: &authorinoapi.JwtAuthenticationSpec{IssuerUrl: issuer},
+			},
+			Credentials: credentials,
+		},
+	}
+}
+
+func testAudienceAuthorizationSpec(audience string) api.AuthorizationSpec {
+	return api.AuthorizationSpec{
+		AuthorizationSpec: authorinoapi.AuthorizationSpec{
+			AuthorizationMethodSpec: authorinoapi.AuthorizationMethodSpec{
+				PatternMatching: &authorinoapi.PatternMatchingAuthorizationSpec{
+					Patterns: []authorinoapi.PatternExpressionOrRef{
+						{PatternExpression: authorinoapi.PatternExpression{Selector: "auth.identity.aud", Operator: "incl", Value: audience}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestEnvoyGatewayJWTFromAuthPolicy(t *testing.T) {
+	server := testOIDCServer(t)
+	defer server.Close()
+	issuer := server.URL + "/realm"
+
+	testCases := []struct {
+		name        string
+		scheme      *api.AuthSchemeSpec
+		expected    *egapi.JWT
+		expectedErr string
+	}{
+		{
+			name: "jwt authentication with audience",
+			scheme: &api.AuthSchemeSpec{
+				Authentication: map[string]api.AuthenticationSpec{
+					"keycloak": testJWTAuthenticationSpec(issuer, authorinoapi.Credentials{}),
+				},
+				Authorization: map[string]api.AuthorizationSpec{
+					"audience": testAudienceAuthorizationSpec("toystore"),
+				},
+			},
+			expected: &egapi.JWT{
+				Providers: []egapi.JWTProvider{
+					{
+						Name:       "keycloak",
+						Issuer:     issuer,
+						Audiences:  []string{"toystore"},
+						RemoteJWKS: egapi.RemoteJWKS{URI: issuer + "/certs"},
+					},
+				},
+			},
+		},
+		{
+			name: "custom credentials",
+			scheme: &api.AuthSchemeSpec{
+				Authentication: map[string]api.AuthenticationSpec{
+					"a-header": testJWTAuthenticationSpec(issuer, authorinoapi.Credentials{
+						AuthorizationHeader: &authorinoapi.Prefixed{Prefix: "JWT"},
+					}),
+					"b-cookie": testJWTAuthenticationSpec(issuer, authorinoapi.Credentials{
+						Cookie: &authorinoapi.Named{Name: "token"},
+					}),
+				},
+			},
+			expected: &egapi.JWT{
+				Providers: []egapi.JWTProvider{
+					{
+						Name:       "a-header",
+						Issuer:     issuer,
+						RemoteJWKS: egapi.RemoteJWKS{URI: issuer + "/certs"},
+						ExtractFrom: &egapi.JWTExtractor{
+							Headers: []egapi.JWTHeaderExtractor{{Name: "Authorization", ValuePrefix: ptr.To("JWT ")}},
+						},
+					},
+					{
+						Name:        "b-cookie",
+						Issuer:      issuer,
+						RemoteJWKS:  egapi.RemoteJWKS{URI: issuer + "/certs"},
+						ExtractFrom: &egapi.JWTExtractor{Cookies: []string{"token"}},
+					},
+				},
+			},
+		},
+		{
+			name: "api key authentication",
+			scheme: &api.AuthSchemeSpec{
+				Authentication: map[string]api.AuthenticationSpec{
+					"api-key": {AuthenticationSpec: authorinoapi.AuthenticationSpec{
+						AuthenticationMethodSpec: authorinoapi.AuthenticationMethodSpec{ApiKey: &authorinoapi.ApiKeyAuthenticationSpec{}},
+					}},
+				},
+			},
+			expectedErr: "only jwt authentication is supported natively",
+		},
+		{
+			name: "more than one audience",
+			scheme: &api.AuthSchemeSpec{
+				Authentication: map[string]api.AuthenticationSpec{
+					"keycloak": testJWTAuthenticationSpec(issuer, authorinoapi.Credentials{}),
+				},
+				Authorization: map[string]api.AuthorizationSpec{
+					"toystore": testAudienceAuthorizationSpec("toystore"),
+					"petstore": testAudienceAuthorizationSpec("petstore"),
+				},
+			},
+			expectedErr: "more than one audience",
+		},
+		{
+			name: "metadata",
+			scheme: &api.AuthSchemeSpec{
+				Authentication: map[string]api.AuthenticationSpec{
+					"keycloak": testJWTAuthenticationSpec(issuer, authorinoapi.Credentials{}),
+				},
+				Metadata: map[string]api.MetadataSpec{"userinfo": {}},
+			},
+			expectedErr: "metadata rules are not supported natively",
+		},
+		{
+			name: "issuer mismatch",
+			scheme: &api.AuthSchemeSpec{
+				Authentication: map[string]api.AuthenticationSpec{
+					"keycloak": testJWTAuthenticationSpec(issuer+"/", authorinoapi.Credentials{}),
+				},
+			},
+			expectedErr: "does not match",
+		},
+		{
+			name: "discovery failure",
+			scheme: &api.AuthSchemeSpec{
+				Authentication: map[string]api.AuthenticationSpec{
+					"keycloak": testJWTAuthenticationSpec(server.URL+"/unknown", authorinoapi.Credentials{}),
+				},
+			},
+			expectedErr: "unexpected status code 404",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(subT *testing.T) {
+			ap := &api.AuthPolicy{Spec: api.AuthPolicySpec{AuthPolicyCommonSpec: api.AuthPolicyCommonSpec{AuthScheme: tc.scheme}}}
+			jwt, err := envoyGatewayJWTFromAuthPolicy(context.Background(), newJWKSURICache(server.Client()), ap)
+			if tc.expectedErr != "" {
+				assert.Assert(subT, err != nil && strings.Contains(err.Error(), tc.expectedErr), "unexpected error: %v", err)
+				return
+			}
+			assert.NilError(subT, err)
+			assert.DeepEqual(subT, jwt, tc.expected)
+		})
+	}
+}
+
+func TestJWKSURICache(t *testing.T) {
+	requests := 0
+	available := true
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		if !available {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		assert.NilError(t, json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   server.URL + "/realm",
+			"jwks_uri": server.URL + "/realm/certs",
+		}))
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	cache := newJWKSURICache(server.Client())
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+	issuer := server.URL + "/realm"
+
+	uri, err := cache.Get(ctx, issuer)
+	assert.NilError(t, err)
+	assert.Equal(t, uri, server.URL+"/realm/certs")
+
+	// cached
+	_, err = cache.Get(ctx, issuer)
+	assert.NilError(t, err)
+	assert.Equal(t, requests, 1)
+
+	// expired and the discovery fails: the last good uri is kept
+	available = false
+	now = now.Add(jwksURITTL)
+	uri, err = cache.Get(ctx, issuer)
+	assert.NilError(t, err)
+	assert.Equal(t, uri, server.URL+"/realm/certs")
+	assert.Equal(t, requests, 2)
+
+	// failures of unknown issuers are remembered until the retry interval
+	_, err = cache.Get(ctx, server.URL+"/other")
+	assert.ErrorContains(t, err, "unexpected status code 503")
+	_, err = cache.Get(ctx, server.URL+"/other")
+	assert.ErrorContains(t, err, "unexpected status code 503")
+	assert.Equal(t, requests, 3)
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"slices"
 
 	egapi "github.com/envoyproxy/gateway/api/v1alpha1"
 	"github.com/go-logr/logr"
 	api "github.com/kuadrant/kuadrant-operator/api/v1beta2"
 	"github.com/kuadrant/kuadrant-operator/pkg/common"
+	"github.com/kuadrant/kuadrant-operator/pkg/kuadranttools"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/kuadrant"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/reconcilers"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/utils"
@@ -26,13 +28,13 @@ const (
 	kuadrantReferenceGrantName = "kuadrant-authorization-rg"
 )
 
-func (r *AuthPolicyReconciler) reconcileEnvoySecurityPolicies(ctx context.Context, ap *api.AuthPolicy, targetNetworkObject client.Object, gwDiffObj *reconcilers.GatewayDiffs) error {
+func (r *AuthPolicyReconciler) reconcileEnvoySecurityPolicies(ctx context.Context, ap *api.AuthPolicy, targetNetworkObject client.Object, gwDiffObj *reconcilers.GatewayDiffs, native *nativeAuth, authorinoName string) error {
 	logger, err := logr.FromContext(ctx)
 	if err != nil {
 		return err
 	}
 	// Create EnvoySecurityPolicy for the authpolicy targetting the route or the gateway
-	esp, err := r.envoySecurityPolicy(ctx, ap, targetNetworkObject, gwDiffObj)
+	esp, err := r.envoySecurityPolicy(ctx, ap, targetNetworkObject, gwDiffObj, native, authorinoName)
 	if err != nil {
 		return err
 	}
@@ -53,7 +55,7 @@ func (r *AuthPolicyReconciler) reconcileEnvoySecurityPolicies(ctx context.Contex
 	return nil
 }
 
-func (r *AuthPolicyReconciler) envoySecurityPolicy(ctx context.Context, ap *api.AuthPolicy, targetNetworkObject client.Object, gwDiffObj *reconcilers.GatewayDiffs) (*egapi.SecurityPolicy, error) {
+func (r *AuthPolicyReconciler) envoySecurityPolicy(ctx context.Context, ap *api.AuthPolicy, targetNetworkObject client.Object, gwDiffObj *reconcilers.GatewayDiffs, native *nativeAuth, authorinoName string) (*egapi.SecurityPolicy, error) {
 	logger, _ := logr.FromContext(ctx)
 	logger = logger.WithName("envoySecurityPolicy")
 
@@ -70,7 +72,7 @@ func (r *AuthPolicyReconciler) envoySecurityPolicy(ctx context.Context, ap *api.
 
 	esp := &egapi.SecurityPolicy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("on-%s", targetNetworkObject.GetName()),
+			Name:      envoySecurityPolicyName(targetNetworkObject),
 			Namespace: targetNetworkObject.GetNamespace(),
 			Labels:    envoySecurityPolicyLabels(client.ObjectKeyFromObject(ap), kuadrantNamespace),
 		},
@@ -82,7 +84,7 @@ func (r *AuthPolicyReconciler) envoySecurityPolicy(ctx context.Context, ap *api.
 			ExtAuth: &egapi.ExtAuth{
 				GRPC: &egapi.GRPCExtAuthService{
 					BackendRef: gatewayapiv1.BackendObjectReference{
-						Name:      gatewayapiv1.ObjectName("authorino-authorino-authorization"),
+						Name:      gatewayapiv1.ObjectName(kuadranttools.AuthorinoAuthorizationServiceName(authorinoName)),
 						Namespace: ptr.To(gatewayapiv1.Namespace(kuadrantNamespace)),
 						Port:      ptr.To(gatewayapiv1.PortNumber(50051)),
 					},
@@ -97,6 +99,19 @@ func (r *AuthPolicyReconciler) envoySecurityPolicy(ctx context.Context, ap *api.
 		return esp, nil
 	}
 
+	// the route authpolicy that disables auth overrides the security policy of the gateway with one without auth
+	if ap.Spec.Disabled {
+		esp.Spec.ExtAuth = nil
+		return esp, nil
+	}
+
+	// the route authpolicy enforced natively does not rely on authorino, thus it does not depend on the gateway authpolicy
+	if native.InUse() {
+		esp.Spec.ExtAuth = nil
+		esp.Spec.JWT = native.JWT
+		return esp, nil
+	}
+
 	switch targetNetworkObject.(type) {
 	case *gatewayapiv1.Gateway:
 		// Check there is at least one httproute attached to the gateway
@@ -117,7 +132,7 @@ func (r *AuthPolicyReconciler) envoySecurityPolicy(ctx context.Context, ap *api.
 	return esp, nil
 }
 
-// Creates a reference grant permitting access to the authorino service from the security group namespace
+// Creates a reference grant permitting access to the authorino services from the security group namespace
 // This is required for both xRoutes as well as Gateways, however this may not be required for gateways in future - see https://github.com/envoyproxy/gateway/issues/3450
 func (r *AuthPolicyReconciler) securityPolicyReferenceGrant(ctx context.Context, ap *api.AuthPolicy) (*gatewayapiv1beta1.ReferenceGrant, error) {
 	logger, _ := logr.FromContext(ctx)
@@ -139,18 +154,11 @@ func (r *AuthPolicyReconciler) securityPolicyReferenceGrant(ctx context.Context,
 			Name:      kuadrantReferenceGrantName,
 			Namespace: kuadrantNamespace,
 		},
-		Spec: gatewayapiv1beta1.ReferenceGrantSpec{
-			To: []gatewayapiv1beta1.ReferenceGrantTo{
-				{
-					Group: "",
-					Kind:  "Service",
-					Name:  ptr.To(gatewayapiv1.ObjectName("authorino-authorino-authorization")),
-				},
-			},
-		},
 	}
 
 	espNamespaces := make(map[string]struct{})
+	// the shared authorino service is always granted, plus the services of the dedicated instances in use
+	services := []string{kuadranttools.AuthorinoAuthorizationServiceName(common.AuthorinoName)}
 	listOptions := &client.ListOptions{LabelSelector: labels.SelectorFromSet(map[string]string{kuadrant.KuadrantNamespaceAnnotation: kuadrantNamespace})}
 	espList := &egapi.SecurityPolicyList{}
 	if err := r.Client().List(ctx, espList, listOptions); err != nil {
@@ -162,6 +170,9 @@ func (r *AuthPolicyReconciler) securityPolicyReferenceGrant(ctx context.Context,
 		// if the authpolicy is pending deletion for the namespace of the security policy then do not append
 		if esp.DeletionTimestamp == nil && esp.Namespace != kuadrantNamespace && (ap.DeletionTimestamp == nil || ap.Namespace != esp.Namespace) {
 			espNamespaces[esp.Namespace] = struct{}{}
+			if esp.Spec.ExtAuth != nil && esp.Spec.ExtAuth.GRPC != nil && !slices.Contains(services, string(esp.Spec.ExtAuth.GRPC.BackendRef.Name)) {
+				services = append(services, string(esp.Spec.ExtAuth.GRPC.BackendRef.Name))
+			}
 		}
 	}
 
@@ -177,6 +188,15 @@ func (r *AuthPolicyReconciler) securityPolicyReferenceGrant(ctx context.Context,
 	}
 	rg.Spec.From = refGrantFrom
 
+	slices.Sort(services)
+	rg.Spec.To = utils.Map(services, func(service string) gatewayapiv1beta1.ReferenceGrantTo {
+		return gatewayapiv1beta1.ReferenceGrantTo{
+			Group: "",
+			Kind:  "Service",
+			Name:  ptr.To(gatewayapiv1.ObjectName(service)),
+		}
+	})
+
 	return rg, nil
 }
 
@@ -197,6 +217,11 @@ func alwaysUpdateEnvoySecurityPolicy(existingObj, desiredObj client.Object) (boo
 		existing.Spec.ExtAuth = desired.Spec.ExtAuth
 	}
 
+	if !reflect.DeepEqual(existing.Spec.JWT, desired.Spec.JWT) {
+		update = true
+		existing.Spec.JWT = desired.Spec.JWT
+	}
+
 	if !reflect.DeepEqual(existing.Spec.TargetRef, desired.Spec.TargetRef) {
 		update = true
 		existing.Spec.TargetRef = desired.Spec.TargetRef
@@ -239,6 +264,10 @@ func alwaysUpdateAuthReferenceGrant(existingObj, desiredObj client.Object) (bool
 	return update, nil
 }
 
+func envoySecurityPolicyName(targetNetworkObject client.Object) string {
+	return fmt.Sprintf("on-%s", targetNetworkObject.GetName())
+}
+
 func referenceGrantFromNamespace(namespace gatewayapiv1.Namespace) gatewayapiv1beta1.ReferenceGrantFrom {
 	return gatewayapiv1beta1.ReferenceGrantFrom{
 		Group:     egapi.GroupName,          // must be envoy-gateway group name
@@ -5,8 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
+	"sort"
+	"strings"
 
 	"github.com/go-logr/logr"
+	"google.golang.org/protobuf/proto"
 	istiosecurity "istio.io/api/security/v1beta1"
 	istio "istio.io/client-go/pkg/apis/security/v1beta1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -29,7 +33,7 @@ import (
 var KuadrantExtAuthProviderName = env.GetString("AUTH_PROVIDER", "kuadrant-authorization")
 
 // reconcileIstioAuthorizationPolicies translates and reconciles `AuthRules` into an Istio AuthorizationPoilcy containing them.
-func (r *AuthPolicyReconciler) reconcileIstioAuthorizationPolicies(ctx context.Context, ap *api.AuthPolicy, targetNetworkObject client.Object, gwDiffObj *reconcilers.GatewayDiffs) error {
+func (r *AuthPolicyReconciler) reconcileIstioAuthorizationPolicies(ctx context.Context, ap *api.AuthPolicy, targetNetworkObject client.Object, gwDiffObj *reconcilers.GatewayDiffs, authorinoName string) error {
 	if err := r.deleteIstioAuthorizationPolicies(ctx, ap, gwDiffObj); err != nil {
 		return err
 	}
@@ -41,7 +45,7 @@ func (r *AuthPolicyReconciler) reconcileIstioAuthorizationPolicies(ctx context.C
 
 	// Create IstioAuthorizationPolicy for each gateway directly or indirectly referred by the policy (existing and new)
 	for _, gw := range append(gwDiffObj.GatewaysWithValidPolicyRef, gwDiffObj.GatewaysMissingPolicyRef...) {
-		iap, err := r.istioAuthorizationPolicy(ctx, ap, targetNetworkObject, gw)
+		iap, err := r.istioAuthorizationPolicy(ctx, ap, targetNetworkObject, gw, authorinoName)
 		if err != nil {
 			return err
 		}
@@ -81,7 +85,7 @@ func (r *AuthPolicyReconciler) deleteIstioAuthorizationPolicies(ctx context.Cont
 	return nil
 }
 
-func (r *AuthPolicyReconciler) istioAuthorizationPolicy(ctx context.Context, ap *api.AuthPolicy, targetNetworkObject client.Object, gw kuadrant.GatewayWrapper) (*istio.AuthorizationPolicy, error) {
+func (r *AuthPolicyReconciler) istioAuthorizationPolicy(ctx context.Context, ap *api.AuthPolicy, targetNetworkObject client.Object, gw kuadrant.GatewayWrapper, authorinoName string) (*istio.AuthorizationPolicy, error) {
 	logger, _ := logr.FromContext(ctx)
 	logger = logger.WithName("istioAuthorizationPolicy")
 
@@ -98,12 +102,20 @@ func (r *AuthPolicyReconciler) istioAuthorizationPolicy(ctx context.Context, ap
 			Selector: kuadrantistioutils.WorkloadSelectorFromGateway(ctx, r.Client(), gateway),
 			ActionDetail: &istiosecurity.AuthorizationPolicy_Provider{
 				Provider: &istiosecurity.AuthorizationPolicy_ExtensionProvider{
-					Name: KuadrantExtAuthProviderName,
+					Name: istioExtAuthProviderName(authorinoName),
 				},
 			},
 		},
 	}
 
+	// the rules of the route that disables auth are excluded from the authorizationpolicy of the gateway already,
+	// as the route has an authpolicy of its own
+	if ap.Spec.Disabled {
+		logger.V(1).Info("auth disabled for the route, skipping istio authorizationpolicy for the route authpolicy")
+		utils.TagObjectToDelete(iap)
+		return iap, nil
+	}
+
 	var route *gatewayapiv1.HTTPRoute
 
 	gwHostnames := gw.Hostnames()
@@ -156,6 +168,9 @@ func (r *AuthPolicyReconciler) istioAuthorizationPolicy(ctx context.Context, ap
 		// make sure all istio authorizationpolicy rules include the hosts so we don't send a request to authorino for hosts that are not in the scope of the policy
 		hosts := utils.HostnamesToStrings(routeHostnames)
 		for i := range rules {
+			if len(rules[i].To) == 0 {
+				rules[i].To = []*istiosecurity.Rule_To{{Operation: &istiosecurity.Operation{}}}
+			}
 			for j := range rules[i].To {
 				if len(rules[i].To[j].Operation.Hosts) > 0 {
 					continue
@@ -169,6 +184,15 @@ func (r *AuthPolicyReconciler) istioAuthorizationPolicy(ctx context.Context, ap
 	return iap, nil
 }
 
+// istioExtAuthProviderName returns the name of the extension provider of the mesh config that points to the
+// authorino instance, i.e. the kuadrant one for the shared instance, or one suffixed with the name of the dedicated instance
+func istioExtAuthProviderName(authorinoName string) string {
+	if authorinoName == common.AuthorinoName {
+		return KuadrantExtAuthProviderName
+	}
+	return fmt.Sprintf("%s-%s", KuadrantExtAuthProviderName, authorinoName)
+}
+
 // istioAuthorizationPolicyName generates the name of an AuthorizationPolicy.
 func istioAuthorizationPolicyName(gwName string, targetRef gatewayapiv1alpha2.PolicyTargetReference) string {
 	switch targetRef.Kind {
@@ -184,8 +208,8 @@ func istioAuthorizationPolicyLabels(gwKey, apKey client.ObjectKey) map[string]st
 	return map[string]string{
 		common.AuthPolicyBackRefAnnotation:                              apKey.Name,
 		fmt.Sprintf("%s-namespace", common.AuthPolicyBackRefAnnotation): apKey.Namespace,
-		"gateway-namespace":                                             gwKey.Namespace,
-		"gateway":                                                       gwKey.Name,
+		"gateway-namespace": gwKey.Namespace,
+		"gateway":           gwKey.Name,
 	}
 }
 
@@ -222,7 +246,7 @@ func istioAuthorizationPolicyRulesFromRouteSelectors(route *gatewayapiv1.HTTPRou
 		}
 	}
 
-	return istioRules, nil
+	return mergeIstioAuthorizationPolicyRules(istioRules), nil
 }
 
 // istioAuthorizationPolicyRulesFromHTTPRoute builds a list of Istio AuthorizationPolicy rules from an HTTPRoute,
@@ -235,7 +259,112 @@ func istioAuthorizationPolicyRulesFromHTTPRoute(route *gatewayapiv1.HTTPRoute) [
 		istioRules = append(istioRules, istioAuthorizationPolicyRulesFromHTTPRouteRule(rule, hostnamesForConditions)...)
 	}
 
-	return istioRules
+	return mergeIstioAuthorizationPolicyRules(istioRules)
+}
+
+// mergeIstioAuthorizationPolicyRules merges Istio AuthorizationPolicy rules that share the same `when` conditions
+// into a single rule whose `to` operations are the union of the operations of the merged rules.
+// Operations of a rule are OR'ed, so merging does not change the set of requests matched by the rules.
+// * Rules are kept in the order of first appearance of their `when` conditions.
+// * Duplicate operations are dropped.
+// * A rule without operations, or with an empty operation, matches any request under its `when` conditions,
+// so the merged rule has no operations either. If it has no `when` conditions either, it is the only rule returned.
+func mergeIstioAuthorizationPolicyRules(rules []*istiosecurity.Rule) []*istiosecurity.Rule {
+	merged := []*istiosecurity.Rule{}
+	catchAll := map[string]bool{}
+	index := map[string]int{}
+
+	for _, rule := range rules {
+		key := istioAuthorizationPolicyConditionsKey(rule.When)
+		idx, exists := index[key]
+		if !exists {
+			index[key] = len(merged)
+			catchAll[key] = isCatchAllIstioAuthorizationPolicyRule(rule)
+			mergedRule := &istiosecurity.Rule{From: rule.From, When: rule.When}
+			if !catchAll[key] {
+				mergedRule.To = append([]*istiosecurity.Rule_To{}, rule.To...)
+			}
+			merged = append(merged, mergedRule)
+			continue
+		}
+		if catchAll[key] {
+			continue
+		}
+		if isCatchAllIstioAuthorizationPolicyRule(rule) {
+			catchAll[key] = true
+			merged[idx].To = nil
+			continue
+		}
+		for _, to := range rule.To {
+			merged[idx].To = mergeIstioAuthorizationPolicyOperation(merged[idx].To, to)
+		}
+	}
+
+	// a rule that matches any request without conditions makes all other rules redundant
+	if idx, exists := index[""]; exists && catchAll[""] {
+		return []*istiosecurity.Rule{merged[idx]}
+	}
+
+	return merged
+}
+
+// mergeIstioAuthorizationPolicyOperation adds an operation to a list of operations of an Istio AuthorizationPolicy rule.
+// Operations that differ only by their paths are collapsed into one, and duplicate operations are dropped.
+func mergeIstioAuthorizationPolicyOperation(tos []*istiosecurity.Rule_To, to *istiosecurity.Rule_To) []*istiosecurity.Rule_To {
+	withoutPaths := func(operation *istiosecurity.Operation) *istiosecurity.Operation {
+		if operation == nil {
+			return nil
+		}
+		o := operation.DeepCopy()
+		o.Paths = nil
+		return o
+	}
+
+	for i := range tos {
+		if proto.Equal(tos[i], to) {
+			return tos
+		}
+		existing := tos[i].Operation
+		if existing == nil || to.Operation == nil || len(existing.Paths) == 0 || len(to.Operation.Paths) == 0 {
+			continue
+		}
+		if !proto.Equal(withoutPaths(existing), withoutPaths(to.Operation)) {
+			continue
+		}
+		operation := existing.DeepCopy()
+		for _, path := range to.Operation.Paths {
+			if !slices.Contains(operation.Paths, path) {
+				operation.Paths = append(operation.Paths, path)
+			}
+		}
+		tos[i] = &istiosecurity.Rule_To{Operation: operation}
+		return tos
+	}
+
+	return append(tos, to)
+}
+
+// isCatchAllIstioAuthorizationPolicyRule tells whether an Istio AuthorizationPolicy rule matches any request
+// under its `when` conditions, i.e. it has no operations or at least one of its operations is empty
+func isCatchAllIstioAuthorizationPolicyRule(rule *istiosecurity.Rule) bool {
+	if len(rule.To) == 0 {
+		return true
+	}
+	_, found := utils.Find(rule.To, func(to *istiosecurity.Rule_To) bool {
+		return to.Operation == nil || proto.Equal(to.Operation, &istiosecurity.Operation{})
+	})
+	return found
+}
+
+// istioAuthorizationPolicyConditionsKey returns a canonical representation of a list of `when` conditions of an
+// Istio AuthorizationPolicy rule, regardless of the order of the conditions
+func istioAuthorizationPolicyConditionsKey(conditions []*istiosecurity.Condition) string {
+	keys := make([]string, 0, len(conditions))
+	for _, condition := range conditions {
+		keys = append(keys, fmt.Sprintf("%s=%s!%s", condition.Key, strings.Join(condition.Values, ","), strings.Join(condition.NotValues, ",")))
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ";")
 }
 
 // istioAuthorizationPolicyRulesFromHTTPRouteRule builds a list of Istio AuthorizationPolicy rules from a HTTPRouteRule
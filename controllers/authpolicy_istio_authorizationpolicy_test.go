@@ -3,14 +3,25 @@
 package controllers
 
 import (
+	"flag"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
+	"google.golang.org/protobuf/proto"
 	istiosecurity "istio.io/api/security/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
 	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	"sigs.k8s.io/yaml"
+
+	api "github.com/kuadrant/kuadrant-operator/api/v1beta2"
+	"github.com/kuadrant/kuadrant-operator/pkg/common"
 )
 
+var updateGoldenFiles = flag.Bool("update", false, "update the golden files of the tests")
+
 func TestIstioAuthorizationPolicyRulesFromHTTPRouteRule(t *testing.T) {
 	testCases := []struct {
 		name      string
@@ -343,3 +354,199 @@ func TestIstioAuthorizationPolicyRulesFromHTTPRouteRule(t *testing.T) {
 		})
 	}
 }
+
+func TestMergeIstioAuthorizationPolicyRules(t *testing.T) {
+	headerCondition := []*istiosecurity.Condition{{Key: "request.headers[x-foo]", Values: []string{"a-value"}}}
+	to := func(methods []string, paths ...string) *istiosecurity.Rule_To {
+		return &istiosecurity.Rule_To{Operation: &istiosecurity.Operation{Hosts: []string{"toystore.kuadrant.io"}, Methods: methods, Paths: paths}}
+	}
+
+	testCases := []struct {
+		name     string
+		rules    []*istiosecurity.Rule
+		expected []*istiosecurity.Rule
+	}{
+		{
+			name:     "paths are merged",
+			rules:    []*istiosecurity.Rule{{To: []*istiosecurity.Rule_To{to(nil, "/toy*")}}, {To: []*istiosecurity.Rule_To{to(nil, "/foo")}}},
+			expected: []*istiosecurity.Rule{{To: []*istiosecurity.Rule_To{to(nil, "/toy*", "/foo")}}},
+		},
+		{
+			name:     "operations with different methods are not merged",
+			rules:    []*istiosecurity.Rule{{To: []*istiosecurity.Rule_To{to([]string{"GET"}, "/toy*")}}, {To: []*istiosecurity.Rule_To{to([]string{"POST"}, "/foo")}}},
+			expected: []*istiosecurity.Rule{{To: []*istiosecurity.Rule_To{to([]string{"GET"}, "/toy*"), to([]string{"POST"}, "/foo")}}},
+		},
+		{
+			name:     "duplicate operations are dropped",
+			rules:    []*istiosecurity.Rule{{To: []*istiosecurity.Rule_To{to([]string{"GET"})}}, {To: []*istiosecurity.Rule_To{to([]string{"GET"})}}},
+			expected: []*istiosecurity.Rule{{To: []*istiosecurity.Rule_To{to([]string{"GET"})}}},
+		},
+		{
+			name:     "rules with different conditions are not merged",
+			rules:    []*istiosecurity.Rule{{To: []*istiosecurity.Rule_To{to(nil, "/toy*")}}, {To: []*istiosecurity.Rule_To{to(nil, "/foo")}, When: headerCondition}},
+			expected: []*istiosecurity.Rule{{To: []*istiosecurity.Rule_To{to(nil, "/toy*")}}, {To: []*istiosecurity.Rule_To{to(nil, "/foo")}, When: headerCondition}},
+		},
+		{
+			name:     "rule without operations matches all requests",
+			rules:    []*istiosecurity.Rule{{To: []*istiosecurity.Rule_To{to(nil, "/toy*")}, When: headerCondition}, {When: headerCondition}},
+			expected: []*istiosecurity.Rule{{When: headerCondition}},
+		},
+		{
+			name:     "rule without operations nor conditions makes other rules redundant",
+			rules:    []*istiosecurity.Rule{{To: []*istiosecurity.Rule_To{to(nil, "/foo")}, When: headerCondition}, {}},
+			expected: []*istiosecurity.Rule{{}},
+		},
+		{
+			name:     "rule with an empty operation matches all requests",
+			rules:    []*istiosecurity.Rule{{To: []*istiosecurity.Rule_To{to(nil, "/toy*")}}, {To: []*istiosecurity.Rule_To{{Operation: &istiosecurity.Operation{}}}}},
+			expected: []*istiosecurity.Rule{{}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(subT *testing.T) {
+			result := mergeIstioAuthorizationPolicyRules(tc.rules)
+			if len(result) != len(tc.expected) {
+				subT.Fatalf("expected %d rules, got %d", len(tc.expected), len(result))
+			}
+			for i := range result {
+				if !proto.Equal(result[i], tc.expected[i]) {
+					subT.Errorf("expected rule %d to be %v, got %v", i, tc.expected[i], result[i])
+				}
+			}
+		})
+	}
+}
+
+// TestIstioAuthorizationPolicyRulesGolden compares the Istio AuthorizationPolicy rules generated for an AuthPolicy and
+// a HTTPRoute with the golden files in testdata/istio_authorizationpolicy.
+// Run the test with -update to regenerate the golden files.
+func TestIstioAuthorizationPolicyRulesGolden(t *testing.T) {
+	route := &gatewayapiv1.HTTPRoute{
+		Spec: gatewayapiv1.HTTPRouteSpec{
+			Hostnames: []gatewayapiv1.Hostname{"*.toystore.kuadrant.io", "toystore.kuadrant.io"},
+			Rules: []gatewayapiv1.HTTPRouteRule{
+				{ // list and create toys
+					Matches: []gatewayapiv1.HTTPRouteMatch{
+						{Method: ptr.To(gatewayapiv1.HTTPMethodGet), Path: &gatewayapiv1.HTTPPathMatch{Type: ptr.To(gatewayapiv1.PathMatchPathPrefix), Value: ptr.To("/toys")}},
+						{Method: ptr.To(gatewayapiv1.HTTPMethodPost), Path: &gatewayapiv1.HTTPPathMatch{Type: ptr.To(gatewayapiv1.PathMatchExact), Value: ptr.To("/toys")}},
+					},
+				},
+				{ // static assets
+					Matches: []gatewayapiv1.HTTPRouteMatch{
+						{Path: &gatewayapiv1.HTTPPathMatch{Type: ptr.To(gatewayapiv1.PathMatchPathPrefix), Value: ptr.To("/assets")}},
+						{Path: &gatewayapiv1.HTTPPathMatch{Type: ptr.To(gatewayapiv1.PathMatchPathPrefix), Value: ptr.To("/static")}},
+					},
+				},
+				{ // admin
+					Matches: []gatewayapiv1.HTTPRouteMatch{
+						{
+							Path:    &gatewayapiv1.HTTPPathMatch{Type: ptr.To(gatewayapiv1.PathMatchPathPrefix), Value: ptr.To("/admin")},
+							Headers: []gatewayapiv1.HTTPHeaderMatch{{Type: ptr.To(gatewayapiv1.HeaderMatchExact), Name: "x-role", Value: "admin"}},
+						},
+						{
+							Path:    &gatewayapiv1.HTTPPathMatch{Type: ptr.To(gatewayapiv1.PathMatchPathPrefix), Value: ptr.To("/internal")},
+							Headers: []gatewayapiv1.HTTPHeaderMatch{{Type: ptr.To(gatewayapiv1.HeaderMatchExact), Name: "x-role", Value: "admin"}},
+						},
+						{
+							Path:    &gatewayapiv1.HTTPPathMatch{Type: ptr.To(gatewayapiv1.PathMatchPathPrefix), Value: ptr.To("/admin")},
+							Headers: []gatewayapiv1.HTTPHeaderMatch{{Type: ptr.To(gatewayapiv1.HeaderMatchRegularExpression), Name: "x-role", Value: "^(super)?admin$"}},
+						},
+					},
+				},
+				{ // search
+					Matches: []gatewayapiv1.HTTPRouteMatch{
+						{
+							Path:        &gatewayapiv1.HTTPPathMatch{Type: ptr.To(gatewayapiv1.PathMatchExact), Value: ptr.To("/search")},
+							QueryParams: []gatewayapiv1.HTTPQueryParamMatch{{Type: ptr.To(gatewayapiv1.QueryParamMatchExact), Name: "q", Value: "toy"}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	authPolicy := func(routeSelectors ...api.RouteSelector) *api.AuthPolicy {
+		return &api.AuthPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "toystore", Namespace: "toystore"},
+			Spec:       api.AuthPolicySpec{AuthPolicyCommonSpec: api.AuthPolicyCommonSpec{RouteSelectors: routeSelectors}},
+		}
+	}
+
+	regexRoute := route.DeepCopy()
+	regexRoute.Spec.Rules = append(regexRoute.Spec.Rules, gatewayapiv1.HTTPRouteRule{
+		Matches: []gatewayapiv1.HTTPRouteMatch{
+			{Path: &gatewayapiv1.HTTPPathMatch{Type: ptr.To(gatewayapiv1.PathMatchRegularExpression), Value: ptr.To("^/img/.+$")}},
+		},
+	})
+
+	testCases := []struct {
+		name  string
+		ap    *api.AuthPolicy
+		route *gatewayapiv1.HTTPRoute
+	}{
+		{
+			name:  "all_route_rules",
+			ap:    authPolicy(),
+			route: route,
+		},
+		{
+			name:  "regular_expression_path",
+			ap:    authPolicy(),
+			route: regexRoute,
+		},
+		{
+			name: "route_selectors",
+			ap: authPolicy(
+				api.RouteSelector{
+					Hostnames: []gatewayapiv1.Hostname{"toystore.kuadrant.io"},
+					Matches:   []gatewayapiv1.HTTPRouteMatch{{Path: &gatewayapiv1.HTTPPathMatch{Type: ptr.To(gatewayapiv1.PathMatchPathPrefix), Value: ptr.To("/toys")}}},
+				},
+				api.RouteSelector{
+					Matches: []gatewayapiv1.HTTPRouteMatch{{Path: &gatewayapiv1.HTTPPathMatch{Type: ptr.To(gatewayapiv1.PathMatchPathPrefix), Value: ptr.To("/admin")}}},
+				},
+			),
+			route: route,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(subT *testing.T) {
+			rules, err := istioAuthorizationPolicyRules(tc.ap, tc.route)
+			if err != nil {
+				subT.Fatal(err)
+			}
+			result, err := yaml.Marshal(rules)
+			if err != nil {
+				subT.Fatal(err)
+			}
+
+			goldenFile := filepath.Join("testdata", "istio_authorizationpolicy", tc.name+".yaml")
+			if *updateGoldenFiles {
+				if err := os.MkdirAll(filepath.Dir(goldenFile), 0o755); err != nil {
+					subT.Fatal(err)
+				}
+				if err := os.WriteFile(goldenFile, result, 0o600); err != nil {
+					subT.Fatal(err)
+				}
+			}
+
+			expected, err := os.ReadFile(goldenFile)
+			if err != nil {
+				subT.Fatal(err)
+			}
+			if string(result) != string(expected) {
+				subT.Errorf("rules do not match the golden file %s\nexpected:\n%s\ngot:\n%s", goldenFile, expected, result)
+			}
+		})
+	}
+}
+
+func TestIstioExtAuthProviderName(t *testing.T) {
+	if name := istioExtAuthProviderName(common.AuthorinoName); name != KuadrantExtAuthProviderName {
+		t.Errorf("expected the provider of the shared instance, got %s", name)
+	}
+	if name := istioExtAuthProviderName("authorino-ns-gw"); name != KuadrantExtAuthProviderName+"-authorino-ns-gw" {
+		t.Errorf("expected the provider of the dedicated instance, got %s", name)
+	}
+}
@@ -2,10 +2,10 @@ package controllers
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"slices"
+	"strings"
 
 	"github.com/go-logr/logr"
 	authorinoapi "github.com/kuadrant/authorino/api/v1beta2"
@@ -14,20 +14,20 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
 	gatewayapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 
 	api "github.com/kuadrant/kuadrant-operator/api/v1beta2"
+	"github.com/kuadrant/kuadrant-operator/pkg/common"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/kuadrant"
-	"github.com/kuadrant/kuadrant-operator/pkg/library/utils"
+	"github.com/kuadrant/kuadrant-operator/pkg/library/reconcilers"
 )
 
 // reconcileStatus makes sure status block of AuthPolicy is up-to-date.
-func (r *AuthPolicyReconciler) reconcileStatus(ctx context.Context, ap *api.AuthPolicy, targetNetworkObject client.Object, specErr error) (ctrl.Result, error) {
+func (r *AuthPolicyReconciler) reconcileStatus(ctx context.Context, ap *api.AuthPolicy, targetNetworkObject client.Object, native *nativeAuth, specErr error) (ctrl.Result, error) {
 	logger, _ := logr.FromContext(ctx)
 	logger.V(1).Info("Reconciling AuthPolicy status", "spec error", specErr)
 
-	newStatus := r.calculateStatus(ctx, ap, targetNetworkObject, specErr)
+	newStatus := r.calculateStatus(ctx, ap, targetNetworkObject, native, specErr)
 
 	equalStatus := ap.Status.Equals(newStatus, logger)
 	logger.V(1).Info("Status", "status is different", !equalStatus)
@@ -59,23 +59,50 @@ func (r *AuthPolicyReconciler) reconcileStatus(ctx context.Context, ap *api.Auth
 	return ctrl.Result{}, nil
 }
 
-func (r *AuthPolicyReconciler) calculateStatus(ctx context.Context, ap *api.AuthPolicy, targetNetworkObject client.Object, specErr error) *api.AuthPolicyStatus {
+func (r *AuthPolicyReconciler) calculateStatus(ctx context.Context, ap *api.AuthPolicy, targetNetworkObject client.Object, native *nativeAuth, specErr error) *api.AuthPolicyStatus {
 	newStatus := &api.AuthPolicyStatus{
 		Conditions:         slices.Clone(ap.Status.Conditions),
 		ObservedGeneration: ap.Status.ObservedGeneration,
 	}
 
+	// the policy that loses hosts to other policies is not accepted
+	if specErr == nil && !native.InUse() {
+		specErr = r.hostConflictsErr(ctx, ap)
+	}
+
 	acceptedCond := r.acceptedCondition(ap, specErr)
 	meta.SetStatusCondition(&newStatus.Conditions, *acceptedCond)
 
 	// Do not set enforced condition if Accepted condition is false
 	if meta.IsStatusConditionFalse(newStatus.Conditions, string(gatewayapiv1alpha2.PolicyReasonAccepted)) {
+		meta.RemoveStatusCondition(&newStatus.Conditions, api.AuthPolicyConditionNativeAuth)
+		meta.RemoveStatusCondition(&newStatus.Conditions, api.AuthPolicyConditionAuthSchemeAccepted)
+		meta.RemoveStatusCondition(&newStatus.Conditions, api.AuthPolicyConditionHostsLinked)
+		newStatus.Ancestors = reconcilers.PolicyAncestorStatuses(ctx, r.Client(), r.TargetRefReconciler.BackReferenceIndex, ap, targetNetworkObject, specErr, nil, ap.Status.Ancestors)
 		return newStatus
 	}
 
-	enforcedCond := r.enforcedCondition(ctx, ap, targetNetworkObject)
+	if native != nil {
+		meta.SetStatusCondition(&newStatus.Conditions, *nativeAuthCondition(native))
+	} else {
+		meta.RemoveStatusCondition(&newStatus.Conditions, api.AuthPolicyConditionNativeAuth)
+	}
+
+	newStatus.AuthConfig = r.authConfigSummary(ctx, ap, native)
+	if newStatus.AuthConfig != nil {
+		for _, cond := range authConfigConditions(newStatus.AuthConfig) {
+			meta.SetStatusCondition(&newStatus.Conditions, cond)
+		}
+	} else {
+		meta.RemoveStatusCondition(&newStatus.Conditions, api.AuthPolicyConditionAuthSchemeAccepted)
+		meta.RemoveStatusCondition(&newStatus.Conditions, api.AuthPolicyConditionHostsLinked)
+	}
+
+	enforcedCond := r.enforcedCondition(ctx, ap, targetNetworkObject, native, newStatus.AuthConfig)
 	meta.SetStatusCondition(&newStatus.Conditions, *enforcedCond)
 
+	newStatus.Ancestors = reconcilers.PolicyAncestorStatuses(ctx, r.Client(), r.TargetRefReconciler.BackReferenceIndex, ap, targetNetworkObject, specErr, enforcedCond, ap.Status.Ancestors)
+
 	return newStatus
 }
 
@@ -84,27 +111,42 @@ func (r *AuthPolicyReconciler) acceptedCondition(policy kuadrant.Policy, specErr
 }
 
 // enforcedCondition checks if the provided AuthPolicy is enforced, ensuring it is properly configured and applied based
-// on the status of the associated AuthConfig and Gateway.
-func (r *AuthPolicyReconciler) enforcedCondition(ctx context.Context, policy *api.AuthPolicy, targetNetworkObject client.Object) *metav1.Condition {
+// on the status of the associated AuthConfig and Gateway, or of the SecurityPolicy if enforced natively.
+func (r *AuthPolicyReconciler) enforcedCondition(ctx context.Context, policy *api.AuthPolicy, targetNetworkObject client.Object, native *nativeAuth, authConfig *api.AuthConfigSummary) *metav1.Condition {
 	logger, _ := logr.FromContext(ctx)
 
 	// Check if the policy is overridden
-	// Note: This logic assumes synchronous processing, where computing the desired AuthConfig, marking the AuthPolicy
-	// as overridden, and calculating the Enforced condition happen sequentially.
-	// Introducing a goroutine in this flow could break this assumption and lead to unexpected behavior.
-	if r.OverriddenPolicyMap.IsPolicyOverridden(policy) {
+	topologyIndexes, err := r.topologyIndexes()
+	if err != nil {
+		logger.Error(err, "Failed to read the topology")
+		return kuadrant.EnforcedCondition(policy, kuadrant.NewErrUnknown(policy.Kind(), err), false)
+	}
+	if topologyIndexes.IsPolicyOverridden(policy) {
 		logger.V(1).Info("Gateway Policy is overridden")
-		return r.handleGatewayPolicyOverride(logger, policy, targetNetworkObject)
+		return overriddenCondition(logger, policy, topologyIndexes.OverridingPolicies(policy))
 	}
 
-	// Check if the AuthConfig is ready
-	authConfigReady, err := r.isAuthConfigReady(ctx, policy)
-	if err != nil {
-		logger.Error(err, "Failed to check AuthConfig and Gateway")
-		return kuadrant.EnforcedCondition(policy, kuadrant.NewErrUnknown(policy.Kind(), err), false)
+	if policy.Spec.Disabled {
+		logger.V(1).Info("AuthPolicy disables auth for the route")
+		return kuadrant.EnforcedCondition(policy, nil, true)
 	}
 
-	if !authConfigReady {
+	if native.InUse() {
+		accepted, err := r.isSecurityPolicyAccepted(ctx, targetNetworkObject)
+		if err != nil {
+			logger.Error(err, "Failed to check SecurityPolicy")
+			return kuadrant.EnforcedCondition(policy, kuadrant.NewErrUnknown(policy.Kind(), err), false)
+		}
+		if !accepted {
+			logger.V(1).Info("SecurityPolicy is not accepted")
+			return kuadrant.EnforcedCondition(policy, kuadrant.NewErrUnknown(policy.Kind(), errors.New("SecurityPolicy is not accepted yet")), false)
+		}
+		logger.V(1).Info("AuthPolicy is enforced natively")
+		return kuadrant.EnforcedCondition(policy, nil, true)
+	}
+
+	// Check if the AuthConfig is ready
+	if authConfig == nil || !authConfig.Ready {
 		logger.V(1).Info("AuthConfig is not ready")
 		return kuadrant.EnforcedCondition(policy, kuadrant.NewErrUnknown(policy.Kind(), errors.New("AuthScheme is not ready yet")), false)
 	}
@@ -113,36 +155,317 @@ func (r *AuthPolicyReconciler) enforcedCondition(ctx context.Context, policy *ap
 	return kuadrant.EnforcedCondition(policy, nil, true)
 }
 
-// isAuthConfigReady checks if the AuthConfig is ready.
-func (r *AuthPolicyReconciler) isAuthConfigReady(ctx context.Context, policy *api.AuthPolicy) (bool, error) {
-	apKey := client.ObjectKeyFromObject(policy)
+// hostConflictsErr checks the hosts of the AuthConfig of the policy that authorino did not link because they are linked
+// to the AuthConfigs of other policies served by the same authorino instance, returning a conflict error naming them
+func (r *AuthPolicyReconciler) hostConflictsErr(ctx context.Context, policy *api.AuthPolicy) error {
+	logger, _ := logr.FromContext(ctx)
+
+	authConfig := &authorinoapi.AuthConfig{}
+	authConfigKey := client.ObjectKey{Namespace: policy.Namespace, Name: authConfigName(client.ObjectKeyFromObject(policy))}
+	if err := r.Client().Get(ctx, authConfigKey, authConfig); err != nil {
+		if !apierrors.IsNotFound(err) {
+			logger.V(1).Info("failed to get AuthConfig to check host conflicts", "error", err.Error())
+		}
+		return nil
+	}
+
+	authConfigList := &authorinoapi.AuthConfigList{}
+	if err := r.Client().List(ctx, authConfigList); err != nil {
+		logger.V(1).Info("failed to list AuthConfigs to check host conflicts", "error", err.Error())
+		return nil
+	}
+
+	conflicts := authConfigHostConflicts(authConfig, authConfigList.Items)
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	hosts := make([]string, 0, len(conflicts))
+	var owners []string
+	for host, hostOwners := range conflicts {
+		hosts = append(hosts, host)
+		for _, owner := range hostOwners {
+			if !slices.Contains(owners, owner) {
+				owners = append(owners, owner)
+			}
+		}
+	}
+	slices.Sort(hosts)
+	slices.Sort(owners)
+
+	return kuadrant.NewErrConflict(policy.Kind(), strings.Join(owners, ", "), fmt.Errorf("hosts %s are already in use", strings.Join(hosts, ", ")))
+}
+
+// authConfigHostConflicts returns the hosts of the AuthConfig not linked by authorino because they are linked to other
+// AuthConfigs served by the same authorino instance, along with the policies (or else the AuthConfigs) that own them
+func authConfigHostConflicts(authConfig *authorinoapi.AuthConfig, others []authorinoapi.AuthConfig) map[string][]string {
+	conflicts := make(map[string][]string)
+	for _, host := range authConfig.Spec.Hosts {
+		if slices.Contains(authConfig.Status.Summary.HostsReady, host) {
+			continue
+		}
+		for idx := range others {
+			other := &others[idx]
+			if client.ObjectKeyFromObject(other) == client.ObjectKeyFromObject(authConfig) ||
+				other.GetLabels()[common.AuthorinoInstanceLabel] != authConfig.GetLabels()[common.AuthorinoInstanceLabel] ||
+				!slices.Contains(other.Status.Summary.HostsReady, host) {
+				continue
+			}
+			conflicts[host] = append(conflicts[host], authConfigOwner(other))
+		}
+	}
+	return conflicts
+}
+
+// authConfigOwner returns the AuthPolicy that owns the AuthConfig, or else the AuthConfig itself
+func authConfigOwner(authConfig *authorinoapi.AuthConfig) string {
+	for _, ownerRef := range authConfig.GetOwnerReferences() {
+		if ownerRef.Kind == "AuthPolicy" {
+			return client.ObjectKey{Namespace: authConfig.Namespace, Name: ownerRef.Name}.String()
+		}
+	}
+	return fmt.Sprintf("AuthConfig %s", client.ObjectKeyFromObject(authConfig))
+}
+
+// authConfigSummary fetches the AuthConfig of the policy and summarizes its status as reported by Authorino.
+// Returns nil for policies not enforced by Authorino or whose AuthConfig cannot be read.
+func (r *AuthPolicyReconciler) authConfigSummary(ctx context.Context, policy *api.AuthPolicy, native *nativeAuth) *api.AuthConfigSummary {
+	if policy.Spec.Disabled || native.InUse() {
+		return nil
+	}
+
+	logger, _ := logr.FromContext(ctx)
+
 	authConfigKey := client.ObjectKey{
 		Namespace: policy.Namespace,
-		Name:      authConfigName(apKey),
+		Name:      authConfigName(client.ObjectKeyFromObject(policy)),
 	}
 	authConfig := &authorinoapi.AuthConfig{}
-	err := r.GetResource(ctx, authConfigKey, authConfig)
-	if err != nil {
+	if err := r.GetResource(ctx, authConfigKey, authConfig); err != nil {
 		if !apierrors.IsNotFound(err) {
-			return false, fmt.Errorf("failed to get AuthConfig: %w", err)
+			logger.Error(err, "Failed to get AuthConfig")
 		}
+		return nil
 	}
-	return authConfig.Status.Ready(), nil
+
+	return authConfigStatusSummary(authConfig)
 }
 
-// handleGatewayPolicyOverride handles the case where the Gateway Policy is overridden by filtering policy references
-// and creating a corresponding error condition.
-func (r *AuthPolicyReconciler) handleGatewayPolicyOverride(logger logr.Logger, policy *api.AuthPolicy, targetNetworkObject client.Object) *metav1.Condition {
-	obj := targetNetworkObject.(*gatewayapiv1.Gateway)
-	gatewayWrapper := kuadrant.GatewayWrapper{Gateway: obj, Referrer: policy}
-	refs := gatewayWrapper.PolicyRefs()
-	filteredRef := utils.Filter(refs, func(key client.ObjectKey) bool {
-		return key != client.ObjectKeyFromObject(policy)
-	})
-	jsonData, err := json.Marshal(filteredRef)
-	if err != nil {
-		logger.Error(err, "Failed to marshal filtered references")
-		return kuadrant.EnforcedCondition(policy, kuadrant.NewErrUnknown(policy.Kind(), err), false)
+// authConfigStatusSummary maps the status of an AuthConfig reported by Authorino to the summary of the AuthConfig in
+// the status of the policy.
+// Authorino either loads all the rules of an AuthConfig or none of them, thus the rules are all accepted if Authorino
+// loaded the AuthConfig, even if some of its hosts are not linked. Otherwise, the rules whose references (issuer URLs,
+// endpoints, Secret names) appear in the failure message reported by Authorino are listed as failed, as long as the
+// message points at them unambiguously.
+func authConfigStatusSummary(authConfig *authorinoapi.AuthConfig) *api.AuthConfigSummary {
+	summary := &api.AuthConfigSummary{
+		Name:  authConfig.Name,
+		Ready: authConfig.Status.Ready(),
+	}
+
+	for _, condition := range authConfig.Status.Conditions {
+		if condition.Type == authorinoapi.StatusConditionReady {
+			summary.Reason = condition.Reason
+			summary.Message = condition.Message
+		}
 	}
-	return kuadrant.EnforcedCondition(policy, kuadrant.NewErrOverridden(policy.Kind(), string(jsonData)), false)
+
+	for _, host := range authConfig.Spec.Hosts {
+		if slices.Contains(authConfig.Status.Summary.HostsReady, host) {
+			summary.HostsReady = append(summary.HostsReady, host)
+		} else {
+			summary.HostsNotReady = append(summary.HostsNotReady, host)
+		}
+	}
+
+	accepted := authConfigAccepted(summary.Reason)
+	var failed []string
+	if !accepted {
+		failed = authConfigFailedRules(summary.Message, authConfigRuleReferences(authConfig.Spec))
+	}
+	rulesSummary := func(phase string, names []string) api.AuthRulesSummary {
+		rules := api.AuthRulesSummary{}
+		for _, name := range names {
+			switch {
+			case accepted:
+				rules.Accepted = append(rules.Accepted, name)
+			case slices.Contains(failed, authRuleID(phase, name)):
+				rules.Failed = append(rules.Failed, name)
+			}
+		}
+		return rules
+	}
+	summary.Authentication = rulesSummary("authentication", sortedMapKeys(authConfig.Spec.Authentication))
+	summary.Metadata = rulesSummary("metadata", sortedMapKeys(authConfig.Spec.Metadata))
+	summary.Authorization = rulesSummary("authorization", sortedMapKeys(authConfig.Spec.Authorization))
+
+	return summary
+}
+
+// authConfigAccepted returns true if Authorino loaded the AuthConfig, regardless of its hosts being linked.
+// Hosts not linked are reported by the HostsLinked condition of the policy instead.
+func authConfigAccepted(reason string) bool {
+	return reason == authorinoapi.StatusReasonReconciled || reason == authorinoapi.StatusReasonHostsNotLinked
+}
+
+// authRuleID identifies an auth rule of an AuthConfig across the phases
+func authRuleID(phase, name string) string {
+	return fmt.Sprintf("%s/%s", phase, name)
+}
+
+// authConfigFailedRules returns the rules, as phase/name, whose references appear in the failure message of an AuthConfig.
+// References contained in longer ones that also appear in the message (e.g. an issuer URL and an endpoint under it) do
+// not count as matches on their own. If the matching references belong to different sets of rules, the message is
+// ambiguous and no rule is returned.
+func authConfigFailedRules(message string, references map[string]map[string][]string) []string {
+	if message == "" {
+		return nil
+	}
+
+	rulesByRef := make(map[string][]string)
+	for phase, rules := range references {
+		for name, refs := range rules {
+			for _, ref := range refs {
+				if strings.Contains(message, ref) && !slices.Contains(rulesByRef[ref], authRuleID(phase, name)) {
+					rulesByRef[ref] = append(rulesByRef[ref], authRuleID(phase, name))
+				}
+			}
+		}
+	}
+
+	matched := sortedMapKeys(rulesByRef)
+	var failed []string
+	for _, ref := range matched {
+		if slices.ContainsFunc(matched, func(other string) bool {
+			return other != ref && strings.Contains(other, ref)
+		}) {
+			continue
+		}
+		rules := rulesByRef[ref]
+		slices.Sort(rules)
+		if failed != nil && !slices.Equal(failed, rules) {
+			return nil
+		}
+		failed = rules
+	}
+	return failed
+}
+
+// authConfigRuleReferences returns the external references (issuer URLs, endpoints, Secret names) of the
+// authentication, metadata and authorization rules of an AuthConfig, indexed by phase and rule name
+func authConfigRuleReferences(spec authorinoapi.AuthConfigSpec) map[string]map[string][]string {
+	httpEndpointReferences := func(http *authorinoapi.HttpEndpointSpec) []string {
+		if http == nil {
+			return nil
+		}
+		refs := []string{http.Url}
+		if http.SharedSecret != nil {
+			refs = append(refs, http.SharedSecret.Name)
+		}
+		if http.OAuth2 != nil {
+			refs = append(refs, http.OAuth2.TokenUrl, http.OAuth2.ClientSecret.Name)
+		}
+		return refs
+	}
+
+	references := map[string]map[string][]string{
+		"authentication": {},
+		"metadata":       {},
+		"authorization":  {},
+	}
+
+	for name, authentication := range spec.Authentication {
+		var refs []string
+		switch {
+		case authentication.Jwt != nil:
+			refs = append(refs, authentication.Jwt.IssuerUrl)
+		case authentication.OAuth2TokenIntrospection != nil:
+			refs = append(refs, authentication.OAuth2TokenIntrospection.Url)
+			if credentials := authentication.OAuth2TokenIntrospection.Credentials; credentials != nil {
+				refs = append(refs, credentials.Name)
+			}
+		}
+		references["authentication"][name] = refs
+	}
+
+	for name, metadata := range spec.Metadata {
+		var refs []string
+		switch {
+		case metadata.Http != nil:
+			refs = httpEndpointReferences(metadata.Http)
+		case metadata.UserInfo != nil:
+			if identitySource, ok := spec.Authentication[metadata.UserInfo.IdentitySource]; ok && identitySource.Jwt != nil {
+				refs = append(refs, identitySource.Jwt.IssuerUrl)
+			}
+		case metadata.Uma != nil:
+			refs = append(refs, metadata.Uma.Endpoint)
+			if credentials := metadata.Uma.Credentials; credentials != nil {
+				refs = append(refs, credentials.Name)
+			}
+		}
+		references["metadata"][name] = refs
+	}
+
+	for name, authorization := range spec.Authorization {
+		var refs []string
+		switch {
+		case authorization.Opa != nil && authorization.Opa.External != nil:
+			refs = httpEndpointReferences(authorization.Opa.External.HttpEndpointSpec)
+		case authorization.SpiceDB != nil:
+			refs = append(refs, authorization.SpiceDB.Endpoint)
+			if sharedSecret := authorization.SpiceDB.SharedSecret; sharedSecret != nil {
+				refs = append(refs, sharedSecret.Name)
+			}
+		}
+		references["authorization"][name] = refs
+	}
+
+	// drop empty references, that would otherwise match any failure message
+	for _, phase := range references {
+		for name, refs := range phase {
+			phase[name] = slices.DeleteFunc(refs, func(ref string) bool { return ref == "" })
+		}
+	}
+
+	return references
+}
+
+// authConfigConditions builds the conditions of the policy that report the status of its AuthConfig
+func authConfigConditions(summary *api.AuthConfigSummary) []metav1.Condition {
+	authSchemeCond := metav1.Condition{
+		Type:   api.AuthPolicyConditionAuthSchemeAccepted,
+		Status: metav1.ConditionTrue,
+		Reason: authorinoapi.StatusReasonReconciled,
+	}
+	if !authConfigAccepted(summary.Reason) {
+		authSchemeCond.Status = metav1.ConditionFalse
+		authSchemeCond.Reason = summary.Reason
+		if authSchemeCond.Reason == "" {
+			authSchemeCond.Reason = authorinoapi.StatusReasonUnknown
+		}
+		var failed []string
+		failed = append(failed, summary.Authentication.Failed...)
+		failed = append(failed, summary.Metadata.Failed...)
+		failed = append(failed, summary.Authorization.Failed...)
+		authSchemeCond.Message = "AuthScheme is not accepted by Authorino"
+		if len(failed) > 0 {
+			authSchemeCond.Message = fmt.Sprintf("%s; failed rules: %s", authSchemeCond.Message, strings.Join(failed, ", "))
+		}
+		if summary.Message != "" {
+			authSchemeCond.Message = fmt.Sprintf("%s; %s", authSchemeCond.Message, summary.Message)
+		}
+	}
+
+	hostsCond := metav1.Condition{
+		Type:   api.AuthPolicyConditionHostsLinked,
+		Status: metav1.ConditionTrue,
+		Reason: authorinoapi.StatusReasonHostsLinked,
+	}
+	if len(summary.HostsNotReady) > 0 {
+		hostsCond.Status = metav1.ConditionFalse
+		hostsCond.Reason = authorinoapi.StatusReasonHostsNotLinked
+		hostsCond.Message = fmt.Sprintf("Hosts not linked by Authorino: %s", strings.Join(summary.HostsNotReady, ", "))
+	}
+
+	return []metav1.Condition{authSchemeCond, hostsCond}
 }
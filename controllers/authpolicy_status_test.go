@@ -0,0 +1,280 @@
+//go:build unit
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	authorinoapi "github.com/kuadrant/authorino/api/v1beta2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/kuadrant/kuadrant-operator/api/v1beta2"
+	"github.com/kuadrant/kuadrant-operator/pkg/common"
+)
+
+func testAuthConfig(name, owner string, hosts, hostsReady []string, labels map[string]string) authorinoapi.AuthConfig {
+	authConfig := authorinoapi.AuthConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "testNS", Labels: labels},
+		Spec:       authorinoapi.AuthConfigSpec{Hosts: hosts},
+		Status:     authorinoapi.AuthConfigStatus{Summary: authorinoapi.AuthConfigStatusSummary{HostsReady: hostsReady}},
+	}
+	if owner != "" {
+		authConfig.OwnerReferences = []metav1.OwnerReference{{Kind: "AuthPolicy", Name: owner}}
+	}
+	return authConfig
+}
+
+func TestAuthConfigHostConflicts(t *testing.T) {
+	dedicated := map[string]string{common.AuthorinoInstanceLabel: "authorino-gw-ns-gw"}
+
+	testCases := []struct {
+		name       string
+		authConfig authorinoapi.AuthConfig
+		others     []authorinoapi.AuthConfig
+		expected   map[string][]string
+	}{
+		{
+			name:       "all hosts linked",
+			authConfig: testAuthConfig("ac", "ap", []string{"a.toystore.com"}, []string{"a.toystore.com"}, nil),
+			others: []authorinoapi.AuthConfig{
+				testAuthConfig("other", "other-ap", []string{"b.toystore.com"}, []string{"b.toystore.com"}, nil),
+			},
+			expected: map[string][]string{},
+		},
+		{
+			name:       "host linked to the authconfig of another policy",
+			authConfig: testAuthConfig("ac", "ap", []string{"a.toystore.com", "b.toystore.com"}, []string{"a.toystore.com"}, nil),
+			others: []authorinoapi.AuthConfig{
+				testAuthConfig("other", "other-ap", []string{"b.toystore.com"}, []string{"b.toystore.com"}, nil),
+				testAuthConfig("manual", "", []string{"b.toystore.com"}, []string{"b.toystore.com"}, nil),
+			},
+			expected: map[string][]string{"b.toystore.com": {"testNS/other-ap", "AuthConfig testNS/manual"}},
+		},
+		{
+			name:       "host not linked yet",
+			authConfig: testAuthConfig("ac", "ap", []string{"a.toystore.com"}, nil, nil),
+			others: []authorinoapi.AuthConfig{
+				testAuthConfig("other", "other-ap", []string{"a.toystore.com"}, nil, nil),
+			},
+			expected: map[string][]string{},
+		},
+		{
+			name:       "host linked in another authorino instance",
+			authConfig: testAuthConfig("ac", "ap", []string{"a.toystore.com"}, nil, dedicated),
+			others: []authorinoapi.AuthConfig{
+				testAuthConfig("other", "other-ap", []string{"a.toystore.com"}, []string{"a.toystore.com"}, nil),
+			},
+			expected: map[string][]string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(subT *testing.T) {
+			others := append(tc.others, tc.authConfig)
+			if got := authConfigHostConflicts(&tc.authConfig, others); !reflect.DeepEqual(got, tc.expected) {
+				subT.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestAuthConfigStatusSummary(t *testing.T) {
+	authConfig := func(reason, message string, hostsReady []string) *authorinoapi.AuthConfig {
+		authConfig := testAuthConfig("ac", "ap", []string{"a.toystore.com", "b.toystore.com"}, hostsReady, nil)
+		authConfig.Spec.Authentication = map[string]authorinoapi.AuthenticationSpec{
+			"keycloak": {AuthenticationMethodSpec: authorinoapi.AuthenticationMethodSpec{Jwt: &authorinoapi.JwtAuthenticationSpec{IssuerUrl: "http://keycloak/realms/kuadrant"}}},
+			"api-keys": {AuthenticationMethodSpec: authorinoapi.AuthenticationMethodSpec{ApiKey: &authorinoapi.ApiKeyAuthenticationSpec{}}},
+		}
+		authConfig.Spec.Metadata = map[string]authorinoapi.MetadataSpec{
+			"user-info": {MetadataMethodSpec: authorinoapi.MetadataMethodSpec{UserInfo: &authorinoapi.UserInfoMetadataSpec{IdentitySource: "keycloak"}}},
+			"groups":    {MetadataMethodSpec: authorinoapi.MetadataMethodSpec{Http: &authorinoapi.HttpEndpointSpec{Url: "http://groups", SharedSecret: &authorinoapi.SecretKeyReference{Name: "groups-secret"}}}},
+		}
+		authConfig.Spec.Authorization = map[string]authorinoapi.AuthorizationSpec{
+			"admins": {AuthorizationMethodSpec: authorinoapi.AuthorizationMethodSpec{PatternMatching: &authorinoapi.PatternMatchingAuthorizationSpec{}}},
+		}
+		status := "False"
+		if reason == authorinoapi.StatusReasonReconciled && len(hostsReady) == 2 {
+			status = "True"
+		}
+		authConfig.Status.Conditions = []authorinoapi.AuthConfigStatusCondition{
+			{Type: authorinoapi.StatusConditionReady, Status: corev1.ConditionStatus(status), Reason: reason, Message: message},
+		}
+		return &authConfig
+	}
+
+	testCases := []struct {
+		name       string
+		authConfig *authorinoapi.AuthConfig
+		expected   *api.AuthConfigSummary
+	}{
+		{
+			name:       "ready",
+			authConfig: authConfig(authorinoapi.StatusReasonReconciled, "", []string{"a.toystore.com", "b.toystore.com"}),
+			expected: &api.AuthConfigSummary{
+				Name:           "ac",
+				Ready:          true,
+				Reason:         authorinoapi.StatusReasonReconciled,
+				HostsReady:     []string{"a.toystore.com", "b.toystore.com"},
+				Authentication: api.AuthRulesSummary{Accepted: []string{"api-keys", "keycloak"}},
+				Metadata:       api.AuthRulesSummary{Accepted: []string{"groups", "user-info"}},
+				Authorization:  api.AuthRulesSummary{Accepted: []string{"admins"}},
+			},
+		},
+		{
+			name:       "host not linked",
+			authConfig: authConfig(authorinoapi.StatusReasonReconciled, "", []string{"a.toystore.com"}),
+			expected: &api.AuthConfigSummary{
+				Name:           "ac",
+				Reason:         authorinoapi.StatusReasonReconciled,
+				HostsReady:     []string{"a.toystore.com"},
+				HostsNotReady:  []string{"b.toystore.com"},
+				Authentication: api.AuthRulesSummary{Accepted: []string{"api-keys", "keycloak"}},
+				Metadata:       api.AuthRulesSummary{Accepted: []string{"groups", "user-info"}},
+				Authorization:  api.AuthRulesSummary{Accepted: []string{"admins"}},
+			},
+		},
+		{
+			name:       "oidc issuer not resolved",
+			authConfig: authConfig(authorinoapi.StatusReasonCachingError, `Get "http://keycloak/realms/kuadrant/.well-known/openid-configuration": dial tcp: lookup keycloak: no such host`, nil),
+			expected: &api.AuthConfigSummary{
+				Name:           "ac",
+				Reason:         authorinoapi.StatusReasonCachingError,
+				Message:        `Get "http://keycloak/realms/kuadrant/.well-known/openid-configuration": dial tcp: lookup keycloak: no such host`,
+				HostsNotReady:  []string{"a.toystore.com", "b.toystore.com"},
+				Authentication: api.AuthRulesSummary{Failed: []string{"keycloak"}},
+				Metadata:       api.AuthRulesSummary{Failed: []string{"user-info"}},
+			},
+		},
+		{
+			name:       "secret not resolved",
+			authConfig: authConfig(authorinoapi.StatusReasonCachingError, `Secret "groups-secret" not found`, nil),
+			expected: &api.AuthConfigSummary{
+				Name:          "ac",
+				Reason:        authorinoapi.StatusReasonCachingError,
+				Message:       `Secret "groups-secret" not found`,
+				HostsNotReady: []string{"a.toystore.com", "b.toystore.com"},
+				Metadata:      api.AuthRulesSummary{Failed: []string{"groups"}},
+			},
+		},
+		{
+			name:       "hosts not linked",
+			authConfig: authConfig(authorinoapi.StatusReasonHostsNotLinked, "one or more hosts are not linked to the resource", []string{"a.toystore.com"}),
+			expected: &api.AuthConfigSummary{
+				Name:           "ac",
+				Reason:         authorinoapi.StatusReasonHostsNotLinked,
+				Message:        "one or more hosts are not linked to the resource",
+				HostsReady:     []string{"a.toystore.com"},
+				HostsNotReady:  []string{"b.toystore.com"},
+				Authentication: api.AuthRulesSummary{Accepted: []string{"api-keys", "keycloak"}},
+				Metadata:       api.AuthRulesSummary{Accepted: []string{"groups", "user-info"}},
+				Authorization:  api.AuthRulesSummary{Accepted: []string{"admins"}},
+			},
+		},
+		{
+			name:       "ambiguous failure",
+			authConfig: authConfig(authorinoapi.StatusReasonCachingError, `Secret "groups-secret" not found; Get "http://keycloak/realms/kuadrant/.well-known/openid-configuration": EOF`, nil),
+			expected: &api.AuthConfigSummary{
+				Name:          "ac",
+				Reason:        authorinoapi.StatusReasonCachingError,
+				Message:       `Secret "groups-secret" not found; Get "http://keycloak/realms/kuadrant/.well-known/openid-configuration": EOF`,
+				HostsNotReady: []string{"a.toystore.com", "b.toystore.com"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(subT *testing.T) {
+			if result := authConfigStatusSummary(tc.authConfig); !reflect.DeepEqual(result, tc.expected) {
+				subT.Errorf("expected %+v, got %+v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestAuthConfigConditions(t *testing.T) {
+	conditions := authConfigConditions(&api.AuthConfigSummary{
+		Reason:         authorinoapi.StatusReasonCachingError,
+		Message:        `Secret "groups-secret" not found`,
+		HostsReady:     []string{"a.toystore.com"},
+		HostsNotReady:  []string{"b.toystore.com"},
+		Authentication: api.AuthRulesSummary{Failed: []string{"keycloak"}},
+		Metadata:       api.AuthRulesSummary{Failed: []string{"groups"}},
+	})
+
+	expected := []metav1.Condition{
+		{
+			Type:    api.AuthPolicyConditionAuthSchemeAccepted,
+			Status:  metav1.ConditionFalse,
+			Reason:  authorinoapi.StatusReasonCachingError,
+			Message: `AuthScheme is not accepted by Authorino; failed rules: keycloak, groups; Secret "groups-secret" not found`,
+		},
+		{
+			Type:    api.AuthPolicyConditionHostsLinked,
+			Status:  metav1.ConditionFalse,
+			Reason:  authorinoapi.StatusReasonHostsNotLinked,
+			Message: "Hosts not linked by Authorino: b.toystore.com",
+		},
+	}
+	if !reflect.DeepEqual(conditions, expected) {
+		t.Errorf("expected %+v, got %+v", expected, conditions)
+	}
+}
+
+func TestAuthConfigFailedRules(t *testing.T) {
+	references := map[string]map[string][]string{
+		"authentication": {"keycloak": {"http://keycloak/realms/kuadrant"}},
+		"metadata": {
+			"user-info": {"http://keycloak/realms/kuadrant"},
+			"groups":    {"http://keycloak/realms/kuadrant/groups", "groups-secret"},
+			"roles":     {"http://roles"},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		message  string
+		expected []string
+	}{
+		{name: "no message"},
+		{name: "no match", message: "unknown error"},
+		{name: "reference shared by rules", message: `Get "http://keycloak/realms/kuadrant/.well-known/openid-configuration": EOF`, expected: []string{"authentication/keycloak", "metadata/user-info"}},
+		{name: "longest reference", message: `Post "http://keycloak/realms/kuadrant/groups": EOF`, expected: []string{"metadata/groups"}},
+		{name: "several references of a rule", message: `Secret "groups-secret" not found for http://keycloak/realms/kuadrant/groups`, expected: []string{"metadata/groups"}},
+		{name: "references of different rules", message: `Get "http://roles": EOF; Secret "groups-secret" not found`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(subT *testing.T) {
+			if result := authConfigFailedRules(tc.message, references); !reflect.DeepEqual(result, tc.expected) {
+				subT.Errorf("expected %v, got %v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestAuthConfigConditionsHostsNotLinked(t *testing.T) {
+	conditions := authConfigConditions(&api.AuthConfigSummary{
+		Reason:        authorinoapi.StatusReasonHostsNotLinked,
+		HostsReady:    []string{"a.toystore.com"},
+		HostsNotReady: []string{"b.toystore.com"},
+	})
+
+	expected := []metav1.Condition{
+		{
+			Type:   api.AuthPolicyConditionAuthSchemeAccepted,
+			Status: metav1.ConditionTrue,
+			Reason: authorinoapi.StatusReasonReconciled,
+		},
+		{
+			Type:    api.AuthPolicyConditionHostsLinked,
+			Status:  metav1.ConditionFalse,
+			Reason:  authorinoapi.StatusReasonHostsNotLinked,
+			Message: "Hosts not linked by Authorino: b.toystore.com",
+		},
+	}
+	if !reflect.DeepEqual(conditions, expected) {
+		t.Errorf("expected %+v, got %+v", expected, conditions)
+	}
+}
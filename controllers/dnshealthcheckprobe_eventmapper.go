@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"context"
 	"fmt"
 
 	"k8s.io/apimachinery/pkg/types"
@@ -25,7 +26,7 @@ type DNSHealthCheckProbeEventMapper struct {
 	opts mappers.MapperOptions
 }
 
-func (m *DNSHealthCheckProbeEventMapper) MapToPolicy(obj client.Object, policyKind kuadrant.Referrer) []reconcile.Request {
+func (m *DNSHealthCheckProbeEventMapper) MapToPolicy(_ context.Context, obj client.Object, policyKind kuadrant.Referrer) []reconcile.Request {
 	logger := m.opts.Logger.V(1).WithValues("object", client.ObjectKeyFromObject(obj))
 	probe, ok := obj.(*kuadrantdnsv1alpha1.DNSHealthCheckProbe)
 	if !ok {
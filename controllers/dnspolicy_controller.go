@@ -95,7 +95,7 @@ func (r *DNSPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 				if delResErr == nil {
 					delResErr = err
 				}
-				return r.reconcileStatus(ctx, dnsPolicy, kuadrant.NewErrTargetNotFound(dnsPolicy.Kind(), dnsPolicy.GetTargetRef(), delResErr))
+				return r.reconcileStatus(ctx, dnsPolicy, nil, kuadrant.NewErrTargetNotFound(dnsPolicy.Kind(), dnsPolicy.GetTargetRef(), delResErr))
 			}
 			return ctrl.Result{}, err
 		}
@@ -127,7 +127,7 @@ func (r *DNSPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 
 	specErr := r.reconcileResources(ctx, dnsPolicy, targetNetworkObject)
 
-	statusResult, statusErr := r.reconcileStatus(ctx, dnsPolicy, specErr)
+	statusResult, statusErr := r.reconcileStatus(ctx, dnsPolicy, targetNetworkObject, specErr)
 
 	if specErr != nil {
 		return ctrl.Result{}, specErr
@@ -148,7 +148,7 @@ func (r *DNSPolicyReconciler) reconcileResources(ctx context.Context, dnsPolicy
 	dnsPolicy.Default()
 
 	// reconcile based on gateway diffs
-	gatewayDiffObj, err := reconcilers.ComputeGatewayDiffs(ctx, r.Client(), dnsPolicy, targetNetworkObject)
+	gatewayDiffObj, err := reconcilers.ComputeGatewayDiffs(ctx, r.Client(), r.TargetRefReconciler.BackReferenceIndex, dnsPolicy, targetNetworkObject)
 	if err != nil {
 		return err
 	}
@@ -172,13 +172,6 @@ func (r *DNSPolicyReconciler) reconcileResources(ctx context.Context, dnsPolicy
 		return errors.Join(fmt.Errorf("reconcile TargetBackReference error %w", err), updateErr)
 	}
 
-	// set annotation of policies affecting the gateway
-	if err := r.TargetRefReconciler.ReconcileGatewayPolicyReferences(ctx, dnsPolicy, gatewayDiffObj); err != nil {
-		gatewayCondition = BuildPolicyAffectedCondition(DNSPolicyAffected, dnsPolicy, targetNetworkObject, gatewayapiv1alpha2.PolicyConditionReason(PolicyReasonUnknown), err)
-		updateErr := r.updateGatewayCondition(ctx, gatewayCondition, gatewayDiffObj)
-		return errors.Join(fmt.Errorf("ReconcileGatewayPolicyReferences error %w", err), updateErr)
-	}
-
 	// set gateway policy affected condition status - should be the last step, only when all the reconciliation steps succeed
 	updateErr := r.updateGatewayCondition(ctx, gatewayCondition, gatewayDiffObj)
 	if updateErr != nil {
@@ -205,16 +198,11 @@ func (r *DNSPolicyReconciler) deleteResources(ctx context.Context, dnsPolicy *v1
 		}
 	}
 
-	gatewayDiffObj, err := reconcilers.ComputeGatewayDiffs(ctx, r.Client(), dnsPolicy, targetNetworkObject)
+	gatewayDiffObj, err := reconcilers.ComputeGatewayDiffs(ctx, r.Client(), r.TargetRefReconciler.BackReferenceIndex, dnsPolicy, targetNetworkObject)
 	if err != nil {
 		return err
 	}
 
-	// update annotation of policies affecting the gateway
-	if err := r.TargetRefReconciler.ReconcileGatewayPolicyReferences(ctx, dnsPolicy, gatewayDiffObj); err != nil {
-		return err
-	}
-
 	// remove gateway policy affected condition status
 	return r.updateGatewayCondition(ctx, metav1.Condition{Type: DNSPolicyAffected}, gatewayDiffObj)
 }
@@ -248,7 +236,10 @@ func (r *DNSPolicyReconciler) updateGatewayCondition(ctx context.Context, condit
 }
 
 func (r *DNSPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	gatewayEventMapper := mappers.NewGatewayEventMapper(mappers.WithLogger(r.Logger().WithName("gatewayEventMapper")))
+	gatewayEventMapper := mappers.NewGatewayEventMapper(
+		mappers.WithLogger(r.Logger().WithName("gatewayEventMapper")),
+		mappers.WithBackReferenceIndex(r.TargetRefReconciler.BackReferenceIndex),
+	)
 	dnsHealthCheckProbeEventMapper := NewDNSHealthCheckProbeEventMapper(mappers.WithLogger(r.Logger().WithName("dnsHealthCheckProbeEventMapper")))
 
 	r.dnsHelper = dnsHelper{Client: r.Client()}
@@ -258,13 +249,13 @@ func (r *DNSPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Watches(
 			&gatewayapiv1.Gateway{},
 			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, object client.Object) []reconcile.Request {
-				return gatewayEventMapper.MapToPolicy(object, &v1alpha1.DNSPolicy{})
+				return gatewayEventMapper.MapToPolicy(ctx, object, &v1alpha1.DNSPolicy{})
 			}),
 		).
 		Watches(
 			&kuadrantdnsv1alpha1.DNSHealthCheckProbe{},
 			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, object client.Object) []reconcile.Request {
-				return dnsHealthCheckProbeEventMapper.MapToPolicy(object, &v1alpha1.DNSPolicy{})
+				return dnsHealthCheckProbeEventMapper.MapToPolicy(ctx, object, &v1alpha1.DNSPolicy{})
 			}),
 		)
 	return ctrlr.Complete(r)
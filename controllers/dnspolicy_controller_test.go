@@ -4,7 +4,6 @@ package controllers
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"time"
 
@@ -255,15 +254,15 @@ var _ = Describe("DNSPolicy controller", func() {
 
 		It("should set gateway back reference", func() {
 			policyBackRefValue := testNamespace + "/" + dnsPolicy.Name
-			refs, _ := json.Marshal([]client.ObjectKey{{Name: dnsPolicy.Name, Namespace: testNamespace}})
-			policiesBackRefValue := string(refs)
+			policyKey := client.ObjectKey{Name: dnsPolicy.Name, Namespace: testNamespace}
 
 			Eventually(func(g Gomega) {
 				gw := &gatewayapiv1.Gateway{}
 				err := k8sClient.Get(ctx, client.ObjectKey{Name: gateway.Name, Namespace: testNamespace}, gw)
 				g.Expect(err).NotTo(HaveOccurred())
 				g.Expect(gw.Annotations).To(HaveKeyWithValue(v1alpha1.DNSPolicyDirectReferenceAnnotationName, policyBackRefValue))
-				g.Expect(gw.Annotations).To(HaveKeyWithValue(v1alpha1.DNSPolicyBackReferenceAnnotationName, policiesBackRefValue))
+				g.Expect(gw.Annotations).ToNot(HaveKey(v1alpha1.DNSPolicyBackReferenceAnnotationName))
+				g.Expect(kuadrant.BackReferencesFromIndex(ctx, backReferenceIndex, gw, dnsPolicy)).To(ContainElement(policyKey))
 			}, TestTimeoutMedium, time.Second).Should(Succeed())
 		})
 	})
@@ -376,14 +375,14 @@ var _ = Describe("DNSPolicy controller", func() {
 
 		It("should set gateway back reference", func() {
 			policyBackRefValue := testNamespace + "/" + dnsPolicy.Name
-			refs, _ := json.Marshal([]client.ObjectKey{{Name: dnsPolicy.Name, Namespace: testNamespace}})
-			policiesBackRefValue := string(refs)
+			policyKey := client.ObjectKey{Name: dnsPolicy.Name, Namespace: testNamespace}
 
 			Eventually(func(g Gomega) {
 				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(gateway), gateway)
 				g.Expect(err).NotTo(HaveOccurred())
 				g.Expect(gateway.Annotations).To(HaveKeyWithValue(v1alpha1.DNSPolicyDirectReferenceAnnotationName, policyBackRefValue))
-				g.Expect(gateway.Annotations).To(HaveKeyWithValue(v1alpha1.DNSPolicyBackReferenceAnnotationName, policiesBackRefValue))
+				g.Expect(gateway.Annotations).ToNot(HaveKey(v1alpha1.DNSPolicyBackReferenceAnnotationName))
+				g.Expect(kuadrant.BackReferencesFromIndex(ctx, backReferenceIndex, gateway, dnsPolicy)).To(ContainElement(policyKey))
 			}, TestTimeoutMedium, time.Second).Should(Succeed())
 		})
 
@@ -418,14 +417,14 @@ var _ = Describe("DNSPolicy controller", func() {
 
 		It("should remove gateway back reference on policy deletion", func() {
 			policyBackRefValue := testNamespace + "/" + dnsPolicy.Name
-			refs, _ := json.Marshal([]client.ObjectKey{{Name: dnsPolicy.Name, Namespace: testNamespace}})
-			policiesBackRefValue := string(refs)
+			policyKey := client.ObjectKey{Name: dnsPolicy.Name, Namespace: testNamespace}
 
 			Eventually(func(g Gomega) {
 				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(gateway), gateway)
 				g.Expect(err).NotTo(HaveOccurred())
 				g.Expect(gateway.Annotations).To(HaveKeyWithValue(v1alpha1.DNSPolicyDirectReferenceAnnotationName, policyBackRefValue))
-				g.Expect(gateway.Annotations).To(HaveKeyWithValue(v1alpha1.DNSPolicyBackReferenceAnnotationName, policiesBackRefValue))
+				g.Expect(gateway.Annotations).ToNot(HaveKey(v1alpha1.DNSPolicyBackReferenceAnnotationName))
+				g.Expect(kuadrant.BackReferencesFromIndex(ctx, backReferenceIndex, gateway, dnsPolicy)).To(ContainElement(policyKey))
 				g.Expect(gateway.Status.Conditions).To(
 					ContainElement(MatchFields(IgnoreExtras, Fields{
 						"Type":               Equal(DNSPolicyAffected),
@@ -447,7 +446,7 @@ var _ = Describe("DNSPolicy controller", func() {
 				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(gateway), gateway)
 				g.Expect(err).NotTo(HaveOccurred())
 				g.Expect(gateway.Annotations).ToNot(HaveKey(v1alpha1.DNSPolicyDirectReferenceAnnotationName))
-				g.Expect(gateway.Annotations).ToNot(HaveKeyWithValue(v1alpha1.DNSPolicyBackReferenceAnnotationName, policiesBackRefValue))
+				g.Expect(kuadrant.BackReferencesFromIndex(ctx, backReferenceIndex, gateway, dnsPolicy)).ToNot(ContainElement(policyKey))
 				g.Expect(gateway.Status.Conditions).ToNot(
 					ContainElement(MatchFields(IgnoreExtras, Fields{
 						"Type": Equal(string(DNSPolicyAffected)),
@@ -26,16 +26,18 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/gateway-api/apis/v1alpha2"
 
 	kuadrantdnsv1alpha1 "github.com/kuadrant/dns-operator/api/v1alpha1"
 	"github.com/kuadrant/kuadrant-operator/api/v1alpha1"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/kuadrant"
+	"github.com/kuadrant/kuadrant-operator/pkg/library/reconcilers"
 )
 
-func (r *DNSPolicyReconciler) reconcileStatus(ctx context.Context, dnsPolicy *v1alpha1.DNSPolicy, specErr error) (ctrl.Result, error) {
-	newStatus := r.calculateStatus(ctx, dnsPolicy, specErr)
+func (r *DNSPolicyReconciler) reconcileStatus(ctx context.Context, dnsPolicy *v1alpha1.DNSPolicy, targetNetworkObject client.Object, specErr error) (ctrl.Result, error) {
+	newStatus := r.calculateStatus(ctx, dnsPolicy, targetNetworkObject, specErr)
 
 	equalStatus := equality.Semantic.DeepEqual(newStatus, dnsPolicy.Status)
 	if equalStatus && dnsPolicy.Generation == dnsPolicy.Status.ObservedGeneration {
@@ -61,7 +63,7 @@ func (r *DNSPolicyReconciler) reconcileStatus(ctx context.Context, dnsPolicy *v1
 	return ctrl.Result{}, nil
 }
 
-func (r *DNSPolicyReconciler) calculateStatus(ctx context.Context, dnsPolicy *v1alpha1.DNSPolicy, specErr error) *v1alpha1.DNSPolicyStatus {
+func (r *DNSPolicyReconciler) calculateStatus(ctx context.Context, dnsPolicy *v1alpha1.DNSPolicy, targetNetworkObject client.Object, specErr error) *v1alpha1.DNSPolicyStatus {
 	newStatus := &v1alpha1.DNSPolicyStatus{
 		// Copy initial conditions. Otherwise, status will always be updated
 		Conditions:         slices.Clone(dnsPolicy.Status.Conditions),
@@ -73,12 +75,15 @@ func (r *DNSPolicyReconciler) calculateStatus(ctx context.Context, dnsPolicy *v1
 
 	// Do not set enforced condition if Accepted condition is false
 	if meta.IsStatusConditionFalse(newStatus.Conditions, string(v1alpha2.PolicyConditionAccepted)) {
+		newStatus.Ancestors = reconcilers.PolicyAncestorStatuses(ctx, r.Client(), r.TargetRefReconciler.BackReferenceIndex, dnsPolicy, targetNetworkObject, specErr, nil, dnsPolicy.Status.Ancestors)
 		return newStatus
 	}
 
 	enforcedCondition := r.enforcedCondition(ctx, dnsPolicy)
 	meta.SetStatusCondition(&newStatus.Conditions, *enforcedCondition)
 
+	newStatus.Ancestors = reconcilers.PolicyAncestorStatuses(ctx, r.Client(), r.TargetRefReconciler.BackReferenceIndex, dnsPolicy, targetNetworkObject, specErr, enforcedCondition, dnsPolicy.Status.Ancestors)
+
 	return newStatus
 }
 
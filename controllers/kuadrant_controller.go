@@ -22,18 +22,28 @@ import (
 
 	"github.com/go-logr/logr"
 	authorinov1beta1 "github.com/kuadrant/authorino-operator/api/v1beta1"
+	authorinoapi "github.com/kuadrant/authorino/api/v1beta2"
 	limitadorv1alpha1 "github.com/kuadrant/limitador-operator/api/v1alpha1"
 	appsv1 "k8s.io/api/apps/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	kuadrantv1beta1 "github.com/kuadrant/kuadrant-operator/api/v1beta1"
 	"github.com/kuadrant/kuadrant-operator/pkg/common"
 	"github.com/kuadrant/kuadrant-operator/pkg/kuadranttools"
+	"github.com/kuadrant/kuadrant-operator/pkg/library/kuadrant"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/reconcilers"
+	"github.com/kuadrant/kuadrant-operator/pkg/library/utils"
 	"github.com/kuadrant/kuadrant-operator/pkg/log"
 )
 
@@ -58,6 +68,7 @@ type KuadrantReconciler struct {
 //+kubebuilder:rbac:groups="gateway.networking.k8s.io",resources=gateways,verbs=get;list;watch;create;update;delete;patch
 //+kubebuilder:rbac:groups="gateway.networking.k8s.io",resources=httproutes,verbs=get;list;patch;update;watch
 //+kubebuilder:rbac:groups=operator.authorino.kuadrant.io,resources=authorinos,verbs=get;list;watch;create;update;delete;patch
+//+kubebuilder:rbac:groups=authorino.kuadrant.io,resources=authconfigs,verbs=get;list;watch
 //+kubebuilder:rbac:groups=install.istio.io,resources=istiooperators,verbs=get;list;watch;create;update;patch
 //+kubebuilder:rbac:groups=operator.istio.io,resources=istios,verbs=get;list;watch;create;update;patch
 //+kubebuilder:rbac:groups=maistra.io,resources=servicemeshcontrolplanes,verbs=get;list;watch;update;use;patch
@@ -195,38 +206,118 @@ func (r *KuadrantReconciler) reconcileLimitador(ctx context.Context, kObj *kuadr
 }
 
 func (r *KuadrantReconciler) reconcileAuthorino(ctx context.Context, kObj *kuadrantv1beta1.Kuadrant) error {
-	tmpFalse := false
-	authorino := &authorinov1beta1.Authorino{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "Authorino",
-			APIVersion: "operator.authorino.kuadrant.io/v1beta1",
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "authorino",
-			Namespace: kObj.Namespace,
-		},
-		Spec: authorinov1beta1.AuthorinoSpec{
-			ClusterWide:            true,
-			SupersedingHostSubsets: true,
-			Listener: authorinov1beta1.Listener{
-				Tls: authorinov1beta1.Tls{
-					Enabled: &tmpFalse,
-				},
-			},
-			OIDCServer: authorinov1beta1.OIDCServer{
-				Tls: authorinov1beta1.Tls{
-					Enabled: &tmpFalse,
-				},
-			},
-		},
-	}
+	authorino := kuadranttools.Authorino(kObj, common.AuthorinoName)
 
 	err := r.SetOwnerReference(kObj, authorino)
 	if err != nil {
 		return err
 	}
 
-	return r.ReconcileResource(ctx, &authorinov1beta1.Authorino{}, authorino, reconcilers.CreateOnlyMutator)
+	if err := r.ReconcileResource(ctx, &authorinov1beta1.Authorino{}, authorino, kuadranttools.AuthorinoMutator); err != nil {
+		return err
+	}
+
+	return r.reconcileDedicatedAuthorinos(ctx, kObj)
+}
+
+// reconcileDedicatedAuthorinos makes sure there is one Authorino instance for each gateway that opted in to a dedicated
+// one, and deletes the instances of the gateways that no longer need them
+func (r *KuadrantReconciler) reconcileDedicatedAuthorinos(ctx context.Context, kObj *kuadrantv1beta1.Kuadrant) error {
+	logger, err := logr.FromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	gwList := &gatewayapiv1.GatewayList{}
+	if err := r.Client().List(ctx, gwList); err != nil {
+		return err
+	}
+
+	desired := make(map[string]struct{})
+	for idx := range gwList.Items {
+		gw := &gwList.Items[idx]
+		if gw.GetAnnotations()[kuadrant.KuadrantNamespaceAnnotation] != kObj.Namespace || !kuadranttools.IsDedicatedAuthorinoGateway(gw, kObj) {
+			continue
+		}
+
+		authorino := kuadranttools.Authorino(kObj, kuadranttools.DedicatedAuthorinoName(client.ObjectKeyFromObject(gw)))
+		if err := r.SetOwnerReference(kObj, authorino); err != nil {
+			return err
+		}
+		if err := r.ReconcileResource(ctx, &authorinov1beta1.Authorino{}, authorino, kuadranttools.AuthorinoMutator); err != nil {
+			return err
+		}
+		desired[authorino.Name] = struct{}{}
+	}
+
+	selector, err := labels.Parse(common.AuthorinoInstanceLabel)
+	if err != nil {
+		return err
+	}
+	authorinoList := &authorinov1beta1.AuthorinoList{}
+	if err := r.Client().List(ctx, authorinoList, client.InNamespace(kObj.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return err
+	}
+	for idx := range authorinoList.Items {
+		authorino := &authorinoList.Items[idx]
+		if _, ok := desired[authorino.Name]; ok {
+			continue
+		}
+		// the authconfigs are moved to the new instance by the reconciliation of their authpolicies, which is triggered
+		// by the same change, so the instance is only deleted once it no longer serves any authconfig
+		inUse, err := r.isAuthorinoInUse(ctx, authorino.Name)
+		if err != nil {
+			return err
+		}
+		if inUse {
+			logger.V(1).Info("dedicated authorino no longer required still serves authconfigs", "authorino", authorino.Name)
+			continue
+		}
+		logger.V(1).Info("deleting dedicated authorino no longer required", "authorino", authorino.Name)
+		if err := r.DeleteResource(ctx, authorino); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isAuthorinoInUse tells whether there are authconfigs labeled for the dedicated authorino instance
+func (r *KuadrantReconciler) isAuthorinoInUse(ctx context.Context, authorinoName string) (bool, error) {
+	authConfigList := &authorinoapi.AuthConfigList{}
+	if err := r.Client().List(ctx, authConfigList, client.MatchingLabels(kuadranttools.AuthorinoAuthConfigLabels(authorinoName))); err != nil {
+		return false, err
+	}
+	return len(authConfigList.Items) > 0, nil
+}
+
+// authConfigToKuadrants maps events of the authconfigs served by dedicated authorino instances to events of the kuadrant
+// instances, so the dedicated instances no longer required are deleted once they no longer serve any authconfig
+func (r *KuadrantReconciler) authConfigToKuadrants(ctx context.Context, _ client.Object) []reconcile.Request {
+	kuadrantList := &kuadrantv1beta1.KuadrantList{}
+	if err := r.Client().List(ctx, kuadrantList); err != nil {
+		r.Logger().V(1).Info("cannot map authconfig event to kuadrant", "error", err.Error())
+		return nil
+	}
+	return utils.Map(kuadrantList.Items, func(k kuadrantv1beta1.Kuadrant) reconcile.Request {
+		return reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&k)}
+	})
+}
+
+// gatewayToKuadrant maps events of the gateways to events of the kuadrant instance managing them
+func (r *KuadrantReconciler) gatewayToKuadrant(ctx context.Context, obj client.Object) []reconcile.Request {
+	kNamespace, ok := obj.GetAnnotations()[kuadrant.KuadrantNamespaceAnnotation]
+	if !ok {
+		return nil
+	}
+	kuadrantList := &kuadrantv1beta1.KuadrantList{}
+	if err := r.Client().List(ctx, kuadrantList, client.InNamespace(kNamespace)); err != nil {
+		r.Logger().V(1).Info("cannot map gateway event to kuadrant", "error", err.Error())
+		return nil
+	}
+	return utils.Map(kuadrantList.Items, func(k kuadrantv1beta1.Kuadrant) reconcile.Request {
+		return reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&k)}
+	})
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -236,5 +327,34 @@ func (r *KuadrantReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&appsv1.Deployment{}).
 		Owns(&limitadorv1alpha1.Limitador{}).
 		Owns(&authorinov1beta1.Authorino{}).
+		// Gateways can opt in to a dedicated authorino instance
+		Watches(
+			&gatewayapiv1.Gateway{},
+			handler.EnqueueRequestsFromMapFunc(r.gatewayToKuadrant),
+			builder.WithPredicates(predicate.AnnotationChangedPredicate{}),
+		).
+		// Dedicated authorino instances are deleted once they no longer serve any authconfig
+		Watches(
+			&authorinoapi.AuthConfig{},
+			handler.EnqueueRequestsFromMapFunc(r.authConfigToKuadrants),
+			builder.WithPredicates(dedicatedAuthorinoAuthConfigPredicate()),
+		).
 		Complete(r)
 }
+
+// dedicatedAuthorinoAuthConfigPredicate filters the events of the authconfigs down to the ones that release a dedicated
+// authorino instance, i.e. authconfigs no longer labeled for the instance or deleted
+func dedicatedAuthorinoAuthConfigPredicate() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(_ event.CreateEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldInstance, ok := e.ObjectOld.GetLabels()[common.AuthorinoInstanceLabel]
+			return ok && oldInstance != e.ObjectNew.GetLabels()[common.AuthorinoInstanceLabel]
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			_, ok := e.Object.GetLabels()[common.AuthorinoInstanceLabel]
+			return ok
+		},
+		GenericFunc: func(_ event.GenericEvent) bool { return false },
+	}
+}
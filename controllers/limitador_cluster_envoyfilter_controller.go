@@ -28,6 +28,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
 
@@ -35,7 +36,9 @@ import (
 	"github.com/kuadrant/kuadrant-operator/api/v1beta2"
 	kuadrantistioutils "github.com/kuadrant/kuadrant-operator/pkg/istio"
 	"github.com/kuadrant/kuadrant-operator/pkg/kuadranttools"
+	kuadrantgatewayapi "github.com/kuadrant/kuadrant-operator/pkg/library/gatewayapi"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/kuadrant"
+	"github.com/kuadrant/kuadrant-operator/pkg/library/mappers"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/reconcilers"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/utils"
 )
@@ -43,6 +46,8 @@ import (
 // LimitadorClusterEnvoyFilterReconciler reconciles a EnvoyFilter object with limitador's cluster
 type LimitadorClusterEnvoyFilterReconciler struct {
 	*reconcilers.BaseReconciler
+	TopologyCache      *kuadrantgatewayapi.TopologyCache
+	BackReferenceIndex kuadrant.BackReferenceIndex
 }
 
 //+kubebuilder:rbac:groups=networking.istio.io,resources=envoyfilters,verbs=get;list;watch;create;update;patch;delete
@@ -122,7 +127,10 @@ func (r *LimitadorClusterEnvoyFilterReconciler) desiredRateLimitingClusterEnvoyF
 		},
 	}
 
-	gateway := kuadrant.GatewayWrapper{Gateway: gw, Referrer: &v1beta2.RateLimitPolicy{}}
+	gateway, err := kuadrant.NewGatewayWrapper(ctx, r.BackReferenceIndex, gw, &v1beta2.RateLimitPolicy{})
+	if err != nil {
+		return nil, err
+	}
 	rlpRefs := gateway.PolicyRefs()
 	logger.V(1).Info("desiredRateLimitingClusterEnvoyFilter", "rlpRefs", rlpRefs)
 
@@ -161,11 +169,20 @@ func (r *LimitadorClusterEnvoyFilterReconciler) SetupWithManager(mgr ctrl.Manage
 		return nil
 	}
 
+	topologyToParentGatewaysEventMapper := mappers.NewTopologyToParentGatewaysEventMapper(
+		mappers.WithLogger(r.Logger().WithName("topologyToParentGatewaysEventMapper")),
+		mappers.WithClient(r.Client()),
+	)
+
 	return ctrl.NewControllerManagedBy(mgr).
 		// Limitador cluster EnvoyFilter controller only cares about
-		// the annotation having references to RLP's
-		// kuadrant.io/ratelimitpolicies
-		For(&gatewayapiv1.Gateway{}, builder.WithPredicates(predicate.AnnotationChangedPredicate{})).
+		// the gateways having RLP's attached, directly or through their routes
+		For(&gatewayapiv1.Gateway{}).
 		Owns(&istioclientnetworkingv1alpha3.EnvoyFilter{}).
+		WatchesRawSource(
+			r.TopologyCache.Source(),
+			handler.EnqueueRequestsFromMapFunc(topologyToParentGatewaysEventMapper.Map),
+			builder.WithPredicates(predicate.NewPredicateFuncs(isRateLimitTopologyObject)),
+		).
 		Complete(r)
 }
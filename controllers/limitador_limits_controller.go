@@ -0,0 +1,233 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/go-logr/logr"
+	limitadorv1alpha1 "github.com/kuadrant/limitador-operator/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	kuadrantv1beta1 "github.com/kuadrant/kuadrant-operator/api/v1beta1"
+	kuadrantv1beta2 "github.com/kuadrant/kuadrant-operator/api/v1beta2"
+	"github.com/kuadrant/kuadrant-operator/pkg/common"
+	kuadrantgatewayapi "github.com/kuadrant/kuadrant-operator/pkg/library/gatewayapi"
+	"github.com/kuadrant/kuadrant-operator/pkg/library/kuadrant"
+	"github.com/kuadrant/kuadrant-operator/pkg/library/reconcilers"
+	"github.com/kuadrant/kuadrant-operator/pkg/rlptools"
+	"github.com/kuadrant/kuadrant-operator/pkg/rlptools/wasm"
+)
+
+// LimitadorLimitsReconciler reconciles the limits of a Limitador object out of all the accepted RateLimitPolicies
+// of the kuadrant instance. It is the only writer of Limitador.Spec.Limits.
+type LimitadorLimitsReconciler struct {
+	*reconcilers.BaseReconciler
+	TopologyCache *kuadrantgatewayapi.TopologyCache
+}
+
+//+kubebuilder:rbac:groups=limitador.kuadrant.io,resources=limitadors,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=kuadrant.io,resources=ratelimitpolicies,verbs=get;list;watch
+
+// Reconcile computes the limits of the Limitador object from scratch on every event, so limits of
+// RateLimitPolicies reconciled concurrently or deleted are never lost nor left behind.
+func (r *LimitadorLimitsReconciler) Reconcile(eventCtx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := r.Logger().WithValues("limitador", req.NamespacedName)
+	if req.Name != common.LimitadorName {
+		logger.V(1).Info("not the limitador of a kuadrant instance, skipping")
+		return ctrl.Result{}, nil
+	}
+	logger.Info("Reconciling limitador limits")
+	ctx := logr.NewContext(eventCtx, logger)
+
+	// optimistic concurrency: the limitador object is read and updated with the same resource version,
+	// and the whole computation is retried on conflict. The limitador is read from the API server, bypassing
+	// the cache, so retries are not bound to fail with the same stale resource version.
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		limitador := &limitadorv1alpha1.Limitador{}
+		if err := r.APIClientReader().Get(ctx, req.NamespacedName, limitador); err != nil {
+			return err
+		}
+
+		if !isKuadrantLimitador(limitador) {
+			logger.V(1).Info("limitador not managed by a kuadrant instance, skipping")
+			return nil
+		}
+
+		rateLimitIndex, err := r.buildRateLimitIndex(ctx, limitador.Namespace)
+		if err != nil {
+			return err
+		}
+
+		if rlptools.Equal(rateLimitIndex.ToRateLimits(), limitador.Spec.Limits) {
+			logger.V(1).Info("limitador is up to date, skipping update")
+			return nil
+		}
+
+		limitador.Spec.Limits = rateLimitIndex.ToRateLimits()
+		err = r.Client().Update(ctx, limitador)
+		logger.V(1).Info("update limitador", "err", err)
+		return err
+	})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Info("no limitador found")
+			return ctrl.Result{}, nil
+		}
+		logger.Error(err, "failed to reconcile limitador limits")
+		return ctrl.Result{}, err
+	}
+
+	logger.Info("limitador limits reconciled successfully")
+	return ctrl.Result{}, nil
+}
+
+// buildRateLimitIndex lists the RateLimitPolicies of the kuadrant instance and indexes the limits of the accepted ones
+func (r *LimitadorLimitsReconciler) buildRateLimitIndex(ctx context.Context, kuadrantNamespace string) (*rlptools.RateLimitIndex, error) {
+	logger, _ := logr.FromContext(ctx)
+
+	rlpList := &kuadrantv1beta2.RateLimitPolicyList{}
+	if err := r.Client().List(ctx, rlpList); err != nil {
+		return nil, err
+	}
+
+	// the topology resolves the gateways of the limits scoped to the gateways
+	topology, err := r.TopologyCache.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimitIndex := rateLimitIndexFromPolicies(wasm.TopologyIndexesFromTopology(topology), kuadrantNamespace, rlpList.Items)
+	logger.V(1).Info("buildRateLimitIndex", "ratelimitpolicies", rateLimitIndex.Keys())
+
+	return rateLimitIndex, nil
+}
+
+// rateLimitIndexFromPolicies indexes the limits of the accepted policies of a kuadrant instance that are not marked for
+// deletion. Policies are indexed in order of creation, so the resulting list of limits is stable.
+func rateLimitIndexFromPolicies(t *kuadrantgatewayapi.TopologyIndexes, kuadrantNamespace string, rlps []kuadrantv1beta2.RateLimitPolicy) *rlptools.RateLimitIndex {
+	policies := make([]kuadrantgatewayapi.Policy, 0, len(rlps))
+	for idx := range rlps {
+		rlp := &rlps[idx]
+		if rlp.GetDeletionTimestamp() != nil {
+			continue
+		}
+		if ns, isSet := kuadrant.GetKuadrantNamespaceFromPolicy(rlp); !isSet || ns != kuadrantNamespace {
+			continue
+		}
+		if !meta.IsStatusConditionTrue(rlp.Status.Conditions, string(gatewayapiv1alpha2.PolicyConditionAccepted)) {
+			continue
+		}
+		policies = append(policies, rlp)
+	}
+	sort.Sort(kuadrantgatewayapi.PolicyByCreationTimestamp(policies))
+
+	rateLimitIndex := rlptools.NewRateLimitIndex()
+	for _, policy := range policies {
+		rlp := policy.(*kuadrantv1beta2.RateLimitPolicy)
+		rateLimitIndex.Set(client.ObjectKeyFromObject(rlp), rlptools.LimitadorRateLimitsFromRLP(t, rlp))
+	}
+
+	return rateLimitIndex
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *LimitadorLimitsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("limitador-limits").
+		For(&limitadorv1alpha1.Limitador{}, builder.WithPredicates(predicate.NewPredicateFuncs(isKuadrantLimitador))).
+		// any event of any ratelimitpolicy may change the limits, and so may events of gateways and routes change the
+		// namespaces of the limits scoped to the gateways. Events are received after the topology cache has been
+		// updated, so the reconciliation always reads a snapshot that includes the triggering change
+		WatchesRawSource(
+			r.TopologyCache.Source(),
+			handler.EnqueueRequestsFromMapFunc(r.topologyToLimitadors),
+			builder.WithPredicates(predicate.NewPredicateFuncs(isRateLimitTopologyObject)),
+		).
+		Complete(r)
+}
+
+// topologyToLimitadors maps events of ratelimitpolicies to the limitador of the kuadrant instance, and events of
+// gateways and routes to the limitadors of all the kuadrant instances
+func (r *LimitadorLimitsReconciler) topologyToLimitadors(ctx context.Context, obj client.Object) []reconcile.Request {
+	if _, isPolicy := obj.(*kuadrantv1beta2.RateLimitPolicy); isPolicy {
+		return r.rateLimitPolicyToLimitador(ctx, obj)
+	}
+
+	limitadorList := &limitadorv1alpha1.LimitadorList{}
+	if err := r.Client().List(ctx, limitadorList); err != nil {
+		r.Logger().V(1).Error(err, "failed to list limitadors")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0)
+	for idx := range limitadorList.Items {
+		if isKuadrantLimitador(&limitadorList.Items[idx]) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&limitadorList.Items[idx])})
+		}
+	}
+	return requests
+}
+
+// isKuadrantLimitador tells whether the object is the limitador of a kuadrant instance, i.e. it is named after
+// common.LimitadorName and controlled by a Kuadrant CR living in the same namespace
+func isKuadrantLimitador(obj client.Object) bool {
+	if obj.GetName() != common.LimitadorName {
+		return false
+	}
+	owner := metav1.GetControllerOf(obj)
+	if owner == nil {
+		return false
+	}
+	ownerGV, err := schema.ParseGroupVersion(owner.APIVersion)
+	if err != nil {
+		return false
+	}
+	return ownerGV.Group == kuadrantv1beta1.GroupVersion.Group && owner.Kind == "Kuadrant"
+}
+
+// rateLimitPolicyToLimitador maps events of ratelimitpolicies to the limitador of the kuadrant instance
+func (r *LimitadorLimitsReconciler) rateLimitPolicyToLimitador(ctx context.Context, obj client.Object) []reconcile.Request {
+	logger := r.Logger().WithValues("ratelimitpolicy", client.ObjectKeyFromObject(obj))
+
+	rlp, ok := obj.(*kuadrantv1beta2.RateLimitPolicy)
+	if !ok {
+		logger.Info("cannot map ratelimitpolicy event to limitador", "error", fmt.Sprintf("%T is not a *kuadrantv1beta2.RateLimitPolicy", obj))
+		return nil
+	}
+
+	kuadrantNamespace, isSet := kuadrant.GetKuadrantNamespaceFromPolicy(rlp)
+	if !isSet {
+		logger.V(1).Info("ratelimitpolicy not assigned to a kuadrant instance yet")
+		return nil
+	}
+
+	return []reconcile.Request{{NamespacedName: client.ObjectKey{Name: common.LimitadorName, Namespace: kuadrantNamespace}}}
+}
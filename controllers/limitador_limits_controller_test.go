@@ -0,0 +1,81 @@
+//go:build unit
+
+package controllers
+
+import (
+	"testing"
+
+	limitadorv1alpha1 "github.com/kuadrant/limitador-operator/api/v1alpha1"
+	"gotest.tools/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	kuadrantv1beta2 "github.com/kuadrant/kuadrant-operator/api/v1beta2"
+	"github.com/kuadrant/kuadrant-operator/pkg/common"
+	"github.com/kuadrant/kuadrant-operator/pkg/library/kuadrant"
+)
+
+func Test_rateLimitIndexFromPolicies(t *testing.T) {
+	policy := func(name string, creation int64, kuadrantNamespace string, accepted bool) kuadrantv1beta2.RateLimitPolicy {
+		rlp := kuadrantv1beta2.RateLimitPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         "app-ns",
+				Name:              name,
+				CreationTimestamp: metav1.Unix(creation, 0),
+			},
+			Spec: kuadrantv1beta2.RateLimitPolicySpec{
+				RateLimitPolicyCommonSpec: kuadrantv1beta2.RateLimitPolicyCommonSpec{
+					Limits: map[string]kuadrantv1beta2.Limit{
+						"l1": {Rates: []kuadrantv1beta2.Rate{{Limit: 10, Duration: 1, Unit: "second"}}},
+					},
+				},
+			},
+		}
+		if kuadrantNamespace != "" {
+			rlp.Annotations = map[string]string{kuadrant.KuadrantNamespaceAnnotation: kuadrantNamespace}
+		}
+		status := metav1.ConditionFalse
+		if accepted {
+			status = metav1.ConditionTrue
+		}
+		rlp.Status.Conditions = []metav1.Condition{{Type: string(gatewayapiv1alpha2.PolicyConditionAccepted), Status: status}}
+		return rlp
+	}
+
+	deleted := policy("deleted", 1, "kuadrant-system", true)
+	deleted.DeletionTimestamp = &metav1.Time{}
+
+	index := rateLimitIndexFromPolicies(nil, "kuadrant-system", []kuadrantv1beta2.RateLimitPolicy{
+		policy("newer", 3, "kuadrant-system", true),
+		policy("older", 2, "kuadrant-system", true),
+		policy("not-accepted", 1, "kuadrant-system", false),
+		policy("other-instance", 1, "other-kuadrant", true),
+		policy("unassigned", 1, "", true),
+		deleted,
+	})
+
+	assert.DeepEqual(t, index.Keys(), []client.ObjectKey{
+		{Namespace: "app-ns", Name: "older"},
+		{Namespace: "app-ns", Name: "newer"},
+	})
+	assert.Equal(t, len(index.ToRateLimits()), 2)
+}
+
+func Test_isKuadrantLimitador(t *testing.T) {
+	limitador := func(name string, owners ...metav1.OwnerReference) *limitadorv1alpha1.Limitador {
+		return &limitadorv1alpha1.Limitador{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "kuadrant-system", Name: name, OwnerReferences: owners},
+		}
+	}
+	controller := func(apiVersion, kind string) metav1.OwnerReference {
+		return metav1.OwnerReference{APIVersion: apiVersion, Kind: kind, Name: "kuadrant", Controller: ptr.To(true)}
+	}
+
+	assert.Assert(t, isKuadrantLimitador(limitador(common.LimitadorName, controller("kuadrant.io/v1beta1", "Kuadrant"))))
+	assert.Assert(t, !isKuadrantLimitador(limitador("other", controller("kuadrant.io/v1beta1", "Kuadrant"))))
+	assert.Assert(t, !isKuadrantLimitador(limitador(common.LimitadorName)))
+	assert.Assert(t, !isKuadrantLimitador(limitador(common.LimitadorName, controller("example.com/v1", "Kuadrant"))))
+	assert.Assert(t, !isKuadrantLimitador(limitador(common.LimitadorName, metav1.OwnerReference{APIVersion: "kuadrant.io/v1beta1", Kind: "Kuadrant", Name: "kuadrant"})))
+}
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/kuadrant/kuadrant-operator/pkg/library/fieldindexers"
+	kuadrantgatewayapi "github.com/kuadrant/kuadrant-operator/pkg/library/gatewayapi"
+	"github.com/kuadrant/kuadrant-operator/pkg/library/kuadrant"
+	"github.com/kuadrant/kuadrant-operator/pkg/library/mappers"
+	"github.com/kuadrant/kuadrant-operator/pkg/library/utils"
+)
+
+// overriddenCondition returns the enforced condition of a gateway policy overridden by the policies targeting
+// the routes of the gateway
+func overriddenCondition(logger logr.Logger, policy kuadrant.Policy, overridingPolicies []kuadrantgatewayapi.Policy) *metav1.Condition {
+	refs := utils.Map(overridingPolicies, func(p kuadrantgatewayapi.Policy) client.ObjectKey {
+		return client.ObjectKeyFromObject(p)
+	})
+	jsonData, err := json.Marshal(refs)
+	if err != nil {
+		logger.Error(err, "Failed to marshal overriding policy references")
+		return kuadrant.EnforcedCondition(policy, kuadrant.NewErrUnknown(policy.Kind(), err), false)
+	}
+	return kuadrant.EnforcedCondition(policy, kuadrant.NewErrOverridden(policy.Kind(), string(jsonData)), false)
+}
+
+// topologyToGatewayPolicies maps events of the topology to events of the policies of the kind of the list that target
+// the parent gateways, whose overridden state may have changed.
+// The policies are looked up by gateway in the target ref index, so no snapshot of the topology is built per event.
+func topologyToGatewayPolicies(cl client.Reader, logger logr.Logger, mapper *mappers.TopologyToParentGatewaysEventMapper, newList func() client.ObjectList) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		requests := make([]reconcile.Request, 0)
+		for _, gatewayRequest := range mapper.Map(ctx, obj) {
+			list := newList()
+			if err := cl.List(ctx, list, client.MatchingFields{
+				fieldindexers.PolicyTargetRefField: fieldindexers.PolicyTargetRefIndexValue("Gateway", gatewayRequest.NamespacedName),
+			}); err != nil {
+				logger.V(1).Info("cannot map topology event to gateway policies", "gateway", gatewayRequest.NamespacedName, "error", err.Error())
+				continue
+			}
+			items, err := meta.ExtractList(list)
+			if err != nil {
+				logger.V(1).Info("cannot map topology event to gateway policies", "gateway", gatewayRequest.NamespacedName, "error", err.Error())
+				continue
+			}
+			for _, item := range items {
+				if policy, ok := item.(client.Object); ok {
+					requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(policy)})
+				}
+			}
+		}
+		return requests
+	}
+}
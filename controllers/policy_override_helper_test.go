@@ -0,0 +1,63 @@
+//go:build unit
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"gotest.tools/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	kuadrantv1beta2 "github.com/kuadrant/kuadrant-operator/api/v1beta2"
+	"github.com/kuadrant/kuadrant-operator/pkg/library/fieldindexers"
+	"github.com/kuadrant/kuadrant-operator/pkg/library/mappers"
+)
+
+func Test_topologyToGatewayPolicies(t *testing.T) {
+	policy := func(name, targetKind, targetName string) *kuadrantv1beta2.RateLimitPolicy {
+		return &kuadrantv1beta2.RateLimitPolicy{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app-ns", Name: name},
+			Spec: kuadrantv1beta2.RateLimitPolicySpec{
+				TargetRef: gatewayapiv1alpha2.PolicyTargetReference{
+					Group:     gatewayapiv1.GroupName,
+					Kind:      gatewayapiv1.Kind(targetKind),
+					Name:      gatewayapiv1.ObjectName(targetName),
+					Namespace: ptr.To(gatewayapiv1.Namespace("gw-ns")),
+				},
+			},
+		}
+	}
+
+	scheme := runtime.NewScheme()
+	assert.NilError(t, kuadrantv1beta2.AddToScheme(scheme))
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			policy("gw-policy", "Gateway", "gw"),
+			policy("other-gw-policy", "Gateway", "other-gw"),
+			policy("route-policy", "HTTPRoute", "gw"),
+		).
+		WithIndex(&kuadrantv1beta2.RateLimitPolicy{}, fieldindexers.PolicyTargetRefField, func(obj client.Object) []string {
+			targetRef := obj.(*kuadrantv1beta2.RateLimitPolicy).GetTargetRef()
+			return []string{fieldindexers.PolicyTargetRefIndexValue(string(targetRef.Kind), client.ObjectKey{Namespace: string(*targetRef.Namespace), Name: string(targetRef.Name)})}
+		}).
+		Build()
+
+	mapFunc := topologyToGatewayPolicies(cl, logr.Discard(), mappers.NewTopologyToParentGatewaysEventMapper(mappers.WithClient(cl)), func() client.ObjectList {
+		return &kuadrantv1beta2.RateLimitPolicyList{}
+	})
+
+	gateway := &gatewayapiv1.Gateway{ObjectMeta: metav1.ObjectMeta{Namespace: "gw-ns", Name: "gw"}}
+	assert.DeepEqual(t, mapFunc(context.Background(), gateway), []reconcile.Request{
+		{NamespacedName: client.ObjectKey{Namespace: "app-ns", Name: "gw-policy"}},
+	})
+}
@@ -26,15 +26,17 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
 	gwapiv1a2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 
 	kuadrantv1beta1 "github.com/kuadrant/kuadrant-operator/api/v1beta1"
-	kuadrantv1beta2 "github.com/kuadrant/kuadrant-operator/api/v1beta2"
 	kuadrantenvoygateway "github.com/kuadrant/kuadrant-operator/pkg/envoygateway"
 	"github.com/kuadrant/kuadrant-operator/pkg/kuadranttools"
+	kuadrantgatewayapi "github.com/kuadrant/kuadrant-operator/pkg/library/gatewayapi"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/mappers"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/reconcilers"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/utils"
@@ -45,6 +47,7 @@ import (
 // https://gateway.envoyproxy.io/latest/api/extension_types/#envoypatchpolicy
 type RateLimitingEnvoyPatchPolicyReconciler struct {
 	*reconcilers.BaseReconciler
+	TopologyCache *kuadrantgatewayapi.TopologyCache
 }
 
 //+kubebuilder:rbac:groups=gateway.envoyproxy.io,resources=envoypatchpolicies,verbs=get;list;watch;create;update;patch;delete
@@ -149,7 +152,12 @@ func (r *RateLimitingEnvoyPatchPolicyReconciler) desiredEnvoyPatchPolicy(ctx con
 	//
 	// Wasm filter patch
 	//
-	wasmConfig, err := wasm.ConfigFromGateway(ctx, r.Client(), gw)
+	topology, err := r.TopologyCache.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	wasmConfig, err := wasm.ConfigFromGateway(ctx, topology, gw)
 	if err != nil {
 		return nil, err
 	}
@@ -200,12 +208,8 @@ func (r *RateLimitingEnvoyPatchPolicyReconciler) SetupWithManager(mgr ctrl.Manag
 		return nil
 	}
 
-	httpRouteToParentGatewaysEventMapper := mappers.NewHTTPRouteToParentGatewaysEventMapper(
-		mappers.WithLogger(r.Logger().WithName("httpRouteToParentGatewaysEventMapper")),
-	)
-
-	rlpToParentGatewaysEventMapper := mappers.NewPolicyToParentGatewaysEventMapper(
-		mappers.WithLogger(r.Logger().WithName("ratelimitpolicyToParentGatewaysEventMapper")),
+	topologyToParentGatewaysEventMapper := mappers.NewTopologyToParentGatewaysEventMapper(
+		mappers.WithLogger(r.Logger().WithName("topologyToParentGatewaysEventMapper")),
 		mappers.WithClient(r.Client()),
 	)
 
@@ -217,13 +221,12 @@ func (r *RateLimitingEnvoyPatchPolicyReconciler) SetupWithManager(mgr ctrl.Manag
 
 		For(&gatewayapiv1.Gateway{}).
 		Owns(&egv1alpha1.EnvoyPatchPolicy{}).
-		Watches(
-			&gatewayapiv1.HTTPRoute{},
-			handler.EnqueueRequestsFromMapFunc(httpRouteToParentGatewaysEventMapper.Map),
-		).
-		Watches(
-			&kuadrantv1beta2.RateLimitPolicy{},
-			handler.EnqueueRequestsFromMapFunc(rlpToParentGatewaysEventMapper.Map),
+		// Events of gateways, routes and policies are received after the topology cache has been updated,
+		// so the reconciliation always reads a snapshot that includes the triggering change
+		WatchesRawSource(
+			r.TopologyCache.Source(),
+			handler.EnqueueRequestsFromMapFunc(topologyToParentGatewaysEventMapper.Map),
+			builder.WithPredicates(predicate.NewPredicateFuncs(isRateLimitTopologyObject)),
 		).
 		Complete(r)
 }
@@ -27,12 +27,15 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/env"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	kuadrantv1beta2 "github.com/kuadrant/kuadrant-operator/api/v1beta2"
 	kuadrantistioutils "github.com/kuadrant/kuadrant-operator/pkg/istio"
+	kuadrantgatewayapi "github.com/kuadrant/kuadrant-operator/pkg/library/gatewayapi"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/mappers"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/reconcilers"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/utils"
@@ -46,6 +49,7 @@ var (
 // RateLimitingWASMPluginReconciler reconciles a WASMPlugin object for rate limiting
 type RateLimitingWASMPluginReconciler struct {
 	*reconcilers.BaseReconciler
+	TopologyCache *kuadrantgatewayapi.TopologyCache
 }
 
 //+kubebuilder:rbac:groups=extensions.istio.io,resources=wasmplugins,verbs=get;list;watch;create;update;patch;delete
@@ -118,7 +122,12 @@ func (r *RateLimitingWASMPluginReconciler) desiredRateLimitingWASMPlugin(ctx con
 
 	logger := baseLogger.WithValues("wasmplugin", client.ObjectKeyFromObject(wasmPlugin))
 
-	pluginConfig, err := wasm.ConfigFromGateway(ctx, r.Client(), gw)
+	topology, err := r.TopologyCache.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	pluginConfig, err := wasm.ConfigFromGateway(ctx, topology, gw)
 	if err != nil {
 		return nil, err
 	}
@@ -155,12 +164,8 @@ func (r *RateLimitingWASMPluginReconciler) SetupWithManager(mgr ctrl.Manager) er
 		return nil
 	}
 
-	httpRouteToParentGatewaysEventMapper := mappers.NewHTTPRouteToParentGatewaysEventMapper(
-		mappers.WithLogger(r.Logger().WithName("httpRouteToParentGatewaysEventMapper")),
-	)
-
-	rlpToParentGatewaysEventMapper := mappers.NewPolicyToParentGatewaysEventMapper(
-		mappers.WithLogger(r.Logger().WithName("ratelimitpolicyToParentGatewaysEventMapper")),
+	topologyToParentGatewaysEventMapper := mappers.NewTopologyToParentGatewaysEventMapper(
+		mappers.WithLogger(r.Logger().WithName("topologyToParentGatewaysEventMapper")),
 		mappers.WithClient(r.Client()),
 	)
 
@@ -174,13 +179,21 @@ func (r *RateLimitingWASMPluginReconciler) SetupWithManager(mgr ctrl.Manager) er
 		// TODO(eguzki): consider having the WasmPlugin as the type of object being *reconciled*
 		For(&gatewayapiv1.Gateway{}).
 		Owns(&istioclientgoextensionv1alpha1.WasmPlugin{}).
-		Watches(
-			&gatewayapiv1.HTTPRoute{},
-			handler.EnqueueRequestsFromMapFunc(httpRouteToParentGatewaysEventMapper.Map),
-		).
-		Watches(
-			&kuadrantv1beta2.RateLimitPolicy{},
-			handler.EnqueueRequestsFromMapFunc(rlpToParentGatewaysEventMapper.Map),
+		// Events of gateways, routes and policies are received after the topology cache has been updated,
+		// so the reconciliation always reads a snapshot that includes the triggering change
+		WatchesRawSource(
+			r.TopologyCache.Source(),
+			handler.EnqueueRequestsFromMapFunc(topologyToParentGatewaysEventMapper.Map),
+			builder.WithPredicates(predicate.NewPredicateFuncs(isRateLimitTopologyObject)),
 		).
 		Complete(r)
 }
+
+// isRateLimitTopologyObject filters out events of the topology related to policies other than RateLimitPolicies
+func isRateLimitTopologyObject(obj client.Object) bool {
+	if _, isPolicy := obj.(kuadrantgatewayapi.Policy); !isPolicy {
+		return true
+	}
+	_, isRateLimitPolicy := obj.(*kuadrantv1beta2.RateLimitPolicy)
+	return isRateLimitPolicy
+}
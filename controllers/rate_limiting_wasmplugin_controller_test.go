@@ -203,7 +203,7 @@ var _ = Describe("Rate Limiting WasmPlugin controller", func() {
 								Rates: []kuadrantv1beta2.Rate{
 									{Limit: 50, Duration: 1, Unit: kuadrantv1beta2.TimeUnit("minute")},
 								},
-								Counters: []kuadrantv1beta2.ContextSelector{"auth.identity.username"},
+								Counters: []kuadrantv1beta2.Counter{{Selector: "auth.identity.username"}},
 								RouteSelectors: []kuadrantv1beta2.RouteSelector{
 									{ // selects the 1st HTTPRouteRule (i.e. get|post /toys*) for one of the hostnames
 										Matches: []gatewayapiv1.HTTPRouteMatch{
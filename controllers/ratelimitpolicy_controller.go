@@ -21,18 +21,24 @@ import (
 	"encoding/json"
 
 	"github.com/go-logr/logr"
+	limitadorv1alpha1 "github.com/kuadrant/limitador-operator/api/v1alpha1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	kuadrantv1beta2 "github.com/kuadrant/kuadrant-operator/api/v1beta2"
+	"github.com/kuadrant/kuadrant-operator/pkg/common"
+	kuadrantgatewayapi "github.com/kuadrant/kuadrant-operator/pkg/library/gatewayapi"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/kuadrant"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/mappers"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/reconcilers"
+	"github.com/kuadrant/kuadrant-operator/pkg/rlptools"
 )
 
 const rateLimitPolicyFinalizer = "ratelimitpolicy.kuadrant.io/finalizer"
@@ -41,12 +47,13 @@ const rateLimitPolicyFinalizer = "ratelimitpolicy.kuadrant.io/finalizer"
 type RateLimitPolicyReconciler struct {
 	*reconcilers.BaseReconciler
 	TargetRefReconciler reconcilers.TargetRefReconciler
+	// TopologyCache computes whether the policies targeting gateways are overridden
+	TopologyCache *kuadrantgatewayapi.TopologyCache
 }
 
 //+kubebuilder:rbac:groups=kuadrant.io,resources=ratelimitpolicies,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=kuadrant.io,resources=ratelimitpolicies/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=kuadrant.io,resources=ratelimitpolicies/finalizers,verbs=update
-//+kubebuilder:rbac:groups=limitador.kuadrant.io,resources=limitadors,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch;update;patch
 //+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gateways,verbs=get;list;watch;update;patch
 
@@ -95,7 +102,7 @@ func (r *RateLimitPolicyReconciler) Reconcile(eventCtx context.Context, req ctrl
 				if delResErr == nil {
 					delResErr = err
 				}
-				return r.reconcileStatus(ctx, rlp, kuadrant.NewErrTargetNotFound(rlp.Kind(), rlp.GetTargetRef(), delResErr))
+				return r.reconcileStatus(ctx, rlp, nil, kuadrant.NewErrTargetNotFound(rlp.Kind(), rlp.GetTargetRef(), delResErr))
 			}
 			return ctrl.Result{}, err
 		}
@@ -132,7 +139,7 @@ func (r *RateLimitPolicyReconciler) Reconcile(eventCtx context.Context, req ctrl
 	specErr := r.reconcileResources(ctx, rlp, targetNetworkObject)
 
 	// reconcile ratelimitpolicy status
-	statusResult, statusErr := r.reconcileStatus(ctx, rlp, specErr)
+	statusResult, statusErr := r.reconcileStatus(ctx, rlp, targetNetworkObject, specErr)
 
 	if specErr != nil {
 		return ctrl.Result{}, specErr
@@ -169,45 +176,74 @@ func (r *RateLimitPolicyReconciler) reconcileResources(ctx context.Context, rlp
 		return err
 	}
 
-	// reconcile based on gateway diffs
-	gatewayDiffObj, err := reconcilers.ComputeGatewayDiffs(ctx, r.Client(), rlp, targetNetworkObject)
-	if err != nil {
+	// the limits are reconciled by the LimitadorLimitsReconciler, out of the policies assigned to the kuadrant instance
+	if err := r.reconcileKuadrantNamespace(ctx, rlp); err != nil {
 		return err
 	}
 
-	if err := r.reconcileLimits(ctx, rlp); err != nil {
+	if err := r.checkLimitIdentifierCollisions(rlp); err != nil {
 		return err
 	}
 
 	// set direct back ref - i.e. claim the target network object as taken asap
-	if err := r.reconcileNetworkResourceDirectBackReference(ctx, rlp, targetNetworkObject); err != nil {
-		return err
+	return r.reconcileNetworkResourceDirectBackReference(ctx, rlp, targetNetworkObject)
+}
+
+func (r *RateLimitPolicyReconciler) deleteResources(ctx context.Context, rlp *kuadrantv1beta2.RateLimitPolicy, targetNetworkObject client.Object) error {
+	// remove direct back ref
+	if targetNetworkObject != nil {
+		return r.deleteNetworkResourceDirectBackReference(ctx, targetNetworkObject, rlp)
 	}
 
-	// set annotation of policies affecting the gateway - should be the last step, only when all the reconciliation steps succeed
-	return r.TargetRefReconciler.ReconcileGatewayPolicyReferences(ctx, rlp, gatewayDiffObj)
+	return nil
 }
 
-func (r *RateLimitPolicyReconciler) deleteResources(ctx context.Context, rlp *kuadrantv1beta2.RateLimitPolicy, targetNetworkObject client.Object) error {
-	// delete based on gateway diffs
-	gatewayDiffObj, err := reconcilers.ComputeGatewayDiffs(ctx, r.Client(), rlp, targetNetworkObject)
+// reconcileKuadrantNamespace assigns the policy to the kuadrant instance of the targeted network resource
+func (r *RateLimitPolicyReconciler) reconcileKuadrantNamespace(ctx context.Context, rlp *kuadrantv1beta2.RateLimitPolicy) error {
+	logger, _ := logr.FromContext(ctx)
+
+	if _, isSet := kuadrant.GetKuadrantNamespaceFromPolicy(rlp); isSet {
+		return nil
+	}
+
+	kuadrantNamespace, err := kuadrant.GetKuadrantNamespaceFromPolicyTargetRef(ctx, r.Client(), rlp)
 	if err != nil {
+		logger.Error(err, "failed to get kuadrant namespace")
 		return err
 	}
+	kuadrant.AnnotateObject(rlp, kuadrantNamespace)
+	err = r.UpdateResource(ctx, rlp)
+	logger.V(1).Info("reconcileKuadrantNamespace: update policy", "kuadrant namespace", kuadrantNamespace, "err", err)
+	return err
+}
 
-	if err := r.deleteLimits(ctx, rlp); err != nil && !apierrors.IsNotFound(err) {
-		return err
+// checkLimitIdentifierCollisions returns a conflict error if any two limits of the policy would share the counters
+func (r *RateLimitPolicyReconciler) checkLimitIdentifierCollisions(rlp *kuadrantv1beta2.RateLimitPolicy) error {
+	if err := rlptools.LimitIdentifierCollision(rlp); err != nil {
+		return kuadrant.NewErrConflict(rlp.Kind(), client.ObjectKeyFromObject(rlp).String(), err)
 	}
+	return nil
+}
 
-	// remove direct back ref
-	if targetNetworkObject != nil {
-		if err := r.deleteNetworkResourceDirectBackReference(ctx, targetNetworkObject, rlp); err != nil {
-			return err
-		}
+// limitadorToRateLimitPolicies maps the limitador instance of a kuadrant instance to the policies assigned to it
+func (r *RateLimitPolicyReconciler) limitadorToRateLimitPolicies(ctx context.Context, obj client.Object) []reconcile.Request {
+	if obj.GetName() != common.LimitadorName {
+		return nil
 	}
 
-	// update annotation of policies affecting the gateway
-	return r.TargetRefReconciler.ReconcileGatewayPolicyReferences(ctx, rlp, gatewayDiffObj)
+	rlpList := &kuadrantv1beta2.RateLimitPolicyList{}
+	if err := r.Client().List(ctx, rlpList); err != nil {
+		r.Logger().V(1).Error(err, "failed to list ratelimitpolicies")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0)
+	for idx := range rlpList.Items {
+		if ns, isSet := kuadrant.GetKuadrantNamespaceFromPolicy(&rlpList.Items[idx]); isSet && ns == obj.GetNamespace() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&rlpList.Items[idx])})
+		}
+	}
+	return requests
 }
 
 // Ensures only one RLP targets the network resource
@@ -221,15 +257,30 @@ func (r *RateLimitPolicyReconciler) deleteNetworkResourceDirectBackReference(ctx
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *RateLimitPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	httpRouteEventMapper := mappers.NewHTTPRouteEventMapper(mappers.WithLogger(r.Logger().WithName("httpRouteEventMapper")))
-	gatewayEventMapper := mappers.NewGatewayEventMapper(mappers.WithLogger(r.Logger().WithName("gatewayEventMapper")))
+	httpRouteEventMapper := mappers.NewHTTPRouteEventMapper(
+		mappers.WithLogger(r.Logger().WithName("httpRouteEventMapper")),
+		mappers.WithBackReferenceIndex(r.TargetRefReconciler.BackReferenceIndex),
+	)
+	gatewayEventMapper := mappers.NewGatewayEventMapper(
+		mappers.WithLogger(r.Logger().WithName("gatewayEventMapper")),
+		mappers.WithBackReferenceIndex(r.TargetRefReconciler.BackReferenceIndex),
+	)
+	topologyToParentGatewaysEventMapper := mappers.NewTopologyToParentGatewaysEventMapper(
+		mappers.WithLogger(r.Logger().WithName("topologyToParentGatewaysEventMapper")),
+		mappers.WithClient(r.Client()),
+	)
 
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&kuadrantv1beta2.RateLimitPolicy{}).
+		// status-only updates, such as the ones that report the usage of the limits, do not need to be reconciled
+		For(&kuadrantv1beta2.RateLimitPolicy{}, builder.WithPredicates(predicate.Or(
+			predicate.GenerationChangedPredicate{},
+			predicate.LabelChangedPredicate{},
+			predicate.AnnotationChangedPredicate{},
+		))).
 		Watches(
 			&gatewayapiv1.HTTPRoute{},
 			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, object client.Object) []reconcile.Request {
-				return httpRouteEventMapper.MapToPolicy(object, &kuadrantv1beta2.RateLimitPolicy{})
+				return httpRouteEventMapper.MapToPolicy(ctx, object, &kuadrantv1beta2.RateLimitPolicy{})
 			}),
 		).
 		// Currently the purpose is to generate events when rlp references change in gateways
@@ -237,8 +288,30 @@ func (r *RateLimitPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Watches(
 			&gatewayapiv1.Gateway{},
 			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, object client.Object) []reconcile.Request {
-				return gatewayEventMapper.MapToPolicy(object, &kuadrantv1beta2.RateLimitPolicy{})
+				return gatewayEventMapper.MapToPolicy(ctx, object, &kuadrantv1beta2.RateLimitPolicy{})
 			}),
 		).
+		// the policies are enforced by the limitador instance of the kuadrant instance they are assigned to
+		Watches(
+			&limitadorv1alpha1.Limitador{},
+			handler.EnqueueRequestsFromMapFunc(r.limitadorToRateLimitPolicies),
+		).
+		// Events of routes and rate limit policies are received after the topology cache has been updated,
+		// so the overridden state of the gateway policies is computed out of a snapshot that includes the triggering change
+		WatchesRawSource(
+			r.TopologyCache.Source(),
+			handler.EnqueueRequestsFromMapFunc(topologyToGatewayPolicies(r.Client(), r.Logger(), topologyToParentGatewaysEventMapper, func() client.ObjectList { return &kuadrantv1beta2.RateLimitPolicyList{} })),
+			builder.WithPredicates(predicate.NewPredicateFuncs(isRateLimitPolicyRouteTopologyObject)),
+		).
 		Complete(r)
 }
+
+// isRateLimitPolicyRouteTopologyObject filters the events of the topology down to the ones of routes and rate limit policies
+func isRateLimitPolicyRouteTopologyObject(obj client.Object) bool {
+	switch obj.(type) {
+	case *gatewayapiv1.HTTPRoute, *kuadrantv1beta2.RateLimitPolicy:
+		return true
+	default:
+		return false
+	}
+}
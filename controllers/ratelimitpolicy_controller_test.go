@@ -4,7 +4,6 @@ package controllers
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -21,6 +20,7 @@ import (
 
 	kuadrantv1beta2 "github.com/kuadrant/kuadrant-operator/api/v1beta2"
 	"github.com/kuadrant/kuadrant-operator/pkg/common"
+	"github.com/kuadrant/kuadrant-operator/pkg/library/kuadrant"
 	"github.com/kuadrant/kuadrant-operator/pkg/rlptools"
 )
 
@@ -128,11 +128,7 @@ var _ = Describe("RateLimitPolicy controller", func() {
 			err = k8sClient.Get(context.Background(), gwKey, existingGateway)
 			// must exist
 			Expect(err).ToNot(HaveOccurred())
-			refs := []client.ObjectKey{rlpKey}
-			serialized, err := json.Marshal(refs)
-			Expect(err).ToNot(HaveOccurred())
-			Expect(existingGateway.GetAnnotations()).To(HaveKeyWithValue(
-				rlp.BackReferenceAnnotationName(), string(serialized)))
+			Expect(kuadrant.BackReferencesFromIndex(context.Background(), backReferenceIndex, existingGateway, rlp)).To(Equal([]client.ObjectKey{rlpKey}))
 		})
 	})
 
@@ -208,10 +204,7 @@ var _ = Describe("RateLimitPolicy controller", func() {
 			err = k8sClient.Get(context.Background(), gwKey, existingGateway)
 			// must exist
 			Expect(err).ToNot(HaveOccurred())
-			refs := []client.ObjectKey{rlpKey}
-			serialized, err := json.Marshal(refs)
-			Expect(err).ToNot(HaveOccurred())
-			Expect(existingGateway.GetAnnotations()).To(HaveKeyWithValue(rlp.BackReferenceAnnotationName(), string(serialized)))
+			Expect(kuadrant.BackReferencesFromIndex(context.Background(), backReferenceIndex, existingGateway, rlp)).To(Equal([]client.ObjectKey{rlpKey}))
 		})
 
 		It("Creates all the resources for a basic Gateway and RateLimitPolicy when missing a HTTPRoute attached to the Gateway", func() {
@@ -255,11 +248,7 @@ var _ = Describe("RateLimitPolicy controller", func() {
 			err = k8sClient.Get(context.Background(), gwKey, existingGateway)
 			// must exist
 			Expect(err).ToNot(HaveOccurred())
-			refs := []client.ObjectKey{rlpKey}
-			serialized, err := json.Marshal(refs)
-			Expect(err).ToNot(HaveOccurred())
-			Expect(existingGateway.GetAnnotations()).To(HaveKeyWithValue(
-				rlp.BackReferenceAnnotationName(), string(serialized)))
+			Expect(kuadrant.BackReferencesFromIndex(context.Background(), backReferenceIndex, existingGateway, rlp)).To(Equal([]client.ObjectKey{rlpKey}))
 		})
 	})
 
@@ -318,10 +307,7 @@ var _ = Describe("RateLimitPolicy controller", func() {
 
 			// Gateway should contain HTTPRoute RLP in backreference
 			Expect(k8sClient.Get(ctx, gwKey, existingGateway)).To(Succeed())
-			serialized, err := json.Marshal(rlpKey)
-			Expect(err).ToNot(HaveOccurred())
-			Expect(existingGateway.GetAnnotations()).To(HaveKey(routeRLP.BackReferenceAnnotationName()))
-			Expect(existingGateway.GetAnnotations()[routeRLP.BackReferenceAnnotationName()]).To(ContainSubstring(string(serialized)))
+			Expect(kuadrant.BackReferencesFromIndex(ctx, backReferenceIndex, existingGateway, routeRLP)).To(ContainElement(rlpKey))
 
 		}, SpecTimeout(time.Minute))
 	})
@@ -2,22 +2,29 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"slices"
 
 	"github.com/go-logr/logr"
+	limitadorv1alpha1 "github.com/kuadrant/limitador-operator/api/v1alpha1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	kuadrantv1beta2 "github.com/kuadrant/kuadrant-operator/api/v1beta2"
+	"github.com/kuadrant/kuadrant-operator/pkg/common"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/kuadrant"
+	"github.com/kuadrant/kuadrant-operator/pkg/library/reconcilers"
+	"github.com/kuadrant/kuadrant-operator/pkg/rlptools/wasm"
 )
 
-func (r *RateLimitPolicyReconciler) reconcileStatus(ctx context.Context, rlp *kuadrantv1beta2.RateLimitPolicy, specErr error) (ctrl.Result, error) {
+func (r *RateLimitPolicyReconciler) reconcileStatus(ctx context.Context, rlp *kuadrantv1beta2.RateLimitPolicy, targetNetworkObject client.Object, specErr error) (ctrl.Result, error) {
 	logger, _ := logr.FromContext(ctx)
-	newStatus := r.calculateStatus(ctx, rlp, specErr)
+	newStatus := r.calculateStatus(ctx, rlp, targetNetworkObject, specErr)
 
 	equalStatus := rlp.Status.Equals(newStatus, logger)
 	logger.V(1).Info("Status", "status is different", !equalStatus)
@@ -36,8 +43,12 @@ func (r *RateLimitPolicyReconciler) reconcileStatus(ctx context.Context, rlp *ku
 
 	logger.V(1).Info("Updating Status", "sequence no:", fmt.Sprintf("sequence No: %v->%v", rlp.Status.ObservedGeneration, newStatus.ObservedGeneration))
 
+	// The usage is owned by the usage poller, so it is left out of the patch.
+	// The patch is rejected if the policy has changed since it was read, so a stale status never overwrites a newer one.
+	base := rlp.DeepCopy()
+	base.Status.Usage = nil
 	rlp.Status = *newStatus
-	updateErr := r.Client().Status().Update(ctx, rlp)
+	updateErr := r.Client().Status().Patch(ctx, rlp, client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{}))
 	logger.V(1).Info("Updating Status", "err", updateErr)
 	if updateErr != nil {
 		// Ignore conflicts, resource might just be outdated.
@@ -51,7 +62,7 @@ func (r *RateLimitPolicyReconciler) reconcileStatus(ctx context.Context, rlp *ku
 	return ctrl.Result{}, nil
 }
 
-func (r *RateLimitPolicyReconciler) calculateStatus(_ context.Context, rlp *kuadrantv1beta2.RateLimitPolicy, specErr error) *kuadrantv1beta2.RateLimitPolicyStatus {
+func (r *RateLimitPolicyReconciler) calculateStatus(ctx context.Context, rlp *kuadrantv1beta2.RateLimitPolicy, targetNetworkObject client.Object, specErr error) *kuadrantv1beta2.RateLimitPolicyStatus {
 	newStatus := &kuadrantv1beta2.RateLimitPolicyStatus{
 		// Copy initial conditions. Otherwise, status will always be updated
 		Conditions:         slices.Clone(rlp.Status.Conditions),
@@ -62,5 +73,48 @@ func (r *RateLimitPolicyReconciler) calculateStatus(_ context.Context, rlp *kuad
 
 	meta.SetStatusCondition(&newStatus.Conditions, *acceptedCond)
 
+	newStatus.Ancestors = reconcilers.PolicyAncestorStatuses(ctx, r.Client(), r.TargetRefReconciler.BackReferenceIndex, rlp, targetNetworkObject, specErr, r.enforcedCondition(ctx, rlp), rlp.Status.Ancestors)
+
+	if specErr == nil {
+		newStatus.ReportModeLimits = rlp.ReportModeLimits()
+	}
+
 	return newStatus
 }
+
+// enforcedCondition returns the enforced condition of the policy out of whether the policy is overridden and the
+// readiness of the limitador instance of the kuadrant instance the policy is assigned to, or nil if it cannot be determined
+func (r *RateLimitPolicyReconciler) enforcedCondition(ctx context.Context, rlp *kuadrantv1beta2.RateLimitPolicy) *metav1.Condition {
+	logger, _ := logr.FromContext(ctx)
+
+	topology, err := r.TopologyCache.Snapshot()
+	if err != nil {
+		logger.V(1).Info("failed to read the topology", "error", err.Error())
+		return kuadrant.EnforcedCondition(rlp, kuadrant.NewErrUnknown(rlp.Kind(), err), false)
+	}
+	if topologyIndexes := wasm.TopologyIndexesFromTopology(topology); topologyIndexes.IsPolicyOverridden(rlp) {
+		logger.V(1).Info("Gateway Policy is overridden")
+		return overriddenCondition(logger, rlp, topologyIndexes.OverridingPolicies(rlp))
+	}
+
+	kuadrantNamespace, isSet := kuadrant.GetKuadrantNamespaceFromPolicy(rlp)
+	if !isSet {
+		return kuadrant.EnforcedCondition(rlp, kuadrant.NewErrUnknown(rlp.Kind(), errors.New("policy not yet assigned to a kuadrant instance")), false)
+	}
+
+	limitador := &limitadorv1alpha1.Limitador{}
+	err = r.Client().Get(ctx, client.ObjectKey{Name: common.LimitadorName, Namespace: kuadrantNamespace}, limitador)
+	if apierrors.IsNotFound(err) {
+		return kuadrant.EnforcedCondition(rlp, kuadrant.NewErrUnknown(rlp.Kind(), errors.New("limitador not found")), false)
+	}
+	if err != nil {
+		logger.V(1).Info("failed to get limitador", "error", err.Error())
+		return nil
+	}
+
+	if ready := meta.FindStatusCondition(limitador.Status.Conditions, "Ready"); ready == nil || ready.Status != metav1.ConditionTrue {
+		return kuadrant.EnforcedCondition(rlp, kuadrant.NewErrUnknown(rlp.Kind(), errors.New("limitador is not ready")), false)
+	}
+
+	return kuadrant.EnforcedCondition(rlp, nil, true)
+}
@@ -47,6 +47,8 @@ import (
 	kuadrantv1alpha1 "github.com/kuadrant/kuadrant-operator/api/v1alpha1"
 	kuadrantv1beta1 "github.com/kuadrant/kuadrant-operator/api/v1beta1"
 	kuadrantv1beta2 "github.com/kuadrant/kuadrant-operator/api/v1beta2"
+	"github.com/kuadrant/kuadrant-operator/pkg/library/fieldindexers"
+	kuadrantgatewayapi "github.com/kuadrant/kuadrant-operator/pkg/library/gatewayapi"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/kuadrant"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/reconcilers"
 	"github.com/kuadrant/kuadrant-operator/pkg/log"
@@ -59,6 +61,7 @@ import (
 var cfg *rest.Config
 var k8sClient client.Client
 var testEnv *envtest.Environment
+var backReferenceIndex kuadrant.BackReferenceIndex
 
 func testClient() client.Client { return k8sClient }
 
@@ -137,6 +140,35 @@ var _ = BeforeSuite(func() {
 	})
 	Expect(err).ToNot(HaveOccurred())
 
+	err = fieldindexers.HTTPRouteIndexByGateway(mgr, log.Log.WithName("kuadrant").WithName("indexer").WithName("routeIndexByGateway"))
+	Expect(err).NotTo(HaveOccurred())
+
+	for _, policyType := range []kuadrantgatewayapi.Policy{
+		&kuadrantv1beta2.RateLimitPolicy{},
+		&kuadrantv1beta2.AuthPolicy{},
+		&kuadrantv1alpha1.DNSPolicy{},
+		&kuadrantv1alpha1.TLSPolicy{},
+	} {
+		err = fieldindexers.PolicyIndexByTargetRef(mgr, policyType, log.Log.WithName("kuadrant").WithName("indexer").WithName("policyIndexByTargetRef"))
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	backReferenceIndex = fieldindexers.NewBackReferenceIndex(mgr.GetClient(), log.Log.WithName("kuadrant").WithName("backreferences")).
+		WithPolicyList(&kuadrantv1beta2.RateLimitPolicy{}, &kuadrantv1beta2.RateLimitPolicyList{}).
+		WithPolicyList(&kuadrantv1beta2.AuthPolicy{}, &kuadrantv1beta2.AuthPolicyList{}).
+		WithPolicyList(&kuadrantv1alpha1.DNSPolicy{}, &kuadrantv1alpha1.DNSPolicyList{}).
+		WithPolicyList(&kuadrantv1alpha1.TLSPolicy{}, &kuadrantv1alpha1.TLSPolicyList{})
+
+	topologyCache := kuadrantgatewayapi.NewTopologyCache(
+		log.Log.WithName("kuadrant").WithName("topology"),
+		&kuadrantv1beta2.RateLimitPolicy{},
+		&kuadrantv1beta2.AuthPolicy{},
+		&kuadrantv1alpha1.DNSPolicy{},
+		&kuadrantv1alpha1.TLSPolicy{},
+	)
+	err = topologyCache.SetupWithManager(mgr)
+	Expect(err).NotTo(HaveOccurred())
+
 	authPolicyBaseReconciler := reconcilers.NewBaseReconciler(
 		mgr.GetClient(), mgr.GetScheme(), mgr.GetAPIReader(),
 		log.Log.WithName("authpolicy"),
@@ -145,8 +177,8 @@ var _ = BeforeSuite(func() {
 
 	err = (&AuthPolicyReconciler{
 		BaseReconciler:      authPolicyBaseReconciler,
-		TargetRefReconciler: reconcilers.TargetRefReconciler{Client: mgr.GetClient()},
-		OverriddenPolicyMap: kuadrant.NewOverriddenPolicyMap(),
+		TargetRefReconciler: reconcilers.TargetRefReconciler{Client: mgr.GetClient(), BackReferenceIndex: backReferenceIndex},
+		TopologyCache:       topologyCache,
 	}).SetupWithManager(mgr)
 	Expect(err).NotTo(HaveOccurred())
 
@@ -158,7 +190,21 @@ var _ = BeforeSuite(func() {
 
 	err = (&RateLimitPolicyReconciler{
 		BaseReconciler:      rateLimitPolicyBaseReconciler,
-		TargetRefReconciler: reconcilers.TargetRefReconciler{Client: mgr.GetClient()},
+		TargetRefReconciler: reconcilers.TargetRefReconciler{Client: mgr.GetClient(), BackReferenceIndex: backReferenceIndex},
+		TopologyCache:       topologyCache,
+	}).SetupWithManager(mgr)
+
+	Expect(err).NotTo(HaveOccurred())
+
+	limitadorLimitsBaseReconciler := reconcilers.NewBaseReconciler(
+		mgr.GetClient(), mgr.GetScheme(), mgr.GetAPIReader(),
+		log.Log.WithName("ratelimitpolicy").WithName("limitador"),
+		mgr.GetEventRecorderFor("LimitadorLimits"),
+	)
+
+	err = (&LimitadorLimitsReconciler{
+		BaseReconciler: limitadorLimitsBaseReconciler,
+		TopologyCache:  topologyCache,
 	}).SetupWithManager(mgr)
 
 	Expect(err).NotTo(HaveOccurred())
@@ -171,7 +217,7 @@ var _ = BeforeSuite(func() {
 
 	err = (&TLSPolicyReconciler{
 		BaseReconciler:      tlsPolicyBaseReconciler,
-		TargetRefReconciler: reconcilers.TargetRefReconciler{Client: mgr.GetClient()},
+		TargetRefReconciler: reconcilers.TargetRefReconciler{Client: mgr.GetClient(), BackReferenceIndex: backReferenceIndex},
 	}).SetupWithManager(mgr)
 
 	Expect(err).NotTo(HaveOccurred())
@@ -184,7 +230,7 @@ var _ = BeforeSuite(func() {
 
 	err = (&DNSPolicyReconciler{
 		BaseReconciler:      dnsPolicyBaseReconciler,
-		TargetRefReconciler: reconcilers.TargetRefReconciler{Client: mgr.GetClient()},
+		TargetRefReconciler: reconcilers.TargetRefReconciler{Client: mgr.GetClient(), BackReferenceIndex: backReferenceIndex},
 	}).SetupWithManager(mgr)
 
 	Expect(err).NotTo(HaveOccurred())
@@ -208,7 +254,9 @@ var _ = BeforeSuite(func() {
 	)
 
 	err = (&LimitadorClusterEnvoyFilterReconciler{
-		BaseReconciler: limitadorClusterEnvoyFilterBaseReconciler,
+		BaseReconciler:     limitadorClusterEnvoyFilterBaseReconciler,
+		TopologyCache:      topologyCache,
+		BackReferenceIndex: backReferenceIndex,
 	}).SetupWithManager(mgr)
 
 	Expect(err).NotTo(HaveOccurred())
@@ -233,6 +281,7 @@ var _ = BeforeSuite(func() {
 
 	err = (&RateLimitingWASMPluginReconciler{
 		BaseReconciler: rateLimitingWASMPluginBaseReconciler,
+		TopologyCache:  topologyCache,
 	}).SetupWithManager(mgr)
 
 	Expect(err).NotTo(HaveOccurred())
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"reflect"
 
+	certmanv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	"github.com/go-logr/logr"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -88,7 +89,7 @@ func (r *TLSPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 				if delResErr == nil {
 					delResErr = err
 				}
-				return r.reconcileStatus(ctx, tlsPolicy, kuadrant.NewErrTargetNotFound(tlsPolicy.Kind(), tlsPolicy.GetTargetRef(), delResErr))
+				return r.reconcileStatus(ctx, tlsPolicy, nil, kuadrant.NewErrTargetNotFound(tlsPolicy.Kind(), tlsPolicy.GetTargetRef(), delResErr))
 			}
 			return ctrl.Result{}, err
 		}
@@ -117,7 +118,7 @@ func (r *TLSPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 
 	specErr := r.reconcileResources(ctx, tlsPolicy, targetReferenceObject)
 
-	statusResult, statusErr := r.reconcileStatus(ctx, tlsPolicy, specErr)
+	statusResult, statusErr := r.reconcileStatus(ctx, tlsPolicy, targetReferenceObject, specErr)
 
 	if specErr != nil {
 		return ctrl.Result{}, specErr
@@ -150,7 +151,7 @@ func (r *TLSPolicyReconciler) reconcileResources(ctx context.Context, tlsPolicy
 	}
 
 	// reconcile based on gateway diffs
-	gatewayDiffObj, err := reconcilers.ComputeGatewayDiffs(ctx, r.Client(), tlsPolicy, targetNetworkObject)
+	gatewayDiffObj, err := reconcilers.ComputeGatewayDiffs(ctx, r.Client(), r.TargetRefReconciler.BackReferenceIndex, tlsPolicy, targetNetworkObject)
 	if err != nil {
 		return err
 	}
@@ -168,13 +169,6 @@ func (r *TLSPolicyReconciler) reconcileResources(ctx context.Context, tlsPolicy
 		return errors.Join(fmt.Errorf("reconcile TargetBackReference error %w", err), updateErr)
 	}
 
-	// set annotation of policies affecting the gateway
-	if err = r.TargetRefReconciler.ReconcileGatewayPolicyReferences(ctx, tlsPolicy, gatewayDiffObj); err != nil {
-		gatewayCondition = BuildPolicyAffectedCondition(TLSPolicyAffected, tlsPolicy, targetNetworkObject, gatewayapiv1alpha2.PolicyConditionReason(PolicyReasonUnknown), err)
-		updateErr := r.updateGatewayCondition(ctx, gatewayCondition, gatewayDiffObj)
-		return errors.Join(fmt.Errorf("ReconcileGatewayPolicyReferences error %w", err), updateErr)
-	}
-
 	// set gateway policy affected condition status - should be the last step, only when all the reconciliation steps succeed
 	updateErr := r.updateGatewayCondition(ctx, gatewayCondition, gatewayDiffObj)
 	if updateErr != nil {
@@ -186,7 +180,7 @@ func (r *TLSPolicyReconciler) reconcileResources(ctx context.Context, tlsPolicy
 
 func (r *TLSPolicyReconciler) deleteResources(ctx context.Context, tlsPolicy *v1alpha1.TLSPolicy, targetNetworkObject client.Object) error {
 	// delete based on gateway diffs
-	gatewayDiffObj, err := reconcilers.ComputeGatewayDiffs(ctx, r.Client(), tlsPolicy, targetNetworkObject)
+	gatewayDiffObj, err := reconcilers.ComputeGatewayDiffs(ctx, r.Client(), r.TargetRefReconciler.BackReferenceIndex, tlsPolicy, targetNetworkObject)
 	if err != nil {
 		return err
 	}
@@ -202,11 +196,6 @@ func (r *TLSPolicyReconciler) deleteResources(ctx context.Context, tlsPolicy *v1
 		}
 	}
 
-	// update annotation of policies affecting the gateway
-	if err := r.TargetRefReconciler.ReconcileGatewayPolicyReferences(ctx, tlsPolicy, gatewayDiffObj); err != nil {
-		return err
-	}
-
 	// remove gateway policy affected condition status
 	return r.updateGatewayCondition(ctx, metav1.Condition{Type: string(TLSPolicyAffected)}, gatewayDiffObj)
 }
@@ -241,16 +230,24 @@ func (r *TLSPolicyReconciler) updateGatewayCondition(ctx context.Context, condit
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *TLSPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	gatewayEventMapper := mappers.NewGatewayEventMapper(mappers.WithLogger(r.Logger().WithName("gatewayEventMapper")))
+	gatewayEventMapper := mappers.NewGatewayEventMapper(
+		mappers.WithLogger(r.Logger().WithName("gatewayEventMapper")),
+		mappers.WithBackReferenceIndex(r.TargetRefReconciler.BackReferenceIndex),
+	)
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.TLSPolicy{}).
 		Watches(
 			&gatewayapiv1.Gateway{},
 			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, object client.Object) []reconcile.Request {
-				return gatewayEventMapper.MapToPolicy(object, &v1alpha1.TLSPolicy{})
+				return gatewayEventMapper.MapToPolicy(ctx, object, &v1alpha1.TLSPolicy{})
 			}),
 		).
+		// the policies are enforced once the certificates managed for them are ready
+		Watches(
+			&certmanv1.Certificate{},
+			handler.EnqueueRequestsFromMapFunc(certificateToTLSPolicy),
+		).
 		Complete(r)
 }
 
@@ -266,3 +263,17 @@ func (r *TLSPolicyReconciler) FetchValidGateway(ctx context.Context, key client.
 
 	return gw, nil
 }
+
+// certificateToTLSPolicy maps a certificate to the policy it is managed for, out of the labels of the certificate
+func certificateToTLSPolicy(_ context.Context, obj client.Object) []reconcile.Request {
+	policyLabel := (&v1alpha1.TLSPolicy{}).DirectReferenceAnnotationName()
+	name, ok := obj.GetLabels()[policyLabel]
+	if !ok {
+		return nil
+	}
+	namespace, ok := obj.GetLabels()[fmt.Sprintf("%s-namespace", policyLabel)]
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: client.ObjectKey{Namespace: namespace, Name: name}}}
+}
@@ -4,7 +4,6 @@ package controllers
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"time"
 
@@ -22,6 +21,7 @@ import (
 	gatewayapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 
 	"github.com/kuadrant/kuadrant-operator/api/v1alpha1"
+	"github.com/kuadrant/kuadrant-operator/pkg/library/kuadrant"
 )
 
 var _ = Describe("TLSPolicy controller", Ordered, func() {
@@ -158,8 +158,7 @@ var _ = Describe("TLSPolicy controller", Ordered, func() {
 
 		It("should set gateway back reference and policy affected status", func() {
 			policyBackRefValue := testNamespace + "/" + tlsPolicy.Name
-			refs, _ := json.Marshal([]client.ObjectKey{{Name: tlsPolicy.Name, Namespace: testNamespace}})
-			policiesBackRefValue := string(refs)
+			policyKey := client.ObjectKey{Name: tlsPolicy.Name, Namespace: testNamespace}
 
 			Eventually(func(g Gomega) {
 				gw := &gatewayapiv1.Gateway{}
@@ -167,7 +166,8 @@ var _ = Describe("TLSPolicy controller", Ordered, func() {
 				//Check annotations
 				g.Expect(err).NotTo(HaveOccurred())
 				g.Expect(gw.Annotations).To(HaveKeyWithValue(v1alpha1.TLSPolicyDirectReferenceAnnotationName, policyBackRefValue))
-				g.Expect(gw.Annotations).To(HaveKeyWithValue(v1alpha1.TLSPolicyBackReferenceAnnotationName, policiesBackRefValue))
+				g.Expect(gw.Annotations).ToNot(HaveKey(v1alpha1.TLSPolicyBackReferenceAnnotationName))
+				g.Expect(kuadrant.BackReferencesFromIndex(ctx, backReferenceIndex, gw, tlsPolicy)).To(ContainElement(policyKey))
 				//Check status
 				g.Expect(gw.Status.Conditions).To(
 					ContainElement(MatchFields(IgnoreExtras, Fields{
@@ -18,20 +18,30 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"slices"
+	"sort"
+	"strings"
 
+	certmanv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	certmanmetav1 "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/kuadrant/kuadrant-operator/api/v1alpha1"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/kuadrant"
+	"github.com/kuadrant/kuadrant-operator/pkg/library/reconcilers"
 )
 
-func (r *TLSPolicyReconciler) reconcileStatus(ctx context.Context, tlsPolicy *v1alpha1.TLSPolicy, specErr error) (ctrl.Result, error) {
-	newStatus := r.calculateStatus(tlsPolicy, specErr)
+func (r *TLSPolicyReconciler) reconcileStatus(ctx context.Context, tlsPolicy *v1alpha1.TLSPolicy, targetNetworkObject client.Object, specErr error) (ctrl.Result, error) {
+	newStatus := r.calculateStatus(ctx, tlsPolicy, targetNetworkObject, specErr)
 
 	equalStatus := equality.Semantic.DeepEqual(newStatus, tlsPolicy.Status)
 	if equalStatus && tlsPolicy.Generation == tlsPolicy.Status.ObservedGeneration {
@@ -57,7 +67,7 @@ func (r *TLSPolicyReconciler) reconcileStatus(ctx context.Context, tlsPolicy *v1
 	return ctrl.Result{}, nil
 }
 
-func (r *TLSPolicyReconciler) calculateStatus(tlsPolicy *v1alpha1.TLSPolicy, specErr error) *v1alpha1.TLSPolicyStatus {
+func (r *TLSPolicyReconciler) calculateStatus(ctx context.Context, tlsPolicy *v1alpha1.TLSPolicy, targetNetworkObject client.Object, specErr error) *v1alpha1.TLSPolicyStatus {
 	newStatus := &v1alpha1.TLSPolicyStatus{
 		// Copy initial conditions. Otherwise, status will always be updated
 		Conditions:         slices.Clone(tlsPolicy.Status.Conditions),
@@ -67,5 +77,35 @@ func (r *TLSPolicyReconciler) calculateStatus(tlsPolicy *v1alpha1.TLSPolicy, spe
 	acceptedCond := kuadrant.AcceptedCondition(tlsPolicy, specErr)
 	meta.SetStatusCondition(&newStatus.Conditions, *acceptedCond)
 
+	newStatus.Ancestors = reconcilers.PolicyAncestorStatuses(ctx, r.Client(), r.TargetRefReconciler.BackReferenceIndex, tlsPolicy, targetNetworkObject, specErr, r.enforcedCondition(ctx, tlsPolicy), tlsPolicy.Status.Ancestors)
+
 	return newStatus
 }
+
+// enforcedCondition returns the enforced condition of the policy out of the readiness of the certificates managed for
+// the policy, or nil if it cannot be determined
+func (r *TLSPolicyReconciler) enforcedCondition(ctx context.Context, tlsPolicy *v1alpha1.TLSPolicy) *metav1.Condition {
+	logger, _ := logr.FromContext(ctx)
+
+	certList := &certmanv1.CertificateList{}
+	listOptions := &client.ListOptions{LabelSelector: labels.SelectorFromSet(policyTLSCertificateLabels(tlsPolicy)), Namespace: tlsPolicy.Namespace}
+	if err := r.Client().List(ctx, certList, listOptions); err != nil {
+		logger.V(1).Info("failed to list certificates", "error", err.Error())
+		return nil
+	}
+
+	var notReady []string
+	for _, cert := range certList.Items {
+		if !slices.ContainsFunc(cert.Status.Conditions, func(c certmanv1.CertificateCondition) bool {
+			return c.Type == certmanv1.CertificateConditionReady && c.Status == certmanmetav1.ConditionTrue
+		}) {
+			notReady = append(notReady, cert.Name)
+		}
+	}
+	if len(notReady) > 0 {
+		sort.Strings(notReady)
+		return kuadrant.EnforcedCondition(tlsPolicy, kuadrant.NewErrUnknown(tlsPolicy.Kind(), fmt.Errorf("certificates not ready: %s", strings.Join(notReady, ", "))), false)
+	}
+
+	return kuadrant.EnforcedCondition(tlsPolicy, nil, true)
+}
@@ -19,8 +19,10 @@ package main
 import (
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"runtime"
+	"time"
 
 	certmanv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	egv1alpha1 "github.com/envoyproxy/gateway/api/v1alpha1"
@@ -35,12 +37,16 @@ import (
 	istioapis "istio.io/istio/operator/pkg/apis"
 	corev1 "k8s.io/api/core/v1"
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
 	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"k8s.io/utils/env"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -52,10 +58,13 @@ import (
 	kuadrantv1beta1 "github.com/kuadrant/kuadrant-operator/api/v1beta1"
 	kuadrantv1beta2 "github.com/kuadrant/kuadrant-operator/api/v1beta2"
 	"github.com/kuadrant/kuadrant-operator/controllers"
+	"github.com/kuadrant/kuadrant-operator/pkg/debug"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/fieldindexers"
-	"github.com/kuadrant/kuadrant-operator/pkg/library/kuadrant"
+	kuadrantgatewayapi "github.com/kuadrant/kuadrant-operator/pkg/library/gatewayapi"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/reconcilers"
 	"github.com/kuadrant/kuadrant-operator/pkg/log"
+	"github.com/kuadrant/kuadrant-operator/pkg/rlptools"
+	"github.com/kuadrant/kuadrant-operator/pkg/webhooks"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -113,6 +122,10 @@ func main() {
 		metricsAddr          string
 		enableLeaderElection bool
 		probeAddr            string
+		enableDebugServer    bool
+		debugAddr            string
+		usagePollInterval    time.Duration
+		enableWebhooks       bool
 		err                  error
 	)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
@@ -120,8 +133,25 @@ func main() {
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.BoolVar(&enableDebugServer, "enable-debug-server", false,
+		"Enable the debug endpoint serving the topology, the effective policies, "+
+			"and the wasm config and authorino hosts computed per gateway.")
+	flag.StringVar(&debugAddr, "debug-bind-address", ":8082", "The address the debug endpoint binds to.")
+	flag.DurationVar(&usagePollInterval, "rate-limit-usage-poll-interval", 0,
+		"Interval to poll Limitador for the usage of the rate limits reported in the status of the RateLimitPolicies. "+
+			"Zero disables the poller.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"Enable the validating admission webhooks of the RateLimitPolicies and AuthPolicies. "+
+			"The serving certificates are expected in the default directory of the webhook server.")
 	flag.Parse()
 
+	// Only the Secrets of the API keys are read by the operator, so the rest of the Secrets of the cluster are not cached
+	apiKeySecretRequirement, err := k8slabels.NewRequirement(kuadrantv1alpha1.APIKeySecretLabel, selection.Exists, nil)
+	if err != nil {
+		setupLog.Error(err, "unable to build the label selector of the api key secrets")
+		os.Exit(1)
+	}
+
 	options := ctrl.Options{
 		Scheme:                 scheme,
 		Metrics:                metricsserver.Options{BindAddress: metricsAddr},
@@ -129,6 +159,11 @@ func main() {
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "f139389e.kuadrant.io",
+		Cache: cache.Options{
+			ByObject: map[client.Object]cache.ByObject{
+				&corev1.Secret{}: {Label: k8slabels.NewSelector().Add(*apiKeySecretRequirement)},
+			},
+		},
 	}
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), options)
@@ -145,6 +180,53 @@ func main() {
 		os.Exit(1)
 	}
 
+	for _, policyType := range []kuadrantgatewayapi.Policy{
+		&kuadrantv1beta2.RateLimitPolicy{},
+		&kuadrantv1beta2.AuthPolicy{},
+		&kuadrantv1alpha1.DNSPolicy{},
+		&kuadrantv1alpha1.TLSPolicy{},
+	} {
+		if err := fieldindexers.PolicyIndexByTargetRef(
+			mgr,
+			policyType,
+			log.Log.WithName("kuadrant").WithName("indexer").WithName("policyIndexByTargetRef"),
+		); err != nil {
+			setupLog.Error(err, "unable to add indexer")
+			os.Exit(1)
+		}
+	}
+
+	backReferenceIndex := fieldindexers.NewBackReferenceIndex(mgr.GetClient(), log.Log.WithName("kuadrant").WithName("backreferences")).
+		WithPolicyList(&kuadrantv1beta2.RateLimitPolicy{}, &kuadrantv1beta2.RateLimitPolicyList{}).
+		WithPolicyList(&kuadrantv1beta2.AuthPolicy{}, &kuadrantv1beta2.AuthPolicyList{}).
+		WithPolicyList(&kuadrantv1alpha1.DNSPolicy{}, &kuadrantv1alpha1.DNSPolicyList{}).
+		WithPolicyList(&kuadrantv1alpha1.TLSPolicy{}, &kuadrantv1alpha1.TLSPolicyList{})
+
+	// strip the legacy back reference annotations from the gateways
+	if err := mgr.Add(reconcilers.NewBackReferenceAnnotationsMigration(
+		mgr.GetClient(),
+		log.Log.WithName("kuadrant").WithName("migration"),
+		&kuadrantv1beta2.RateLimitPolicy{},
+		&kuadrantv1beta2.AuthPolicy{},
+		&kuadrantv1alpha1.DNSPolicy{},
+		&kuadrantv1alpha1.TLSPolicy{},
+	)); err != nil {
+		setupLog.Error(err, "unable to add back reference annotations migration")
+		os.Exit(1)
+	}
+
+	topologyCache := kuadrantgatewayapi.NewTopologyCache(
+		log.Log.WithName("kuadrant").WithName("topology"),
+		&kuadrantv1beta2.RateLimitPolicy{},
+		&kuadrantv1beta2.AuthPolicy{},
+		&kuadrantv1alpha1.DNSPolicy{},
+		&kuadrantv1alpha1.TLSPolicy{},
+	)
+	if err := topologyCache.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to set up topology cache")
+		os.Exit(1)
+	}
+
 	kuadrantBaseReconciler := reconcilers.NewBaseReconciler(
 		mgr.GetClient(), mgr.GetScheme(), mgr.GetAPIReader(),
 		log.Log.WithName("kuadrant"),
@@ -165,13 +247,28 @@ func main() {
 	)
 
 	if err = (&controllers.RateLimitPolicyReconciler{
-		TargetRefReconciler: reconcilers.TargetRefReconciler{Client: mgr.GetClient()},
+		TargetRefReconciler: reconcilers.TargetRefReconciler{Client: mgr.GetClient(), BackReferenceIndex: backReferenceIndex},
 		BaseReconciler:      rateLimitPolicyBaseReconciler,
+		TopologyCache:       topologyCache,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "RateLimitPolicy")
 		os.Exit(1)
 	}
 
+	limitadorLimitsBaseReconciler := reconcilers.NewBaseReconciler(
+		mgr.GetClient(), mgr.GetScheme(), mgr.GetAPIReader(),
+		log.Log.WithName("ratelimitpolicy").WithName("limitador"),
+		mgr.GetEventRecorderFor("LimitadorLimits"),
+	)
+
+	if err = (&controllers.LimitadorLimitsReconciler{
+		BaseReconciler: limitadorLimitsBaseReconciler,
+		TopologyCache:  topologyCache,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "LimitadorLimits")
+		os.Exit(1)
+	}
+
 	authPolicyBaseReconciler := reconcilers.NewBaseReconciler(
 		mgr.GetClient(), mgr.GetScheme(), mgr.GetAPIReader(),
 		log.Log.WithName("authpolicy"),
@@ -179,9 +276,10 @@ func main() {
 	)
 
 	if err = (&controllers.AuthPolicyReconciler{
-		TargetRefReconciler: reconcilers.TargetRefReconciler{Client: mgr.GetClient()},
+		TargetRefReconciler: reconcilers.TargetRefReconciler{Client: mgr.GetClient(), BackReferenceIndex: backReferenceIndex},
 		BaseReconciler:      authPolicyBaseReconciler,
-		OverriddenPolicyMap: kuadrant.NewOverriddenPolicyMap(),
+		TopologyCache:       topologyCache,
+		HTTPClient:          &http.Client{Timeout: 5 * time.Second},
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "AuthPolicy")
 		os.Exit(1)
@@ -195,12 +293,25 @@ func main() {
 
 	if err = (&controllers.DNSPolicyReconciler{
 		BaseReconciler:      dnsPolicyBaseReconciler,
-		TargetRefReconciler: reconcilers.TargetRefReconciler{Client: mgr.GetClient()},
+		TargetRefReconciler: reconcilers.TargetRefReconciler{Client: mgr.GetClient(), BackReferenceIndex: backReferenceIndex},
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "DNSPolicy")
 		os.Exit(1)
 	}
 
+	apiKeyBaseReconciler := reconcilers.NewBaseReconciler(
+		mgr.GetClient(), mgr.GetScheme(), mgr.GetAPIReader(),
+		log.Log.WithName("apikey"),
+		mgr.GetEventRecorderFor("APIKey"),
+	)
+
+	if err = (&controllers.APIKeyReconciler{
+		BaseReconciler: apiKeyBaseReconciler,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "APIKey")
+		os.Exit(1)
+	}
+
 	tlsPolicyBaseReconciler := reconcilers.NewBaseReconciler(
 		mgr.GetClient(), mgr.GetScheme(), mgr.GetAPIReader(),
 		log.Log.WithName("tlspolicy"),
@@ -209,7 +320,7 @@ func main() {
 
 	if err = (&controllers.TLSPolicyReconciler{
 		BaseReconciler:      tlsPolicyBaseReconciler,
-		TargetRefReconciler: reconcilers.TargetRefReconciler{Client: mgr.GetClient()},
+		TargetRefReconciler: reconcilers.TargetRefReconciler{Client: mgr.GetClient(), BackReferenceIndex: backReferenceIndex},
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "TLSPolicy")
 		os.Exit(1)
@@ -222,7 +333,9 @@ func main() {
 	)
 
 	if err = (&controllers.LimitadorClusterEnvoyFilterReconciler{
-		BaseReconciler: limitadorClusterEnvoyFilterBaseReconciler,
+		BaseReconciler:     limitadorClusterEnvoyFilterBaseReconciler,
+		TopologyCache:      topologyCache,
+		BackReferenceIndex: backReferenceIndex,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "EnvoyFilter")
 		os.Exit(1)
@@ -249,6 +362,7 @@ func main() {
 
 	if err = (&controllers.RateLimitingWASMPluginReconciler{
 		BaseReconciler: rateLimitingWASMPluginBaseReconciler,
+		TopologyCache:  topologyCache,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "RateLimitingWASMPlugin")
 		os.Exit(1)
@@ -262,13 +376,48 @@ func main() {
 
 	if err = (&controllers.RateLimitingEnvoyPatchPolicyReconciler{
 		BaseReconciler: rateLimitingEnvoyPatchPolicyBaseReconciler,
+		TopologyCache:  topologyCache,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "RateLimitingEnvoyPatchPolicy")
 		os.Exit(1)
 	}
 
+	if enableWebhooks {
+		if err = webhooks.NewRateLimitPolicyValidator(mgr.GetAPIReader()).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "RateLimitPolicy")
+			os.Exit(1)
+		}
+		if err = webhooks.NewAuthPolicyValidator(mgr.GetAPIReader()).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "AuthPolicy")
+			os.Exit(1)
+		}
+	}
+
 	//+kubebuilder:scaffold:builder
 
+	if enableDebugServer {
+		if err := mgr.Add(debug.NewServer(
+			debugAddr, mgr.GetClient(), topologyCache,
+			log.Log.WithName("kuadrant").WithName("debug"),
+		)); err != nil {
+			setupLog.Error(err, "unable to set up debug server")
+			os.Exit(1)
+		}
+	}
+
+	if usagePollInterval > 0 {
+		if err := mgr.Add(rlptools.NewUsagePoller(
+			mgr.GetClient(),
+			topologyCache,
+			rlptools.NewLimitadorCountersClient(&http.Client{Timeout: 5 * time.Second}),
+			usagePollInterval,
+			log.Log.WithName("ratelimitpolicy").WithName("usage"),
+		)); err != nil {
+			setupLog.Error(err, "unable to set up rate limit usage poller")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
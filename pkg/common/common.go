@@ -30,6 +30,9 @@ const (
 	AuthPolicyBackRefAnnotation    = "kuadrant.io/authpolicy"
 	NamespaceSeparator             = '/'
 	LimitadorName                  = "limitador"
+	AuthorinoName                  = "authorino"
+	DedicatedAuthorinoAnnotation   = "kuadrant.io/dedicated-authorino"
+	AuthorinoInstanceLabel         = "kuadrant.io/authorino"
 )
 
 // MergeMapStringString Merge desired into existing.
@@ -0,0 +1,351 @@
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	authorinoapi "github.com/kuadrant/authorino/api/v1beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	kuadrantv1beta2 "github.com/kuadrant/kuadrant-operator/api/v1beta2"
+	kuadrantgatewayapi "github.com/kuadrant/kuadrant-operator/pkg/library/gatewayapi"
+	"github.com/kuadrant/kuadrant-operator/pkg/library/utils"
+	"github.com/kuadrant/kuadrant-operator/pkg/rlptools/wasm"
+)
+
+const (
+	TopologyPath          = "/debug/topology"
+	EffectivePoliciesPath = "/debug/effective-policies"
+	WasmConfigPath        = "/debug/wasm-config"
+	AuthorinoHostsPath    = "/debug/authorino-hosts"
+
+	shutdownTimeout = 5 * time.Second
+)
+
+// Server serves the internal state of the operator for troubleshooting purposes:
+// the topology of gateways, routes and policies, the effective policies per route rule,
+// and the wasm config and authorino hosts computed for a gateway.
+// All the responses are read-only views of the state.
+type Server struct {
+	addr          string
+	client        client.Client
+	topologyCache *kuadrantgatewayapi.TopologyCache
+	logger        logr.Logger
+}
+
+var _ manager.LeaderElectionRunnable = &Server{}
+
+func NewServer(addr string, cl client.Client, topologyCache *kuadrantgatewayapi.TopologyCache, logger logr.Logger) *Server {
+	return &Server{
+		addr:          addr,
+		client:        cl,
+		topologyCache: topologyCache,
+		logger:        logger,
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, so every replica serves its own state
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements manager.Runnable
+func (s *Server) Start(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:              s.addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		s.logger.Info("starting debug server", "address", s.addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+		close(errCh)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		s.logger.Info("shutting down debug server")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(TopologyPath, s.topology)
+	mux.HandleFunc(EffectivePoliciesPath, s.effectivePolicies)
+	mux.HandleFunc(WasmConfigPath, s.wasmConfig)
+	mux.HandleFunc(AuthorinoHostsPath, s.authorinoHosts)
+	return mux
+}
+
+// topology serves the topology as JSON (default) or in the Graphviz DOT language (?format=dot)
+func (s *Server) topology(w http.ResponseWriter, r *http.Request) {
+	topology, ok := s.snapshot(w)
+	if !ok {
+		return
+	}
+
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+		s.writeJSON(w, topology)
+	case "dot":
+		w.Header().Set("Content-Type", "text/vnd.graphviz")
+		_, _ = w.Write([]byte(topology.ToDOT()))
+	default:
+		http.Error(w, "unsupported format "+format+", use one of: json, dot", http.StatusBadRequest)
+	}
+}
+
+type policyRef struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Limits lists the names of the limits of a RateLimitPolicy that select the route rule
+	Limits []string `json:"limits,omitempty"`
+}
+
+type ruleEffectivePolicies struct {
+	Index    int                           `json:"index"`
+	Matches  []gatewayapiv1.HTTPRouteMatch `json:"matches,omitempty"`
+	Policies []policyRef                   `json:"policies"`
+}
+
+type routeEffectivePolicies struct {
+	Namespace string                  `json:"namespace"`
+	Name      string                  `json:"name"`
+	Rules     []ruleEffectivePolicies `json:"rules"`
+}
+
+// effectivePolicies serves the policies that apply to each rule of the routes.
+// Use the namespace and name query params to narrow it down to a single route.
+func (s *Server) effectivePolicies(w http.ResponseWriter, r *http.Request) {
+	topology, ok := s.snapshot(w)
+	if !ok {
+		return
+	}
+
+	routes := topology.Routes()
+	if key, filtered := objectKeyFromRequest(r); filtered {
+		routes = utils.Filter(routes, func(route kuadrantgatewayapi.RouteNode) bool {
+			return client.ObjectKeyFromObject(route.HTTPRoute) == key
+		})
+		if len(routes) == 0 {
+			http.Error(w, "route "+key.String()+" not found in the topology", http.StatusNotFound)
+			return
+		}
+	}
+
+	result := utils.Map(routes, func(route kuadrantgatewayapi.RouteNode) routeEffectivePolicies {
+		return effectivePoliciesFromRoute(route.HTTPRoute, topology.EffectivePolicies(client.ObjectKeyFromObject(route.HTTPRoute)))
+	})
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Namespace+"/"+result[i].Name < result[j].Namespace+"/"+result[j].Name
+	})
+
+	s.writeJSON(w, result)
+}
+
+func effectivePoliciesFromRoute(route *gatewayapiv1.HTTPRoute, policies []kuadrantgatewayapi.Policy) routeEffectivePolicies {
+	result := routeEffectivePolicies{
+		Namespace: route.Namespace,
+		Name:      route.Name,
+		Rules:     make([]ruleEffectivePolicies, 0, len(route.Spec.Rules)),
+	}
+
+	for idx, rule := range route.Spec.Rules {
+		ruleResult := ruleEffectivePolicies{Index: idx, Matches: rule.Matches, Policies: make([]policyRef, 0)}
+
+		for _, policy := range policies {
+			ref := policyRef{
+				Kind:      policy.GetObjectKind().GroupVersionKind().Kind,
+				Namespace: policy.GetNamespace(),
+				Name:      policy.GetName(),
+			}
+
+			switch p := policy.(type) {
+			case *kuadrantv1beta2.RateLimitPolicy:
+				limits := p.Spec.CommonSpec().LimitsWithPlans()
+				for name, limit := range limits {
+					if selectsRule(limit.RouteSelectors, route, rule) {
+						ref.Limits = append(ref.Limits, name)
+					}
+				}
+				if len(limits) > 0 && len(ref.Limits) == 0 {
+					continue
+				}
+				sort.Strings(ref.Limits)
+			case *kuadrantv1beta2.AuthPolicy:
+				if !selectsRule(p.Spec.CommonSpec().GetRouteSelectors(), route, rule) {
+					continue
+				}
+			}
+
+			ruleResult.Policies = append(ruleResult.Policies, ref)
+		}
+
+		result.Rules = append(result.Rules, ruleResult)
+	}
+
+	return result
+}
+
+// selectsRule returns true if the route rule is selected by any of the route selectors, or if there are no selectors
+func selectsRule(selectors []kuadrantv1beta2.RouteSelector, route *gatewayapiv1.HTTPRoute, rule gatewayapiv1.HTTPRouteRule) bool {
+	if len(selectors) == 0 {
+		return true
+	}
+	for idx := range selectors {
+		if slices.ContainsFunc(selectors[idx].SelectRules(route), func(selected gatewayapiv1.HTTPRouteRule) bool {
+			return reflect.DeepEqual(selected, rule)
+		}) {
+			return true
+		}
+	}
+	return false
+}
+
+// wasmConfig serves the rate limiting wasm config computed for the gateway given by the namespace and name query params
+func (s *Server) wasmConfig(w http.ResponseWriter, r *http.Request) {
+	topology, ok := s.snapshot(w)
+	if !ok {
+		return
+	}
+
+	gw, ok := gatewayFromRequest(w, r, topology)
+	if !ok {
+		return
+	}
+
+	config, err := wasm.ConfigFromGateway(logr.NewContext(r.Context(), s.logger), topology, gw)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeJSON(w, config)
+}
+
+type authConfigHosts struct {
+	Namespace  string   `json:"namespace"`
+	Name       string   `json:"name"`
+	Policy     string   `json:"policy"`
+	Hosts      []string `json:"hosts"`
+	HostsReady []string `json:"hostsReady"`
+}
+
+// authorinoHosts serves the hosts of the authorino authconfigs of the auth policies affecting the gateway
+// given by the namespace and name query params
+func (s *Server) authorinoHosts(w http.ResponseWriter, r *http.Request) {
+	topology, ok := s.snapshot(w)
+	if !ok {
+		return
+	}
+	topology = topology.WithPolicyFilter(func(p kuadrantgatewayapi.Policy) bool {
+		_, isAuthPolicy := p.(*kuadrantv1beta2.AuthPolicy)
+		return isAuthPolicy
+	})
+
+	gw, ok := gatewayFromRequest(w, r, topology)
+	if !ok {
+		return
+	}
+
+	authPolicies := kuadrantgatewayapi.NewTopologyIndexes(topology).PoliciesFromGateway(gw)
+	sort.Sort(kuadrantgatewayapi.PolicyByCreationTimestamp(authPolicies))
+
+	hosts := make([]string, 0)
+	authConfigs := make([]authConfigHosts, 0)
+	for _, policy := range authPolicies {
+		authConfigList := &authorinoapi.AuthConfigList{}
+		if err := s.client.List(r.Context(), authConfigList, client.InNamespace(policy.GetNamespace())); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, authConfig := range authConfigList.Items {
+			if !slices.ContainsFunc(authConfig.GetOwnerReferences(), func(ref metav1.OwnerReference) bool { return ref.UID == policy.GetUID() }) {
+				continue
+			}
+			authConfigs = append(authConfigs, authConfigHosts{
+				Namespace:  authConfig.Namespace,
+				Name:       authConfig.Name,
+				Policy:     client.ObjectKeyFromObject(policy).String(),
+				Hosts:      authConfig.Spec.Hosts,
+				HostsReady: authConfig.Status.Summary.HostsReady,
+			})
+			for _, host := range authConfig.Spec.Hosts {
+				if !slices.Contains(hosts, host) {
+					hosts = append(hosts, host)
+				}
+			}
+		}
+	}
+	sort.Strings(hosts)
+
+	s.writeJSON(w, struct {
+		Hosts       []string          `json:"hosts"`
+		AuthConfigs []authConfigHosts `json:"authConfigs"`
+	}{hosts, authConfigs})
+}
+
+func (s *Server) snapshot(w http.ResponseWriter) (*kuadrantgatewayapi.Topology, bool) {
+	topology, err := s.topologyCache.Snapshot()
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, kuadrantgatewayapi.ErrTopologyCacheNotSynced) {
+			status = http.StatusServiceUnavailable
+		}
+		http.Error(w, err.Error(), status)
+		return nil, false
+	}
+	return topology, true
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		s.logger.Error(err, "failed to write debug response")
+	}
+}
+
+func objectKeyFromRequest(r *http.Request) (client.ObjectKey, bool) {
+	key := client.ObjectKey{Namespace: r.URL.Query().Get("namespace"), Name: r.URL.Query().Get("name")}
+	return key, key.Name != ""
+}
+
+func gatewayFromRequest(w http.ResponseWriter, r *http.Request, topology *kuadrantgatewayapi.Topology) (*gatewayapiv1.Gateway, bool) {
+	key, ok := objectKeyFromRequest(r)
+	if !ok {
+		http.Error(w, "the gateway namespace and name query params are required", http.StatusBadRequest)
+		return nil, false
+	}
+
+	gwNode, found := utils.Find(topology.Gateways(), func(gw kuadrantgatewayapi.GatewayNode) bool {
+		return gw.ObjectKey() == key
+	})
+	if !found {
+		http.Error(w, "gateway "+key.String()+" not found in the topology", http.StatusNotFound)
+		return nil, false
+	}
+
+	return gwNode.Gateway, true
+}
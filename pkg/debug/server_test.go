@@ -0,0 +1,75 @@
+//go:build unit
+
+package debug
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	kuadrantv1beta2 "github.com/kuadrant/kuadrant-operator/api/v1beta2"
+	kuadrantgatewayapi "github.com/kuadrant/kuadrant-operator/pkg/library/gatewayapi"
+)
+
+func TestEffectivePoliciesFromRoute(t *testing.T) {
+	route := &gatewayapiv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "nsA", Name: "toystore"},
+		Spec: gatewayapiv1.HTTPRouteSpec{
+			Hostnames: []gatewayapiv1.Hostname{"*.toystore.com"},
+			Rules: []gatewayapiv1.HTTPRouteRule{
+				{Matches: []gatewayapiv1.HTTPRouteMatch{{Method: ptr.To(gatewayapiv1.HTTPMethodGet)}}},
+				{Matches: []gatewayapiv1.HTTPRouteMatch{{Method: ptr.To(gatewayapiv1.HTTPMethodPost)}}},
+			},
+		},
+	}
+
+	postSelector := []kuadrantv1beta2.RouteSelector{
+		{Matches: []gatewayapiv1.HTTPRouteMatch{{Method: ptr.To(gatewayapiv1.HTTPMethodPost)}}},
+	}
+
+	rlp := &kuadrantv1beta2.RateLimitPolicy{
+		TypeMeta:   metav1.TypeMeta{Kind: "RateLimitPolicy", APIVersion: kuadrantv1beta2.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "nsA", Name: "rlp"},
+		Spec: kuadrantv1beta2.RateLimitPolicySpec{
+			RateLimitPolicyCommonSpec: kuadrantv1beta2.RateLimitPolicyCommonSpec{
+				Limits: map[string]kuadrantv1beta2.Limit{
+					"global": {},
+					"posts":  {RouteSelectors: postSelector},
+				},
+			},
+		},
+	}
+
+	ap := &kuadrantv1beta2.AuthPolicy{
+		TypeMeta:   metav1.TypeMeta{Kind: "AuthPolicy", APIVersion: kuadrantv1beta2.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "nsA", Name: "ap"},
+	}
+	ap.Spec.AuthPolicyCommonSpec.RouteSelectors = postSelector
+
+	result := effectivePoliciesFromRoute(route, []kuadrantgatewayapi.Policy{rlp, ap})
+
+	assert.DeepEqual(t, result, routeEffectivePolicies{
+		Namespace: "nsA",
+		Name:      "toystore",
+		Rules: []ruleEffectivePolicies{
+			{
+				Index:   0,
+				Matches: route.Spec.Rules[0].Matches,
+				Policies: []policyRef{
+					{Kind: "RateLimitPolicy", Namespace: "nsA", Name: "rlp", Limits: []string{"global"}},
+				},
+			},
+			{
+				Index:   1,
+				Matches: route.Spec.Rules[1].Matches,
+				Policies: []policyRef{
+					{Kind: "RateLimitPolicy", Namespace: "nsA", Name: "rlp", Limits: []string{"global", "posts"}},
+					{Kind: "AuthPolicy", Namespace: "nsA", Name: "ap"},
+				},
+			},
+		},
+	})
+}
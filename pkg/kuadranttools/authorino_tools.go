@@ -0,0 +1,129 @@
+package kuadranttools
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+
+	authorinov1beta1 "github.com/kuadrant/authorino-operator/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	kuadrantv1beta1 "github.com/kuadrant/kuadrant-operator/api/v1beta1"
+	"github.com/kuadrant/kuadrant-operator/pkg/common"
+)
+
+// maxAuthorinoNameLength keeps the name of the authorization service created by the authorino operator
+// for the instance (<name>-authorino-authorization) within the length of a DNS label
+const maxAuthorinoNameLength = 39
+
+// DedicatedAuthorinoName returns the name of the Authorino instance dedicated to a gateway
+func DedicatedAuthorinoName(gwKey client.ObjectKey) string {
+	name := fmt.Sprintf("%s-%s-%s", common.AuthorinoName, gwKey.Namespace, gwKey.Name)
+	if len(name) <= maxAuthorinoNameLength {
+		return name
+	}
+	hash := sha256.Sum256([]byte(gwKey.String()))
+	return fmt.Sprintf("%s-%s", name[:maxAuthorinoNameLength-9], hex.EncodeToString(hash[:])[:8])
+}
+
+// IsDedicatedAuthorinoGateway returns true if the gateway gets an Authorino instance of its own,
+// either because it is annotated or because the kuadrant instance enables it for every gateway
+func IsDedicatedAuthorinoGateway(gw *gatewayapiv1.Gateway, kObj *kuadrantv1beta1.Kuadrant) bool {
+	if gw.GetAnnotations()[common.DedicatedAuthorinoAnnotation] == "true" {
+		return true
+	}
+	return kObj != nil && kObj.DedicatedAuthorinoPerGateway()
+}
+
+// AuthorinoNameForGateway returns the name of the Authorino instance serving the AuthConfigs of the gateway
+func AuthorinoNameForGateway(gw *gatewayapiv1.Gateway, kObj *kuadrantv1beta1.Kuadrant) string {
+	if IsDedicatedAuthorinoGateway(gw, kObj) {
+		return DedicatedAuthorinoName(client.ObjectKeyFromObject(gw))
+	}
+	return common.AuthorinoName
+}
+
+// AuthorinoAuthorizationServiceName returns the name of the authorization service of an Authorino instance
+func AuthorinoAuthorizationServiceName(authorinoName string) string {
+	return fmt.Sprintf("%s-authorino-authorization", authorinoName)
+}
+
+// AuthorinoAuthConfigLabelSelectors returns the selector of the AuthConfigs watched by an Authorino instance.
+// Dedicated instances only watch the AuthConfigs labeled for them, whereas the shared one watches the rest.
+func AuthorinoAuthConfigLabelSelectors(authorinoName string) string {
+	if authorinoName == common.AuthorinoName {
+		return fmt.Sprintf("!%s", common.AuthorinoInstanceLabel)
+	}
+	return fmt.Sprintf("%s=%s", common.AuthorinoInstanceLabel, authorinoName)
+}
+
+// AuthorinoAuthConfigLabels returns the labels that make an AuthConfig be served by an Authorino instance
+func AuthorinoAuthConfigLabels(authorinoName string) map[string]string {
+	if authorinoName == common.AuthorinoName {
+		return nil
+	}
+	return map[string]string{common.AuthorinoInstanceLabel: authorinoName}
+}
+
+// Authorino returns the desired Authorino instance of the given name in the kuadrant namespace
+func Authorino(kObj *kuadrantv1beta1.Kuadrant, authorinoName string) *authorinov1beta1.Authorino {
+	tmpFalse := false
+	authorino := &authorinov1beta1.Authorino{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Authorino",
+			APIVersion: "operator.authorino.kuadrant.io/v1beta1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      authorinoName,
+			Namespace: kObj.Namespace,
+		},
+		Spec: authorinov1beta1.AuthorinoSpec{
+			ClusterWide:              true,
+			SupersedingHostSubsets:   true,
+			AuthConfigLabelSelectors: AuthorinoAuthConfigLabelSelectors(authorinoName),
+			Listener: authorinov1beta1.Listener{
+				Tls: authorinov1beta1.Tls{
+					Enabled: &tmpFalse,
+				},
+			},
+			OIDCServer: authorinov1beta1.OIDCServer{
+				Tls: authorinov1beta1.Tls{
+					Enabled: &tmpFalse,
+				},
+			},
+		},
+	}
+	if authorinoName != common.AuthorinoName {
+		authorino.Labels = map[string]string{common.AuthorinoInstanceLabel: authorinoName}
+	}
+	return authorino
+}
+
+// AuthorinoMutator only reconciles the AuthConfigs the Authorino instance watches, leaving the rest of the spec
+// up to the users
+func AuthorinoMutator(existingObj, desiredObj client.Object) (bool, error) {
+	update := false
+	existing, ok := existingObj.(*authorinov1beta1.Authorino)
+	if !ok {
+		return false, fmt.Errorf("existingObj %T is not a *authorinov1beta1.Authorino", existingObj)
+	}
+	desired, ok := desiredObj.(*authorinov1beta1.Authorino)
+	if !ok {
+		return false, fmt.Errorf("desiredObj %T is not a *authorinov1beta1.Authorino", desiredObj)
+	}
+
+	if !reflect.DeepEqual(existing.OwnerReferences, desired.OwnerReferences) {
+		update = true
+		existing.OwnerReferences = desired.OwnerReferences
+	}
+
+	if existing.Spec.AuthConfigLabelSelectors != desired.Spec.AuthConfigLabelSelectors {
+		update = true
+		existing.Spec.AuthConfigLabelSelectors = desired.Spec.AuthConfigLabelSelectors
+	}
+
+	return update, nil
+}
@@ -0,0 +1,94 @@
+//go:build unit
+
+package kuadranttools
+
+import (
+	"strings"
+	"testing"
+
+	authorinov1beta1 "github.com/kuadrant/authorino-operator/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/kuadrant/kuadrant-operator/api/v1beta1"
+	"github.com/kuadrant/kuadrant-operator/pkg/common"
+)
+
+func TestDedicatedAuthorinoName(t *testing.T) {
+	t.Run("short name", func(subT *testing.T) {
+		if got := DedicatedAuthorinoName(client.ObjectKey{Namespace: "gw-ns", Name: "gw"}); got != "authorino-gw-ns-gw" {
+			subT.Errorf("unexpected name %s", got)
+		}
+	})
+
+	t.Run("long name", func(subT *testing.T) {
+		key := client.ObjectKey{Namespace: "a-very-long-gateway-namespace", Name: "a-very-long-gateway-name"}
+		got := DedicatedAuthorinoName(key)
+		if len(got) != maxAuthorinoNameLength || !strings.HasPrefix(got, "authorino-a-very-long") {
+			subT.Errorf("unexpected name %s", got)
+		}
+		other := DedicatedAuthorinoName(client.ObjectKey{Namespace: key.Namespace, Name: key.Name + "-2"})
+		if got == other {
+			subT.Errorf("expected different names for different gateways, got %s", got)
+		}
+	})
+}
+
+func TestAuthorinoNameForGateway(t *testing.T) {
+	gw := func(annotations map[string]string) *gatewayapiv1.Gateway {
+		return &gatewayapiv1.Gateway{ObjectMeta: metav1.ObjectMeta{Name: "gw", Namespace: "gw-ns", Annotations: annotations}}
+	}
+	dedicated := &v1beta1.Kuadrant{Spec: v1beta1.KuadrantSpec{Authorino: &v1beta1.AuthorinoSpec{DedicatedPerGateway: true}}}
+
+	tests := []struct {
+		name string
+		gw   *gatewayapiv1.Gateway
+		kObj *v1beta1.Kuadrant
+		want string
+	}{
+		{name: "shared", gw: gw(nil), kObj: &v1beta1.Kuadrant{}, want: common.AuthorinoName},
+		{name: "no kuadrant", gw: gw(nil), want: common.AuthorinoName},
+		{name: "annotated gateway", gw: gw(map[string]string{common.DedicatedAuthorinoAnnotation: "true"}), want: "authorino-gw-ns-gw"},
+		{name: "dedicated per gateway", gw: gw(nil), kObj: dedicated, want: "authorino-gw-ns-gw"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			if got := AuthorinoNameForGateway(tt.gw, tt.kObj); got != tt.want {
+				subT.Errorf("AuthorinoNameForGateway() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthorino(t *testing.T) {
+	kObj := &v1beta1.Kuadrant{ObjectMeta: metav1.ObjectMeta{Name: "kuadrant", Namespace: "kuadrant-system"}}
+
+	shared := Authorino(kObj, common.AuthorinoName)
+	if shared.Spec.AuthConfigLabelSelectors != "!kuadrant.io/authorino" || shared.Labels != nil {
+		t.Errorf("unexpected shared authorino: %v", shared)
+	}
+
+	dedicated := Authorino(kObj, "authorino-gw-ns-gw")
+	if dedicated.Spec.AuthConfigLabelSelectors != "kuadrant.io/authorino=authorino-gw-ns-gw" || dedicated.Labels[common.AuthorinoInstanceLabel] != "authorino-gw-ns-gw" {
+		t.Errorf("unexpected dedicated authorino: %v", dedicated)
+	}
+}
+
+func TestAuthorinoMutator(t *testing.T) {
+	existing := &authorinov1beta1.Authorino{Spec: authorinov1beta1.AuthorinoSpec{Replicas: new(int32)}}
+	desired := Authorino(&v1beta1.Kuadrant{}, "authorino-gw-ns-gw")
+
+	update, err := AuthorinoMutator(existing, desired)
+	if err != nil || !update {
+		t.Fatalf("expected update, got %v, %v", update, err)
+	}
+	if existing.Spec.AuthConfigLabelSelectors != desired.Spec.AuthConfigLabelSelectors || existing.Spec.Replicas == nil {
+		t.Errorf("unexpected mutated authorino: %v", existing.Spec)
+	}
+
+	update, err = AuthorinoMutator(existing, desired)
+	if err != nil || update {
+		t.Errorf("expected no update, got %v, %v", update, err)
+	}
+}
@@ -0,0 +1,228 @@
+package dag
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kuadrant/kuadrant-operator/pkg/library/utils"
+)
+
+// NodePredicate is used to filter the nodes returned when traversing the DAG
+type NodePredicate func(Node) bool
+
+type Edge struct {
+	Parent NodeID `json:"parent"`
+	Child  NodeID `json:"child"`
+}
+
+// CycleError is returned by the algorithms that require the graph to be acyclic.
+// Path holds the node IDs of the cycle found, starting and ending in the same node.
+type CycleError struct {
+	Path []NodeID
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("cycle found: %s", strings.Join(e.Path, " -> "))
+}
+
+func IsCycle(err error) bool {
+	var cycleErr *CycleError
+	return errors.As(err, &cycleErr)
+}
+
+// Nodes returns all the nodes of the DAG sorted by ID
+func (d *DAG) Nodes() []Node {
+	return utils.Map(d.sortedInternalNodes(), func(n *internalNode) Node { return n.node })
+}
+
+// Edges returns all the edges of the DAG sorted by parent and child IDs
+func (d *DAG) Edges() []Edge {
+	edges := make([]Edge, 0)
+	for _, n := range d.sortedInternalNodes() {
+		for _, childID := range sortedIDs(n.children) {
+			edges = append(edges, Edge{Parent: n.id, Child: childID})
+		}
+	}
+	return edges
+}
+
+// Roots returns the nodes without parents sorted by ID
+func (d *DAG) Roots() []Node {
+	roots := utils.Filter(d.sortedInternalNodes(), func(n *internalNode) bool { return len(n.parents) == 0 })
+	return utils.Map(roots, func(n *internalNode) Node { return n.node })
+}
+
+// Leaves returns the nodes without children sorted by ID
+func (d *DAG) Leaves() []Node {
+	leaves := utils.Filter(d.sortedInternalNodes(), func(n *internalNode) bool { return len(n.children) == 0 })
+	return utils.Map(leaves, func(n *internalNode) Node { return n.node })
+}
+
+// Ancestors returns all the nodes from which the node is reachable, excluding the node itself.
+// Nodes are sorted by distance to the node, and then by ID.
+// Only the nodes that match all the predicates are returned, but the traversal goes through
+// the filtered-out nodes as well.
+func (d *DAG) Ancestors(n NodeID, predicates ...NodePredicate) []Node {
+	return d.traverse(n, func(i *internalNode) map[NodeID]*internalNode { return i.parents }, predicates...)
+}
+
+// Descendants returns all the nodes reachable from the node, excluding the node itself.
+// Nodes are sorted by distance to the node, and then by ID.
+// Only the nodes that match all the predicates are returned, but the traversal goes through
+// the filtered-out nodes as well.
+func (d *DAG) Descendants(n NodeID, predicates ...NodePredicate) []Node {
+	return d.traverse(n, func(i *internalNode) map[NodeID]*internalNode { return i.children }, predicates...)
+}
+
+// traverse walks the graph breadth-first from the node following the given direction
+func (d *DAG) traverse(n NodeID, next func(*internalNode) map[NodeID]*internalNode, predicates ...NodePredicate) []Node {
+	start, exists := d.nodes[n]
+	if !exists {
+		return nil
+	}
+
+	result := make([]Node, 0)
+	visited := map[NodeID]struct{}{n: {}}
+	level := []*internalNode{start}
+
+	for len(level) > 0 {
+		nextLevel := make([]*internalNode, 0)
+		for _, current := range level {
+			for _, id := range sortedIDs(next(current)) {
+				if _, seen := visited[id]; seen {
+					continue
+				}
+				visited[id] = struct{}{}
+				nextLevel = append(nextLevel, d.nodes[id])
+			}
+		}
+		sort.Slice(nextLevel, func(i, j int) bool { return nextLevel[i].id < nextLevel[j].id })
+
+		for _, node := range nextLevel {
+			if matchesAll(node.node, predicates) {
+				result = append(result, node.node)
+			}
+		}
+
+		level = nextLevel
+	}
+
+	return result
+}
+
+// TopologicalSort returns the nodes sorted so that every parent comes before all its children.
+// Ties are broken by ID, so the result is deterministic.
+// Returns a CycleError when the graph is not acyclic.
+func (d *DAG) TopologicalSort() ([]Node, error) {
+	// Kahn's algorithm
+	// https://en.wikipedia.org/wiki/Topological_sorting
+	inDegree := make(map[NodeID]int, len(d.nodes))
+	for id, n := range d.nodes {
+		inDegree[id] = len(n.parents)
+	}
+
+	// S: Set of all nodes with no incoming edge, kept sorted by ID
+	s := utils.Map(d.Roots(), func(n Node) NodeID { return n.ID() })
+
+	result := make([]Node, 0, len(d.nodes))
+	for len(s) != 0 {
+		var id NodeID
+		id, s = s[0], s[1:]
+		result = append(result, d.nodes[id].node)
+
+		for _, childID := range sortedIDs(d.nodes[id].children) {
+			inDegree[childID]--
+			if inDegree[childID] == 0 {
+				s = insertSorted(s, childID)
+			}
+		}
+	}
+
+	if len(result) != len(d.nodes) {
+		return nil, &CycleError{Path: d.FindCycle()}
+	}
+
+	return result, nil
+}
+
+// FindCycle returns the node IDs of a cycle of the graph, starting and ending in the same node.
+// Returns nil when the graph is acyclic.
+func (d *DAG) FindCycle() []NodeID {
+	const (
+		unvisited = iota
+		inProgress
+		done
+	)
+
+	state := make(map[NodeID]int, len(d.nodes))
+	stack := make([]NodeID, 0)
+
+	var visit func(id NodeID) []NodeID
+	visit = func(id NodeID) []NodeID {
+		state[id] = inProgress
+		stack = append(stack, id)
+
+		for _, childID := range sortedIDs(d.nodes[id].children) {
+			switch state[childID] {
+			case inProgress:
+				// the cycle goes from the first occurrence of the child in the stack up to the current node
+				for idx := range stack {
+					if stack[idx] == childID {
+						return append(append([]NodeID(nil), stack[idx:]...), childID)
+					}
+				}
+			case unvisited:
+				if cycle := visit(childID); cycle != nil {
+					return cycle
+				}
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[id] = done
+		return nil
+	}
+
+	for _, n := range d.sortedInternalNodes() {
+		if state[n.id] != unvisited {
+			continue
+		}
+		if cycle := visit(n.id); cycle != nil {
+			return cycle
+		}
+	}
+
+	return nil
+}
+
+func (d *DAG) sortedInternalNodes() []*internalNode {
+	return utils.Map(sortedIDs(d.nodes), func(id NodeID) *internalNode { return d.nodes[id] })
+}
+
+func sortedIDs(nodes map[NodeID]*internalNode) []NodeID {
+	ids := make([]NodeID, 0, len(nodes))
+	for id := range nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func insertSorted(ids []NodeID, id NodeID) []NodeID {
+	idx := sort.SearchStrings(ids, id)
+	ids = append(ids, "")
+	copy(ids[idx+1:], ids[idx:])
+	ids[idx] = id
+	return ids
+}
+
+func matchesAll(node Node, predicates []NodePredicate) bool {
+	for _, predicate := range predicates {
+		if !predicate(node) {
+			return false
+		}
+	}
+	return true
+}
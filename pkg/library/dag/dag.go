@@ -282,3 +282,183 @@ func (d *DAG) Validate() bool {
 
 	return true
 }
+
+// RemoveNode removes the node from the DAG, along with all the edges from and to the node
+func (d *DAG) RemoveNode(n NodeID) error {
+	internalNode, exists := d.nodes[n]
+	if !exists {
+		return &nodeNotFoundError{id: n}
+	}
+
+	for _, parent := range internalNode.parents {
+		delete(parent.children, n)
+	}
+
+	for _, child := range internalNode.children {
+		delete(child.parents, n)
+	}
+
+	delete(d.nodes, n)
+
+	for field, fieldIndex := range d.nodeIndexes {
+		for label, nodeList := range fieldIndex {
+			nodeList = utils.Filter(nodeList, func(node Node) bool { return node.ID() != n })
+			if len(nodeList) == 0 {
+				delete(fieldIndex, label)
+				continue
+			}
+			d.nodeIndexes[field][label] = nodeList
+		}
+	}
+
+	return nil
+}
+
+// RemoveEdge removes the edge between the parent and the child nodes
+func (d *DAG) RemoveEdge(parent NodeID, child NodeID) error {
+	parentInternalNode, parentExists := d.nodes[parent]
+	if !parentExists {
+		return &nodeNotFoundError{id: parent}
+	}
+
+	if _, exists := parentInternalNode.children[child]; !exists {
+		return fmt.Errorf("parent node %s does not have an edge with child %s", parent, child)
+	}
+
+	delete(parentInternalNode.children, child)
+	delete(d.nodes[child].parents, parent)
+
+	return nil
+}
+
+// Clone returns a copy of the DAG that can be mutated independently.
+// Nodes are not deep copied, only the structure of the graph and the indexes.
+func (d *DAG) Clone() *DAG {
+	clone := &DAG{
+		nodes:         make(map[NodeID]*internalNode, len(d.nodes)),
+		fieldIndexers: append([]FieldIndexer(nil), d.fieldIndexers...),
+		nodeIndexes:   make(map[Field]map[NodeLabel][]Node, len(d.nodeIndexes)),
+	}
+
+	for id, n := range d.nodes {
+		clone.nodes[id] = &internalNode{
+			id:       id,
+			node:     n.node,
+			parents:  make(map[NodeID]*internalNode, len(n.parents)),
+			children: make(map[NodeID]*internalNode, len(n.children)),
+		}
+	}
+
+	for id, n := range d.nodes {
+		for parentID := range n.parents {
+			clone.nodes[id].parents[parentID] = clone.nodes[parentID]
+		}
+		for childID := range n.children {
+			clone.nodes[id].children[childID] = clone.nodes[childID]
+		}
+	}
+
+	for field, fieldIndex := range d.nodeIndexes {
+		clone.nodeIndexes[field] = make(map[NodeLabel][]Node, len(fieldIndex))
+		for label, nodeList := range fieldIndex {
+			clone.nodeIndexes[field][label] = append([]Node(nil), nodeList...)
+		}
+	}
+
+	return clone
+}
+
+// ReadOnlyDAG is the read-only view of a DAG
+type ReadOnlyDAG interface {
+	Parents(NodeID) []Node
+	Children(NodeID) []Node
+	GetNode(NodeID) (Node, error)
+	GetNodes(Field, NodeLabel) []Node
+	Nodes() []Node
+	Edges() []Edge
+	Roots() []Node
+	Leaves() []Node
+	Ancestors(NodeID, ...NodePredicate) []Node
+	Descendants(NodeID, ...NodePredicate) []Node
+	TopologicalSort() ([]Node, error)
+	FindCycle() []NodeID
+	Validate() bool
+	ToDOT(...DOTOpt) string
+	MarshalJSON() ([]byte, error)
+}
+
+var _ ReadOnlyDAG = &DAG{}
+
+// Snapshot returns an immutable copy of the DAG.
+// Changes to the DAG after taking the snapshot are not reflected in the snapshot.
+func (d *DAG) Snapshot() ReadOnlyDAG {
+	return &readOnlyDAG{dag: d.Clone()}
+}
+
+// readOnlyDAG wraps a DAG exposing only the read-only methods,
+// so a snapshot cannot be converted back into a mutable DAG
+type readOnlyDAG struct {
+	dag *DAG
+}
+
+var _ ReadOnlyDAG = &readOnlyDAG{}
+
+func (r *readOnlyDAG) Parents(n NodeID) []Node {
+	return r.dag.Parents(n)
+}
+
+func (r *readOnlyDAG) Children(n NodeID) []Node {
+	return r.dag.Children(n)
+}
+
+func (r *readOnlyDAG) GetNode(n NodeID) (Node, error) {
+	return r.dag.GetNode(n)
+}
+
+func (r *readOnlyDAG) GetNodes(field Field, label NodeLabel) []Node {
+	return r.dag.GetNodes(field, label)
+}
+
+func (r *readOnlyDAG) Nodes() []Node {
+	return r.dag.Nodes()
+}
+
+func (r *readOnlyDAG) Edges() []Edge {
+	return r.dag.Edges()
+}
+
+func (r *readOnlyDAG) Roots() []Node {
+	return r.dag.Roots()
+}
+
+func (r *readOnlyDAG) Leaves() []Node {
+	return r.dag.Leaves()
+}
+
+func (r *readOnlyDAG) Ancestors(n NodeID, predicates ...NodePredicate) []Node {
+	return r.dag.Ancestors(n, predicates...)
+}
+
+func (r *readOnlyDAG) Descendants(n NodeID, predicates ...NodePredicate) []Node {
+	return r.dag.Descendants(n, predicates...)
+}
+
+func (r *readOnlyDAG) TopologicalSort() ([]Node, error) {
+	return r.dag.TopologicalSort()
+}
+
+func (r *readOnlyDAG) FindCycle() []NodeID {
+	return r.dag.FindCycle()
+}
+
+func (r *readOnlyDAG) Validate() bool {
+	return r.dag.Validate()
+}
+
+func (r *readOnlyDAG) ToDOT(opts ...DOTOpt) string {
+	return r.dag.ToDOT(opts...)
+}
+
+func (r *readOnlyDAG) MarshalJSON() ([]byte, error) {
+	return r.dag.MarshalJSON()
+}
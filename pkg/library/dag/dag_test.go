@@ -3,7 +3,9 @@
 package dag
 
 import (
+	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
 
 	"gotest.tools/assert"
@@ -378,3 +380,198 @@ func TestDAGIndexes(t *testing.T) {
 		assert.Assert(subT, utils.SameElements(indexedNodes, nodes), "index for Field 1 and label commonLabel failed")
 	})
 }
+
+// testDAG builds a DAG out of the given nodes and edges
+func testDAG(t *testing.T, nodes []NodeID, edges [][2]NodeID, opts ...Opt) *DAG {
+	d := NewDAG(opts...)
+	for _, id := range nodes {
+		assert.NilError(t, d.AddNode(NodeTest(id)))
+	}
+	for _, edge := range edges {
+		assert.NilError(t, d.AddEdge(edge[0], edge[1]))
+	}
+	return d
+}
+
+func nodeIDs(nodes []Node) []NodeID {
+	return utils.Map(nodes, func(n Node) NodeID { return n.ID() })
+}
+
+// gatewayclass -> gateway -> listener -> route -> rule
+var inheritanceNodes = []NodeID{"gc", "gw-a", "gw-b", "l-a1", "l-b1", "r-1", "r-2", "rule-1", "rule-2"}
+var inheritanceEdges = [][2]NodeID{
+	{"gc", "gw-a"}, {"gc", "gw-b"},
+	{"gw-a", "l-a1"}, {"gw-b", "l-b1"},
+	{"l-a1", "r-1"}, {"l-b1", "r-1"}, {"l-b1", "r-2"},
+	{"r-1", "rule-1"}, {"r-2", "rule-2"},
+}
+
+func TestDAGTopologicalSort(t *testing.T) {
+	t.Run("empty DAG", func(subT *testing.T) {
+		sorted, err := NewDAG().TopologicalSort()
+		assert.NilError(subT, err)
+		assert.Equal(subT, len(sorted), 0)
+	})
+
+	t.Run("parents come before children", func(subT *testing.T) {
+		d := testDAG(subT, inheritanceNodes, inheritanceEdges)
+		sorted, err := d.TopologicalSort()
+		assert.NilError(subT, err)
+		assert.DeepEqual(subT, nodeIDs(sorted), []NodeID{"gc", "gw-a", "gw-b", "l-a1", "l-b1", "r-1", "r-2", "rule-1", "rule-2"})
+	})
+
+	t.Run("ties are broken by ID", func(subT *testing.T) {
+		d := testDAG(subT, []NodeID{"c", "b", "a", "d"}, [][2]NodeID{{"c", "d"}, {"a", "d"}})
+		sorted, err := d.TopologicalSort()
+		assert.NilError(subT, err)
+		assert.DeepEqual(subT, nodeIDs(sorted), []NodeID{"a", "b", "c", "d"})
+	})
+
+	t.Run("cycles are reported", func(subT *testing.T) {
+		d := testDAG(subT, []NodeID{"0", "1", "2", "3"}, [][2]NodeID{{"0", "1"}, {"1", "2"}, {"2", "3"}, {"3", "1"}})
+		_, err := d.TopologicalSort()
+		assert.Assert(subT, IsCycle(err))
+		var cycleErr *CycleError
+		assert.Assert(subT, errors.As(err, &cycleErr))
+		assert.DeepEqual(subT, cycleErr.Path, []NodeID{"1", "2", "3", "1"})
+		assert.Error(subT, err, "cycle found: 1 -> 2 -> 3 -> 1")
+	})
+}
+
+func TestDAGFindCycle(t *testing.T) {
+	t.Run("acyclic DAG", func(subT *testing.T) {
+		d := testDAG(subT, inheritanceNodes, inheritanceEdges)
+		assert.Assert(subT, d.FindCycle() == nil)
+	})
+
+	t.Run("self loop", func(subT *testing.T) {
+		d := testDAG(subT, []NodeID{"0"}, [][2]NodeID{{"0", "0"}})
+		assert.DeepEqual(subT, d.FindCycle(), []NodeID{"0", "0"})
+	})
+
+	t.Run("cycle without roots", func(subT *testing.T) {
+		d := testDAG(subT, []NodeID{"0", "1", "2"}, [][2]NodeID{{"0", "1"}, {"1", "2"}, {"2", "0"}})
+		assert.DeepEqual(subT, d.FindCycle(), []NodeID{"0", "1", "2", "0"})
+		assert.Assert(subT, !d.Validate())
+	})
+}
+
+func TestDAGAncestorsAndDescendants(t *testing.T) {
+	d := testDAG(t, inheritanceNodes, inheritanceEdges)
+
+	t.Run("unknown node", func(subT *testing.T) {
+		assert.Assert(subT, d.Ancestors("unknown") == nil)
+		assert.Assert(subT, d.Descendants("unknown") == nil)
+	})
+
+	t.Run("ancestors sorted by distance", func(subT *testing.T) {
+		assert.DeepEqual(subT, nodeIDs(d.Ancestors("rule-1")), []NodeID{"r-1", "l-a1", "l-b1", "gw-a", "gw-b", "gc"})
+		assert.DeepEqual(subT, nodeIDs(d.Ancestors("gc")), []NodeID{})
+	})
+
+	t.Run("descendants sorted by distance", func(subT *testing.T) {
+		assert.DeepEqual(subT, nodeIDs(d.Descendants("gw-b")), []NodeID{"l-b1", "r-1", "r-2", "rule-1", "rule-2"})
+		assert.DeepEqual(subT, nodeIDs(d.Descendants("rule-1")), []NodeID{})
+	})
+
+	t.Run("predicates filter the result but not the traversal", func(subT *testing.T) {
+		isGateway := func(n Node) bool { return strings.HasPrefix(n.ID(), "gw-") }
+		notB := func(n Node) bool { return !strings.HasSuffix(n.ID(), "-b") }
+		assert.DeepEqual(subT, nodeIDs(d.Ancestors("rule-2", isGateway)), []NodeID{"gw-b"})
+		assert.DeepEqual(subT, nodeIDs(d.Ancestors("rule-1", isGateway, notB)), []NodeID{"gw-a"})
+		isRule := func(n Node) bool { return strings.HasPrefix(n.ID(), "rule-") }
+		assert.DeepEqual(subT, nodeIDs(d.Descendants("gc", isRule)), []NodeID{"rule-1", "rule-2"})
+	})
+}
+
+func TestDAGRootsLeavesAndEdges(t *testing.T) {
+	d := testDAG(t, inheritanceNodes, inheritanceEdges)
+	assert.DeepEqual(t, nodeIDs(d.Roots()), []NodeID{"gc"})
+	assert.DeepEqual(t, nodeIDs(d.Leaves()), []NodeID{"rule-1", "rule-2"})
+	assert.Equal(t, len(d.Edges()), len(inheritanceEdges))
+	assert.DeepEqual(t, d.Edges()[0], Edge{Parent: "gc", Child: "gw-a"})
+}
+
+func TestDAGRemoveNode(t *testing.T) {
+	typeIndexer := WithFieldIndexer(Field("type"), func(n Node) []NodeLabel {
+		return []NodeLabel{strings.Split(n.ID(), "-")[0]}
+	})
+
+	t.Run("unknown node", func(subT *testing.T) {
+		err := NewDAG().RemoveNode("unknown")
+		assert.Assert(subT, IsNodeNotFound(err))
+	})
+
+	t.Run("edges and indexes are removed", func(subT *testing.T) {
+		d := testDAG(subT, inheritanceNodes, inheritanceEdges, typeIndexer)
+		assert.NilError(subT, d.RemoveNode("r-1"))
+
+		_, err := d.GetNode("r-1")
+		assert.Assert(subT, IsNodeNotFound(err))
+		assert.DeepEqual(subT, nodeIDs(d.Children("l-a1")), []NodeID{})
+		assert.DeepEqual(subT, nodeIDs(d.Children("l-b1")), []NodeID{"r-2"})
+		assert.DeepEqual(subT, nodeIDs(d.Parents("rule-1")), []NodeID{})
+		assert.DeepEqual(subT, nodeIDs(d.GetNodes(Field("type"), NodeLabel("r"))), []NodeID{"r-2"})
+		assert.DeepEqual(subT, nodeIDs(d.Roots()), []NodeID{"gc", "rule-1"})
+	})
+
+	t.Run("remove edge", func(subT *testing.T) {
+		d := testDAG(subT, inheritanceNodes, inheritanceEdges)
+		assert.NilError(subT, d.RemoveEdge("l-b1", "r-1"))
+		assert.DeepEqual(subT, nodeIDs(d.Parents("r-1")), []NodeID{"l-a1"})
+		assert.ErrorContains(subT, d.RemoveEdge("l-b1", "r-1"), "does not have an edge")
+	})
+}
+
+func TestDAGCloneAndSnapshot(t *testing.T) {
+	typeIndexer := WithFieldIndexer(Field("type"), func(n Node) []NodeLabel {
+		return []NodeLabel{strings.Split(n.ID(), "-")[0]}
+	})
+	d := testDAG(t, inheritanceNodes, inheritanceEdges, typeIndexer)
+
+	snapshot := d.Snapshot()
+	clone := d.Clone()
+
+	assert.NilError(t, d.RemoveNode("gw-a"))
+	assert.NilError(t, clone.AddNode(NodeTest("gw-c")))
+	assert.NilError(t, clone.AddEdge("gc", "gw-c"))
+
+	assert.DeepEqual(t, nodeIDs(d.Children("gc")), []NodeID{"gw-b"})
+	assert.DeepEqual(t, nodeIDs(snapshot.Descendants("gc", func(n Node) bool { return strings.HasPrefix(n.ID(), "gw-") })),
+		[]NodeID{"gw-a", "gw-b"})
+	assert.DeepEqual(t, nodeIDs(snapshot.GetNodes(Field("type"), NodeLabel("gw"))), []NodeID{"gw-a", "gw-b"})
+	assert.DeepEqual(t, nodeIDs(clone.Descendants("gc", func(n Node) bool { return strings.HasPrefix(n.ID(), "gw-") })),
+		[]NodeID{"gw-a", "gw-b", "gw-c"})
+	assert.Equal(t, len(clone.GetNodes(Field("type"), NodeLabel("gw"))), 3)
+
+	// the snapshot cannot be converted back into a mutable DAG
+	_, ok := snapshot.(*DAG)
+	assert.Assert(t, !ok)
+	_, ok = snapshot.(interface{ AddNode(Node) error })
+	assert.Assert(t, !ok)
+}
+
+func TestDAGExport(t *testing.T) {
+	typeIndexer := WithFieldIndexer(Field("type"), func(n Node) []NodeLabel {
+		return []NodeLabel{strings.Split(n.ID(), "-")[0]}
+	})
+	d := testDAG(t, []NodeID{"gw-a", "r-\"1\""}, [][2]NodeID{{"gw-a", "r-\"1\""}}, typeIndexer)
+
+	t.Run("DOT", func(subT *testing.T) {
+		assert.Equal(subT, d.ToDOT(), `digraph "dag" {
+  "gw-a" [label="gw-a"];
+  "r-\"1\"" [label="r-\"1\""];
+  "gw-a" -> "r-\"1\"";
+}
+`)
+		dot := d.ToDOT(WithDOTGraphName("topology"), WithDOTNodeLabel(func(n Node) string { return strings.ToUpper(n.ID()) }))
+		assert.Assert(subT, strings.HasPrefix(dot, `digraph "topology" {`))
+		assert.Assert(subT, strings.Contains(dot, `"gw-a" [label="GW-A"];`))
+	})
+
+	t.Run("JSON", func(subT *testing.T) {
+		out, err := json.Marshal(d)
+		assert.NilError(subT, err)
+		assert.Equal(subT, string(out), `{"nodes":[{"id":"gw-a","labels":{"type":["gw"]}},{"id":"r-\"1\"","labels":{"type":["r"]}}],"edges":[{"parent":"gw-a","child":"r-\"1\""}]}`)
+	})
+}
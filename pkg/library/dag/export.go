@@ -0,0 +1,97 @@
+package dag
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type dotOptions struct {
+	graphName string
+	nodeLabel func(Node) string
+}
+
+type DOTOpt func(*dotOptions)
+
+// WithDOTGraphName sets the name of the graph in the DOT output. Defaults to "dag"
+func WithDOTGraphName(name string) DOTOpt {
+	return func(o *dotOptions) {
+		o.graphName = name
+	}
+}
+
+// WithDOTNodeLabel sets the function used to label the nodes in the DOT output. Defaults to the node ID
+func WithDOTNodeLabel(f func(Node) string) DOTOpt {
+	return func(o *dotOptions) {
+		o.nodeLabel = f
+	}
+}
+
+// ToDOT exports the DAG in the Graphviz DOT language.
+// Nodes and edges are sorted by ID, so the output is deterministic.
+func (d *DAG) ToDOT(opts ...DOTOpt) string {
+	o := &dotOptions{
+		graphName: "dag",
+		nodeLabel: func(n Node) string { return n.ID() },
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", quoteDOT(o.graphName))
+	for _, n := range d.Nodes() {
+		fmt.Fprintf(&b, "  %s [label=%s];\n", quoteDOT(n.ID()), quoteDOT(o.nodeLabel(n)))
+	}
+	for _, e := range d.Edges() {
+		fmt.Fprintf(&b, "  %s -> %s;\n", quoteDOT(e.Parent), quoteDOT(e.Child))
+	}
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+func quoteDOT(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+type jsonNode struct {
+	ID     NodeID             `json:"id"`
+	Labels map[Field][]string `json:"labels,omitempty"`
+}
+
+type jsonDAG struct {
+	Nodes []jsonNode `json:"nodes"`
+	Edges []Edge     `json:"edges"`
+}
+
+// MarshalJSON exports the DAG as a list of nodes, including the labels of the field indexes, and a list of edges.
+// Nodes and edges are sorted by ID, so the output is deterministic.
+func (d *DAG) MarshalJSON() ([]byte, error) {
+	nodeLabels := make(map[NodeID]map[Field][]string)
+	for field, fieldIndex := range d.nodeIndexes {
+		for label, nodeList := range fieldIndex {
+			for _, n := range nodeList {
+				if nodeLabels[n.ID()] == nil {
+					nodeLabels[n.ID()] = make(map[Field][]string)
+				}
+				nodeLabels[n.ID()][field] = append(nodeLabels[n.ID()][field], label)
+			}
+		}
+	}
+
+	out := jsonDAG{
+		Nodes: make([]jsonNode, 0, len(d.nodes)),
+		Edges: d.Edges(),
+	}
+	for _, n := range d.Nodes() {
+		labels := nodeLabels[n.ID()]
+		for field := range labels {
+			sort.Strings(labels[field])
+		}
+		out.Nodes = append(out.Nodes, jsonNode{ID: n.ID(), Labels: labels})
+	}
+
+	return json.Marshal(out)
+}
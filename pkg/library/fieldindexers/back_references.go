@@ -0,0 +1,115 @@
+package fieldindexers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	kuadrantgatewayapi "github.com/kuadrant/kuadrant-operator/pkg/library/gatewayapi"
+	"github.com/kuadrant/kuadrant-operator/pkg/library/kuadrant"
+	"github.com/kuadrant/kuadrant-operator/pkg/library/utils"
+)
+
+var _ kuadrant.BackReferenceIndex = &BackReferenceIndex{}
+
+// BackReferenceIndex resolves the policies that directly or indirectly target a network object out of the
+// PolicyTargetRefField and HTTPRouteGatewayParentField indexes, instead of back references stored in annotations.
+// * HTTPRoute: policies targeting the route
+// * Gateway: policies targeting the gateway, and policies targeting the routes accepted by the gateway
+type BackReferenceIndex struct {
+	reader    client.Reader
+	logger    logr.Logger
+	listTypes map[reflect.Type]client.ObjectList
+}
+
+// NewBackReferenceIndex returns a BackReferenceIndex that reads from the given (cached) client.
+// The field indexes must be declared for all the policy kinds registered with WithPolicyList.
+func NewBackReferenceIndex(reader client.Reader, logger logr.Logger) *BackReferenceIndex {
+	return &BackReferenceIndex{
+		reader:    reader,
+		logger:    logger,
+		listTypes: make(map[reflect.Type]client.ObjectList),
+	}
+}
+
+// WithPolicyList registers the list type used to query the policies of the same kind as the given referrer
+func (i *BackReferenceIndex) WithPolicyList(referrer kuadrant.Referrer, list client.ObjectList) *BackReferenceIndex {
+	i.listTypes[reflect.TypeOf(referrer)] = list
+	return i
+}
+
+// BackReferences returns the keys of the policies of the kind of the referrer that directly or indirectly target the
+// network object, sorted by creation timestamp
+func (i *BackReferenceIndex) BackReferences(ctx context.Context, obj client.Object, referrer kuadrant.Referrer) ([]client.ObjectKey, error) {
+	switch o := obj.(type) {
+	case *gatewayapiv1.HTTPRoute:
+		return i.policyKeys(ctx, referrer, "HTTPRoute", client.ObjectKeyFromObject(o))
+
+	case *gatewayapiv1.Gateway:
+		gwKey := client.ObjectKeyFromObject(o)
+		refs, err := i.policyKeys(ctx, referrer, "Gateway", gwKey)
+		if err != nil {
+			return nil, err
+		}
+
+		routeList := &gatewayapiv1.HTTPRouteList{}
+		if err := i.reader.List(ctx, routeList, client.MatchingFields{HTTPRouteGatewayParentField: gwKey.String()}); err != nil {
+			return nil, fmt.Errorf("failed to list httproutes of gateway %s: %w", gwKey, err)
+		}
+		for idx := range routeList.Items {
+			routeRefs, err := i.policyKeys(ctx, referrer, "HTTPRoute", client.ObjectKeyFromObject(&routeList.Items[idx]))
+			if err != nil {
+				return nil, err
+			}
+			for _, ref := range routeRefs {
+				if !slices.Contains(refs, ref) {
+					refs = append(refs, ref)
+				}
+			}
+		}
+		return refs, nil
+
+	default:
+		return make([]client.ObjectKey, 0), nil
+	}
+}
+
+// policyKeys returns the keys of the policies of the kind of the referrer targeting a network object, sorted by creation timestamp
+func (i *BackReferenceIndex) policyKeys(ctx context.Context, referrer kuadrant.Referrer, targetKind string, targetKey client.ObjectKey) ([]client.ObjectKey, error) {
+	listType, ok := i.listTypes[reflect.TypeOf(referrer)]
+	if !ok {
+		i.logger.V(1).Info("unknown policy kind", "referrer", reflect.TypeOf(referrer).String())
+		return make([]client.ObjectKey, 0), nil
+	}
+
+	list, ok := reflect.New(reflect.TypeOf(listType).Elem()).Interface().(client.ObjectList)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a list of objects", listType)
+	}
+
+	if err := i.reader.List(ctx, list, client.MatchingFields{PolicyTargetRefField: PolicyTargetRefIndexValue(targetKind, targetKey)}); err != nil {
+		return nil, fmt.Errorf("failed to list %s policies targeting %s %s: %w", referrer.Kind(), targetKind, targetKey, err)
+	}
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make([]kuadrantgatewayapi.Policy, 0, len(items))
+	for _, item := range items {
+		if policy, ok := item.(kuadrantgatewayapi.Policy); ok {
+			policies = append(policies, policy)
+		}
+	}
+	sort.Sort(kuadrantgatewayapi.PolicyByCreationTimestamp(policies))
+
+	return utils.Map(policies, func(p kuadrantgatewayapi.Policy) client.ObjectKey { return client.ObjectKeyFromObject(p) }), nil
+}
@@ -0,0 +1,148 @@
+//go:build unit
+
+package fieldindexers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"gotest.tools/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	"github.com/kuadrant/kuadrant-operator/pkg/library/kuadrant"
+)
+
+type testPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	TargetRef         gatewayapiv1alpha2.PolicyTargetReference `json:"targetRef"`
+}
+
+func (p *testPolicy) GetTargetRef() gatewayapiv1alpha2.PolicyTargetReference { return p.TargetRef }
+func (p *testPolicy) Kind() string                                           { return "TestPolicy" }
+func (p *testPolicy) BackReferenceAnnotationName() string                    { return "kuadrant.io/testpolicies" }
+func (p *testPolicy) DirectReferenceAnnotationName() string                  { return "kuadrant.io/testpolicy" }
+
+func (p *testPolicy) DeepCopyObject() runtime.Object {
+	out := *p
+	p.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	p.TargetRef.DeepCopyInto(&out.TargetRef)
+	return &out
+}
+
+type testPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []testPolicy `json:"items"`
+}
+
+func (l *testPolicyList) DeepCopyObject() runtime.Object {
+	out := &testPolicyList{TypeMeta: l.TypeMeta}
+	l.ListMeta.DeepCopyInto(&out.ListMeta)
+	for i := range l.Items {
+		out.Items = append(out.Items, *l.Items[i].DeepCopyObject().(*testPolicy))
+	}
+	return out
+}
+
+func TestBackReferenceIndex(t *testing.T) {
+	gv := schema.GroupVersion{Group: "test.kuadrant.io", Version: "v1"}
+	s := runtime.NewScheme()
+	assert.NilError(t, gatewayapiv1.AddToScheme(s))
+	s.AddKnownTypes(gv, &testPolicy{}, &testPolicyList{})
+	metav1.AddToGroupVersion(s, gv)
+
+	gw := &gatewayapiv1.Gateway{ObjectMeta: metav1.ObjectMeta{Namespace: "gw-ns", Name: "gw-1"}}
+	parentRef := gatewayapiv1.ParentReference{
+		Group:     ptr.To(gatewayapiv1.Group(gatewayapiv1.GroupName)),
+		Kind:      ptr.To(gatewayapiv1.Kind("Gateway")),
+		Namespace: ptr.To(gatewayapiv1.Namespace("gw-ns")),
+		Name:      "gw-1",
+	}
+	route := &gatewayapiv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "app-ns", Name: "route-1"},
+		Spec: gatewayapiv1.HTTPRouteSpec{
+			CommonRouteSpec: gatewayapiv1.CommonRouteSpec{ParentRefs: []gatewayapiv1.ParentReference{parentRef}},
+		},
+		Status: gatewayapiv1.HTTPRouteStatus{
+			RouteStatus: gatewayapiv1.RouteStatus{
+				Parents: []gatewayapiv1.RouteParentStatus{
+					{
+						ParentRef:  parentRef,
+						Conditions: []metav1.Condition{{Type: string(gatewayapiv1.RouteConditionAccepted), Status: metav1.ConditionTrue}},
+					},
+				},
+			},
+		},
+	}
+	gwPolicy := &testPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "gw-ns", Name: "gw-policy", CreationTimestamp: metav1.Unix(1, 0)},
+		TargetRef:  gatewayapiv1alpha2.PolicyTargetReference{Group: gatewayapiv1.GroupName, Kind: "Gateway", Name: "gw-1"},
+	}
+	routePolicy := &testPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "app-ns", Name: "route-policy", CreationTimestamp: metav1.Unix(2, 0)},
+		TargetRef:  gatewayapiv1alpha2.PolicyTargetReference{Group: gatewayapiv1.GroupName, Kind: "HTTPRoute", Name: "route-1"},
+	}
+	otherPolicy := &testPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "app-ns", Name: "other-policy"},
+		TargetRef:  gatewayapiv1alpha2.PolicyTargetReference{Group: gatewayapiv1.GroupName, Kind: "HTTPRoute", Name: "route-2"},
+	}
+
+	routeIndexValue := func(client.Object) []string {
+		return []string{client.ObjectKeyFromObject(gw).String()}
+	}
+
+	cl := fake.NewClientBuilder().
+		WithScheme(s).
+		WithObjects(gw, route, gwPolicy, routePolicy, otherPolicy).
+		WithIndex(&testPolicy{}, PolicyTargetRefField, policyTargetRefIndexer(logr.Discard())).
+		WithIndex(&gatewayapiv1.HTTPRoute{}, HTTPRouteGatewayParentField, routeIndexValue).
+		Build()
+
+	index := NewBackReferenceIndex(cl, logr.Discard()).WithPolicyList(&testPolicy{}, &testPolicyList{})
+
+	ctx := context.TODO()
+
+	t.Run("gateway", func(subT *testing.T) {
+		refs, err := index.BackReferences(ctx, gw, &testPolicy{})
+		assert.NilError(subT, err)
+		assert.DeepEqual(subT, refs, []client.ObjectKey{
+			{Namespace: "gw-ns", Name: "gw-policy"},
+			{Namespace: "app-ns", Name: "route-policy"},
+		})
+	})
+
+	t.Run("httproute", func(subT *testing.T) {
+		refs, err := index.BackReferences(ctx, route, &testPolicy{})
+		assert.NilError(subT, err)
+		assert.DeepEqual(subT, refs, []client.ObjectKey{
+			{Namespace: "app-ns", Name: "route-policy"},
+		})
+	})
+
+	t.Run("unknown policy kind", func(subT *testing.T) {
+		refs, err := index.BackReferences(ctx, gw, &kuadrant.PolicyKindStub{})
+		assert.NilError(subT, err)
+		assert.Equal(subT, len(refs), 0)
+	})
+
+	t.Run("list error", func(subT *testing.T) {
+		// the httproutes are not indexed by parent gateway
+		cl := fake.NewClientBuilder().
+			WithScheme(s).
+			WithObjects(gw, route, gwPolicy, routePolicy).
+			WithIndex(&testPolicy{}, PolicyTargetRefField, policyTargetRefIndexer(logr.Discard())).
+			Build()
+		index := NewBackReferenceIndex(cl, logr.Discard()).WithPolicyList(&testPolicy{}, &testPolicyList{})
+		_, err := index.BackReferences(ctx, gw, &testPolicy{})
+		assert.Assert(subT, err != nil)
+	})
+}
@@ -0,0 +1,51 @@
+package fieldindexers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	kuadrantgatewayapi "github.com/kuadrant/kuadrant-operator/pkg/library/gatewayapi"
+)
+
+const (
+	PolicyTargetRefField = ".spec.targetRef"
+)
+
+// PolicyIndexByTargetRef declares an index key that we can later use with the client as a pseudo-field name,
+// allowing to query all the policies of a given kind that target a given network object
+func PolicyIndexByTargetRef(mgr ctrl.Manager, policyType kuadrantgatewayapi.Policy, baseLogger logr.Logger) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), policyType, PolicyTargetRefField, policyTargetRefIndexer(baseLogger)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func policyTargetRefIndexer(baseLogger logr.Logger) client.IndexerFunc {
+	return func(rawObj client.Object) []string {
+		policy, assertionOk := rawObj.(kuadrantgatewayapi.Policy)
+		if !assertionOk {
+			baseLogger.V(1).Error(fmt.Errorf("%T is not a kuadrant policy", rawObj), "cannot map")
+			return nil
+		}
+
+		targetRef := policy.GetTargetRef()
+		targetKey := client.ObjectKey{
+			Name:      string(targetRef.Name),
+			Namespace: string(ptr.Deref(targetRef.Namespace, gatewayapiv1.Namespace(policy.GetNamespace()))),
+		}
+
+		return []string{PolicyTargetRefIndexValue(string(targetRef.Kind), targetKey)}
+	}
+}
+
+// PolicyTargetRefIndexValue returns the value of the PolicyTargetRefField index for a network object
+func PolicyTargetRefIndexValue(kind string, key client.ObjectKey) string {
+	return fmt.Sprintf("%s/%s", kind, key)
+}
@@ -3,6 +3,7 @@ package gatewayapi
 import (
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -57,6 +58,18 @@ func (g *GatewayNode) ObjectKey() client.ObjectKey {
 type Topology struct {
 	graph  *dag.DAG
 	Logger logr.Logger
+
+	// policyFilter narrows down the attached policies returned by the nodes of the topology
+	policyFilter func(Policy) bool
+
+	// memo holds the values derived from the topology. Every view of the topology holds its own memo, as the
+	// values depend on the policy filter of the view
+	memo *topologyMemo
+}
+
+type topologyMemo struct {
+	mu     sync.Mutex
+	values map[string]any
 }
 
 type gatewayDAGNode struct {
@@ -170,7 +183,45 @@ func NewTopology(opts ...TopologyOpts) (*Topology, error) {
 		return nil, errors.New("DAG is not valid")
 	}
 
-	return &Topology{graph, o.logger}, nil
+	return &Topology{graph: graph, Logger: o.logger, memo: &topologyMemo{values: make(map[string]any)}}, nil
+}
+
+// Memoize returns the value stored under the key, building and storing it the first time it is requested.
+// Values are not shared with the views of the topology, so a value never depends on a policy filter other than the
+// one of the topology it is memoized in. As topologies are immutable, values are computed at most once per topology.
+func (g *Topology) Memoize(key string, build func() any) any {
+	if g.memo == nil {
+		return build()
+	}
+
+	g.memo.mu.Lock()
+	defer g.memo.mu.Unlock()
+
+	if value, ok := g.memo.values[key]; ok {
+		return value
+	}
+	value := build()
+	g.memo.values[key] = value
+	return value
+}
+
+// WithPolicyFilter returns a view of the topology that only exposes the attached policies accepted by the filter.
+// The view shares the underlying graph with the original topology, thus it is cheap to build, but not the memoized
+// values. Filters are cumulative.
+func (g *Topology) WithPolicyFilter(filter func(Policy) bool) *Topology {
+	policyFilter := filter
+	if currentFilter := g.policyFilter; currentFilter != nil {
+		policyFilter = func(p Policy) bool { return currentFilter(p) && filter(p) }
+	}
+
+	return &Topology{graph: g.graph, Logger: g.Logger, policyFilter: policyFilter, memo: &topologyMemo{values: make(map[string]any)}}
+}
+
+func (g *Topology) filterPolicies(policies []Policy) []Policy {
+	if g.policyFilter == nil {
+		return policies
+	}
+	return utils.Filter(policies, g.policyFilter)
 }
 
 type edge struct {
@@ -263,12 +314,12 @@ func (g *Topology) Gateways() []GatewayNode {
 				)
 				return RouteNode{}
 			}
-			return RouteNode(rDAGNode)
+			return RouteNode{HTTPRoute: rDAGNode.HTTPRoute, attachedPolicies: g.filterPolicies(rDAGNode.attachedPolicies)}
 		})
 
 		return GatewayNode{
 			Gateway:          gNode.Gateway,
-			attachedPolicies: gNode.attachedPolicies,
+			attachedPolicies: g.filterPolicies(gNode.attachedPolicies),
 			routes:           routes,
 		}
 	})
@@ -286,6 +337,6 @@ func (g *Topology) Routes() []RouteNode {
 			)
 			return RouteNode{}
 		}
-		return RouteNode(rNode)
+		return RouteNode{HTTPRoute: rNode.HTTPRoute, attachedPolicies: g.filterPolicies(rNode.attachedPolicies)}
 	})
 }
@@ -0,0 +1,455 @@
+package gatewayapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	toolscache "k8s.io/client-go/tools/cache"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+var ErrTopologyCacheNotSynced = errors.New("topology cache not synced yet")
+
+// TopologyCache maintains one cluster-wide topology of gateways, routes and policies.
+// The state is fed by the shared informers of the manager and updated incrementally on every event.
+// The topology is built lazily out of the cached state and memoized until the next event invalidates it,
+// so every consumer reads the same consistent snapshot.
+// Snapshots are shared among consumers and must be treated as read-only.
+type TopologyCache struct {
+	logger      logr.Logger
+	scheme      *runtime.Scheme
+	policyTypes []Policy
+
+	mu            sync.RWMutex
+	gateways      map[client.ObjectKey]*gatewayapiv1.Gateway
+	routes        map[client.ObjectKey]*gatewayapiv1.HTTPRoute
+	policies      map[policyCacheKey]Policy
+	snapshot      *Topology
+	registrations []toolscache.ResourceEventHandlerRegistration
+	subscribers   []*topologyCacheSubscriber
+	stopped       bool
+}
+
+var _ manager.LeaderElectionRunnable = &TopologyCache{}
+
+type policyCacheKey struct {
+	schema.GroupKind
+	client.ObjectKey
+}
+
+func NewTopologyCache(logger logr.Logger, policyTypes ...Policy) *TopologyCache {
+	return &TopologyCache{
+		logger:      logger,
+		policyTypes: policyTypes,
+		gateways:    make(map[client.ObjectKey]*gatewayapiv1.Gateway),
+		routes:      make(map[client.ObjectKey]*gatewayapiv1.HTTPRoute),
+		policies:    make(map[policyCacheKey]Policy),
+	}
+}
+
+// SetupWithManager registers the event handlers of the cache in the shared informers of the manager
+// for gateways, httproutes and every policy type the cache was built for.
+// The cache is also added to the manager as a runnable, so the subscribers are stopped when the manager is.
+func (c *TopologyCache) SetupWithManager(mgr ctrl.Manager) error {
+	c.scheme = mgr.GetScheme()
+
+	if err := mgr.Add(c); err != nil {
+		return err
+	}
+
+	objs := []client.Object{&gatewayapiv1.Gateway{}, &gatewayapiv1.HTTPRoute{}}
+	for _, policyType := range c.policyTypes {
+		objs = append(objs, policyType)
+	}
+
+	for _, obj := range objs {
+		informer, err := mgr.GetCache().GetInformer(context.Background(), obj)
+		if err != nil {
+			return err
+		}
+		registration, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				c.apply(obj, nil, false)
+			},
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				c.apply(newObj, oldObj, false)
+			},
+			DeleteFunc: func(obj interface{}) {
+				if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+					obj = tombstone.Obj
+				}
+				c.apply(obj, nil, true)
+			},
+		})
+		if err != nil {
+			return err
+		}
+		c.registrations = append(c.registrations, registration)
+	}
+
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, so the subscribers of every replica are stopped
+func (c *TopologyCache) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements manager.Runnable. It blocks until the context is done and then stops all the subscribers.
+func (c *TopologyCache) Start(ctx context.Context) error {
+	<-ctx.Done()
+
+	c.mu.Lock()
+	c.stopped = true
+	subscribers := c.subscribers
+	c.mu.Unlock()
+
+	for _, subscriber := range subscribers {
+		subscriber.stop()
+	}
+	return nil
+}
+
+// HasSynced returns true when the initial state of all the informers has been delivered to the cache
+func (c *TopologyCache) HasSynced() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, registration := range c.registrations {
+		if !registration.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+// Snapshot returns the current topology with all the cached gateways, routes and policies.
+// Use Topology.WithPolicyFilter to narrow it down to a given kind of policy.
+func (c *TopologyCache) Snapshot() (*Topology, error) {
+	if !c.HasSynced() {
+		return nil, ErrTopologyCacheNotSynced
+	}
+
+	c.mu.RLock()
+	snapshot := c.snapshot
+	c.mu.RUnlock()
+	if snapshot != nil {
+		return snapshot, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// another reader may have built the snapshot in the meantime
+	if c.snapshot != nil {
+		return c.snapshot, nil
+	}
+
+	gateways := make([]*gatewayapiv1.Gateway, 0, len(c.gateways))
+	for _, gw := range c.gateways {
+		gateways = append(gateways, gw)
+	}
+	sort.Slice(gateways, func(i, j int) bool {
+		return client.ObjectKeyFromObject(gateways[i]).String() < client.ObjectKeyFromObject(gateways[j]).String()
+	})
+
+	routes := make([]*gatewayapiv1.HTTPRoute, 0, len(c.routes))
+	for _, route := range c.routes {
+		routes = append(routes, route)
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		return client.ObjectKeyFromObject(routes[i]).String() < client.ObjectKeyFromObject(routes[j]).String()
+	})
+
+	policies := make([]Policy, 0, len(c.policies))
+	for _, policy := range c.policies {
+		policies = append(policies, policy)
+	}
+	sort.Sort(PolicyByCreationTimestamp(policies))
+
+	t, err := NewTopology(
+		WithLogger(c.logger),
+		WithGateways(gateways),
+		WithRoutes(routes),
+		WithPolicies(policies),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	c.snapshot = t
+
+	return t, nil
+}
+
+// Subscribe returns a channel that receives a generic event for every object after its change has been applied to
+// the cache. For updates, both the old and the new version of the object are sent.
+// Events are queued per subscriber, so a subscriber that is not drained (e.g. a controller that only runs in the leader
+// replica) never blocks the informers. While queued, the events of an object are coalesced into the oldest version
+// not yet sent and the latest one.
+// Subscribers must be registered before the manager is started. The channels are closed once the cache is stopped.
+func (c *TopologyCache) Subscribe() <-chan event.GenericEvent {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	subscriber := newTopologyCacheSubscriber()
+	if c.stopped {
+		close(subscriber.events)
+		return subscriber.events
+	}
+	c.subscribers = append(c.subscribers, subscriber)
+	go subscriber.run()
+	return subscriber.events
+}
+
+// Source returns a controller-runtime source that triggers events after the cache has been updated,
+// thus guaranteeing that reconciliations read a snapshot that includes the triggering change.
+func (c *TopologyCache) Source() source.Source {
+	return &source.Channel{Source: c.Subscribe()}
+}
+
+func (c *TopologyCache) apply(obj, oldObj interface{}, deleted bool) {
+	o, ok := obj.(client.Object)
+	if !ok {
+		c.logger.Error(fmt.Errorf("%T is not a client.Object", obj), "cannot apply event to the topology cache")
+		return
+	}
+
+	// objects coming from the informers usually lack type metadata, which the topology relies on to build node IDs
+	o = o.DeepCopyObject().(client.Object)
+	if o.GetObjectKind().GroupVersionKind().Empty() && c.scheme != nil {
+		gvk, err := apiutil.GVKForObject(o, c.scheme)
+		if err != nil {
+			c.logger.Error(err, "cannot apply event to the topology cache")
+			return
+		}
+		o.GetObjectKind().SetGroupVersionKind(gvk)
+	}
+
+	// the cache always stores the latest version of the objects, so the snapshot reads the latest status of the
+	// policies, but status-only updates, such as the ones that report the usage of the limits, only trigger the
+	// subscribers when the policy is accepted or enforced, or it stops being so
+	notify := true
+	if old, ok := oldObj.(client.Object); ok && !deleted && isPolicyStatusOnlyUpdate(old, o) {
+		notify = policyConditionsChanged(old, o)
+	}
+
+	c.mu.Lock()
+	key := client.ObjectKeyFromObject(o)
+	switch typedObj := o.(type) {
+	case *gatewayapiv1.Gateway:
+		if deleted {
+			delete(c.gateways, key)
+		} else {
+			c.gateways[key] = typedObj
+		}
+	case *gatewayapiv1.HTTPRoute:
+		if deleted {
+			delete(c.routes, key)
+		} else {
+			c.routes[key] = typedObj
+		}
+	case Policy:
+		policyKey := policyCacheKey{GroupKind: typedObj.GetObjectKind().GroupVersionKind().GroupKind(), ObjectKey: key}
+		if deleted {
+			delete(c.policies, policyKey)
+		} else {
+			c.policies[policyKey] = typedObj
+		}
+	default:
+		c.mu.Unlock()
+		c.logger.V(1).Info("unexpected object type, skipping it", "type", fmt.Sprintf("%T", o))
+		return
+	}
+	c.snapshot = nil
+	subscribers := c.subscribers
+	c.mu.Unlock()
+
+	c.logger.V(1).Info("topology cache updated", "type", fmt.Sprintf("%T", o), "key", key, "deleted", deleted)
+
+	if !notify {
+		return
+	}
+
+	old, _ := oldObj.(client.Object)
+	for _, subscriber := range subscribers {
+		subscriber.enqueue(old, o)
+	}
+}
+
+// isPolicyStatusOnlyUpdate returns true if both versions of the object are policies
+// with the same generation, labels, annotations and deletion timestamp
+func isPolicyStatusOnlyUpdate(old, latest client.Object) bool {
+	if _, ok := latest.(Policy); !ok {
+		return false
+	}
+	if _, ok := old.(Policy); !ok {
+		return false
+	}
+	return old.GetGeneration() == latest.GetGeneration() &&
+		reflect.DeepEqual(old.GetLabels(), latest.GetLabels()) &&
+		reflect.DeepEqual(old.GetAnnotations(), latest.GetAnnotations()) &&
+		old.GetDeletionTimestamp().Equal(latest.GetDeletionTimestamp())
+}
+
+// policyNotifiedConditions are the conditions of the policies whose changes are notified to the subscribers
+// even if nothing but the status of the policy has changed
+var policyNotifiedConditions = []string{
+	string(gatewayapiv1alpha2.PolicyConditionAccepted),
+	"Enforced", // kuadrant.PolicyConditionEnforced, which cannot be imported without an import cycle
+}
+
+// policyConditionsChanged returns true if the status of any of the notified conditions differs between both versions
+// of the policy. Policies do not share a type for their status, thus the conditions are read from the unstructured
+// representation of the objects.
+func policyConditionsChanged(old, latest client.Object) bool {
+	oldConditions := policyConditions(old)
+	latestConditions := policyConditions(latest)
+	for _, conditionType := range policyNotifiedConditions {
+		oldCondition := meta.FindStatusCondition(oldConditions, conditionType)
+		latestCondition := meta.FindStatusCondition(latestConditions, conditionType)
+		if (oldCondition == nil) != (latestCondition == nil) {
+			return true
+		}
+		if oldCondition != nil && oldCondition.Status != latestCondition.Status {
+			return true
+		}
+	}
+	return false
+}
+
+func policyConditions(obj client.Object) []metav1.Condition {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil
+	}
+	rawConditions, found, err := unstructured.NestedSlice(u, "status", "conditions")
+	if err != nil || !found {
+		return nil
+	}
+	conditions := make([]metav1.Condition, 0, len(rawConditions))
+	for _, rawCondition := range rawConditions {
+		condition, ok := rawCondition.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditionType, _, _ := unstructured.NestedString(condition, "type")
+		conditionStatus, _, _ := unstructured.NestedString(condition, "status")
+		conditions = append(conditions, metav1.Condition{Type: conditionType, Status: metav1.ConditionStatus(conditionStatus)})
+	}
+	return conditions
+}
+
+type topologyCacheSubscriber struct {
+	events chan event.GenericEvent
+	done   chan struct{}
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   []policyCacheKey
+	pending map[policyCacheKey]*topologyCacheSubscriberEvent
+	stopped bool
+}
+
+type topologyCacheSubscriberEvent struct {
+	old    client.Object
+	latest client.Object
+}
+
+func newTopologyCacheSubscriber() *topologyCacheSubscriber {
+	s := &topologyCacheSubscriber{
+		events:  make(chan event.GenericEvent),
+		done:    make(chan struct{}),
+		pending: make(map[policyCacheKey]*topologyCacheSubscriberEvent),
+	}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+// enqueue adds the change of an object to the queue of the subscriber without blocking.
+// If the object has a change pending already, only the latest version of the object is updated.
+func (s *topologyCacheSubscriber) enqueue(old, latest client.Object) {
+	key := policyCacheKey{GroupKind: latest.GetObjectKind().GroupVersionKind().GroupKind(), ObjectKey: client.ObjectKeyFromObject(latest)}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return
+	}
+	if e, ok := s.pending[key]; ok {
+		e.latest = latest
+		return
+	}
+	s.pending[key] = &topologyCacheSubscriberEvent{old: old, latest: latest}
+	s.queue = append(s.queue, key)
+	s.cond.Signal()
+}
+
+// stop makes run return, discarding the events still queued
+func (s *topologyCacheSubscriber) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return
+	}
+	s.stopped = true
+	close(s.done)
+	s.cond.Broadcast()
+}
+
+// run sends the queued events to the channel of the subscriber, in order, until the subscriber is stopped
+func (s *topologyCacheSubscriber) run() {
+	defer close(s.events)
+
+	for {
+		s.mu.Lock()
+		for len(s.queue) == 0 && !s.stopped {
+			s.cond.Wait()
+		}
+		if s.stopped {
+			s.mu.Unlock()
+			return
+		}
+		key := s.queue[0]
+		s.queue = s.queue[1:]
+		e := s.pending[key]
+		delete(s.pending, key)
+		s.mu.Unlock()
+
+		if e.old != nil && !s.send(e.old) {
+			return
+		}
+		if !s.send(e.latest) {
+			return
+		}
+	}
+}
+
+// send blocks until the object is received or the subscriber is stopped
+func (s *topologyCacheSubscriber) send(obj client.Object) bool {
+	select {
+	case s.events <- event.GenericEvent{Object: obj}:
+		return true
+	case <-s.done:
+		return false
+	}
+}
@@ -0,0 +1,275 @@
+//go:build unit
+
+package gatewayapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/kuadrant/kuadrant-operator/pkg/library/utils"
+	"github.com/kuadrant/kuadrant-operator/pkg/log"
+)
+
+type testRegistration bool
+
+func (r testRegistration) HasSynced() bool {
+	return bool(r)
+}
+
+func TestTopologyCache_Snapshot(t *testing.T) {
+	t.Run("not synced", func(subT *testing.T) {
+		c := NewTopologyCache(log.NewLogger())
+		c.registrations = append(c.registrations, testRegistration(true), testRegistration(false))
+
+		_, err := c.Snapshot()
+		assert.Assert(subT, errors.Is(err, ErrTopologyCacheNotSynced))
+	})
+
+	t.Run("empty cache", func(subT *testing.T) {
+		c := NewTopologyCache(log.NewLogger())
+
+		topology, err := c.Snapshot()
+		assert.NilError(subT, err)
+		assert.Assert(subT, len(topology.Gateways()) == 0)
+		assert.Assert(subT, len(topology.Routes()) == 0)
+	})
+
+	t.Run("snapshot is memoized until the next event", func(subT *testing.T) {
+		c := NewTopologyCache(log.NewLogger())
+		gw1 := testBasicGateway("gw1", NS)
+		c.apply(gw1, nil, false)
+
+		topology, err := c.Snapshot()
+		assert.NilError(subT, err)
+		assert.Assert(subT, len(topology.Gateways()) == 1)
+
+		sameTopology, err := c.Snapshot()
+		assert.NilError(subT, err)
+		assert.Assert(subT, topology == sameTopology, "snapshot should have been memoized")
+
+		c.apply(testBasicGateway("gw2", NS), nil, false)
+
+		newTopology, err := c.Snapshot()
+		assert.NilError(subT, err)
+		assert.Assert(subT, topology != newTopology, "snapshot should have been invalidated")
+		assert.Assert(subT, len(newTopology.Gateways()) == 2)
+		// previous snapshots are immutable
+		assert.Assert(subT, len(topology.Gateways()) == 1)
+	})
+
+	t.Run("incremental updates", func(subT *testing.T) {
+		c := NewTopologyCache(log.NewLogger())
+		gw1 := testBasicGateway("gw1", NS)
+		route1 := testBasicRoute("route1", NS, gw1)
+		route2 := testBasicRoute("route2", NS, gw1)
+		policy1 := testBasicRoutePolicy("policy1", NS, route1)
+		policy2 := testBasicGatewayPolicy("policy2", NS, gw1)
+
+		for _, obj := range []client.Object{gw1, route1, route2, policy1, policy2} {
+			c.apply(obj, nil, false)
+		}
+
+		topology, err := c.Snapshot()
+		assert.NilError(subT, err)
+		assert.Assert(subT, len(topology.Gateways()) == 1)
+		assert.Assert(subT, len(topology.Gateways()[0].Routes()) == 2)
+		assert.Assert(subT, len(topology.Gateways()[0].AttachedPolicies()) == 1)
+		assert.Assert(subT, len(topology.Routes()) == 2)
+
+		// delete a route and its policy
+		c.apply(route1, nil, true)
+		c.apply(policy1, nil, true)
+
+		topology, err = c.Snapshot()
+		assert.NilError(subT, err)
+		assert.Assert(subT, len(topology.Routes()) == 1)
+		assert.Equal(subT, topology.Routes()[0].Name, "route2")
+		assert.Assert(subT, len(topology.Routes()[0].AttachedPolicies()) == 0)
+
+		// update the gateway so it is no longer programmed
+		c.apply(testInvalidGateway("gw1", NS), gw1, false)
+
+		topology, err = c.Snapshot()
+		assert.NilError(subT, err)
+		assert.Assert(subT, len(topology.Gateways()) == 0)
+	})
+}
+
+func TestTopologyCache_Subscribe(t *testing.T) {
+	c := NewTopologyCache(log.NewLogger())
+	events := c.Subscribe()
+
+	gw1 := testBasicGateway("gw1", NS)
+	c.apply(gw1, nil, false)
+
+	e := <-events
+	assert.Equal(t, client.ObjectKeyFromObject(e.Object), client.ObjectKeyFromObject(gw1))
+
+	// updates send the old and the new object
+	route := testBasicRoute("route1", NS, gw1)
+	gw2 := testBasicGateway("gw2", NS)
+	updatedRoute := testBasicRoute("route1", NS, gw2)
+	c.apply(updatedRoute, route, false)
+
+	oldEvent := <-events
+	newEvent := <-events
+	oldRoute, ok := oldEvent.Object.(*gatewayapiv1.HTTPRoute)
+	assert.Assert(t, ok)
+	newRoute, ok := newEvent.Object.(*gatewayapiv1.HTTPRoute)
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, GetRouteAcceptedGatewayParentKeys(oldRoute), []client.ObjectKey{client.ObjectKeyFromObject(gw1)})
+	assert.DeepEqual(t, GetRouteAcceptedGatewayParentKeys(newRoute), []client.ObjectKey{client.ObjectKeyFromObject(gw2)})
+
+	// the subscriber reads the state after the change has been applied
+	topology, err := c.Snapshot()
+	assert.NilError(t, err)
+	routeKeys := utils.Map(topology.Routes(), func(r RouteNode) client.ObjectKey { return client.ObjectKeyFromObject(r.HTTPRoute) })
+	assert.DeepEqual(t, routeKeys, []client.ObjectKey{client.ObjectKeyFromObject(route)})
+}
+
+func TestTopologyCache_SubscriberNotDrained(t *testing.T) {
+	c := NewTopologyCache(log.NewLogger())
+	events := c.Subscribe()
+
+	const numGateways = 5000
+	gatewayVersion := func(version int) *gatewayapiv1.Gateway {
+		gw := testBasicGateway("updated", NS)
+		gw.ResourceVersion = fmt.Sprint(version)
+		return gw
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// many events and none of them read
+		for i := 0; i < numGateways; i++ {
+			c.apply(testBasicGateway(fmt.Sprintf("gw%d", i), NS), nil, false)
+		}
+		// several updates of the same object while still queued
+		for i := 1; i < 10; i++ {
+			c.apply(gatewayVersion(i), gatewayVersion(i-1), false)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("applying changes to the cache blocked on a subscriber not drained")
+	}
+
+	received := 0
+	var updatedVersions []string
+	for len(updatedVersions) < 2 {
+		e := <-events
+		received++
+		if e.Object.GetName() == "updated" {
+			updatedVersions = append(updatedVersions, e.Object.GetResourceVersion())
+		}
+	}
+	assert.Equal(t, received, numGateways+2)
+	// the updates were coalesced into the oldest and the latest versions
+	assert.DeepEqual(t, updatedVersions, []string{"0", "9"})
+}
+
+func TestTopologyCache_PolicyStatusOnlyUpdate(t *testing.T) {
+	c := NewTopologyCache(log.NewLogger())
+	events := c.Subscribe()
+
+	gw1 := testBasicGateway("gw1", NS)
+	policy := testBasicGatewayPolicy("policy1", NS, gw1)
+	policy.SetGeneration(1)
+	c.apply(gw1, nil, false)
+	c.apply(policy, nil, false)
+	<-events
+	<-events
+
+	// status-only update that does not change the notified conditions, e.g. a report of the usage of the limits
+	statusUpdated := policy.DeepCopyObject().(*TestPolicy)
+	statusUpdated.SetResourceVersion("2")
+	statusUpdated.Status.Conditions = []metav1.Condition{{Type: "Other", Status: metav1.ConditionTrue}}
+	c.apply(statusUpdated, policy, false)
+
+	select {
+	case e := <-events:
+		t.Fatalf("unexpected event for a status-only update: %v", client.ObjectKeyFromObject(e.Object))
+	case <-time.After(100 * time.Millisecond):
+	}
+	// the cache stores the latest version anyway
+	topology, err := c.Snapshot()
+	assert.NilError(t, err)
+	assert.Equal(t, topology.Gateways()[0].AttachedPolicies()[0].GetResourceVersion(), "2")
+
+	// status-only update that accepts the policy
+	accepted := statusUpdated.DeepCopy()
+	accepted.SetResourceVersion("3")
+	accepted.Status.Conditions = append(accepted.Status.Conditions, metav1.Condition{Type: "Accepted", Status: metav1.ConditionTrue})
+	c.apply(accepted, statusUpdated, false)
+
+	oldEvent := <-events
+	newEvent := <-events
+	assert.Equal(t, oldEvent.Object.GetResourceVersion(), "2")
+	assert.Equal(t, newEvent.Object.GetResourceVersion(), "3")
+
+	// spec update
+	specUpdated := accepted.DeepCopy()
+	specUpdated.SetResourceVersion("4")
+	specUpdated.SetGeneration(2)
+	c.apply(specUpdated, accepted, false)
+
+	newTopology, err := c.Snapshot()
+	assert.NilError(t, err)
+	assert.Assert(t, topology != newTopology, "snapshot should have been invalidated")
+	oldEvent = <-events
+	newEvent = <-events
+	assert.Equal(t, oldEvent.Object.GetGeneration(), int64(1))
+	assert.Equal(t, newEvent.Object.GetGeneration(), int64(2))
+}
+
+func TestTopologyCache_Stop(t *testing.T) {
+	c := NewTopologyCache(log.NewLogger())
+	drained := c.Subscribe()
+	notDrained := c.Subscribe()
+
+	c.apply(testBasicGateway("gw1", NS), nil, false)
+	<-drained
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error)
+	go func() {
+		stopped <- c.Start(ctx)
+	}()
+	cancel()
+
+	select {
+	case err := <-stopped:
+		assert.NilError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("the cache did not stop on context done")
+	}
+
+	// the goroutines of the subscribers return and close the channels,
+	// both while waiting for new events and while blocked on sending a queued one
+	for _, events := range []<-chan event.GenericEvent{drained, notDrained} {
+		select {
+		case _, ok := <-events:
+			for ok {
+				_, ok = <-events
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatal("the subscriber was not stopped on context done")
+		}
+	}
+
+	// subscribing to a stopped cache returns a closed channel
+	_, ok := <-c.Subscribe()
+	assert.Assert(t, !ok)
+}
@@ -0,0 +1,168 @@
+package gatewayapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kuadrant/kuadrant-operator/pkg/library/dag"
+	"github.com/kuadrant/kuadrant-operator/pkg/library/utils"
+)
+
+type policyDAGNode struct {
+	Policy
+}
+
+func (p policyDAGNode) ID() string {
+	return dagNodeIDFromObject(p.Policy)
+}
+
+// ToDOT exports the topology in the Graphviz DOT language.
+// Policies are represented as nodes with an edge to the gateway or route they target.
+func (g *Topology) ToDOT() string {
+	graph := g.graph.Clone()
+
+	addPolicies := func(targetID dag.NodeID, policies []Policy) {
+		for _, policy := range policies {
+			node := policyDAGNode{Policy: policy}
+			// policies are not unique in the graph
+			if _, err := graph.GetNode(node.ID()); dag.IsNodeNotFound(err) {
+				if err := graph.AddNode(node); err != nil {
+					g.Logger.Error(err, "cannot add policy to the exported topology")
+					continue
+				}
+			}
+			if err := graph.AddEdge(node.ID(), targetID); err != nil {
+				g.Logger.Error(err, "cannot add policy to the exported topology")
+			}
+		}
+	}
+
+	for _, gateway := range g.Gateways() {
+		addPolicies(dagNodeIDFromObject(gateway.Gateway), gateway.AttachedPolicies())
+	}
+	for _, route := range g.Routes() {
+		addPolicies(dagNodeIDFromObject(route.HTTPRoute), route.AttachedPolicies())
+	}
+
+	return graph.ToDOT(dag.WithDOTGraphName("topology"), dag.WithDOTNodeLabel(func(n dag.Node) string {
+		obj, ok := n.(client.Object)
+		if !ok {
+			return n.ID()
+		}
+		return fmt.Sprintf("%s %s", obj.GetObjectKind().GroupVersionKind().Kind, client.ObjectKeyFromObject(obj))
+	}))
+}
+
+type topologyObjectRef struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+func topologyObjectRefFromObject(obj client.Object) topologyObjectRef {
+	return topologyObjectRef{
+		Kind:      obj.GetObjectKind().GroupVersionKind().Kind,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+	}
+}
+
+type topologyGatewayJSON struct {
+	topologyObjectRef
+	Policies []topologyObjectRef `json:"policies"`
+	Routes   []topologyObjectRef `json:"routes"`
+}
+
+type topologyRouteJSON struct {
+	topologyObjectRef
+	Policies []topologyObjectRef `json:"policies"`
+}
+
+// MarshalJSON exports the gateways and routes of the topology with the policies attached to them.
+// Gateways, routes and policies are sorted, so the output is deterministic.
+func (g *Topology) MarshalJSON() ([]byte, error) {
+	sortRefs := func(refs []topologyObjectRef) []topologyObjectRef {
+		sort.Slice(refs, func(i, j int) bool {
+			return fmt.Sprintf("%s/%s/%s", refs[i].Kind, refs[i].Namespace, refs[i].Name) <
+				fmt.Sprintf("%s/%s/%s", refs[j].Kind, refs[j].Namespace, refs[j].Name)
+		})
+		return refs
+	}
+	policyRefs := func(policies []Policy) []topologyObjectRef {
+		return sortRefs(utils.Map(policies, func(p Policy) topologyObjectRef { return topologyObjectRefFromObject(p) }))
+	}
+
+	gateways := utils.Map(g.Gateways(), func(gw GatewayNode) topologyGatewayJSON {
+		return topologyGatewayJSON{
+			topologyObjectRef: topologyObjectRefFromObject(gw.Gateway),
+			Policies:          policyRefs(gw.AttachedPolicies()),
+			Routes: sortRefs(utils.Map(gw.Routes(), func(r RouteNode) topologyObjectRef {
+				return topologyObjectRefFromObject(r.HTTPRoute)
+			})),
+		}
+	})
+	sort.Slice(gateways, func(i, j int) bool {
+		return fmt.Sprintf("%s/%s", gateways[i].Namespace, gateways[i].Name) < fmt.Sprintf("%s/%s", gateways[j].Namespace, gateways[j].Name)
+	})
+
+	routes := utils.Map(g.Routes(), func(r RouteNode) topologyRouteJSON {
+		return topologyRouteJSON{
+			topologyObjectRef: topologyObjectRefFromObject(r.HTTPRoute),
+			Policies:          policyRefs(r.AttachedPolicies()),
+		}
+	})
+	sort.Slice(routes, func(i, j int) bool {
+		return fmt.Sprintf("%s/%s", routes[i].Namespace, routes[i].Name) < fmt.Sprintf("%s/%s", routes[j].Namespace, routes[j].Name)
+	})
+
+	return json.Marshal(struct {
+		Gateways []topologyGatewayJSON `json:"gateways"`
+		Routes   []topologyRouteJSON   `json:"routes"`
+	}{gateways, routes})
+}
+
+// EffectivePolicies returns the policies that apply to the route, of all the kinds of policies in the topology.
+// For each kind of policy, the policies attached to the route take precedence over the ones attached to the
+// parent gateways of the route. Policies attached to the gateways apply to the route only when no policy of the
+// same kind is attached to the route.
+// Returns nil if the route is not in the topology.
+func (g *Topology) EffectivePolicies(routeKey client.ObjectKey) []Policy {
+	var route *RouteNode
+	for _, r := range g.Routes() {
+		if client.ObjectKeyFromObject(r.HTTPRoute) == routeKey {
+			routeNode := r
+			route = &routeNode
+			break
+		}
+	}
+	if route == nil {
+		return nil
+	}
+
+	routePolicyKinds := make(map[string]struct{})
+	for _, policy := range route.AttachedPolicies() {
+		routePolicyKinds[policy.GetObjectKind().GroupVersionKind().GroupKind().String()] = struct{}{}
+	}
+
+	effective := append([]Policy{}, route.AttachedPolicies()...)
+
+	parentKeys := GetRouteAcceptedGatewayParentKeys(route.HTTPRoute)
+	for _, gateway := range g.Gateways() {
+		if !slices.Contains(parentKeys, gateway.ObjectKey()) {
+			continue
+		}
+		for _, policy := range gateway.AttachedPolicies() {
+			if _, overridden := routePolicyKinds[policy.GetObjectKind().GroupVersionKind().GroupKind().String()]; !overridden {
+				effective = append(effective, policy)
+			}
+		}
+	}
+
+	sort.Sort(PolicyByCreationTimestamp(effective))
+
+	return effective
+}
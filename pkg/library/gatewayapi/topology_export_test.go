@@ -0,0 +1,91 @@
+//go:build unit
+
+package gatewayapi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/kuadrant/kuadrant-operator/pkg/library/utils"
+	"github.com/kuadrant/kuadrant-operator/pkg/log"
+)
+
+func TestTopologyExport(t *testing.T) {
+	gw1 := testBasicGateway("gw1", NS)
+	route1 := testBasicRoute("route1", NS, gw1)
+	gwPolicy := testBasicGatewayPolicy("gw-policy", NS, gw1)
+	routePolicy := testBasicRoutePolicy("route-policy", NS, route1)
+
+	topology, err := NewTopology(
+		WithLogger(log.NewLogger()),
+		WithGateways([]*gatewayapiv1.Gateway{gw1}),
+		WithRoutes([]*gatewayapiv1.HTTPRoute{route1}),
+		WithPolicies([]Policy{gwPolicy, routePolicy}),
+	)
+	assert.NilError(t, err)
+
+	t.Run("DOT", func(subT *testing.T) {
+		dot := topology.ToDOT()
+		assert.Assert(subT, strings.HasPrefix(dot, `digraph "topology" {`))
+		for _, expected := range []string{
+			`[label="Gateway nsA/gw1"];`,
+			`[label="HTTPRoute nsA/route1"];`,
+			`[label="TestPolicy nsA/gw-policy"];`,
+			`"example.com/v1, Kind=TestPolicy#nsA/route-policy" -> "gateway.networking.k8s.io/v1, Kind=HTTPRoute#nsA/route1";`,
+			`"gateway.networking.k8s.io/v1, Kind=Gateway#nsA/gw1" -> "gateway.networking.k8s.io/v1, Kind=HTTPRoute#nsA/route1";`,
+		} {
+			assert.Assert(subT, strings.Contains(dot, expected), "expected %q in %s", expected, dot)
+		}
+	})
+
+	t.Run("JSON", func(subT *testing.T) {
+		out, err := json.Marshal(topology)
+		assert.NilError(subT, err)
+		assert.Equal(subT, string(out), `{"gateways":[{"kind":"Gateway","namespace":"nsA","name":"gw1",`+
+			`"policies":[{"kind":"TestPolicy","namespace":"nsA","name":"gw-policy"}],`+
+			`"routes":[{"kind":"HTTPRoute","namespace":"nsA","name":"route1"}]}],`+
+			`"routes":[{"kind":"HTTPRoute","namespace":"nsA","name":"route1",`+
+			`"policies":[{"kind":"TestPolicy","namespace":"nsA","name":"route-policy"}]}]}`)
+	})
+}
+
+func TestTopologyEffectivePolicies(t *testing.T) {
+	withKind := func(p Policy, kind string) Policy {
+		p.GetObjectKind().SetGroupVersionKind(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: kind})
+		return p
+	}
+
+	gw1 := testBasicGateway("gw1", NS)
+	gw2 := testBasicGateway("gw2", NS)
+	route1 := testBasicRoute("route1", NS, gw1, gw2)
+	route2 := testBasicRoute("route2", NS, gw1)
+
+	policies := []Policy{
+		withKind(testBasicGatewayPolicy("gw1-a", NS, gw1), "PolicyA"),
+		withKind(testBasicGatewayPolicy("gw1-b", NS, gw1), "PolicyB"),
+		withKind(testBasicGatewayPolicy("gw2-b", NS, gw2), "PolicyB"),
+		withKind(testBasicRoutePolicy("route1-a", NS, route1), "PolicyA"),
+	}
+
+	topology, err := NewTopology(
+		WithLogger(log.NewLogger()),
+		WithGateways([]*gatewayapiv1.Gateway{gw1, gw2}),
+		WithRoutes([]*gatewayapiv1.HTTPRoute{route1, route2}),
+		WithPolicies(policies),
+	)
+	assert.NilError(t, err)
+
+	names := func(policies []Policy) []string {
+		return utils.Map(policies, func(p Policy) string { return p.GetName() })
+	}
+
+	assert.DeepEqual(t, names(topology.EffectivePolicies(client.ObjectKeyFromObject(route1))), []string{"gw1-b", "gw2-b", "route1-a"})
+	assert.DeepEqual(t, names(topology.EffectivePolicies(client.ObjectKeyFromObject(route2))), []string{"gw1-a", "gw1-b"})
+	assert.Assert(t, topology.EffectivePolicies(client.ObjectKey{Namespace: NS, Name: "unknown"}) == nil)
+}
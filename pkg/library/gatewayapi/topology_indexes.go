@@ -2,6 +2,8 @@ package gatewayapi
 
 import (
 	"encoding/json"
+	"slices"
+	"strings"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
@@ -17,6 +19,11 @@ type TopologyIndexes struct {
 	// Type: Gateway -> []Policy
 	gatewayPolicies map[client.ObjectKey][]Policy
 
+	// policyGateways is the inverse of the gatewayPolicies index, i.e. an index of policies mapping to the gateways
+	// the policies directly or indirectly are targeting, sorted by gateway key
+	// Type: Policy -> []Gateway
+	policyGateways map[client.ObjectKey][]client.ObjectKey
+
 	// policyRoute is an index of policies mapping to HTTPRoutes
 	// The index only includes policies targeting only existing and accepted (by parent gateways) HTTPRoutes
 	// Type: Policy -> HTTPRoute
@@ -26,6 +33,11 @@ type TopologyIndexes struct {
 	// Gateway -> []HTTPRoute
 	untargetedRoutes map[client.ObjectKey][]*gatewayapiv1.HTTPRoute
 
+	// policyGateway is an index of policies mapping to Gateways
+	// The index only includes policies directly targeting existing gateways
+	// Type: Policy -> Gateway
+	policyGateway map[client.ObjectKey]client.ObjectKey
+
 	// Raw topology with gateways, routes and policies
 	// Currently only used for logging
 	internalTopology *Topology
@@ -36,19 +48,32 @@ func NewTopologyIndexes(t *Topology) *TopologyIndexes {
 		return nil
 	}
 
+	gatewayPolicies := buildGatewayPoliciesIndex(t)
+
 	return &TopologyIndexes{
-		gatewayPolicies:  buildGatewayPoliciesIndex(t),
+		gatewayPolicies:  gatewayPolicies,
+		policyGateways:   buildPolicyGatewaysIndex(gatewayPolicies),
 		policyRoute:      buildPolicyRouteIndex(t),
 		untargetedRoutes: buildUntargetedRoutesIndex(t),
+		policyGateway:    buildPolicyGatewayIndex(t),
 		internalTopology: t,
 	}
 }
 
 // PoliciesFromGateway returns Kuadrant Policies which
 // directly or indirectly are targeting the gateway given as input.
+// The indexes are shared among the readers of a topology snapshot, thus a copy of the list is returned
+// so callers can sort it or modify it otherwise.
 // Type: Gateway -> []Policy
 func (k *TopologyIndexes) PoliciesFromGateway(gateway *gatewayapiv1.Gateway) []Policy {
-	return k.gatewayPolicies[client.ObjectKeyFromObject(gateway)]
+	return slices.Clone(k.gatewayPolicies[client.ObjectKeyFromObject(gateway)])
+}
+
+// GatewaysFromPolicy returns the keys of the gateways the policy given as input
+// directly or indirectly is targeting, sorted by key.
+// Type: Policy -> []Gateway
+func (k *TopologyIndexes) GatewaysFromPolicy(policy Policy) []client.ObjectKey {
+	return k.policyGateways[client.ObjectKeyFromObject(policy)]
 }
 
 // GetPolicyHTTPRoute returns the HTTPRoute being targeted by the policy.
@@ -65,6 +90,39 @@ func (k *TopologyIndexes) GetUntargetedRoutes(gateway *gatewayapiv1.Gateway) []*
 	return k.untargetedRoutes[client.ObjectKeyFromObject(gateway)]
 }
 
+// IsPolicyOverridden returns true if the policy targets a gateway and all the HTTPRoutes attached to the gateway
+// are targeted by policies of their own, thus leaving no route where the gateway policy can be enforced.
+// It also returns true if no HTTPRoute is attached to the gateway at all.
+// Policies targeting HTTPRoutes are never overridden.
+// The computation only depends on the topology, thus it holds for any kind of policy,
+// as long as the topology is filtered down to the kind of the policy.
+func (k *TopologyIndexes) IsPolicyOverridden(policy Policy) bool {
+	gatewayKey, ok := k.policyGateway[client.ObjectKeyFromObject(policy)]
+	if !ok {
+		return false
+	}
+	return len(k.untargetedRoutes[gatewayKey]) == 0
+}
+
+// OverridingPolicies returns the policies that override the gateway policy given as input,
+// i.e. the other policies affecting the targeted gateway, sorted by key.
+// The routes of a gateway are not sorted in the topology, thus the policies are sorted so the result is stable.
+// Type: Policy -> []Policy
+func (k *TopologyIndexes) OverridingPolicies(policy Policy) []Policy {
+	gatewayKey, ok := k.policyGateway[client.ObjectKeyFromObject(policy)]
+	if !ok {
+		return nil
+	}
+	policyKey := client.ObjectKeyFromObject(policy)
+	policies := utils.Filter(k.gatewayPolicies[gatewayKey], func(p Policy) bool {
+		return client.ObjectKeyFromObject(p) != policyKey
+	})
+	slices.SortFunc(policies, func(a, b Policy) int {
+		return strings.Compare(client.ObjectKeyFromObject(a).String(), client.ObjectKeyFromObject(b).String())
+	})
+	return policies
+}
+
 // String representation of the topology
 // This is not designed to be a serialization format that could be deserialized
 func (k *TopologyIndexes) String() string {
@@ -143,6 +201,27 @@ func buildGatewayPoliciesIndex(t *Topology) map[client.ObjectKey][]Policy {
 	return index
 }
 
+func buildPolicyGatewaysIndex(gatewayPolicies map[client.ObjectKey][]Policy) map[client.ObjectKey][]client.ObjectKey {
+	// Build Policy -> []Gateway index out of the Gateway -> []Policy one
+	index := make(map[client.ObjectKey][]client.ObjectKey, 0)
+	for gatewayKey, policies := range gatewayPolicies {
+		for _, policy := range policies {
+			policyKey := client.ObjectKeyFromObject(policy)
+			if !slices.Contains(index[policyKey], gatewayKey) {
+				index[policyKey] = append(index[policyKey], gatewayKey)
+			}
+		}
+	}
+
+	for _, gatewayKeys := range index {
+		slices.SortFunc(gatewayKeys, func(a, b client.ObjectKey) int {
+			return strings.Compare(a.String(), b.String())
+		})
+	}
+
+	return index
+}
+
 func buildPolicyRouteIndex(t *Topology) map[client.ObjectKey]*gatewayapiv1.HTTPRoute {
 	// Build Policy -> HTTPRoute index with the route targeted by the indexed policy
 	index := make(map[client.ObjectKey]*gatewayapiv1.HTTPRoute, 0)
@@ -173,3 +252,15 @@ func buildUntargetedRoutesIndex(t *Topology) map[client.ObjectKey][]*gatewayapiv
 
 	return index
 }
+
+func buildPolicyGatewayIndex(t *Topology) map[client.ObjectKey]client.ObjectKey {
+	// Build Policy -> Gateway index with the gateway targeted by the indexed policy
+	index := make(map[client.ObjectKey]client.ObjectKey, 0)
+	for _, gatewayNode := range t.Gateways() {
+		for _, policy := range gatewayNode.AttachedPolicies() {
+			index[client.ObjectKeyFromObject(policy)] = gatewayNode.ObjectKey()
+		}
+	}
+
+	return index
+}
@@ -10,6 +10,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
 
+	"github.com/kuadrant/kuadrant-operator/pkg/library/utils"
 	"github.com/kuadrant/kuadrant-operator/pkg/log"
 )
 
@@ -165,6 +166,54 @@ func TestTopologyIndexes_PoliciesFromGateway(t *testing.T) {
 	})
 }
 
+func TestTopologyIndexes_GatewaysFromPolicy(t *testing.T) {
+	t.Run("unknown policy", func(subT *testing.T) {
+		t, err := NewTopology(WithLogger(log.NewLogger()))
+		assert.NilError(subT, err)
+		topologyIndexes := NewTopologyIndexes(t)
+
+		gw1 := testBasicGateway("gw1", NS)
+		assert.Equal(subT, len(topologyIndexes.GatewaysFromPolicy(testBasicGatewayPolicy("policy1", NS, gw1))), 0)
+	})
+
+	t.Run("policies targeting gateways and routes with multiple parent gateways", func(subT *testing.T) {
+		// route1 -> gw1
+		// route1 -> gw2
+		// route2 -> gw2
+		// policy1 -> route1
+		// policy2 -> gw2
+
+		gw1 := testBasicGateway("gw1", NS)
+		gw2 := testBasicGateway("gw2", NS)
+		gateways := []*gatewayapiv1.Gateway{gw2, gw1}
+
+		route1 := testBasicRoute("route1", NS, gw1, gw2)
+		route2 := testBasicRoute("route2", NS, gw2)
+		routes := []*gatewayapiv1.HTTPRoute{route1, route2}
+
+		routePolicy := testBasicRoutePolicy("policy1", NS, route1)
+		gwPolicy := testBasicGatewayPolicy("policy2", NS, gw2)
+		policies := []Policy{routePolicy, gwPolicy}
+
+		t, err := NewTopology(
+			WithGateways(gateways),
+			WithRoutes(routes),
+			WithPolicies(policies),
+			WithLogger(log.NewLogger()),
+		)
+		assert.NilError(subT, err)
+		topologyIndexes := NewTopologyIndexes(t)
+
+		assert.DeepEqual(subT, topologyIndexes.GatewaysFromPolicy(routePolicy), []client.ObjectKey{
+			client.ObjectKeyFromObject(gw1),
+			client.ObjectKeyFromObject(gw2),
+		})
+		assert.DeepEqual(subT, topologyIndexes.GatewaysFromPolicy(gwPolicy), []client.ObjectKey{
+			client.ObjectKeyFromObject(gw2),
+		})
+	})
+}
+
 func TestTopologyIndexes_GetPolicyHTTPRoute(t *testing.T) {
 	t.Run("empty topology", func(subT *testing.T) {
 		// policy1 -> route1
@@ -340,6 +389,96 @@ func TestTopologyIndexes_GetUntargetedRoutes(t *testing.T) {
 	})
 }
 
+func TestTopologyIndexes_IsPolicyOverridden(t *testing.T) {
+	t.Run("gateway without routes", func(subT *testing.T) {
+		// gw1
+		// policy1 -> gw1
+		gw1 := testBasicGateway("gw1", NS)
+		gatewayPolicy := testBasicGatewayPolicy("policy1", NS, gw1)
+
+		t, err := NewTopology(
+			WithGateways([]*gatewayapiv1.Gateway{gw1}),
+			WithPolicies([]Policy{gatewayPolicy}),
+			WithLogger(log.NewLogger()),
+		)
+		assert.NilError(subT, err)
+		topologyIndexes := NewTopologyIndexes(t)
+
+		assert.Assert(subT, topologyIndexes.IsPolicyOverridden(gatewayPolicy))
+		assert.Equal(subT, len(topologyIndexes.OverridingPolicies(gatewayPolicy)), 0)
+	})
+
+	t.Run("all routes have policies", func(subT *testing.T) {
+		// gw1
+		// route 1 -> gw1
+		// route 2 -> gw1
+		// policy1 -> gw1
+		// policy2 -> route1
+		// policy3 -> route2
+		gw1 := testBasicGateway("gw1", NS)
+		route1 := testBasicRoute("route1", NS, gw1)
+		route2 := testBasicRoute("route2", NS, gw1)
+		gatewayPolicy := testBasicGatewayPolicy("policy1", NS, gw1)
+		routePolicy1 := testBasicRoutePolicy("policy2", NS, route1)
+		routePolicy2 := testBasicRoutePolicy("policy3", NS, route2)
+
+		t, err := NewTopology(
+			WithGateways([]*gatewayapiv1.Gateway{gw1}),
+			WithRoutes([]*gatewayapiv1.HTTPRoute{route1, route2}),
+			WithPolicies([]Policy{gatewayPolicy, routePolicy1, routePolicy2}),
+			WithLogger(log.NewLogger()),
+		)
+		assert.NilError(subT, err)
+		topologyIndexes := NewTopologyIndexes(t)
+
+		assert.Assert(subT, topologyIndexes.IsPolicyOverridden(gatewayPolicy))
+		assert.Assert(subT, !topologyIndexes.IsPolicyOverridden(routePolicy1))
+		assert.Assert(subT, !topologyIndexes.IsPolicyOverridden(routePolicy2))
+		assert.DeepEqual(subT,
+			utils.Map(topologyIndexes.OverridingPolicies(gatewayPolicy), func(p Policy) client.ObjectKey { return client.ObjectKeyFromObject(p) }),
+			[]client.ObjectKey{client.ObjectKeyFromObject(routePolicy1), client.ObjectKeyFromObject(routePolicy2)},
+		)
+	})
+
+	t.Run("only one route is untargeted", func(subT *testing.T) {
+		// gw1
+		// route 1 -> gw1
+		// route 2 -> gw1
+		// policy1 -> gw1
+		// policy2 -> route1
+		gw1 := testBasicGateway("gw1", NS)
+		route1 := testBasicRoute("route1", NS, gw1)
+		route2 := testBasicRoute("route2", NS, gw1)
+		gatewayPolicy := testBasicGatewayPolicy("policy1", NS, gw1)
+		routePolicy1 := testBasicRoutePolicy("policy2", NS, route1)
+
+		t, err := NewTopology(
+			WithGateways([]*gatewayapiv1.Gateway{gw1}),
+			WithRoutes([]*gatewayapiv1.HTTPRoute{route1, route2}),
+			WithPolicies([]Policy{gatewayPolicy, routePolicy1}),
+			WithLogger(log.NewLogger()),
+		)
+		assert.NilError(subT, err)
+		topologyIndexes := NewTopologyIndexes(t)
+
+		assert.Assert(subT, !topologyIndexes.IsPolicyOverridden(gatewayPolicy))
+	})
+
+	t.Run("gateway not found", func(subT *testing.T) {
+		gw1 := testBasicGateway("gw1", NS)
+		gatewayPolicy := testBasicGatewayPolicy("policy1", NS, gw1)
+
+		t, err := NewTopology(
+			WithPolicies([]Policy{gatewayPolicy}),
+			WithLogger(log.NewLogger()),
+		)
+		assert.NilError(subT, err)
+		topologyIndexes := NewTopologyIndexes(t)
+
+		assert.Assert(subT, !topologyIndexes.IsPolicyOverridden(gatewayPolicy))
+	})
+}
+
 func TestTopologyIndexes_TopologyString(t *testing.T) {
 	t.Run("empty topology", func(subT *testing.T) {
 		t, err := NewTopology(WithLogger(log.NewLogger()))
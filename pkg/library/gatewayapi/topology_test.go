@@ -239,3 +239,54 @@ func TestGatewayAPITopology_RouteNode_AttachedPolicies(t *testing.T) {
 	assert.Assert(t, ok, "expected route not found")
 	assert.Equal(t, len(route2Node.AttachedPolicies()), 0)
 }
+
+func TestGatewayAPITopology_WithPolicyFilter(t *testing.T) {
+	gw1 := testBasicGateway("gw1", NS)
+	route1 := testBasicRoute("route1", NS, gw1)
+	gwPolicy := testBasicGatewayPolicy("gw-policy", NS, gw1)
+	routePolicy := testBasicRoutePolicy("route-policy", NS, route1)
+
+	topology, err := NewTopology(
+		WithLogger(log.NewLogger()),
+		WithGateways([]*gatewayapiv1.Gateway{gw1}),
+		WithRoutes([]*gatewayapiv1.HTTPRoute{route1}),
+		WithPolicies([]Policy{gwPolicy, routePolicy}),
+	)
+	assert.NilError(t, err)
+
+	onlyGatewayPolicies := topology.WithPolicyFilter(func(p Policy) bool { return IsTargetRefGateway(p.GetTargetRef()) })
+
+	assert.Assert(t, len(onlyGatewayPolicies.Gateways()) == 1)
+	assert.Assert(t, len(onlyGatewayPolicies.Gateways()[0].AttachedPolicies()) == 1)
+	assert.Assert(t, len(onlyGatewayPolicies.Gateways()[0].Routes()) == 1)
+	assert.Assert(t, len(onlyGatewayPolicies.Gateways()[0].Routes()[0].AttachedPolicies()) == 0)
+	assert.Assert(t, len(onlyGatewayPolicies.Routes()) == 1)
+	assert.Assert(t, len(onlyGatewayPolicies.Routes()[0].AttachedPolicies()) == 0)
+
+	nothing := onlyGatewayPolicies.WithPolicyFilter(func(p Policy) bool { return IsTargetRefHTTPRoute(p.GetTargetRef()) })
+	assert.Assert(t, len(nothing.Gateways()[0].AttachedPolicies()) == 0)
+
+	// the original topology is not affected
+	assert.Assert(t, len(topology.Routes()[0].AttachedPolicies()) == 1)
+}
+
+func TestGatewayAPITopology_Memoize(t *testing.T) {
+	topology, err := NewTopology(WithLogger(log.NewLogger()), WithGateways([]*gatewayapiv1.Gateway{testBasicGateway("gw1", NS)}))
+	assert.NilError(t, err)
+
+	builds := 0
+	build := func() any {
+		builds++
+		return builds
+	}
+
+	assert.Equal(t, topology.Memoize("key", build), 1)
+	assert.Equal(t, topology.Memoize("key", build), 1)
+	// views do not share the memoized values, as they depend on the policy filter
+	view := topology.WithPolicyFilter(func(Policy) bool { return false })
+	assert.Equal(t, view.Memoize("key", build), 2)
+	assert.Equal(t, view.Memoize("key", build), 2)
+	assert.Equal(t, topology.Memoize("key", build), 1)
+	assert.Equal(t, topology.WithPolicyFilter(func(Policy) bool { return true }).Memoize("key", build), 3)
+	assert.Equal(t, builds, 3)
+}
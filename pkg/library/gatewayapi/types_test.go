@@ -18,6 +18,11 @@ type TestPolicy struct {
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
 	TargetRef gatewayapiv1alpha2.PolicyTargetReference `json:"targetRef"`
+	Status    TestPolicyStatus                         `json:"status,omitempty"`
+}
+
+type TestPolicyStatus struct {
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 var (
@@ -49,6 +54,12 @@ func (p *TestPolicy) DeepCopyInto(out *TestPolicy) {
 	out.TypeMeta = p.TypeMeta
 	p.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	p.TargetRef.DeepCopyInto(&out.TargetRef)
+	if p.Status.Conditions != nil {
+		out.Status.Conditions = make([]metav1.Condition, len(p.Status.Conditions))
+		for i := range p.Status.Conditions {
+			p.Status.Conditions[i].DeepCopyInto(&out.Status.Conditions[i])
+		}
+	}
 }
 
 func TestPolicyByCreationTimestamp(t *testing.T) {
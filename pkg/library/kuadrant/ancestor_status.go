@@ -0,0 +1,215 @@
+package kuadrant
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	kuadrantgatewayapi "github.com/kuadrant/kuadrant-operator/pkg/library/gatewayapi"
+)
+
+const (
+	// ControllerName is the name of the controller writing the ancestor statuses of the kuadrant policies
+	ControllerName gatewayapiv1.GatewayController = "kuadrant.io/policy-controller"
+
+	// MaxPolicyAncestors is the max number of ancestor statuses a policy can hold, as defined by GEP-713
+	MaxPolicyAncestors = 16
+)
+
+// AncestorsGetter is implemented by the policies that report their GEP-713 ancestor statuses
+type AncestorsGetter interface {
+	GetAncestors() []gatewayapiv1alpha2.PolicyAncestorStatus
+}
+
+// AncestorGatewayKeys returns the keys of the gateways listed in the ancestor statuses written by the kuadrant controller
+func AncestorGatewayKeys(ancestors []gatewayapiv1alpha2.PolicyAncestorStatus) []client.ObjectKey {
+	keys := make([]client.ObjectKey, 0)
+	for _, ancestor := range ancestors {
+		if ancestor.ControllerName != ControllerName || ptr.Deref(ancestor.AncestorRef.Kind, "Gateway") != "Gateway" {
+			continue
+		}
+		key := client.ObjectKey{Namespace: string(ptr.Deref(ancestor.AncestorRef.Namespace, "")), Name: string(ancestor.AncestorRef.Name)}
+		if !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// AncestorStatuses computes the status of the policy with respect to each gateway (and listener) it affects,
+// as defined by GEP-713 (https://gateway-api.sigs.k8s.io/geps/gep-713/#standard-status-struct).
+// The gateways are the ones in the hierarchy of the target network object of the policy. If the target network object
+// is a route attached to specific listeners of a gateway, one ancestor status is computed per listener.
+//
+// Each ancestor status carries:
+// * Accepted condition: computed out of the spec error, and false if the targeted route is not accepted by the gateway
+// * Enforced condition: false if the gateway is not referencing the policy yet; otherwise, the enforced condition
+// given as input, or unknown when nil
+//
+// Conditions of the current ancestor statuses are preserved when unchanged, and ancestor statuses written by other
+// controllers are kept untouched, as long as the list fits within MaxPolicyAncestors. Otherwise, the ancestor statuses
+// of the kuadrant controller take precedence, and the stalest ones of other controllers are dropped.
+func AncestorStatuses(p Policy, targetNetworkObject client.Object, gateways []GatewayWrapper, specErr error, enforced *metav1.Condition, current []gatewayapiv1alpha2.PolicyAncestorStatus) []gatewayapiv1alpha2.PolicyAncestorStatus {
+	ancestors := make([]gatewayapiv1alpha2.PolicyAncestorStatus, 0)
+
+	sortedGateways := slices.Clone(gateways)
+	sort.Slice(sortedGateways, func(i, j int) bool {
+		return sortedGateways[i].Key().String() < sortedGateways[j].Key().String()
+	})
+
+	for _, gw := range sortedGateways {
+		for _, sectionName := range ancestorSectionNames(targetNetworkObject, gw.Gateway) {
+			ancestorRef := gatewayapiv1alpha2.ParentReference{
+				Group:       ptr.To(gatewayapiv1.Group(gatewayapiv1.GroupName)),
+				Kind:        ptr.To(gatewayapiv1.Kind("Gateway")),
+				Namespace:   ptr.To(gatewayapiv1.Namespace(gw.Namespace)),
+				Name:        gatewayapiv1.ObjectName(gw.Name),
+				SectionName: sectionName,
+			}
+
+			var conditions []metav1.Condition
+			if currentAncestor := findAncestorStatus(current, ancestorRef); currentAncestor != nil {
+				conditions = slices.Clone(currentAncestor.Conditions)
+			}
+
+			accepted := ancestorAcceptedCondition(p, targetNetworkObject, gw.Gateway, specErr)
+			meta.SetStatusCondition(&conditions, *accepted)
+
+			if accepted.Status == metav1.ConditionTrue {
+				meta.SetStatusCondition(&conditions, *ancestorEnforcedCondition(p, gw, enforced))
+			} else {
+				meta.RemoveStatusCondition(&conditions, string(PolicyConditionEnforced))
+			}
+
+			ancestors = append(ancestors, gatewayapiv1alpha2.PolicyAncestorStatus{
+				AncestorRef:    ancestorRef,
+				ControllerName: ControllerName,
+				Conditions:     conditions,
+			})
+		}
+	}
+
+	if len(ancestors) > MaxPolicyAncestors {
+		ancestors = ancestors[:MaxPolicyAncestors]
+	}
+
+	others := slices.DeleteFunc(slices.Clone(current), func(a gatewayapiv1alpha2.PolicyAncestorStatus) bool {
+		return a.ControllerName == ControllerName
+	})
+	return append(freshestAncestorStatuses(others, MaxPolicyAncestors-len(ancestors)), ancestors...)
+}
+
+// freshestAncestorStatuses returns at most limit ancestor statuses, dropping the ones whose conditions have been
+// transitioned the longest time ago. The order of the ancestor statuses is preserved.
+func freshestAncestorStatuses(ancestors []gatewayapiv1alpha2.PolicyAncestorStatus, limit int) []gatewayapiv1alpha2.PolicyAncestorStatus {
+	if len(ancestors) <= limit {
+		return ancestors
+	}
+
+	byFreshness := make([]int, len(ancestors))
+	for idx := range ancestors {
+		byFreshness[idx] = idx
+	}
+	sort.SliceStable(byFreshness, func(i, j int) bool {
+		iTime, jTime := lastTransitionTime(ancestors[byFreshness[i]]), lastTransitionTime(ancestors[byFreshness[j]])
+		return jTime.Before(&iTime)
+	})
+	kept := byFreshness[:limit]
+	slices.Sort(kept)
+
+	freshest := make([]gatewayapiv1alpha2.PolicyAncestorStatus, 0, limit)
+	for _, idx := range kept {
+		freshest = append(freshest, ancestors[idx])
+	}
+	return freshest
+}
+
+// lastTransitionTime returns the latest transition time of the conditions of the ancestor status
+func lastTransitionTime(ancestor gatewayapiv1alpha2.PolicyAncestorStatus) metav1.Time {
+	var latest metav1.Time
+	for _, condition := range ancestor.Conditions {
+		if latest.Before(&condition.LastTransitionTime) {
+			latest = condition.LastTransitionTime
+		}
+	}
+	return latest
+}
+
+// ancestorSectionNames returns the listeners of the gateway the route is attached to, or a single nil section name
+// if the target network object is attached to the gateway as a whole
+func ancestorSectionNames(targetNetworkObject client.Object, gw *gatewayapiv1.Gateway) []*gatewayapiv1.SectionName {
+	route, ok := targetNetworkObject.(*gatewayapiv1.HTTPRoute)
+	if !ok {
+		return []*gatewayapiv1.SectionName{nil}
+	}
+
+	sectionNames := make([]*gatewayapiv1.SectionName, 0)
+	for _, parentRef := range route.Spec.ParentRefs {
+		if !kuadrantgatewayapi.IsParentGateway(parentRef) ||
+			string(parentRef.Name) != gw.Name ||
+			string(ptr.Deref(parentRef.Namespace, gatewayapiv1.Namespace(route.Namespace))) != gw.Namespace {
+			continue
+		}
+		if parentRef.SectionName == nil {
+			// attached to all the listeners of the gateway
+			return []*gatewayapiv1.SectionName{nil}
+		}
+		sectionNames = append(sectionNames, parentRef.SectionName)
+	}
+
+	if len(sectionNames) == 0 {
+		return []*gatewayapiv1.SectionName{nil}
+	}
+
+	return sectionNames
+}
+
+func findAncestorStatus(ancestors []gatewayapiv1alpha2.PolicyAncestorStatus, ancestorRef gatewayapiv1alpha2.ParentReference) *gatewayapiv1alpha2.PolicyAncestorStatus {
+	for idx := range ancestors {
+		ancestor := ancestors[idx]
+		if ancestor.ControllerName != ControllerName {
+			continue
+		}
+		ref := ancestor.AncestorRef
+		if ref.Name == ancestorRef.Name &&
+			ptr.Deref(ref.Namespace, "") == ptr.Deref(ancestorRef.Namespace, "") &&
+			ptr.Deref(ref.SectionName, "") == ptr.Deref(ancestorRef.SectionName, "") {
+			return &ancestors[idx]
+		}
+	}
+	return nil
+}
+
+func ancestorAcceptedCondition(p Policy, targetNetworkObject client.Object, gw *gatewayapiv1.Gateway, specErr error) *metav1.Condition {
+	if specErr == nil {
+		if route, ok := targetNetworkObject.(*gatewayapiv1.HTTPRoute); ok &&
+			!slices.Contains(kuadrantgatewayapi.GetRouteAcceptedGatewayParentKeys(route), client.ObjectKeyFromObject(gw)) {
+			specErr = NewErrTargetNotFound(p.Kind(), p.GetTargetRef(),
+				fmt.Errorf("httproute %s not accepted by gateway %s", client.ObjectKeyFromObject(route), client.ObjectKeyFromObject(gw)))
+		}
+	}
+	return AcceptedCondition(p, specErr)
+}
+
+func ancestorEnforcedCondition(p Policy, gw GatewayWrapper, enforced *metav1.Condition) *metav1.Condition {
+	if !gw.ContainsPolicy(client.ObjectKeyFromObject(p)) {
+		return EnforcedCondition(p, NewErrUnknown(p.Kind(), errors.New("policy not yet referenced by the gateway")), false)
+	}
+	if enforced == nil {
+		return &metav1.Condition{
+			Type:    string(PolicyConditionEnforced),
+			Status:  metav1.ConditionUnknown,
+			Reason:  string(PolicyReasonUnknown),
+			Message: fmt.Sprintf("%s enforcement is unknown", p.Kind()),
+		}
+	}
+	return enforced.DeepCopy()
+}
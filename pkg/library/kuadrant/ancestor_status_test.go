@@ -0,0 +1,171 @@
+//go:build unit
+
+package kuadrant
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+func TestAncestorStatuses(t *testing.T) {
+	policy := &FakePolicy{
+		Object: &metav1.PartialObjectMetadata{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app-ns", Name: "policy-1"},
+		},
+	}
+
+	gateway := func(name string, withPolicyRef bool) GatewayWrapper {
+		gw := &gatewayapiv1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "gw-ns", Name: name},
+		}
+		if withPolicyRef {
+			gw.Annotations = map[string]string{"kuadrant.io/testpolicies": `[{"Namespace":"app-ns","Name":"policy-1"}]`}
+		}
+		return GatewayWrapper{Gateway: gw, Referrer: &PolicyKindStub{}}
+	}
+
+	route := func(parentRefs ...gatewayapiv1.ParentReference) *gatewayapiv1.HTTPRoute {
+		r := &gatewayapiv1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app-ns", Name: "route-1"},
+			Spec: gatewayapiv1.HTTPRouteSpec{
+				CommonRouteSpec: gatewayapiv1.CommonRouteSpec{ParentRefs: parentRefs},
+			},
+		}
+		for _, parentRef := range parentRefs {
+			r.Status.Parents = append(r.Status.Parents, gatewayapiv1.RouteParentStatus{
+				ParentRef: parentRef,
+				Conditions: []metav1.Condition{
+					{Type: string(gatewayapiv1.RouteConditionAccepted), Status: metav1.ConditionTrue},
+				},
+			})
+		}
+		return r
+	}
+
+	parentRef := func(gwName string, sectionName *gatewayapiv1.SectionName) gatewayapiv1.ParentReference {
+		return gatewayapiv1.ParentReference{
+			Namespace:   ptr.To(gatewayapiv1.Namespace("gw-ns")),
+			Name:        gatewayapiv1.ObjectName(gwName),
+			SectionName: sectionName,
+		}
+	}
+
+	t.Run("one ancestor per gateway sorted by key", func(subT *testing.T) {
+		ancestors := AncestorStatuses(policy, route(parentRef("gw-b", nil), parentRef("gw-a", nil)), []GatewayWrapper{gateway("gw-b", true), gateway("gw-a", true)}, nil, nil, nil)
+		assert.Equal(subT, len(ancestors), 2)
+		assert.Equal(subT, string(ancestors[0].AncestorRef.Name), "gw-a")
+		assert.Equal(subT, string(ancestors[1].AncestorRef.Name), "gw-b")
+		for _, ancestor := range ancestors {
+			assert.Equal(subT, ancestor.ControllerName, ControllerName)
+			assert.Assert(subT, meta.IsStatusConditionTrue(ancestor.Conditions, string(gatewayapiv1alpha2.PolicyConditionAccepted)))
+			// no enforced condition given as input
+			enforced := meta.FindStatusCondition(ancestor.Conditions, string(PolicyConditionEnforced))
+			assert.Assert(subT, enforced != nil)
+			assert.Equal(subT, enforced.Status, metav1.ConditionUnknown)
+		}
+	})
+
+	t.Run("one ancestor per listener", func(subT *testing.T) {
+		ancestors := AncestorStatuses(policy, route(parentRef("gw-a", ptr.To(gatewayapiv1.SectionName("http"))), parentRef("gw-a", ptr.To(gatewayapiv1.SectionName("https")))), []GatewayWrapper{gateway("gw-a", true)}, nil, nil, nil)
+		assert.Equal(subT, len(ancestors), 2)
+		assert.Equal(subT, string(ptr.Deref(ancestors[0].AncestorRef.SectionName, "")), "http")
+		assert.Equal(subT, string(ptr.Deref(ancestors[1].AncestorRef.SectionName, "")), "https")
+	})
+
+	t.Run("gateway not referencing the policy yet", func(subT *testing.T) {
+		gw := gateway("gw-a", false)
+		ancestors := AncestorStatuses(policy, gw.Gateway, []GatewayWrapper{gw}, nil, nil, nil)
+		assert.Equal(subT, len(ancestors), 1)
+		assert.Assert(subT, ancestors[0].AncestorRef.SectionName == nil)
+		assert.Assert(subT, meta.IsStatusConditionTrue(ancestors[0].Conditions, string(gatewayapiv1alpha2.PolicyConditionAccepted)))
+		assert.Assert(subT, meta.IsStatusConditionFalse(ancestors[0].Conditions, string(PolicyConditionEnforced)))
+	})
+
+	t.Run("route not accepted by the gateway", func(subT *testing.T) {
+		r := route(parentRef("gw-a", nil))
+		r.Status.Parents = nil
+		ancestors := AncestorStatuses(policy, r, []GatewayWrapper{gateway("gw-a", true)}, nil, nil, nil)
+		assert.Equal(subT, len(ancestors), 1)
+		accepted := meta.FindStatusCondition(ancestors[0].Conditions, string(gatewayapiv1alpha2.PolicyConditionAccepted))
+		assert.Assert(subT, accepted != nil)
+		assert.Equal(subT, accepted.Status, metav1.ConditionFalse)
+		assert.Equal(subT, accepted.Reason, string(gatewayapiv1alpha2.PolicyReasonTargetNotFound))
+		assert.Assert(subT, meta.FindStatusCondition(ancestors[0].Conditions, string(PolicyConditionEnforced)) == nil)
+	})
+
+	t.Run("enforced condition given as input", func(subT *testing.T) {
+		enforced := EnforcedCondition(policy, NewErrUnknown(policy.Kind(), errors.New("wasm config not synced")), false)
+		gw := gateway("gw-a", true)
+		ancestors := AncestorStatuses(policy, gw.Gateway, []GatewayWrapper{gw}, nil, enforced, nil)
+		assert.Equal(subT, len(ancestors), 1)
+		assert.Assert(subT, meta.IsStatusConditionFalse(ancestors[0].Conditions, string(PolicyConditionEnforced)))
+
+		ancestors = AncestorStatuses(policy, gw.Gateway, []GatewayWrapper{gw}, nil, EnforcedCondition(policy, nil, true), nil)
+		assert.Assert(subT, meta.IsStatusConditionTrue(ancestors[0].Conditions, string(PolicyConditionEnforced)))
+	})
+
+	t.Run("ancestors of other controllers are preserved", func(subT *testing.T) {
+		other := gatewayapiv1alpha2.PolicyAncestorStatus{
+			AncestorRef:    gatewayapiv1alpha2.ParentReference{Name: "gw-a"},
+			ControllerName: "example.com/other-controller",
+		}
+		stale := gatewayapiv1alpha2.PolicyAncestorStatus{
+			AncestorRef:    gatewayapiv1alpha2.ParentReference{Name: "gw-old"},
+			ControllerName: ControllerName,
+		}
+		gw := gateway("gw-a", true)
+		ancestors := AncestorStatuses(policy, gw.Gateway, []GatewayWrapper{gw}, nil, nil, []gatewayapiv1alpha2.PolicyAncestorStatus{other, stale})
+		assert.Equal(subT, len(ancestors), 2)
+		assert.Equal(subT, ancestors[0].ControllerName, other.ControllerName)
+		assert.Equal(subT, string(ancestors[1].AncestorRef.Name), "gw-a")
+	})
+
+	t.Run("max ancestors", func(subT *testing.T) {
+		gateways := make([]GatewayWrapper, 0)
+		for i := 0; i < MaxPolicyAncestors+4; i++ {
+			gateways = append(gateways, gateway(string(rune('a'+i)), true))
+		}
+		ancestors := AncestorStatuses(policy, nil, gateways, nil, nil, nil)
+		assert.Equal(subT, len(ancestors), MaxPolicyAncestors)
+	})
+
+	t.Run("max ancestors with ancestors of other controllers", func(subT *testing.T) {
+		now := metav1.Now()
+		current := make([]gatewayapiv1alpha2.PolicyAncestorStatus, 0)
+		for i := 0; i < MaxPolicyAncestors; i++ {
+			current = append(current, gatewayapiv1alpha2.PolicyAncestorStatus{
+				AncestorRef:    gatewayapiv1alpha2.ParentReference{Name: gatewayapiv1.ObjectName(fmt.Sprintf("other-%02d", i))},
+				ControllerName: "example.com/other-controller",
+				Conditions: []metav1.Condition{
+					{Type: "Accepted", Status: metav1.ConditionTrue, LastTransitionTime: metav1.NewTime(now.Add(time.Duration(i) * time.Minute))},
+				},
+			})
+		}
+		gateways := []GatewayWrapper{gateway("gw-a", true), gateway("gw-b", true), gateway("gw-c", true)}
+
+		ancestors := AncestorStatuses(policy, nil, gateways, nil, nil, current)
+		assert.Equal(subT, len(ancestors), MaxPolicyAncestors)
+		names := make([]string, 0, len(ancestors))
+		for _, ancestor := range ancestors {
+			names = append(names, string(ancestor.AncestorRef.Name))
+		}
+		// the stalest ancestors of the other controller are dropped, and the kuadrant ones are all kept
+		assert.DeepEqual(subT, names[:MaxPolicyAncestors-3], []string{
+			"other-03", "other-04", "other-05", "other-06", "other-07", "other-08", "other-09",
+			"other-10", "other-11", "other-12", "other-13", "other-14", "other-15",
+		})
+		assert.DeepEqual(subT, names[MaxPolicyAncestors-3:], []string{"gw-a", "gw-b", "gw-c"})
+		for _, ancestor := range ancestors[MaxPolicyAncestors-3:] {
+			assert.Equal(subT, ancestor.ControllerName, ControllerName)
+		}
+	})
+}
@@ -6,13 +6,9 @@ import (
 	"fmt"
 	"slices"
 	"sort"
-	"sync"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
 	gatewayapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
-
-	"github.com/kuadrant/kuadrant-operator/pkg/library/gatewayapi"
 )
 
 const (
@@ -23,41 +19,6 @@ const (
 	PolicyReasonUnknown    gatewayapiv1alpha2.PolicyConditionReason = "Unknown"
 )
 
-func NewOverriddenPolicyMap() *OverriddenPolicyMap {
-	return &OverriddenPolicyMap{
-		policies: make(map[types.UID]bool),
-	}
-}
-
-type OverriddenPolicyMap struct {
-	policies map[types.UID]bool
-	mu       sync.RWMutex
-}
-
-// SetOverriddenPolicy sets the provided Policy as overridden in the tracking map.
-func (o *OverriddenPolicyMap) SetOverriddenPolicy(p Policy) {
-	o.mu.Lock()
-	defer o.mu.Unlock()
-
-	if o.policies == nil {
-		o.policies = make(map[types.UID]bool)
-	}
-	o.policies[p.GetUID()] = true
-}
-
-// RemoveOverriddenPolicy removes the provided Policy from the tracking map of overridden policies.
-func (o *OverriddenPolicyMap) RemoveOverriddenPolicy(p Policy) {
-	o.mu.Lock()
-	defer o.mu.Unlock()
-
-	delete(o.policies, p.GetUID())
-}
-
-// IsPolicyOverridden checks if the provided Policy is overridden based on the tracking map maintained.
-func (o *OverriddenPolicyMap) IsPolicyOverridden(p Policy) bool {
-	return o.policies[p.GetUID()] && gatewayapi.IsTargetRefGateway(p.GetTargetRef())
-}
-
 // ConditionMarshal marshals the set of conditions as a JSON array, sorted by condition type.
 func ConditionMarshal(conditions []metav1.Condition) ([]byte, error) {
 	condCopy := slices.Clone(conditions)
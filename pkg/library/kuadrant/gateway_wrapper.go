@@ -1,20 +1,33 @@
 package kuadrant
 
 import (
-	"encoding/json"
+	"context"
 	"slices"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	kuadrantgatewayapi "github.com/kuadrant/kuadrant-operator/pkg/library/gatewayapi"
-	"github.com/kuadrant/kuadrant-operator/pkg/library/utils"
 )
 
-// GatewayWrapper wraps a Gateway API Gateway adding methods and configs to manage policy references in annotations
+// GatewayWrapper wraps a Gateway API Gateway adding methods and configs to resolve the policies that target the gateway
 type GatewayWrapper struct {
 	*gatewayapiv1.Gateway
 	Referrer
+
+	// policyRefs are the keys of the policies resolved out of a back reference index by NewGatewayWrapper
+	policyRefs []client.ObjectKey
+	resolved   bool
+}
+
+// NewGatewayWrapper wraps a gateway resolving the policies of the kind of the referrer that directly or indirectly
+// target the gateway out of the back reference index
+func NewGatewayWrapper(ctx context.Context, index BackReferenceIndex, gw *gatewayapiv1.Gateway, referrer Referrer) (GatewayWrapper, error) {
+	policyRefs, err := BackReferencesFromIndex(ctx, index, gw, referrer)
+	if err != nil {
+		return GatewayWrapper{}, err
+	}
+	return GatewayWrapper{Gateway: gw, Referrer: referrer, policyRefs: policyRefs, resolved: true}, nil
 }
 
 func (g GatewayWrapper) Key() client.ObjectKey {
@@ -24,109 +37,23 @@ func (g GatewayWrapper) Key() client.ObjectKey {
 	return client.ObjectKeyFromObject(g.Gateway)
 }
 
+// PolicyRefs returns the keys of the policies that directly or indirectly target the gateway, as resolved by
+// NewGatewayWrapper, or out of the legacy back reference annotations of the gateway otherwise
 func (g GatewayWrapper) PolicyRefs() []client.ObjectKey {
 	if g.Gateway == nil {
 		return make([]client.ObjectKey, 0)
 	}
-
-	gwAnnotations := utils.ReadAnnotationsFromObject(g)
-
-	val, ok := gwAnnotations[g.BackReferenceAnnotationName()]
-	if !ok {
-		return make([]client.ObjectKey, 0)
+	if g.resolved {
+		return g.policyRefs
 	}
-
-	refs := BackReferencesFromObject(g.Gateway, g.Referrer)
-
-	err := json.Unmarshal([]byte(val), &refs)
-	if err != nil {
-		return make([]client.ObjectKey, 0)
-	}
-
-	return refs
+	return BackReferencesFromAnnotations(g.Gateway, g.Referrer)
 }
 
 func (g GatewayWrapper) ContainsPolicy(policyKey client.ObjectKey) bool {
 	if g.Gateway == nil {
 		return false
 	}
-	refs := BackReferencesFromObject(g.Gateway, g.Referrer)
-	return slices.Contains(refs, policyKey)
-}
-
-// AddPolicy tries to add a policy to the existing ref list.
-// Returns true if policy was added, false otherwise
-func (g GatewayWrapper) AddPolicy(policyKey client.ObjectKey) bool {
-	if g.Gateway == nil {
-		return false
-	}
-
-	// annotation exists and contains a back reference to the policy → nothing to do
-	if g.ContainsPolicy(policyKey) {
-		return false
-	}
-
-	gwAnnotations := utils.ReadAnnotationsFromObject(g)
-	_, annotationFound := gwAnnotations[g.BackReferenceAnnotationName()]
-
-	// annotation does not exist → create it
-	if !annotationFound {
-		refs := []client.ObjectKey{policyKey}
-		serialized, err := json.Marshal(refs)
-		if err != nil {
-			return false
-		}
-		gwAnnotations[g.BackReferenceAnnotationName()] = string(serialized)
-		g.SetAnnotations(gwAnnotations)
-		return true
-	}
-
-	// annotation exists and does not contain a back reference to the policy → add the policy to it
-	refs := append(BackReferencesFromObject(g.Gateway, g.Referrer), policyKey)
-	serialized, err := json.Marshal(refs)
-	if err != nil {
-		return false
-	}
-	gwAnnotations[g.BackReferenceAnnotationName()] = string(serialized)
-	g.SetAnnotations(gwAnnotations)
-	return true
-}
-
-// DeletePolicy tries to delete a policy from the existing ref list.
-// Returns true if the policy was deleted, false otherwise
-func (g GatewayWrapper) DeletePolicy(policyKey client.ObjectKey) bool {
-	if g.Gateway == nil {
-		return false
-	}
-
-	gwAnnotations := utils.ReadAnnotationsFromObject(g)
-
-	// annotation does not exist → nothing to do
-	refsAsStr, annotationFound := gwAnnotations[g.BackReferenceAnnotationName()]
-	if !annotationFound {
-		return false
-	}
-
-	var refs []client.ObjectKey
-	err := json.Unmarshal([]byte(refsAsStr), &refs)
-	if err != nil {
-		return false
-	}
-
-	// annotation exists and contains a back reference to the policy → remove the policy from it
-	if idx := slices.Index(refs, policyKey); idx >= 0 {
-		refs = append(refs[:idx], refs[idx+1:]...)
-		serialized, err := json.Marshal(refs)
-		if err != nil {
-			return false
-		}
-		gwAnnotations[g.BackReferenceAnnotationName()] = string(serialized)
-		g.SetAnnotations(gwAnnotations)
-		return true
-	}
-
-	// annotation exists and does not contain a back reference the policy → nothing to do
-	return false
+	return slices.Contains(g.PolicyRefs(), policyKey)
 }
 
 // Hostnames builds a list of hostnames from the listeners.
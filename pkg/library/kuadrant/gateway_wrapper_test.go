@@ -3,6 +3,8 @@
 package kuadrant
 
 import (
+	"context"
+	"errors"
 	"slices"
 	"testing"
 
@@ -75,49 +77,42 @@ func TestGatewayWrapperContainsPolicy(t *testing.T) {
 	}
 }
 
-func TestGatewayWrapperAddPolicy(t *testing.T) {
-	gateway := gatewayapiv1.Gateway{
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace:   "gw-ns",
-			Name:        "gw-1",
-			Annotations: map[string]string{"kuadrant.io/testpolicies": `[{"Namespace":"app-ns","Name":"policy-1"},{"Namespace":"app-ns","Name":"policy-2"}]`},
-		},
-	}
-	gw := GatewayWrapper{
-		Gateway:  &gateway,
-		Referrer: &PolicyKindStub{},
-	}
-	if gw.AddPolicy(client.ObjectKey{Namespace: "app-ns", Name: "policy-1"}) {
-		t.Error("GatewayWrapper.AddPolicy() expected to return false")
-	}
-	if !gw.AddPolicy(client.ObjectKey{Namespace: "app-ns", Name: "policy-3"}) {
-		t.Error("GatewayWrapper.AddPolicy() expected to return true")
-	}
-	if gw.Annotations["kuadrant.io/testpolicies"] != `[{"Namespace":"app-ns","Name":"policy-1"},{"Namespace":"app-ns","Name":"policy-2"},{"Namespace":"app-ns","Name":"policy-3"}]` {
-		t.Error("GatewayWrapper.AddPolicy() expected to have added policy ref to the annotations")
+type backReferenceIndexStub map[client.ObjectKey][]client.ObjectKey
+
+func (i backReferenceIndexStub) BackReferences(_ context.Context, obj client.Object, _ Referrer) ([]client.ObjectKey, error) {
+	refs, ok := i[client.ObjectKeyFromObject(obj)]
+	if !ok {
+		return nil, errors.New("index not available")
 	}
+	return refs, nil
 }
 
-func TestGatewayDeletePolicy(t *testing.T) {
-	gateway := gatewayapiv1.Gateway{
+func TestGatewayWrapperPolicyRefsFromIndex(t *testing.T) {
+	index := backReferenceIndexStub{
+		{Namespace: "gw-ns", Name: "gw-1"}: {{Namespace: "app-ns", Name: "policy-3"}},
+	}
+
+	gw, err := NewGatewayWrapper(context.TODO(), index, &gatewayapiv1.Gateway{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace:   "gw-ns",
 			Name:        "gw-1",
-			Annotations: map[string]string{"kuadrant.io/testpolicies": `[{"Namespace":"app-ns","Name":"policy-1"},{"Namespace":"app-ns","Name":"policy-2"}]`},
+			Annotations: map[string]string{"kuadrant.io/testpolicies": `[{"Namespace":"app-ns","Name":"policy-1"}]`},
 		},
+	}, &PolicyKindStub{})
+	if err != nil {
+		t.Fatal(err)
 	}
-	gw := GatewayWrapper{
-		Gateway:  &gateway,
-		Referrer: &PolicyKindStub{},
-	}
-	if !gw.DeletePolicy(client.ObjectKey{Namespace: "app-ns", Name: "policy-1"}) {
-		t.Error("GatewayWrapper.DeletePolicy() expected to return true")
+	// legacy annotations are ignored when an index is set
+	if gw.ContainsPolicy(client.ObjectKey{Namespace: "app-ns", Name: "policy-1"}) {
+		t.Error("GatewayWrapper.ContainsPolicy() should not contain app-ns/policy-1")
 	}
-	if gw.DeletePolicy(client.ObjectKey{Namespace: "app-ns", Name: "policy-3"}) {
-		t.Error("GatewayWrapper.DeletePolicy() expected to return false")
+	if !gw.ContainsPolicy(client.ObjectKey{Namespace: "app-ns", Name: "policy-3"}) {
+		t.Error("GatewayWrapper.ContainsPolicy() should contain app-ns/policy-3")
 	}
-	if gw.Annotations["kuadrant.io/testpolicies"] != `[{"Namespace":"app-ns","Name":"policy-2"}]` {
-		t.Error("GatewayWrapper.DeletePolicy() expected to have deleted policy ref from the annotations")
+
+	// errors of the index are not hidden
+	if _, err := NewGatewayWrapper(context.TODO(), index, &gatewayapiv1.Gateway{ObjectMeta: metav1.ObjectMeta{Namespace: "gw-ns", Name: "gw-2"}}, &PolicyKindStub{}); err == nil {
+		t.Error("NewGatewayWrapper() should fail when the index fails")
 	}
 }
 
@@ -162,7 +157,7 @@ func TestBackReferencesFromGatewayWrapper(t *testing.T) {
 		},
 		Referrer: &PolicyKindStub{},
 	}
-	refs := utils.Map(BackReferencesFromObject(gw.Gateway, gw.Referrer), func(ref client.ObjectKey) string { return ref.String() })
+	refs := utils.Map(BackReferencesFromAnnotations(gw.Gateway, gw.Referrer), func(ref client.ObjectKey) string { return ref.String() })
 	if !slices.Contains(refs, "app-ns/policy-1") {
 		t.Error("GatewayWrapper.PolicyRefs() should contain app-ns/policy-1")
 	}
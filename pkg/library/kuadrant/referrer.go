@@ -1,6 +1,7 @@
 package kuadrant
 
 import (
+	"context"
 	"encoding/json"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -11,14 +12,38 @@ import (
 type Referrer interface {
 	// Kind returns the kind of the referrer object, typically a Kuadrant Policy kind.
 	Kind() string
-	// BackReferenceAnnotationName returns the name of the annotation in a target reference object that contains the back references to the referrer objects.
+	// BackReferenceAnnotationName returns the name of the legacy annotation in a target reference object that used to contain the back references to the referrer objects.
 	BackReferenceAnnotationName() string
 	// DirectReferenceAnnotationName return the name of the annotation for direct reference
 	DirectReferenceAnnotationName() string
 }
 
+// BackReferenceIndex resolves the referrer objects that directly or indirectly target a network object,
+// without relying on back references stored in the network object.
+type BackReferenceIndex interface {
+	BackReferences(ctx context.Context, obj client.Object, referrer Referrer) ([]client.ObjectKey, error)
+}
+
+// BackReferencesFromIndex returns the names of the policies that target an object, directly or indirectly.
+// The back references are resolved out of the index. If the index is nil, they are read from the legacy annotations
+// of the object.
+func BackReferencesFromIndex(ctx context.Context, index BackReferenceIndex, obj client.Object, referrer Referrer) ([]client.ObjectKey, error) {
+	if index != nil {
+		return index.BackReferences(ctx, obj, referrer)
+	}
+
+	return BackReferencesFromAnnotations(obj, referrer), nil
+}
+
 // BackReferencesFromObject returns the names of the policies listed in the annotations of a target ref object.
+//
+// Deprecated: the back reference annotations are no longer kept up to date. Use BackReferencesFromIndex instead.
 func BackReferencesFromObject(obj client.Object, referrer Referrer) []client.ObjectKey {
+	return BackReferencesFromAnnotations(obj, referrer)
+}
+
+// BackReferencesFromAnnotations returns the names of the policies listed in the legacy back reference annotation of an object.
+func BackReferencesFromAnnotations(obj client.Object, referrer Referrer) []client.ObjectKey {
 	backRefs, found := utils.ReadAnnotationsFromObject(obj)[referrer.BackReferenceAnnotationName()]
 	if !found {
 		return make([]client.ObjectKey, 0)
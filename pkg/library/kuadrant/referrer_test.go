@@ -3,6 +3,7 @@
 package kuadrant
 
 import (
+	"context"
 	"slices"
 	"testing"
 
@@ -13,7 +14,7 @@ import (
 	"github.com/kuadrant/kuadrant-operator/pkg/library/utils"
 )
 
-func TestBackReferencesFromObject(t *testing.T) {
+func TestBackReferencesFromIndex(t *testing.T) {
 	obj := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace:   "gw-ns",
@@ -25,7 +26,12 @@ func TestBackReferencesFromObject(t *testing.T) {
 
 	policyKind := &PolicyKindStub{}
 
-	refs := utils.Map(BackReferencesFromObject(obj, policyKind), func(ref client.ObjectKey) string { return ref.String() })
+	// without an index, the back references are read from the legacy annotations
+	backRefs, err := BackReferencesFromIndex(context.TODO(), nil, obj, policyKind)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refs := utils.Map(backRefs, func(ref client.ObjectKey) string { return ref.String() })
 	if !slices.Contains(refs, "app-ns/policy-1") {
 		t.Error("GatewayWrapper.PolicyRefs() should contain app-ns/policy-1")
 	}
@@ -36,3 +42,18 @@ func TestBackReferencesFromObject(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestBackReferencesFromObject(t *testing.T) {
+	obj := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "gw-ns",
+			Name:        "gw-1",
+			Annotations: map[string]string{"kuadrant.io/testpolicies": `[{"Namespace":"app-ns","Name":"policy-1"}]`},
+		},
+	}
+
+	refs := utils.Map(BackReferencesFromObject(obj, &PolicyKindStub{}), func(ref client.ObjectKey) string { return ref.String() }) //nolint:staticcheck // deprecated but still supported
+	if !slices.Equal(refs, []string{"app-ns/policy-1"}) {
+		t.Errorf("unexpected back references: %v", refs)
+	}
+}
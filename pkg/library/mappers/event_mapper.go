@@ -1,6 +1,8 @@
 package mappers
 
 import (
+	"context"
+
 	"github.com/go-logr/logr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -10,7 +12,7 @@ import (
 )
 
 type EventMapper interface {
-	MapToPolicy(client.Object, kuadrant.Referrer) []reconcile.Request
+	MapToPolicy(context.Context, client.Object, kuadrant.Referrer) []reconcile.Request
 }
 
 // options
@@ -28,13 +30,22 @@ func WithClient(cl client.Client) MapperOption {
 	})
 }
 
+// WithBackReferenceIndex sets the index used to resolve the policies that target the network objects.
+// If not set, the policies are read from the legacy back reference annotations of the network objects.
+func WithBackReferenceIndex(index kuadrant.BackReferenceIndex) MapperOption {
+	return newFuncMapperOption(func(o *MapperOptions) {
+		o.BackReferenceIndex = index
+	})
+}
+
 type MapperOption interface {
 	apply(*MapperOptions)
 }
 
 type MapperOptions struct {
-	Logger logr.Logger
-	Client client.Client
+	Logger             logr.Logger
+	Client             client.Client
+	BackReferenceIndex kuadrant.BackReferenceIndex
 }
 
 var defaultMapperOptions = MapperOptions{
@@ -1,6 +1,7 @@
 package mappers
 
 import (
+	"context"
 	"fmt"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -20,7 +21,7 @@ type gatewayEventMapper struct {
 	opts MapperOptions
 }
 
-func (m *gatewayEventMapper) MapToPolicy(obj client.Object, policyKind kuadrant.Referrer) []reconcile.Request {
+func (m *gatewayEventMapper) MapToPolicy(ctx context.Context, obj client.Object, policyKind kuadrant.Referrer) []reconcile.Request {
 	logger := m.opts.Logger.WithValues("gateway", client.ObjectKeyFromObject(obj))
 
 	gateway, ok := obj.(*gatewayapiv1.Gateway)
@@ -29,9 +30,15 @@ func (m *gatewayEventMapper) MapToPolicy(obj client.Object, policyKind kuadrant.
 		return []reconcile.Request{}
 	}
 
+	policyKeys, err := kuadrant.BackReferencesFromIndex(ctx, m.opts.BackReferenceIndex, gateway, policyKind)
+	if err != nil {
+		logger.Error(err, "cannot map gateway related event to kuadrant policy")
+		return []reconcile.Request{}
+	}
+
 	requests := make([]reconcile.Request, 0)
 
-	for _, policyKey := range kuadrant.BackReferencesFromObject(gateway, policyKind) {
+	for _, policyKey := range policyKeys {
 		logger.V(1).Info("kuadrant policy possibly affected by the gateway related event found", policyKind.Kind(), policyKey)
 		requests = append(requests, reconcile.Request{NamespacedName: policyKey})
 	}
@@ -3,6 +3,7 @@
 package mappers
 
 import (
+	"context"
 	"testing"
 
 	"gotest.tools/assert"
@@ -18,19 +19,19 @@ func TestNewGatewayEventMapper(t *testing.T) {
 	em := NewGatewayEventMapper(WithLogger(log.NewLogger()))
 
 	t.Run("not gateway related event", func(subT *testing.T) {
-		requests := em.MapToPolicy(&gatewayapiv1.HTTPRoute{}, &kuadrant.PolicyKindStub{})
+		requests := em.MapToPolicy(context.TODO(), &gatewayapiv1.HTTPRoute{}, &kuadrant.PolicyKindStub{})
 		assert.DeepEqual(subT, []reconcile.Request{}, requests)
 	})
 
 	t.Run("gateway related event - no requests", func(subT *testing.T) {
-		requests := em.MapToPolicy(&gatewayapiv1.Gateway{}, &kuadrant.PolicyKindStub{})
+		requests := em.MapToPolicy(context.TODO(), &gatewayapiv1.Gateway{}, &kuadrant.PolicyKindStub{})
 		assert.DeepEqual(subT, []reconcile.Request{}, requests)
 	})
 
 	t.Run("gateway related event - requests", func(subT *testing.T) {
 		gateway := &gatewayapiv1.Gateway{}
 		gateway.SetAnnotations(map[string]string{"kuadrant.io/testpolicies": `[{"Namespace":"app-ns","Name":"policy-1"},{"Namespace":"app-ns","Name":"policy-2"}]`})
-		requests := em.MapToPolicy(gateway, &kuadrant.PolicyKindStub{})
+		requests := em.MapToPolicy(context.TODO(), gateway, &kuadrant.PolicyKindStub{})
 		expected := []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "app-ns", Name: "policy-1"}}, {NamespacedName: types.NamespacedName{Namespace: "app-ns", Name: "policy-2"}}}
 		assert.DeepEqual(subT, expected, requests)
 	})
@@ -1,6 +1,7 @@
 package mappers
 
 import (
+	"context"
 	"fmt"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -20,7 +21,7 @@ type httpRouteEventMapper struct {
 	opts MapperOptions
 }
 
-func (m *httpRouteEventMapper) MapToPolicy(obj client.Object, policyKind kuadrant.Referrer) []reconcile.Request {
+func (m *httpRouteEventMapper) MapToPolicy(ctx context.Context, obj client.Object, policyKind kuadrant.Referrer) []reconcile.Request {
 	logger := m.opts.Logger.WithValues("httproute", client.ObjectKeyFromObject(obj))
 
 	httpRoute, ok := obj.(*gatewayapiv1.HTTPRoute)
@@ -29,9 +30,15 @@ func (m *httpRouteEventMapper) MapToPolicy(obj client.Object, policyKind kuadran
 		return []reconcile.Request{}
 	}
 
+	policyKeys, err := kuadrant.BackReferencesFromIndex(ctx, m.opts.BackReferenceIndex, httpRoute, policyKind)
+	if err != nil {
+		logger.Error(err, "cannot map httproute event to kuadrant policy")
+		return []reconcile.Request{}
+	}
+
 	requests := make([]reconcile.Request, 0)
 
-	for _, policyKey := range kuadrant.BackReferencesFromObject(httpRoute, policyKind) {
+	for _, policyKey := range policyKeys {
 		logger.V(1).Info("kuadrant policy possibly affected by the httproute related event found", policyKind.Kind(), policyKey)
 		requests = append(requests, reconcile.Request{NamespacedName: policyKey})
 	}
@@ -3,6 +3,7 @@
 package mappers
 
 import (
+	"context"
 	"testing"
 
 	"gotest.tools/assert"
@@ -18,19 +19,19 @@ func TestNewHTTPRouteEventMapper(t *testing.T) {
 	em := NewHTTPRouteEventMapper(WithLogger(log.NewLogger()))
 
 	t.Run("not http route related event", func(subT *testing.T) {
-		requests := em.MapToPolicy(&gatewayapiv1.Gateway{}, &kuadrant.PolicyKindStub{})
+		requests := em.MapToPolicy(context.TODO(), &gatewayapiv1.Gateway{}, &kuadrant.PolicyKindStub{})
 		assert.DeepEqual(subT, []reconcile.Request{}, requests)
 	})
 
 	t.Run("http route related event - no requests", func(subT *testing.T) {
-		requests := em.MapToPolicy(&gatewayapiv1.HTTPRoute{}, &kuadrant.PolicyKindStub{})
+		requests := em.MapToPolicy(context.TODO(), &gatewayapiv1.HTTPRoute{}, &kuadrant.PolicyKindStub{})
 		assert.DeepEqual(subT, []reconcile.Request{}, requests)
 	})
 
 	t.Run("http related event - requests", func(subT *testing.T) {
 		httpRoute := &gatewayapiv1.HTTPRoute{}
 		httpRoute.SetAnnotations(map[string]string{"kuadrant.io/testpolicies": `[{"Namespace":"app-ns","Name":"policy-1"},{"Namespace":"app-ns","Name":"policy-2"}]`})
-		requests := em.MapToPolicy(httpRoute, &kuadrant.PolicyKindStub{})
+		requests := em.MapToPolicy(context.TODO(), httpRoute, &kuadrant.PolicyKindStub{})
 		expected := []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "app-ns", Name: "policy-1"}}, {NamespacedName: types.NamespacedName{Namespace: "app-ns", Name: "policy-2"}}}
 		assert.DeepEqual(subT, expected, requests)
 	})
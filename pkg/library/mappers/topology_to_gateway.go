@@ -0,0 +1,42 @@
+package mappers
+
+import (
+	"context"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	kuadrantgatewayapi "github.com/kuadrant/kuadrant-operator/pkg/library/gatewayapi"
+)
+
+// TopologyToParentGatewaysEventMapper is an EventHandler that maps events of any object of the topology
+// (gateways, httproutes and policies) to events of the gateways affected by the object
+type TopologyToParentGatewaysEventMapper struct {
+	opts         MapperOptions
+	routeMapper  *HTTPRouteToParentGatewaysEventMapper
+	policyMapper *PolicyToParentGatewaysEventMapper
+}
+
+func NewTopologyToParentGatewaysEventMapper(o ...MapperOption) *TopologyToParentGatewaysEventMapper {
+	return &TopologyToParentGatewaysEventMapper{
+		opts:         Apply(o...),
+		routeMapper:  NewHTTPRouteToParentGatewaysEventMapper(o...),
+		policyMapper: NewPolicyToParentGatewaysEventMapper(o...),
+	}
+}
+
+func (m *TopologyToParentGatewaysEventMapper) Map(ctx context.Context, obj client.Object) []reconcile.Request {
+	switch obj.(type) {
+	case *gatewayapiv1.Gateway:
+		return []reconcile.Request{{NamespacedName: client.ObjectKeyFromObject(obj)}}
+	case *gatewayapiv1.HTTPRoute:
+		return m.routeMapper.Map(ctx, obj)
+	case kuadrantgatewayapi.Policy:
+		return m.policyMapper.Map(ctx, obj)
+	default:
+		m.opts.Logger.Error(fmt.Errorf("%T is not part of the topology", obj), "cannot map")
+		return []reconcile.Request{}
+	}
+}
@@ -0,0 +1,77 @@
+package reconcilers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/kuadrant/kuadrant-operator/pkg/library/kuadrant"
+	"github.com/kuadrant/kuadrant-operator/pkg/library/utils"
+)
+
+var _ manager.LeaderElectionRunnable = &BackReferenceAnnotationsMigration{}
+
+// BackReferenceAnnotationsMigration strips from the gateways the legacy annotations that used to store the back
+// references to the policies. Back references are resolved out of field indexes instead.
+// It runs once, when the manager is elected leader.
+type BackReferenceAnnotationsMigration struct {
+	client      client.Client
+	logger      logr.Logger
+	annotations []string
+}
+
+func NewBackReferenceAnnotationsMigration(k8sClient client.Client, logger logr.Logger, referrers ...kuadrant.Referrer) *BackReferenceAnnotationsMigration {
+	return &BackReferenceAnnotationsMigration{
+		client:      k8sClient,
+		logger:      logger,
+		annotations: utils.Map(referrers, func(r kuadrant.Referrer) string { return r.BackReferenceAnnotationName() }),
+	}
+}
+
+func (m *BackReferenceAnnotationsMigration) NeedLeaderElection() bool {
+	return true
+}
+
+// Start runs the migration. Failures are logged and do not stop the manager; gateways that could not be migrated keep
+// the stale annotations, which are no longer read.
+func (m *BackReferenceAnnotationsMigration) Start(ctx context.Context) error {
+	gwList := &gatewayapiv1.GatewayList{}
+	if err := m.client.List(ctx, gwList); err != nil {
+		m.logger.Error(err, "failed to list gateways")
+		return nil
+	}
+
+	for idx := range gwList.Items {
+		gw := &gwList.Items[idx]
+		base := gw.DeepCopy()
+		if !m.stripAnnotations(gw) {
+			continue
+		}
+		err := m.client.Patch(ctx, gw, client.MergeFrom(base))
+		m.logger.V(1).Info("strip back reference annotations", "gateway", client.ObjectKeyFromObject(gw), "err", err)
+		if err != nil {
+			m.logger.Error(err, "failed to strip back reference annotations", "gateway", client.ObjectKeyFromObject(gw))
+		}
+	}
+
+	return nil
+}
+
+// stripAnnotations removes the legacy annotations from the gateway. Returns true if any annotation was removed
+func (m *BackReferenceAnnotationsMigration) stripAnnotations(gw *gatewayapiv1.Gateway) bool {
+	annotations := gw.GetAnnotations()
+	stripped := false
+	for _, annotation := range m.annotations {
+		if _, ok := annotations[annotation]; ok {
+			delete(annotations, annotation)
+			stripped = true
+		}
+	}
+	if stripped {
+		gw.SetAnnotations(annotations)
+	}
+	return stripped
+}
@@ -0,0 +1,49 @@
+//go:build unit
+
+package reconcilers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"gotest.tools/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/kuadrant/kuadrant-operator/pkg/library/kuadrant"
+)
+
+func TestBackReferenceAnnotationsMigration(t *testing.T) {
+	s := runtime.NewScheme()
+	assert.NilError(t, gatewayapiv1.AddToScheme(s))
+
+	gw1 := &gatewayapiv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "gw-ns",
+			Name:      "gw-1",
+			Annotations: map[string]string{
+				"kuadrant.io/testpolicies": `[{"Namespace":"app-ns","Name":"policy-1"}]`,
+				"kuadrant.io/namespace":    "kuadrant-system",
+			},
+		},
+	}
+	gw2 := &gatewayapiv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "gw-ns", Name: "gw-2"},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(gw1, gw2).Build()
+
+	migration := NewBackReferenceAnnotationsMigration(cl, logr.Discard(), &kuadrant.PolicyKindStub{})
+	assert.NilError(t, migration.Start(context.TODO()))
+
+	gw := &gatewayapiv1.Gateway{}
+	assert.NilError(t, cl.Get(context.TODO(), client.ObjectKeyFromObject(gw1), gw))
+	assert.DeepEqual(t, gw.GetAnnotations(), map[string]string{"kuadrant.io/namespace": "kuadrant-system"})
+
+	assert.NilError(t, cl.Get(context.TODO(), client.ObjectKeyFromObject(gw2), gw))
+	assert.Equal(t, len(gw.GetAnnotations()), 0)
+}
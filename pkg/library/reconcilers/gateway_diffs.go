@@ -4,12 +4,18 @@ import (
 	"context"
 	"fmt"
 	"slices"
+	"strings"
 
 	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 
+	kuadrantgatewayapi "github.com/kuadrant/kuadrant-operator/pkg/library/gatewayapi"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/kuadrant"
+	"github.com/kuadrant/kuadrant-operator/pkg/library/utils"
 )
 
 type GatewayDiffs struct {
@@ -23,8 +29,10 @@ type GatewayDiffs struct {
 // * list of gateways to which the policy applies for the first time
 // * list of gateways to which the policy no longer applies
 // * list of gateways to which the policy still applies
-// TODO(@guicassolato): unit test
-func ComputeGatewayDiffs(ctx context.Context, k8sClient client.Reader, policy, targetNetworkObject client.Object) (*GatewayDiffs, error) {
+// The policies that target each gateway are resolved out of the back reference index.
+// Only the gateways in the hierarchy of the target network object and the ones listed as ancestors in the status of the
+// policy are read.
+func ComputeGatewayDiffs(ctx context.Context, k8sClient client.Reader, index kuadrant.BackReferenceIndex, policy, targetNetworkObject client.Object) (*GatewayDiffs, error) {
 	logger, _ := logr.FromContext(ctx)
 
 	var gwKeys []client.ObjectKey
@@ -32,22 +40,47 @@ func ComputeGatewayDiffs(ctx context.Context, k8sClient client.Reader, policy, t
 		gwKeys = targetedGatewayKeys(targetNetworkObject)
 	}
 
-	// TODO(rahulanand16nov): maybe think about optimizing it with a label later
-	allGwList := &gatewayapiv1.GatewayList{}
-	err := k8sClient.List(ctx, allGwList)
-	if err != nil {
-		return nil, err
-	}
-
 	policyKind, ok := policy.(kuadrant.Referrer)
 	if !ok {
 		return nil, fmt.Errorf("policy %s is not a referrer", policy.GetObjectKind().GroupVersionKind())
 	}
 
+	// gateways previously affected by the policy, as reported in the status of the policy
+	var ancestorGwKeys []client.ObjectKey
+	if ancestorsGetter, ok := policy.(kuadrant.AncestorsGetter); ok {
+		ancestorGwKeys = kuadrant.AncestorGatewayKeys(ancestorsGetter.GetAncestors())
+	}
+
+	// only the gateways in the hierarchy of the target network object and the ones reported in the status of the policy
+	// can be affected by the policy or resolve the back reference to it out of the index
+	candidateGwKeys := targetedGatewayKeys(targetNetworkObject)
+	if route, ok := targetNetworkObject.(*gatewayapiv1.HTTPRoute); ok {
+		candidateGwKeys = append(candidateGwKeys, kuadrantgatewayapi.GetRouteAcceptedGatewayParentKeys(route)...)
+	}
+	candidateGwKeys = append(candidateGwKeys, ancestorGwKeys...)
+	slices.SortFunc(candidateGwKeys, func(a, b client.ObjectKey) int { return strings.Compare(a.String(), b.String()) })
+	candidateGwKeys = slices.Compact(candidateGwKeys)
+
+	gateways := make([]kuadrant.GatewayWrapper, 0, len(candidateGwKeys))
+	for _, gwKey := range candidateGwKeys {
+		gateway := &gatewayapiv1.Gateway{}
+		if err := k8sClient.Get(ctx, gwKey, gateway); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		gw, err := kuadrant.NewGatewayWrapper(ctx, index, gateway, policyKind)
+		if err != nil {
+			return nil, err
+		}
+		gateways = append(gateways, gw)
+	}
+
 	gwDiff := &GatewayDiffs{
-		GatewaysMissingPolicyRef:     gatewaysMissingPolicyRef(allGwList, client.ObjectKeyFromObject(policy), gwKeys, policyKind),
-		GatewaysWithValidPolicyRef:   gatewaysWithValidPolicyRef(allGwList, client.ObjectKeyFromObject(policy), gwKeys, policyKind),
-		GatewaysWithInvalidPolicyRef: gatewaysWithInvalidPolicyRef(allGwList, client.ObjectKeyFromObject(policy), gwKeys, policyKind),
+		GatewaysMissingPolicyRef:     gatewaysMissingPolicyRef(gateways, client.ObjectKeyFromObject(policy), gwKeys),
+		GatewaysWithValidPolicyRef:   gatewaysWithValidPolicyRef(gateways, client.ObjectKeyFromObject(policy), gwKeys),
+		GatewaysWithInvalidPolicyRef: gatewaysWithInvalidPolicyRef(gateways, client.ObjectKeyFromObject(policy), gwKeys, ancestorGwKeys),
 	}
 
 	logger.V(1).Info("ComputeGatewayDiffs",
@@ -59,43 +92,27 @@ func ComputeGatewayDiffs(ctx context.Context, k8sClient client.Reader, policy, t
 	return gwDiff, nil
 }
 
-// gatewaysMissingPolicyRef returns gateways referenced by the policy but that miss the reference to it the annotations
-func gatewaysMissingPolicyRef(gwList *gatewayapiv1.GatewayList, policyKey client.ObjectKey, policyGwKeys []client.ObjectKey, policyKind kuadrant.Referrer) []kuadrant.GatewayWrapper {
-	gateways := make([]kuadrant.GatewayWrapper, 0)
-	for i := range gwList.Items {
-		gateway := gwList.Items[i]
-		gw := kuadrant.GatewayWrapper{Gateway: &gateway, Referrer: policyKind}
-		if slices.Contains(policyGwKeys, client.ObjectKeyFromObject(&gateway)) && !gw.ContainsPolicy(policyKey) {
-			gateways = append(gateways, gw)
-		}
-	}
-	return gateways
+// gatewaysMissingPolicyRef returns gateways referenced by the policy but that do not resolve the back reference to it yet
+// (e.g. the targeted route is not accepted by the gateway)
+func gatewaysMissingPolicyRef(gateways []kuadrant.GatewayWrapper, policyKey client.ObjectKey, policyGwKeys []client.ObjectKey) []kuadrant.GatewayWrapper {
+	return utils.Filter(gateways, func(gw kuadrant.GatewayWrapper) bool {
+		return slices.Contains(policyGwKeys, gw.Key()) && !gw.ContainsPolicy(policyKey)
+	})
 }
 
-// gatewaysWithValidPolicyRef returns gateways referenced by the policy that also have the reference in the annotations
-func gatewaysWithValidPolicyRef(gwList *gatewayapiv1.GatewayList, policyKey client.ObjectKey, policyGwKeys []client.ObjectKey, policyKind kuadrant.Referrer) []kuadrant.GatewayWrapper {
-	gateways := make([]kuadrant.GatewayWrapper, 0)
-	for i := range gwList.Items {
-		gateway := gwList.Items[i]
-		gw := kuadrant.GatewayWrapper{Gateway: &gateway, Referrer: policyKind}
-		if slices.Contains(policyGwKeys, client.ObjectKeyFromObject(&gateway)) && gw.ContainsPolicy(policyKey) {
-			gateways = append(gateways, gw)
-		}
-	}
-	return gateways
+// gatewaysWithValidPolicyRef returns gateways referenced by the policy that also resolve the back reference to it
+func gatewaysWithValidPolicyRef(gateways []kuadrant.GatewayWrapper, policyKey client.ObjectKey, policyGwKeys []client.ObjectKey) []kuadrant.GatewayWrapper {
+	return utils.Filter(gateways, func(gw kuadrant.GatewayWrapper) bool {
+		return slices.Contains(policyGwKeys, gw.Key()) && gw.ContainsPolicy(policyKey)
+	})
 }
 
-// gatewaysWithInvalidPolicyRef returns gateways not referenced by the policy that still have the reference in the annotations
-func gatewaysWithInvalidPolicyRef(gwList *gatewayapiv1.GatewayList, policyKey client.ObjectKey, policyGwKeys []client.ObjectKey, policyKind kuadrant.Referrer) []kuadrant.GatewayWrapper {
-	gateways := make([]kuadrant.GatewayWrapper, 0)
-	for i := range gwList.Items {
-		gateway := gwList.Items[i]
-		gw := kuadrant.GatewayWrapper{Gateway: &gateway, Referrer: policyKind}
-		if !slices.Contains(policyGwKeys, client.ObjectKeyFromObject(&gateway)) && gw.ContainsPolicy(policyKey) {
-			gateways = append(gateways, gw)
-		}
-	}
-	return gateways
+// gatewaysWithInvalidPolicyRef returns gateways not referenced by the policy that still resolve the back reference to it,
+// or that are still listed as ancestors in the status of the policy
+func gatewaysWithInvalidPolicyRef(gateways []kuadrant.GatewayWrapper, policyKey client.ObjectKey, policyGwKeys, ancestorGwKeys []client.ObjectKey) []kuadrant.GatewayWrapper {
+	return utils.Filter(gateways, func(gw kuadrant.GatewayWrapper) bool {
+		return !slices.Contains(policyGwKeys, gw.Key()) && (slices.Contains(ancestorGwKeys, gw.Key()) || gw.ContainsPolicy(policyKey))
+	})
 }
 
 // targetedGatewayKeys returns the list of gateways in the hierarchy of a target network object
@@ -121,3 +138,26 @@ func targetedGatewayKeys(targetNetworkObject client.Object) []client.ObjectKey {
 		return []client.ObjectKey{}
 	}
 }
+
+// AffectedGateways returns the gateways in the hierarchy of the target network object of the policy,
+// regardless of whether they already reference the policy or not
+func (d *GatewayDiffs) AffectedGateways() []kuadrant.GatewayWrapper {
+	if d == nil {
+		return nil
+	}
+	return append(slices.Clone(d.GatewaysMissingPolicyRef), d.GatewaysWithValidPolicyRef...)
+}
+
+// PolicyAncestorStatuses computes the GEP-713 ancestor statuses of the policy with respect to the gateways it affects.
+// The current ancestor statuses are returned unchanged if the gateways cannot be computed.
+func PolicyAncestorStatuses(ctx context.Context, k8sClient client.Reader, index kuadrant.BackReferenceIndex, policy kuadrant.Policy, targetNetworkObject client.Object, specErr error, enforced *metav1.Condition, current []gatewayapiv1alpha2.PolicyAncestorStatus) []gatewayapiv1alpha2.PolicyAncestorStatus {
+	logger, _ := logr.FromContext(ctx)
+
+	gatewayDiffObj, err := ComputeGatewayDiffs(ctx, k8sClient, index, policy, targetNetworkObject)
+	if err != nil {
+		logger.Error(err, "failed to compute the ancestor statuses of the policy")
+		return current
+	}
+
+	return kuadrant.AncestorStatuses(policy, targetNetworkObject, gatewayDiffObj.AffectedGateways(), specErr, enforced, current)
+}
@@ -3,19 +3,33 @@
 package reconcilers
 
 import (
+	"context"
+	"fmt"
 	"slices"
 	"testing"
 
 	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 
+	kuadrantv1beta2 "github.com/kuadrant/kuadrant-operator/api/v1beta2"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/kuadrant"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/utils"
 )
 
+func testGatewayWrappers(gwList *gatewayapiv1.GatewayList, policyKind kuadrant.Referrer) []kuadrant.GatewayWrapper {
+	return utils.Map(gwList.Items, func(gw gatewayapiv1.Gateway) kuadrant.GatewayWrapper {
+		return kuadrant.GatewayWrapper{Gateway: &gw, Referrer: policyKind}
+	})
+}
+
 func TestGatewaysMissingPolicyRef(t *testing.T) {
 	gwList := &gatewayapiv1.GatewayList{
 		Items: []gatewayapiv1.Gateway{
@@ -46,10 +60,10 @@ func TestGatewaysMissingPolicyRef(t *testing.T) {
 	policyKind := &kuadrant.PolicyKindStub{}
 	gwName := func(gw kuadrant.GatewayWrapper) string { return gw.Gateway.Name }
 
-	gws = utils.Map(gatewaysMissingPolicyRef(gwList, client.ObjectKey{Namespace: "app-ns", Name: "policy-1"}, []client.ObjectKey{
+	gws = utils.Map(gatewaysMissingPolicyRef(testGatewayWrappers(gwList, policyKind), client.ObjectKey{Namespace: "app-ns", Name: "policy-1"}, []client.ObjectKey{
 		{Namespace: "gw-ns", Name: "gw-2"},
 		{Namespace: "gw-ns", Name: "gw-3"},
-	}, policyKind), gwName)
+	}), gwName)
 
 	if slices.Contains(gws, "gw-1") {
 		t.Error("gateway expected not to be listed as missing policy ref")
@@ -61,9 +75,9 @@ func TestGatewaysMissingPolicyRef(t *testing.T) {
 		t.Error("gateway expected to be listed as missing policy ref")
 	}
 
-	gws = utils.Map(gatewaysMissingPolicyRef(gwList, client.ObjectKey{Namespace: "app-ns", Name: "policy-2"}, []client.ObjectKey{
+	gws = utils.Map(gatewaysMissingPolicyRef(testGatewayWrappers(gwList, policyKind), client.ObjectKey{Namespace: "app-ns", Name: "policy-2"}, []client.ObjectKey{
 		{Namespace: "gw-ns", Name: "gw-1"},
-	}, policyKind), gwName)
+	}), gwName)
 
 	if slices.Contains(gws, "gw-1") {
 		t.Error("gateway expected not to be listed as missing policy ref")
@@ -75,10 +89,10 @@ func TestGatewaysMissingPolicyRef(t *testing.T) {
 		t.Error("gateway expected not to be listed as missing policy ref")
 	}
 
-	gws = utils.Map(gatewaysMissingPolicyRef(gwList, client.ObjectKey{Namespace: "app-ns", Name: "policy-3"}, []client.ObjectKey{
+	gws = utils.Map(gatewaysMissingPolicyRef(testGatewayWrappers(gwList, policyKind), client.ObjectKey{Namespace: "app-ns", Name: "policy-3"}, []client.ObjectKey{
 		{Namespace: "gw-ns", Name: "gw-1"},
 		{Namespace: "gw-ns", Name: "gw-3"},
-	}, policyKind), gwName)
+	}), gwName)
 
 	if !slices.Contains(gws, "gw-1") {
 		t.Error("gateway expected to be listed as missing policy ref")
@@ -121,10 +135,10 @@ func TestGatewaysWithValidPolicyRef(t *testing.T) {
 	policyKind := &kuadrant.PolicyKindStub{}
 	gwName := func(gw kuadrant.GatewayWrapper) string { return gw.Gateway.Name }
 
-	gws = utils.Map(gatewaysWithValidPolicyRef(gwList, client.ObjectKey{Namespace: "app-ns", Name: "policy-1"}, []client.ObjectKey{
+	gws = utils.Map(gatewaysWithValidPolicyRef(testGatewayWrappers(gwList, policyKind), client.ObjectKey{Namespace: "app-ns", Name: "policy-1"}, []client.ObjectKey{
 		{Namespace: "gw-ns", Name: "gw-2"},
 		{Namespace: "gw-ns", Name: "gw-3"},
-	}, policyKind), gwName)
+	}), gwName)
 
 	if slices.Contains(gws, "gw-1") {
 		t.Error("gateway expected not to be listed as with valid policy ref")
@@ -136,9 +150,9 @@ func TestGatewaysWithValidPolicyRef(t *testing.T) {
 		t.Error("gateway expected not to be listed as with valid policy ref")
 	}
 
-	gws = utils.Map(gatewaysWithValidPolicyRef(gwList, client.ObjectKey{Namespace: "app-ns", Name: "policy-2"}, []client.ObjectKey{
+	gws = utils.Map(gatewaysWithValidPolicyRef(testGatewayWrappers(gwList, policyKind), client.ObjectKey{Namespace: "app-ns", Name: "policy-2"}, []client.ObjectKey{
 		{Namespace: "gw-ns", Name: "gw-1"},
-	}, policyKind), gwName)
+	}), gwName)
 
 	if !slices.Contains(gws, "gw-1") {
 		t.Error("gateway expected to be listed as with valid policy ref")
@@ -150,10 +164,10 @@ func TestGatewaysWithValidPolicyRef(t *testing.T) {
 		t.Error("gateway expected not to be listed as with valid policy ref")
 	}
 
-	gws = utils.Map(gatewaysWithValidPolicyRef(gwList, client.ObjectKey{Namespace: "app-ns", Name: "policy-3"}, []client.ObjectKey{
+	gws = utils.Map(gatewaysWithValidPolicyRef(testGatewayWrappers(gwList, policyKind), client.ObjectKey{Namespace: "app-ns", Name: "policy-3"}, []client.ObjectKey{
 		{Namespace: "gw-ns", Name: "gw-1"},
 		{Namespace: "gw-ns", Name: "gw-3"},
-	}, policyKind), gwName)
+	}), gwName)
 
 	if slices.Contains(gws, "gw-1") {
 		t.Error("gateway expected not to be listed as with valid policy ref")
@@ -196,10 +210,10 @@ func TestGatewaysWithInvalidPolicyRef(t *testing.T) {
 	policyKind := &kuadrant.PolicyKindStub{}
 	gwName := func(gw kuadrant.GatewayWrapper) string { return gw.Gateway.Name }
 
-	gws = utils.Map(gatewaysWithInvalidPolicyRef(gwList, client.ObjectKey{Namespace: "app-ns", Name: "policy-1"}, []client.ObjectKey{
+	gws = utils.Map(gatewaysWithInvalidPolicyRef(testGatewayWrappers(gwList, policyKind), client.ObjectKey{Namespace: "app-ns", Name: "policy-1"}, []client.ObjectKey{
 		{Namespace: "gw-ns", Name: "gw-2"},
 		{Namespace: "gw-ns", Name: "gw-3"},
-	}, policyKind), gwName)
+	}, nil), gwName)
 
 	if !slices.Contains(gws, "gw-1") {
 		t.Error("gateway expected to be listed as with invalid policy ref")
@@ -211,9 +225,9 @@ func TestGatewaysWithInvalidPolicyRef(t *testing.T) {
 		t.Error("gateway expected not to be listed as with invalid policy ref")
 	}
 
-	gws = utils.Map(gatewaysWithInvalidPolicyRef(gwList, client.ObjectKey{Namespace: "app-ns", Name: "policy-2"}, []client.ObjectKey{
+	gws = utils.Map(gatewaysWithInvalidPolicyRef(testGatewayWrappers(gwList, policyKind), client.ObjectKey{Namespace: "app-ns", Name: "policy-2"}, []client.ObjectKey{
 		{Namespace: "gw-ns", Name: "gw-1"},
-	}, policyKind), gwName)
+	}, nil), gwName)
 
 	if slices.Contains(gws, "gw-1") {
 		t.Error("gateway expected not to be listed as with invalid policy ref")
@@ -225,10 +239,10 @@ func TestGatewaysWithInvalidPolicyRef(t *testing.T) {
 		t.Error("gateway expected not to be listed as with invalid policy ref")
 	}
 
-	gws = utils.Map(gatewaysWithInvalidPolicyRef(gwList, client.ObjectKey{Namespace: "app-ns", Name: "policy-3"}, []client.ObjectKey{
+	gws = utils.Map(gatewaysWithInvalidPolicyRef(testGatewayWrappers(gwList, policyKind), client.ObjectKey{Namespace: "app-ns", Name: "policy-3"}, []client.ObjectKey{
 		{Namespace: "gw-ns", Name: "gw-1"},
 		{Namespace: "gw-ns", Name: "gw-3"},
-	}, policyKind), gwName)
+	}, nil), gwName)
 
 	if slices.Contains(gws, "gw-1") {
 		t.Error("gateway expected not to be listed as with invalid policy ref")
@@ -239,6 +253,24 @@ func TestGatewaysWithInvalidPolicyRef(t *testing.T) {
 	if slices.Contains(gws, "gw-3") {
 		t.Error("gateway expected not to be listed as with invalid policy ref")
 	}
+
+	// gateways listed as ancestors in the status of the policy
+	gws = utils.Map(gatewaysWithInvalidPolicyRef(testGatewayWrappers(gwList, policyKind), client.ObjectKey{Namespace: "app-ns", Name: "policy-3"}, []client.ObjectKey{
+		{Namespace: "gw-ns", Name: "gw-1"},
+	}, []client.ObjectKey{
+		{Namespace: "gw-ns", Name: "gw-1"},
+		{Namespace: "gw-ns", Name: "gw-3"},
+	}), gwName)
+
+	if slices.Contains(gws, "gw-1") {
+		t.Error("gateway expected not to be listed as with invalid policy ref")
+	}
+	if slices.Contains(gws, "gw-2") {
+		t.Error("gateway expected not to be listed as with invalid policy ref")
+	}
+	if !slices.Contains(gws, "gw-3") {
+		t.Error("gateway expected to be listed as with invalid policy ref")
+	}
 }
 
 func TestTargetedGatewayKeys(t *testing.T) {
@@ -306,3 +338,75 @@ func TestTargetedGatewayKeys(t *testing.T) {
 		t.Fatalf("gwKey value (%+v) does not match expected (%+v)", keys[0], expectedKey)
 	}
 }
+
+type testBackReferenceIndex map[client.ObjectKey][]client.ObjectKey
+
+func (i testBackReferenceIndex) BackReferences(_ context.Context, obj client.Object, _ kuadrant.Referrer) ([]client.ObjectKey, error) {
+	return i[client.ObjectKeyFromObject(obj)], nil
+}
+
+func TestComputeGatewayDiffs(t *testing.T) {
+	gateway := func(name string) *gatewayapiv1.Gateway {
+		return &gatewayapiv1.Gateway{ObjectMeta: metav1.ObjectMeta{Namespace: "gw-ns", Name: name}}
+	}
+	gw1, gw2, gw3, gw4 := gateway("gw-1"), gateway("gw-2"), gateway("gw-3"), gateway("gw-4")
+
+	route := &gatewayapiv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "app-ns", Name: "route"},
+		Spec: gatewayapiv1.HTTPRouteSpec{
+			CommonRouteSpec: gatewayapiv1.CommonRouteSpec{
+				ParentRefs: []gatewayapiv1.ParentReference{
+					{Namespace: ptr.To(gatewayapiv1.Namespace("gw-ns")), Name: "gw-1"},
+					{Namespace: ptr.To(gatewayapiv1.Namespace("gw-ns")), Name: "gw-4"},
+				},
+			},
+		},
+	}
+
+	policyKey := client.ObjectKey{Namespace: "app-ns", Name: "policy"}
+	policy := &kuadrantv1beta2.RateLimitPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: policyKey.Namespace, Name: policyKey.Name},
+		Status: kuadrantv1beta2.RateLimitPolicyStatus{
+			Ancestors: []gatewayapiv1alpha2.PolicyAncestorStatus{{
+				AncestorRef:    gatewayapiv1.ParentReference{Kind: ptr.To(gatewayapiv1.Kind("Gateway")), Namespace: ptr.To(gatewayapiv1.Namespace("gw-ns")), Name: "gw-2"},
+				ControllerName: kuadrant.ControllerName,
+			}},
+		},
+	}
+
+	index := testBackReferenceIndex{
+		client.ObjectKeyFromObject(gw1): {policyKey},
+		// unrelated gateways are never read, even if the index resolved the policy for them
+		client.ObjectKeyFromObject(gw3): {policyKey},
+	}
+
+	s := runtime.NewScheme()
+	if err := gatewayapiv1.Install(s); err != nil {
+		t.Fatal(err)
+	}
+	k8sClient := fake.NewClientBuilder().
+		WithScheme(s).
+		WithObjects(gw1, gw2, gw3, gw4).
+		WithInterceptorFuncs(interceptor.Funcs{
+			List: func(_ context.Context, _ client.WithWatch, list client.ObjectList, _ ...client.ListOption) error {
+				return fmt.Errorf("unexpected list of %T", list)
+			},
+		}).
+		Build()
+
+	gwDiffs, err := ComputeGatewayDiffs(context.TODO(), k8sClient, index, policy, route)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gwName := func(gw kuadrant.GatewayWrapper) string { return gw.Gateway.Name }
+	if names := utils.Map(gwDiffs.GatewaysMissingPolicyRef, gwName); !slices.Equal(names, []string{"gw-4"}) {
+		t.Errorf("unexpected gateways missing policy ref: %v", names)
+	}
+	if names := utils.Map(gwDiffs.GatewaysWithValidPolicyRef, gwName); !slices.Equal(names, []string{"gw-1"}) {
+		t.Errorf("unexpected gateways with valid policy ref: %v", names)
+	}
+	if names := utils.Map(gwDiffs.GatewaysWithInvalidPolicyRef, gwName); !slices.Equal(names, []string{"gw-2"}) {
+		t.Errorf("unexpected gateways with invalid policy ref: %v", names)
+	}
+}
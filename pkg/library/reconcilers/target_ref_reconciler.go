@@ -32,6 +32,10 @@ import (
 
 type TargetRefReconciler struct {
 	client.Client
+
+	// BackReferenceIndex resolves the policies that target the network objects.
+	// If nil, the policies are read from the legacy back reference annotations of the network objects.
+	BackReferenceIndex kuadrant.BackReferenceIndex
 }
 
 // FetchAcceptedGatewayHTTPRoutes returns the list of HTTPRoutes that have been accepted as children of a gateway.
@@ -145,10 +149,10 @@ func (r *TargetRefReconciler) DeleteTargetBackReference(ctx context.Context, tar
 // GetAllGatewayPolicyRefs returns the policy refs of a given policy kind from all gateways managed by kuadrant.
 // The gateway objects are handled in order of creation to mitigate the risk of non-idenpotent reconciliations based on
 // this list of policy refs; nevertheless, the actual order of returned policy refs depends on the order the policy refs
-// appear in the annotations of the gateways.
+// are resolved for each gateway.
 // Only gateways with status programmed are considered.
 func (r *TargetRefReconciler) GetAllGatewayPolicyRefs(ctx context.Context, policyRefsConfig kuadrant.Referrer) ([]client.ObjectKey, error) {
-	var uniquePolicyRefs map[string]struct{}
+	uniquePolicyRefs := make(map[string]struct{})
 	var policyRefs []client.ObjectKey
 
 	gwList := &gatewayapiv1.GatewayList{}
@@ -164,7 +168,11 @@ func (r *TargetRefReconciler) GetAllGatewayPolicyRefs(ctx context.Context, polic
 		if !kuadrant.IsKuadrantManaged(&gateway) || meta.IsStatusConditionFalse(gateway.Status.Conditions, string(gatewayapiv1.GatewayConditionProgrammed)) {
 			continue
 		}
-		gateways = append(gateways, kuadrant.GatewayWrapper{Gateway: &gateway, Referrer: policyRefsConfig})
+		gw, err := kuadrant.NewGatewayWrapper(ctx, r.BackReferenceIndex, &gateway, policyRefsConfig)
+		if err != nil {
+			return nil, err
+		}
+		gateways = append(gateways, gw)
 	}
 	sort.Sort(gateways)
 
@@ -173,39 +181,10 @@ func (r *TargetRefReconciler) GetAllGatewayPolicyRefs(ctx context.Context, polic
 			if _, ok := uniquePolicyRefs[policyRef.String()]; ok {
 				continue
 			}
+			uniquePolicyRefs[policyRef.String()] = struct{}{}
 			policyRefs = append(policyRefs, policyRef)
 		}
 	}
 
 	return policyRefs, nil
 }
-
-// ReconcileGatewayPolicyReferences updates the annotations in the Gateway resources that list to all the policies
-// that directly or indirectly target the gateway, based upon a pre-computed gateway diff object
-func (r *TargetRefReconciler) ReconcileGatewayPolicyReferences(ctx context.Context, policy client.Object, gwDiffObj *GatewayDiffs) error {
-	logger, _ := logr.FromContext(ctx)
-
-	// delete the policy from the annotations of the gateways no longer target by the policy
-	for _, gw := range gwDiffObj.GatewaysWithInvalidPolicyRef {
-		if gw.DeletePolicy(client.ObjectKeyFromObject(policy)) {
-			err := r.Client.Update(ctx, gw.Gateway)
-			logger.V(1).Info("ReconcileGatewayPolicyReferences: update gateway", "gateway with invalid policy ref", gw.Key(), "err", err)
-			if err != nil {
-				return err
-			}
-		}
-	}
-
-	// add the policy to the annotations of the gateways target by the policy
-	for _, gw := range gwDiffObj.GatewaysMissingPolicyRef {
-		if gw.AddPolicy(client.ObjectKeyFromObject(policy)) {
-			err := r.Client.Update(ctx, gw.Gateway)
-			logger.V(1).Info("ReconcileGatewayPolicyReferences: update gateway", "gateway missinf policy ref", gw.Key(), "err", err)
-			if err != nil {
-				return err
-			}
-		}
-	}
-
-	return nil
-}
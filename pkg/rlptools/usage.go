@@ -0,0 +1,164 @@
+package rlptools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+
+	kuadrantv1beta2 "github.com/kuadrant/kuadrant-operator/api/v1beta2"
+	"github.com/kuadrant/kuadrant-operator/pkg/rlptools/wasm"
+)
+
+const (
+	// UsageTopCounters is the max number of counters reported per limit
+	UsageTopCounters = 5
+
+	// UsageMaxLimits is the max number of limits reported in the usage of a policy.
+	// It matches the max items of the usage in the status of the policy, which the limits
+	// expanded from the plans can exceed.
+	UsageMaxLimits = 14
+)
+
+// LimitadorCounter is a counter as returned by the HTTP API of Limitador
+type LimitadorCounter struct {
+	Limit            LimitadorCounterLimit `json:"limit"`
+	SetVariables     map[string]string     `json:"set_variables,omitempty"`
+	Remaining        int                   `json:"remaining"`
+	ExpiresInSeconds int                   `json:"expires_in_seconds"`
+}
+
+// LimitadorCounterLimit is the limit of a counter as returned by the HTTP API of Limitador
+type LimitadorCounterLimit struct {
+	Namespace  string   `json:"namespace"`
+	MaxValue   int      `json:"max_value"`
+	Seconds    int      `json:"seconds"`
+	Conditions []string `json:"conditions"`
+	Variables  []string `json:"variables"`
+}
+
+// LimitadorCountersClient reads the counters from the HTTP API of Limitador
+type LimitadorCountersClient struct {
+	httpClient *http.Client
+}
+
+func NewLimitadorCountersClient(httpClient *http.Client) *LimitadorCountersClient {
+	return &LimitadorCountersClient{httpClient: httpClient}
+}
+
+// Counters returns the counters of the limits of a namespace of Limitador
+func (c *LimitadorCountersClient) Counters(ctx context.Context, baseURL, namespace string) ([]LimitadorCounter, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/counters/%s", strings.TrimSuffix(baseURL, "/"), url.PathEscape(namespace)), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get counters of namespace %s: unexpected status code %d", namespace, resp.StatusCode)
+	}
+
+	counters := make([]LimitadorCounter, 0)
+	if err := json.NewDecoder(resp.Body).Decode(&counters); err != nil {
+		return nil, fmt.Errorf("failed to decode counters of namespace %s: %w", namespace, err)
+	}
+	return counters, nil
+}
+
+// UsageFromCounters aggregates the Limitador counters into the usage of the limits of the policy.
+// Only the topN counters with the highest usage are reported per limit, and only the UsageMaxLimits limits
+// whose top counter has the highest usage are reported. Counters that do not match
+// any of the limits of the policy in any of the namespaces of its limits are ignored.
+func UsageFromCounters(rlp *kuadrantv1beta2.RateLimitPolicy, namespaces []string, counters []LimitadorCounter, topN int) []kuadrantv1beta2.LimitUsage {
+	limitNamesByCondition := make(map[string]string)
+	for name, limit := range rlp.Spec.CommonSpec().LimitsWithPlans() {
+		limitNamesByCondition[limitadorCondition(wasm.LimitIdentifier(name, limit))] = name
+	}
+
+	countersByLimit := make(map[string][]kuadrantv1beta2.CounterUsage)
+	for _, counter := range counters {
+		if !slices.Contains(namespaces, counter.Limit.Namespace) || len(counter.Limit.Conditions) != 1 {
+			continue
+		}
+		name, ok := limitNamesByCondition[counter.Limit.Conditions[0]]
+		if !ok {
+			continue
+		}
+		countersByLimit[name] = append(countersByLimit[name], kuadrantv1beta2.CounterUsage{
+			Namespace: counter.Limit.Namespace,
+			Variables: counter.SetVariables,
+			MaxValue:  counter.Limit.MaxValue,
+			Seconds:   counter.Limit.Seconds,
+			Remaining: counter.Remaining,
+		})
+	}
+
+	if len(countersByLimit) == 0 {
+		return nil
+	}
+
+	usage := make([]kuadrantv1beta2.LimitUsage, 0, len(countersByLimit))
+	for name, limitCounters := range countersByLimit {
+		sort.SliceStable(limitCounters, func(i, j int) bool {
+			return counterUsageLess(limitCounters[i], limitCounters[j])
+		})
+		if len(limitCounters) > topN {
+			limitCounters = limitCounters[:topN]
+		}
+		usage = append(usage, kuadrantv1beta2.LimitUsage{Name: name, Counters: limitCounters})
+	}
+	if len(usage) > UsageMaxLimits {
+		sort.Slice(usage, func(i, j int) bool {
+			if counterUsageLess(usage[i].Counters[0], usage[j].Counters[0]) {
+				return true
+			}
+			if counterUsageLess(usage[j].Counters[0], usage[i].Counters[0]) {
+				return false
+			}
+			return usage[i].Name < usage[j].Name
+		})
+		usage = usage[:UsageMaxLimits]
+	}
+	sort.Slice(usage, func(i, j int) bool { return usage[i].Name < usage[j].Name })
+
+	return usage
+}
+
+// counterUsageLess sorts the counters by usage ratio in descending order.
+// Ties are broken by namespace, time window and variables, so the result is stable across polls.
+func counterUsageLess(a, b kuadrantv1beta2.CounterUsage) bool {
+	// compare (maxValue-remaining)/maxValue without floating point
+	aUsed, bUsed := (a.MaxValue-a.Remaining)*b.MaxValue, (b.MaxValue-b.Remaining)*a.MaxValue
+	if aUsed != bUsed {
+		return aUsed > bUsed
+	}
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	if a.Seconds != b.Seconds {
+		return a.Seconds < b.Seconds
+	}
+	return variablesString(a.Variables) < variablesString(b.Variables)
+}
+
+func variablesString(variables map[string]string) string {
+	keys := make([]string, 0, len(variables))
+	for key := range variables {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", key, variables[key]))
+	}
+	return strings.Join(pairs, ",")
+}
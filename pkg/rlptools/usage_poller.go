@@ -0,0 +1,137 @@
+package rlptools
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	limitadorv1alpha1 "github.com/kuadrant/limitador-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	gatewayapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	kuadrantv1beta2 "github.com/kuadrant/kuadrant-operator/api/v1beta2"
+	"github.com/kuadrant/kuadrant-operator/pkg/common"
+	kuadrantgatewayapi "github.com/kuadrant/kuadrant-operator/pkg/library/gatewayapi"
+	"github.com/kuadrant/kuadrant-operator/pkg/library/kuadrant"
+	"github.com/kuadrant/kuadrant-operator/pkg/rlptools/wasm"
+)
+
+// UsagePoller periodically reads the counters of the limits of the rate limit policies from the HTTP API
+// of Limitador, and reports the counters with the highest usage in the status of the policies.
+// The status of a policy is only patched when the usage changes.
+type UsagePoller struct {
+	client         client.Client
+	topologyCache  *kuadrantgatewayapi.TopologyCache
+	countersClient *LimitadorCountersClient
+	interval       time.Duration
+	logger         logr.Logger
+}
+
+var _ manager.LeaderElectionRunnable = &UsagePoller{}
+
+func NewUsagePoller(cl client.Client, topologyCache *kuadrantgatewayapi.TopologyCache, countersClient *LimitadorCountersClient, interval time.Duration, logger logr.Logger) *UsagePoller {
+	return &UsagePoller{
+		client:         cl,
+		topologyCache:  topologyCache,
+		countersClient: countersClient,
+		interval:       interval,
+		logger:         logger,
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, so only the leader writes the status of the policies
+func (p *UsagePoller) NeedLeaderElection() bool {
+	return true
+}
+
+// Start implements manager.Runnable
+func (p *UsagePoller) Start(ctx context.Context) error {
+	p.logger.Info("starting rate limit usage poller", "interval", p.interval)
+	wait.UntilWithContext(ctx, p.Poll, p.interval)
+	return nil
+}
+
+// Poll reports the usage of the limits of all the accepted rate limit policies
+func (p *UsagePoller) Poll(ctx context.Context) {
+	rlpList := &kuadrantv1beta2.RateLimitPolicyList{}
+	if err := p.client.List(ctx, rlpList); err != nil {
+		p.logger.Error(err, "failed to list ratelimitpolicies")
+		return
+	}
+
+	// the topology resolves the namespaces of the limits scoped to the gateways
+	topology, err := p.topologyCache.Snapshot()
+	if err != nil {
+		p.logger.V(1).Info("failed to get the topology", "error", err.Error())
+		return
+	}
+	topologyIndexes := wasm.TopologyIndexesFromTopology(topology)
+
+	limitadorURLs := make(map[string]string)
+	for idx := range rlpList.Items {
+		rlp := &rlpList.Items[idx]
+		logger := p.logger.WithValues("ratelimitpolicy", client.ObjectKeyFromObject(rlp))
+
+		kuadrantNamespace, isSet := kuadrant.GetKuadrantNamespaceFromPolicy(rlp)
+		if !isSet || rlp.GetDeletionTimestamp() != nil {
+			continue
+		}
+		if !meta.IsStatusConditionTrue(rlp.Status.Conditions, string(gatewayapiv1alpha2.PolicyConditionAccepted)) {
+			continue
+		}
+
+		limitadorURL, ok := limitadorURLs[kuadrantNamespace]
+		if !ok {
+			var err error
+			limitadorURL, err = p.limitadorURL(ctx, kuadrantNamespace)
+			if err != nil {
+				logger.V(1).Info("failed to locate limitador", "error", err.Error())
+			}
+			limitadorURLs[kuadrantNamespace] = limitadorURL
+		}
+		if limitadorURL == "" {
+			continue
+		}
+
+		if err := p.reportUsage(ctx, topologyIndexes, rlp, limitadorURL); err != nil {
+			logger.Error(err, "failed to report rate limit usage")
+		}
+	}
+}
+
+func (p *UsagePoller) reportUsage(ctx context.Context, t *kuadrantgatewayapi.TopologyIndexes, rlp *kuadrantv1beta2.RateLimitPolicy, limitadorURL string) error {
+	namespaces := wasm.LimitsNamespacesFromRLP(t, rlp)
+	counters := make([]LimitadorCounter, 0)
+	for _, namespace := range namespaces {
+		namespaceCounters, err := p.countersClient.Counters(ctx, limitadorURL, namespace)
+		if err != nil {
+			return err
+		}
+		counters = append(counters, namespaceCounters...)
+	}
+
+	usage := UsageFromCounters(rlp, namespaces, counters, UsageTopCounters)
+	if equality.Semantic.DeepEqual(usage, rlp.Status.Usage) {
+		return nil
+	}
+
+	base := rlp.DeepCopy()
+	rlp.Status.Usage = usage
+	return client.IgnoreNotFound(p.client.Status().Patch(ctx, rlp, client.MergeFrom(base)))
+}
+
+func (p *UsagePoller) limitadorURL(ctx context.Context, kuadrantNamespace string) (string, error) {
+	limitador := &limitadorv1alpha1.Limitador{}
+	if err := p.client.Get(ctx, client.ObjectKey{Name: common.LimitadorName, Namespace: kuadrantNamespace}, limitador); err != nil {
+		return "", err
+	}
+	if limitador.Status.Service == nil || limitador.Status.Service.Host == "" {
+		return "", fmt.Errorf("limitador service not ready")
+	}
+	return fmt.Sprintf("http://%s:%d", limitador.Status.Service.Host, limitador.Status.Service.Ports.HTTP), nil
+}
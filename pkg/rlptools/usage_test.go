@@ -0,0 +1,183 @@
+//go:build unit
+
+package rlptools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-logr/logr"
+	limitadorv1alpha1 "github.com/kuadrant/limitador-operator/api/v1alpha1"
+	"gotest.tools/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	kuadrantv1beta2 "github.com/kuadrant/kuadrant-operator/api/v1beta2"
+	"github.com/kuadrant/kuadrant-operator/pkg/common"
+	kuadrantgatewayapi "github.com/kuadrant/kuadrant-operator/pkg/library/gatewayapi"
+	"github.com/kuadrant/kuadrant-operator/pkg/library/kuadrant"
+	"github.com/kuadrant/kuadrant-operator/pkg/library/utils"
+	"github.com/kuadrant/kuadrant-operator/pkg/rlptools/wasm"
+)
+
+func testLimitadorCounter(namespace, limitName string, maxValue, remaining int, variables map[string]string) LimitadorCounter {
+	return LimitadorCounter{
+		Limit: LimitadorCounterLimit{
+			Namespace:  namespace,
+			MaxValue:   maxValue,
+			Seconds:    10,
+			Conditions: []string{limitadorCondition(wasm.LimitNameToLimitadorIdentifier(limitName))},
+		},
+		SetVariables: variables,
+		Remaining:    remaining,
+	}
+}
+
+func TestUsageFromCounters(t *testing.T) {
+	rlp := testRLP_2Limits_1Rate("testNS", "rlpA")
+
+	counters := []LimitadorCounter{
+		testLimitadorCounter("testNS/rlpA", "l1", 10, 8, map[string]string{"user": "alice"}),
+		testLimitadorCounter("testNS/rlpA", "l1", 10, 1, map[string]string{"user": "bob"}),
+		testLimitadorCounter("testNS/rlpA", "l1", 10, 5, map[string]string{"user": "carol"}),
+		testLimitadorCounter("testNS/rlpA", "l2", 10, 3, nil),
+		// unknown limit
+		testLimitadorCounter("testNS/rlpA", "l3", 10, 0, nil),
+		// other policy
+		testLimitadorCounter("testNS/rlpB", "l1", 10, 0, nil),
+	}
+
+	t.Run("top counters per limit", func(subT *testing.T) {
+		usage := UsageFromCounters(rlp, []string{"testNS/rlpA"}, counters, 2)
+		assert.DeepEqual(subT, usage, []kuadrantv1beta2.LimitUsage{
+			{
+				Name: "l1",
+				Counters: []kuadrantv1beta2.CounterUsage{
+					{Namespace: "testNS/rlpA", Variables: map[string]string{"user": "bob"}, MaxValue: 10, Seconds: 10, Remaining: 1},
+					{Namespace: "testNS/rlpA", Variables: map[string]string{"user": "carol"}, MaxValue: 10, Seconds: 10, Remaining: 5},
+				},
+			},
+			{
+				Name: "l2",
+				Counters: []kuadrantv1beta2.CounterUsage{
+					{Namespace: "testNS/rlpA", MaxValue: 10, Seconds: 10, Remaining: 3},
+				},
+			},
+		})
+	})
+
+	t.Run("no counters", func(subT *testing.T) {
+		assert.Assert(subT, UsageFromCounters(rlp, []string{"testNS/rlpA"}, nil, 2) == nil)
+	})
+
+	t.Run("top limits when the limits and the plans exceed the max", func(subT *testing.T) {
+		rlpWithPlans := testRLP_1Limit_1Rate("testNS", "rlpA")
+		rate := []kuadrantv1beta2.Rate{{Limit: 10, Duration: 10, Unit: "second"}}
+		rlpWithPlans.Spec.Limits = make(map[string]kuadrantv1beta2.Limit)
+		planCounters := make([]LimitadorCounter, 0)
+		for i := 0; i < 14; i++ {
+			name := fmt.Sprintf("l%02d", i)
+			rlpWithPlans.Spec.Limits[name] = kuadrantv1beta2.Limit{Rates: rate}
+			remaining := 5
+			if i == 13 {
+				remaining = 8
+			}
+			planCounters = append(planCounters, testLimitadorCounter("testNS/rlpA", name, 10, remaining, nil))
+		}
+		rlpWithPlans.Spec.Plans = &kuadrantv1beta2.Plans{
+			Selector: "auth.identity.plan",
+			Tiers: map[string]kuadrantv1beta2.Plan{
+				"gold": {Rates: rate},
+				"free": {Rates: rate},
+			},
+		}
+		planCounters = append(planCounters,
+			testLimitadorCounter("testNS/rlpA", kuadrantv1beta2.PlanLimitName("gold"), 10, 0, nil),
+			testLimitadorCounter("testNS/rlpA", kuadrantv1beta2.PlanLimitName("free"), 10, 9, nil),
+		)
+
+		usage := UsageFromCounters(rlpWithPlans, []string{"testNS/rlpA"}, planCounters, 2)
+		assert.Equal(subT, len(usage), UsageMaxLimits)
+		names := utils.Map(usage, func(u kuadrantv1beta2.LimitUsage) string { return u.Name })
+		expectedNames := []string{"l00", "l01", "l02", "l03", "l04", "l05", "l06", "l07", "l08", "l09", "l10", "l11", "l12", "plans.gold"}
+		assert.DeepEqual(subT, names, expectedNames)
+	})
+}
+
+func TestUsagePoller(t *testing.T) {
+	counters := map[string][]LimitadorCounter{
+		"testNS/rlpA": {testLimitadorCounter("testNS/rlpA", "l1", 5, 2, map[string]string{"user": "alice"})},
+	}
+	limitadorServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		namespace := r.URL.Path[len("/counters/"):]
+		namespaceCounters, ok := counters[namespace]
+		if !ok {
+			namespaceCounters = []LimitadorCounter{}
+		}
+		_ = json.NewEncoder(w).Encode(namespaceCounters)
+	}))
+	defer limitadorServer.Close()
+
+	host, portStr, err := net.SplitHostPort(limitadorServer.Listener.Addr().String())
+	assert.NilError(t, err)
+	port, err := strconv.Atoi(portStr)
+	assert.NilError(t, err)
+
+	limitador := &limitadorv1alpha1.Limitador{
+		ObjectMeta: metav1.ObjectMeta{Name: common.LimitadorName, Namespace: "kuadrant-system"},
+		Status: limitadorv1alpha1.LimitadorStatus{
+			Service: &limitadorv1alpha1.LimitadorService{
+				Host:  host,
+				Ports: limitadorv1alpha1.Ports{HTTP: int32(port)},
+			},
+		},
+	}
+
+	rlp := testRLP_1Limit_1Rate("testNS", "rlpA")
+	rlp.Annotations = map[string]string{kuadrant.KuadrantNamespaceAnnotation: "kuadrant-system"}
+	rlp.Status.Conditions = []metav1.Condition{{
+		Type:               string(gatewayapiv1alpha2.PolicyConditionAccepted),
+		Status:             metav1.ConditionTrue,
+		Reason:             string(gatewayapiv1alpha2.PolicyReasonAccepted),
+		LastTransitionTime: metav1.Now(),
+	}}
+
+	scheme := runtime.NewScheme()
+	assert.NilError(t, kuadrantv1beta2.AddToScheme(scheme))
+	assert.NilError(t, limitadorv1alpha1.AddToScheme(scheme))
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(limitador, rlp).
+		WithStatusSubresource(rlp).
+		Build()
+
+	poller := NewUsagePoller(cl, kuadrantgatewayapi.NewTopologyCache(logr.Discard(), &kuadrantv1beta2.RateLimitPolicy{}), NewLimitadorCountersClient(limitadorServer.Client()), 0, logr.Discard())
+	ctx := context.Background()
+
+	poller.Poll(ctx)
+
+	updated := &kuadrantv1beta2.RateLimitPolicy{}
+	assert.NilError(t, cl.Get(ctx, client.ObjectKeyFromObject(rlp), updated))
+	assert.DeepEqual(t, updated.Status.Usage, []kuadrantv1beta2.LimitUsage{{
+		Name: "l1",
+		Counters: []kuadrantv1beta2.CounterUsage{
+			{Namespace: "testNS/rlpA", Variables: map[string]string{"user": "alice"}, MaxValue: 5, Seconds: 10, Remaining: 2},
+		},
+	}})
+
+	// counters expired
+	delete(counters, "testNS/rlpA")
+	poller.Poll(ctx)
+
+	assert.NilError(t, cl.Get(ctx, client.ObjectKeyFromObject(rlp), updated))
+	assert.Assert(t, updated.Status.Usage == nil)
+}
@@ -2,65 +2,92 @@ package rlptools
 
 import (
 	"fmt"
+	"slices"
 
 	limitadorv1alpha1 "github.com/kuadrant/limitador-operator/api/v1alpha1"
 
 	kuadrantv1beta2 "github.com/kuadrant/kuadrant-operator/api/v1beta2"
+	kuadrantgatewayapi "github.com/kuadrant/kuadrant-operator/pkg/library/gatewayapi"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/utils"
 	"github.com/kuadrant/kuadrant-operator/pkg/rlptools/wasm"
 )
 
 // LimitadorRateLimitsFromRLP converts rate limits from a Kuadrant RateLimitPolicy into a list of Limitador rate limit
-// objects
-func LimitadorRateLimitsFromRLP(rlp *kuadrantv1beta2.RateLimitPolicy) []limitadorv1alpha1.RateLimit {
-	limitsNamespace := wasm.LimitsNamespaceFromRLP(rlp)
-
+// objects. When the counters are scoped to the gateway, the rate limits are repeated in the namespace of each gateway
+// the policy targets according to the topology.
+// Invalid rates, i.e. with a negative limit or an invalid time window, are skipped, as the policies that specify them
+// are not accepted anyway.
+func LimitadorRateLimitsFromRLP(t *kuadrantgatewayapi.TopologyIndexes, rlp *kuadrantv1beta2.RateLimitPolicy) []limitadorv1alpha1.RateLimit {
 	rateLimits := make([]limitadorv1alpha1.RateLimit, 0)
-	for limitKey, limit := range rlp.Spec.CommonSpec().Limits {
-		limitIdentifier := wasm.LimitNameToLimitadorIdentifier(limitKey)
-		for _, rate := range limit.Rates {
-			maxValue, seconds := rateToSeconds(rate)
-			rateLimits = append(rateLimits, limitadorv1alpha1.RateLimit{
-				Namespace:  limitsNamespace,
-				MaxValue:   maxValue,
-				Seconds:    seconds,
-				Conditions: []string{fmt.Sprintf("%s == \"1\"", limitIdentifier)},
-				Variables:  utils.GetEmptySliceIfNil(limit.CountersAsStringList()),
-				Name:       LimitsNameFromRLP(rlp),
-			})
+	for _, limitsNamespace := range wasm.LimitsNamespacesFromRLP(t, rlp) {
+		for limitKey, limit := range rlp.Spec.CommonSpec().LimitsWithPlans() {
+			limitIdentifier := wasm.LimitIdentifier(limitKey, limit)
+			for _, rate := range limit.Rates {
+				maxValue, seconds, err := rateToSeconds(rate)
+				if err != nil {
+					continue
+				}
+				rateLimits = append(rateLimits, limitadorv1alpha1.RateLimit{
+					Namespace:  limitsNamespace,
+					MaxValue:   maxValue,
+					Seconds:    seconds,
+					Conditions: []string{limitadorCondition(limitIdentifier)},
+					Variables:  utils.GetEmptySliceIfNil(limit.CountersAsStringList()),
+					Name:       LimitsNameFromRLP(rlp),
+				})
+			}
 		}
 	}
 	return rateLimits
 }
 
-func LimitsNameFromRLP(rlp *kuadrantv1beta2.RateLimitPolicy) string {
-	return wasm.LimitsNamespaceFromRLP(rlp)
-}
+// LimitIdentifierCollision checks whether any two limits of the policy share the Limitador identifier, e.g. an explicit
+// id equal to the identifier derived from the name of another limit, in which case the counters would be shared.
+// It returns an error describing the first collision found.
+// Limits of different policies never share counters, because the Limitador namespaces of the limits are qualified by
+// the namespace and the name of the policy, which cannot contain the separators of the Limitador namespace.
+func LimitIdentifierCollision(rlp *kuadrantv1beta2.RateLimitPolicy) error {
+	limits := rlp.Spec.CommonSpec().LimitsWithPlans()
+	names := make([]string, 0, len(limits))
+	for name := range limits {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	limitNames := make(map[string]string, len(names))
+	for _, name := range names {
+		identifier := wasm.LimitIdentifier(name, limits[name])
+		if other, ok := limitNames[identifier]; ok {
+			return fmt.Errorf("limit %s collides with limit %s", name, other)
+		}
+		limitNames[identifier] = name
+	}
 
-var timeUnitMap = map[kuadrantv1beta2.TimeUnit]int{
-	kuadrantv1beta2.TimeUnit("second"): 1,
-	kuadrantv1beta2.TimeUnit("minute"): 60,
-	kuadrantv1beta2.TimeUnit("hour"):   60 * 60,
-	kuadrantv1beta2.TimeUnit("day"):    60 * 60 * 24,
+	return nil
 }
 
-// rateToSeconds converts from RLP Rate API (limit, duration and unit)
-// to Limitador's Limit format (maxValue, Seconds)
-func rateToSeconds(rate kuadrantv1beta2.Rate) (maxValue int, seconds int) {
-	maxValue = rate.Limit
-	seconds = 0
+// limitadorCondition returns the condition of the Limitador limit that matches the descriptors of the limit
+func limitadorCondition(limitIdentifier string) string {
+	return fmt.Sprintf("%s == \"1\"", limitIdentifier)
+}
 
-	if tmpSecs, ok := timeUnitMap[rate.Unit]; ok && rate.Duration > 0 {
-		seconds = tmpSecs * rate.Duration
-	}
+func LimitsNameFromRLP(rlp *kuadrantv1beta2.RateLimitPolicy) string {
+	return wasm.LimitsNamespaceFromRLP(rlp)
+}
 
-	if rate.Duration < 0 {
-		seconds = 0
+// rateToSeconds converts from RLP Rate API (limit, and either window or duration and unit)
+// to Limitador's Limit format (maxValue, Seconds)
+// It fails if the rate is invalid, i.e. the limit is negative or the time window is invalid, rather than returning
+// a limit or a window of zero.
+func rateToSeconds(rate kuadrantv1beta2.Rate) (maxValue int, seconds int, err error) {
+	if err := rate.Validate(); err != nil {
+		return 0, 0, err
 	}
 
-	if rate.Limit < 0 {
-		maxValue = 0
+	seconds, err = rate.WindowSeconds()
+	if err != nil {
+		return 0, 0, err
 	}
 
-	return
+	return rate.Limit, seconds, nil
 }
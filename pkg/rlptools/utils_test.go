@@ -3,15 +3,22 @@
 package rlptools
 
 import (
+	"fmt"
 	"reflect"
 	"regexp"
+	"strings"
 	"testing"
 
 	limitadorv1alpha1 "github.com/kuadrant/limitador-operator/api/v1alpha1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 
 	kuadrantv1beta2 "github.com/kuadrant/kuadrant-operator/api/v1beta2"
+	kuadrantgatewayapi "github.com/kuadrant/kuadrant-operator/pkg/library/gatewayapi"
 	"github.com/kuadrant/kuadrant-operator/pkg/library/utils"
+	"github.com/kuadrant/kuadrant-operator/pkg/rlptools/wasm"
 )
 
 func testRLP_1Limit_1Rate(ns, name string) *kuadrantv1beta2.RateLimitPolicy {
@@ -126,8 +133,8 @@ func testRLP_1Limit_1Rate_1Counter(ns, name string) *kuadrantv1beta2.RateLimitPo
 			RateLimitPolicyCommonSpec: kuadrantv1beta2.RateLimitPolicyCommonSpec{
 				Limits: map[string]kuadrantv1beta2.Limit{
 					"l1": {
-						Counters: []kuadrantv1beta2.ContextSelector{
-							"request.path",
+						Counters: []kuadrantv1beta2.Counter{
+							{Selector: "request.path"},
 						},
 						Rates: []kuadrantv1beta2.Rate{
 							{
@@ -177,7 +184,7 @@ func TestLimitNameToLimitadorIdentifier(t *testing.T) {
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(subT *testing.T) {
-			identifier := LimitNameToLimitadorIdentifier(tc.input)
+			identifier := wasm.LimitNameToLimitadorIdentifier(tc.input)
 			if !tc.expected.MatchString(identifier) {
 				subT.Errorf("identifier does not match, expected(%s), got (%s)", tc.expected, identifier)
 			}
@@ -267,7 +274,7 @@ func TestLimitadorRateLimitsFromRLP(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(subT *testing.T) {
-			rateLimits := LimitadorRateLimitsFromRLP(tc.rlp)
+			rateLimits := LimitadorRateLimitsFromRLP(nil, tc.rlp)
 			// Instead of sorting to compare, check len and then iterate
 			if len(rateLimits) != len(tc.expected) {
 				subT.Errorf("expected limits len (%d), got (%d)", len(tc.expected), len(rateLimits))
@@ -290,6 +297,7 @@ func TestConvertRateIntoSeconds(t *testing.T) {
 		rate             kuadrantv1beta2.Rate
 		expectedMaxValue int
 		expectedSeconds  int
+		expectedErr      bool
 	}{
 		{
 			name: "seconds",
@@ -323,21 +331,35 @@ func TestConvertRateIntoSeconds(t *testing.T) {
 			expectedMaxValue: 5,
 			expectedSeconds:  2 * 60 * 60 * 24,
 		},
+		{
+			name: "week",
+			rate: kuadrantv1beta2.Rate{
+				Limit: 5, Duration: 2, Unit: kuadrantv1beta2.TimeUnit("week"),
+			},
+			expectedMaxValue: 5,
+			expectedSeconds:  2 * 60 * 60 * 24 * 7,
+		},
+		{
+			name: "window",
+			rate: kuadrantv1beta2.Rate{
+				Limit: 5, Window: "P1M",
+			},
+			expectedMaxValue: 5,
+			expectedSeconds:  60 * 60 * 24 * 30,
+		},
 		{
 			name: "negative limit",
 			rate: kuadrantv1beta2.Rate{
 				Limit: -5, Duration: 2, Unit: kuadrantv1beta2.TimeUnit("second"),
 			},
-			expectedMaxValue: 0,
-			expectedSeconds:  2,
+			expectedErr: true,
 		},
 		{
 			name: "negative duration",
 			rate: kuadrantv1beta2.Rate{
 				Limit: 5, Duration: -2, Unit: kuadrantv1beta2.TimeUnit("second"),
 			},
-			expectedMaxValue: 5,
-			expectedSeconds:  0,
+			expectedErr: true,
 		},
 		{
 			name: "limit  is 0",
@@ -352,22 +374,29 @@ func TestConvertRateIntoSeconds(t *testing.T) {
 			rate: kuadrantv1beta2.Rate{
 				Limit: 5, Duration: 0, Unit: kuadrantv1beta2.TimeUnit("second"),
 			},
-			expectedMaxValue: 5,
-			expectedSeconds:  0,
+			expectedErr: true,
 		},
 		{
 			name: "unexpected time unit",
 			rate: kuadrantv1beta2.Rate{
 				Limit: 5, Duration: 2, Unit: kuadrantv1beta2.TimeUnit("unknown"),
 			},
-			expectedMaxValue: 5,
-			expectedSeconds:  0,
+			expectedErr: true,
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(subT *testing.T) {
-			maxValue, seconds := rateToSeconds(tc.rate)
+			maxValue, seconds, err := rateToSeconds(tc.rate)
+			if tc.expectedErr {
+				if err == nil {
+					subT.Errorf("expected error, got maxValue (%d) and seconds (%d)", maxValue, seconds)
+				}
+				return
+			}
+			if err != nil {
+				subT.Fatalf("unexpected error: %v", err)
+			}
 			if maxValue != tc.expectedMaxValue {
 				subT.Errorf("maxValue does not match, expected(%d), got (%d)", tc.expectedMaxValue, maxValue)
 			}
@@ -377,3 +406,191 @@ func TestConvertRateIntoSeconds(t *testing.T) {
 		})
 	}
 }
+
+func TestLimitadorRateLimitsFromRLPWithInvalidRates(t *testing.T) {
+	rlp := testRLP_1Limit_2Rates("testNS", "rlpA")
+	limit := rlp.Spec.Limits["l1"]
+	limit.Rates[1].Unit = kuadrantv1beta2.TimeUnit("unknown")
+	rlp.Spec.Limits["l1"] = limit
+
+	rateLimits := LimitadorRateLimitsFromRLP(nil, rlp)
+	if len(rateLimits) != 1 || rateLimits[0].Seconds == 0 {
+		t.Errorf("expected only the valid rate, got %+v", rateLimits)
+	}
+
+	limit.Rates[1].Unit = limit.Rates[0].Unit
+	limit.Rates[1].Limit = -1
+	rlp.Spec.Limits["l1"] = limit
+
+	rateLimits = LimitadorRateLimitsFromRLP(nil, rlp)
+	if len(rateLimits) != 1 || rateLimits[0].MaxValue != limit.Rates[0].Limit {
+		t.Errorf("expected only the rate with a non-negative limit, got %+v", rateLimits)
+	}
+}
+
+func TestLimitIdentifierCollision(t *testing.T) {
+	t.Run("explicit id takes precedence over the limit name", func(subT *testing.T) {
+		rlp := testRLP_1Limit_1Rate("testNS", "rlpA")
+		limit := rlp.Spec.Limits["l1"]
+		limit.ID = "per_user"
+		rlp.Spec.Limits = map[string]kuadrantv1beta2.Limit{"renamed": limit}
+
+		rateLimits := LimitadorRateLimitsFromRLP(nil, rlp)
+		if len(rateLimits) != 1 || !reflect.DeepEqual(rateLimits[0].Conditions, []string{`limit.per_user == "1"`}) {
+			subT.Errorf("unexpected rate limits: %+v", rateLimits)
+		}
+	})
+
+	t.Run("no collision", func(subT *testing.T) {
+		rlp := testRLP_2Limits_1Rate("testNS", "rlpA")
+		if err := LimitIdentifierCollision(rlp); err != nil {
+			subT.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("explicit id collides with the identifier derived from the name of another limit", func(subT *testing.T) {
+		rlp := testRLP_2Limits_1Rate("testNS", "rlpA")
+		l1 := rlp.Spec.Limits["l1"]
+		l1.ID = strings.TrimPrefix(wasm.LimitNameToLimitadorIdentifier("l2"), wasm.LimitadorRateLimitIdentifierPrefix)
+		rlp.Spec.Limits["l1"] = l1
+		err := LimitIdentifierCollision(rlp)
+		if err == nil || err.Error() != "limit l2 collides with limit l1" {
+			subT.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("policies with the same limits do not share counters", func(subT *testing.T) {
+		rlpA := testRLP_2Limits_1Rate("testNS", "rlpA")
+		rlpB := testRLP_2Limits_1Rate("testNS", "rlpB")
+		for _, rlp := range []*kuadrantv1beta2.RateLimitPolicy{rlpA, rlpB} {
+			l1 := rlp.Spec.Limits["l1"]
+			l1.ID = "per_user"
+			rlp.Spec.Limits["l1"] = l1
+		}
+
+		counters := make(map[string]string)
+		for _, rlp := range []*kuadrantv1beta2.RateLimitPolicy{rlpA, rlpB} {
+			for _, rateLimit := range LimitadorRateLimitsFromRLP(nil, rlp) {
+				key := fmt.Sprintf("%s %v", rateLimit.Namespace, rateLimit.Conditions)
+				if other, ok := counters[key]; ok && other != rlp.GetName() {
+					subT.Errorf("limits of %s and %s share the counters %s", other, rlp.GetName(), key)
+				}
+				counters[key] = rlp.GetName()
+			}
+		}
+		if len(counters) != 4 {
+			subT.Errorf("unexpected counters: %v", counters)
+		}
+	})
+}
+
+func TestLimitadorRateLimitsFromRLPWithGatewayCounterScope(t *testing.T) {
+	gateway := func(ns, name string) *gatewayapiv1.Gateway {
+		return &gatewayapiv1.Gateway{
+			TypeMeta:   metav1.TypeMeta{APIVersion: gatewayapiv1.GroupVersion.String(), Kind: "Gateway"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name},
+			Status: gatewayapiv1.GatewayStatus{
+				Conditions: []metav1.Condition{{Type: string(gatewayapiv1.GatewayConditionProgrammed), Status: metav1.ConditionTrue}},
+			},
+		}
+	}
+	parentRef := func(gw *gatewayapiv1.Gateway) gatewayapiv1.ParentReference {
+		return gatewayapiv1.ParentReference{
+			Group:     ptr.To(gatewayapiv1.Group(gatewayapiv1.GroupName)),
+			Kind:      ptr.To(gatewayapiv1.Kind("Gateway")),
+			Namespace: ptr.To(gatewayapiv1.Namespace(gw.Namespace)),
+			Name:      gatewayapiv1.ObjectName(gw.Name),
+		}
+	}
+	external := gateway("gw-ns", "external")
+	internal := gateway("gw-ns", "internal")
+	route := &gatewayapiv1.HTTPRoute{
+		TypeMeta:   metav1.TypeMeta{APIVersion: gatewayapiv1.GroupVersion.String(), Kind: "HTTPRoute"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "testNS", Name: "toystore"},
+		Spec: gatewayapiv1.HTTPRouteSpec{
+			CommonRouteSpec: gatewayapiv1.CommonRouteSpec{ParentRefs: []gatewayapiv1.ParentReference{parentRef(external), parentRef(internal)}},
+		},
+		Status: gatewayapiv1.HTTPRouteStatus{
+			RouteStatus: gatewayapiv1.RouteStatus{
+				Parents: utils.Map([]*gatewayapiv1.Gateway{external, internal}, func(gw *gatewayapiv1.Gateway) gatewayapiv1.RouteParentStatus {
+					return gatewayapiv1.RouteParentStatus{
+						ParentRef:  parentRef(gw),
+						Conditions: []metav1.Condition{{Type: "Accepted", Status: metav1.ConditionTrue}},
+					}
+				}),
+			},
+		},
+	}
+
+	rlp := testRLP_1Limit_1Rate("testNS", "rlpA")
+	rlp.Spec.CounterScope = kuadrantv1beta2.GatewayCounterScope
+	rlp.Spec.TargetRef = gatewayapiv1alpha2.PolicyTargetReference{
+		Group: gatewayapiv1.GroupName,
+		Kind:  "HTTPRoute",
+		Name:  gatewayapiv1.ObjectName(route.Name),
+	}
+
+	topology, err := kuadrantgatewayapi.NewTopology(
+		kuadrantgatewayapi.WithGateways([]*gatewayapiv1.Gateway{external, internal}),
+		kuadrantgatewayapi.WithRoutes([]*gatewayapiv1.HTTPRoute{route}),
+		kuadrantgatewayapi.WithPolicies([]kuadrantgatewayapi.Policy{rlp}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rateLimits := LimitadorRateLimitsFromRLP(kuadrantgatewayapi.NewTopologyIndexes(topology), rlp)
+	namespaces := utils.Map(rateLimits, func(rl limitadorv1alpha1.RateLimit) string { return rl.Namespace })
+	expected := []string{"testNS/rlpA#gw-ns/external", "testNS/rlpA#gw-ns/internal"}
+	if !reflect.DeepEqual(namespaces, expected) {
+		t.Errorf("unexpected namespaces, expected(%v), got (%v)", expected, namespaces)
+	}
+}
+
+func TestLimitadorRateLimitsFromRLPWithPlans(t *testing.T) {
+	rlp := testRLP_1Limit_1Rate("testNS", "rlpA")
+	rlp.Spec.Plans = &kuadrantv1beta2.Plans{
+		Selector: "auth.identity.plan",
+		Counters: []kuadrantv1beta2.Counter{{Selector: "auth.identity.userid"}},
+		Tiers: map[string]kuadrantv1beta2.Plan{
+			"free": {Rates: []kuadrantv1beta2.Rate{{Limit: 10, Window: "1m"}}},
+			"gold": {Rates: []kuadrantv1beta2.Rate{{Limit: 100, Window: "1m"}, {Limit: 10000, Window: "P1D"}}},
+		},
+	}
+
+	planRateLimit := func(tier string, maxValue, seconds int) limitadorv1alpha1.RateLimit {
+		return limitadorv1alpha1.RateLimit{
+			Namespace:  "testNS/rlpA",
+			MaxValue:   maxValue,
+			Seconds:    seconds,
+			Conditions: []string{limitadorCondition(wasm.LimitNameToLimitadorIdentifier(kuadrantv1beta2.PlanLimitName(tier)))},
+			Variables:  []string{"auth.identity.userid"},
+			Name:       "testNS/rlpA",
+		}
+	}
+	expected := []limitadorv1alpha1.RateLimit{
+		{
+			Namespace:  "testNS/rlpA",
+			MaxValue:   5,
+			Seconds:    10,
+			Conditions: []string{`limit.l1__2804bad6 == "1"`},
+			Variables:  []string{},
+			Name:       "testNS/rlpA",
+		},
+		planRateLimit("free", 10, 60),
+		planRateLimit("gold", 100, 60),
+		planRateLimit("gold", 10000, 86400),
+	}
+
+	rateLimits := LimitadorRateLimitsFromRLP(nil, rlp)
+	if len(rateLimits) != len(expected) {
+		t.Fatalf("expected limits len (%d), got (%d)", len(expected), len(rateLimits))
+	}
+	for _, rl := range rateLimits {
+		if _, found := utils.Find(expected, func(expectedRateLimit limitadorv1alpha1.RateLimit) bool {
+			return reflect.DeepEqual(rl, expectedRateLimit)
+		}); !found {
+			t.Errorf("returned rate limit (%+v) not within expected ones, expected: %v", rl, expected)
+		}
+	}
+}
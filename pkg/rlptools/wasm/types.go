@@ -34,14 +34,30 @@ type StaticSpec struct {
 	Key   string `json:"key"`
 }
 
+// HitsAddendSpec defines the amount the request adds to the counters of the limits,
+// either static or resolved from the context.
+// Precisely one of "value", "selector" must be set.
+type HitsAddendSpec struct {
+	// +optional
+	Value *int `json:"value,omitempty"`
+
+	// Selector of an attribute from the contextual properties provided by kuadrant
+	// during request and connection processing
+	// +optional
+	Selector kuadrantv1beta2.ContextSelector `json:"selector,omitempty"`
+}
+
 // TODO implement one of constraint
-// Precisely one of "static", "selector" must be set.
+// Precisely one of "static", "selector", "hitsAddend" must be set.
 type DataItem struct {
 	// +optional
 	Static *StaticSpec `json:"static,omitempty"`
 
 	// +optional
 	Selector *SelectorSpec `json:"selector,omitempty"`
+
+	// +optional
+	HitsAddend *HitsAddendSpec `json:"hitsAddend,omitempty"`
 }
 
 type PatternOperator kuadrantv1beta2.WhenConditionOperator
@@ -67,6 +83,14 @@ type Condition struct {
 	AllOf []PatternExpression `json:"allOf,omitempty"`
 }
 
+type RuleMode string
+
+const (
+	// RuleModeReport instructs the data plane to never reject requests because of the rule.
+	// The response of the rate limit service is exposed as dynamic metadata and response header instead.
+	RuleModeReport RuleMode = "report"
+)
+
 // Rule defines one rate limit configuration. When conditions are met,
 // it uses `data` section to generate one RLS descriptor.
 type Rule struct {
@@ -74,6 +98,24 @@ type Rule struct {
 	Conditions []Condition `json:"conditions,omitempty"`
 	// +optional
 	Data []DataItem `json:"data,omitempty"`
+	// If not set, the rule is enforced.
+	// +optional
+	Mode RuleMode `json:"mode,omitempty"`
+	// Response returned when the rate limit service responds with over limit.
+	// If not set, the data plane returns 429 Too Many Requests.
+	// +optional
+	Response *Response `json:"response,omitempty"`
+}
+
+// Response defines a custom rate limited response.
+// Header values and body may include `{{selector}}` placeholders to be resolved by the data plane.
+type Response struct {
+	// +optional
+	Code int32 `json:"code,omitempty"`
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+	// +optional
+	Body string `json:"body,omitempty"`
 }
 
 type RateLimitPolicy struct {
@@ -31,6 +31,30 @@ func LimitsNamespaceFromRLP(rlp *kuadrantv1beta2.RateLimitPolicy) string {
 	return fmt.Sprintf("%s/%s", rlp.GetNamespace(), rlp.GetName())
 }
 
+// LimitsNamespaceFromRLPAndGateway returns the namespace of the limits of the policy enforced by the gateway.
+// Unless the counters are scoped to the gateway, the namespace is the same for all the gateways.
+func LimitsNamespaceFromRLPAndGateway(rlp *kuadrantv1beta2.RateLimitPolicy, gwKey client.ObjectKey) string {
+	if rlp.Spec.CounterScope != kuadrantv1beta2.GatewayCounterScope {
+		return LimitsNamespaceFromRLP(rlp)
+	}
+	return fmt.Sprintf("%s#%s", LimitsNamespaceFromRLP(rlp), gwKey)
+}
+
+// LimitsNamespacesFromRLP returns the namespaces of the limits of the policy.
+// When the counters are scoped to the gateway, there is one namespace per gateway the policy directly or indirectly
+// targets according to the topology, i.e. the same gateways whose wasm config includes the policy.
+func LimitsNamespacesFromRLP(t *kuadrantgatewayapi.TopologyIndexes, rlp *kuadrantv1beta2.RateLimitPolicy) []string {
+	if rlp.Spec.CounterScope != kuadrantv1beta2.GatewayCounterScope {
+		return []string{LimitsNamespaceFromRLP(rlp)}
+	}
+	if t == nil {
+		return nil
+	}
+	return utils.Map(t.GatewaysFromPolicy(rlp), func(gwKey client.ObjectKey) string {
+		return LimitsNamespaceFromRLPAndGateway(rlp, gwKey)
+	})
+}
+
 // wasmRules computes WASM rules from the policy and the targeted route.
 // It returns an empty list of wasm rules if the policy specifies no limits or if all limits specified in the policy
 // fail to match any route rule according to the limits route selectors.
@@ -41,7 +65,9 @@ func wasmRules(rlp *kuadrantv1beta2.RateLimitPolicy, route *gatewayapiv1.HTTPRou
 	}
 
 	// Sort RLP limits for consistent comparison with existing wasmplugin objects
-	limits := rlp.Spec.CommonSpec().Limits
+	limits := rlp.Spec.CommonSpec().LimitsWithPlans()
+	namedPatterns := rlp.Spec.CommonSpec().NamedPatterns
+	defaultResponse := rlp.Spec.CommonSpec().Response
 	limitNames := make([]string, 0, len(limits))
 	for name := range limits {
 		limitNames = append(limitNames, name)
@@ -53,9 +79,10 @@ func wasmRules(rlp *kuadrantv1beta2.RateLimitPolicy, route *gatewayapiv1.HTTPRou
 	for _, limitName := range limitNames {
 		// 1 RLP limit <---> 1 WASM rule
 		limit := limits[limitName]
-		limitIdentifier := LimitNameToLimitadorIdentifier(limitName)
-		rule, err := ruleFromLimit(limitIdentifier, &limit, route)
+		limitIdentifier := LimitIdentifier(limitName, limit)
+		rule, err := ruleFromLimit(limitIdentifier, &limit, namedPatterns, route)
 		if err == nil {
+			rule.Response = responseFromLimit(&limit, defaultResponse)
 			rules = append(rules, rule)
 		}
 	}
@@ -63,6 +90,31 @@ func wasmRules(rlp *kuadrantv1beta2.RateLimitPolicy, route *gatewayapiv1.HTTPRou
 	return rules
 }
 
+// responseFromLimit returns the rate limited response of the limit, falling back to the response of the policy
+func responseFromLimit(limit *kuadrantv1beta2.Limit, defaultResponse *kuadrantv1beta2.RateLimitedResponse) *Response {
+	response := defaultResponse
+	if limit.Response != nil {
+		response = limit.Response
+	}
+	if response == nil {
+		return nil
+	}
+	return &Response{
+		Code:    response.Code,
+		Headers: response.Headers,
+		Body:    response.Body,
+	}
+}
+
+// LimitIdentifier returns the identifier of the limit in Limitador.
+// The explicit id of the limit takes precedence over the identifier derived from the name of the limit.
+func LimitIdentifier(limitName string, limit kuadrantv1beta2.Limit) string {
+	if limit.ID != "" {
+		return LimitadorRateLimitIdentifierPrefix + limit.ID
+	}
+	return LimitNameToLimitadorIdentifier(limitName)
+}
+
 func LimitNameToLimitadorIdentifier(uniqueLimitName string) string {
 	identifier := LimitadorRateLimitIdentifierPrefix
 
@@ -82,10 +134,10 @@ func LimitNameToLimitadorIdentifier(uniqueLimitName string) string {
 	return identifier
 }
 
-func ruleFromLimit(limitIdentifier string, limit *kuadrantv1beta2.Limit, route *gatewayapiv1.HTTPRoute) (Rule, error) {
+func ruleFromLimit(limitIdentifier string, limit *kuadrantv1beta2.Limit, namedPatterns map[string]kuadrantv1beta2.PatternExpressions, route *gatewayapiv1.HTTPRoute) (Rule, error) {
 	rule := Rule{}
 
-	conditions, err := conditionsFromLimit(limit, route)
+	conditions, err := conditionsFromLimit(limit, namedPatterns, route)
 	if err != nil {
 		return rule, err
 	}
@@ -96,10 +148,17 @@ func ruleFromLimit(limitIdentifier string, limit *kuadrantv1beta2.Limit, route *
 		rule.Data = data
 	}
 
+	if limit.IsReportMode() {
+		rule.Mode = RuleModeReport
+	}
+
 	return rule, nil
 }
 
-func conditionsFromLimit(limit *kuadrantv1beta2.Limit, route *gatewayapiv1.HTTPRoute) ([]Condition, error) {
+// conditionsFromLimit builds the list of conditions of the wasm rule of a limit.
+// Each combination of a condition built from the route rules and an alternative of the 'when' conditions of the limit
+// (i.e. one of the 'anyOf' patterns) yields one condition.
+func conditionsFromLimit(limit *kuadrantv1beta2.Limit, namedPatterns map[string]kuadrantv1beta2.PatternExpressions, route *gatewayapiv1.HTTPRoute) ([]Condition, error) {
 	if limit == nil {
 		return nil, errors.New("limit should not be nil")
 	}
@@ -126,7 +185,12 @@ func conditionsFromLimit(limit *kuadrantv1beta2.Limit, route *gatewayapiv1.HTTPR
 		}
 	}
 
-	if len(limit.When) == 0 {
+	whenPatterns, err := limit.WhenPatterns(namedPatterns)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(whenPatterns) == 0 {
 		if len(routeConditions) == 0 {
 			return nil, nil
 		}
@@ -134,22 +198,28 @@ func conditionsFromLimit(limit *kuadrantv1beta2.Limit, route *gatewayapiv1.HTTPR
 	}
 
 	if len(routeConditions) > 0 {
-		// merge the 'when' conditions into each route level one
-		mergedConditions := make([]Condition, len(routeConditions))
-		for _, when := range limit.When {
-			for idx := range routeConditions {
-				mergedCondition := routeConditions[idx]
-				mergedCondition.AllOf = append(mergedCondition.AllOf, patternExpresionFromWhen(when))
-				mergedConditions[idx] = mergedCondition
+		// merge each alternative of the 'when' conditions into each route level one
+		mergedConditions := make([]Condition, 0, len(routeConditions)*len(whenPatterns))
+		for _, routeCondition := range routeConditions {
+			for _, whenPattern := range whenPatterns {
+				mergedCondition := Condition{AllOf: slices.Clone(routeCondition.AllOf)}
+				for _, when := range whenPattern {
+					mergedCondition.AllOf = append(mergedCondition.AllOf, patternExpresionFromWhen(when))
+				}
+				mergedConditions = append(mergedConditions, mergedCondition)
 			}
 		}
 		return mergedConditions, nil
 	}
 
 	// build conditions only from the 'when' field
-	whenConditions := make([]Condition, len(limit.When))
-	for idx, when := range limit.When {
-		whenConditions[idx] = Condition{AllOf: []PatternExpression{patternExpresionFromWhen(when)}}
+	whenConditions := make([]Condition, 0, len(whenPatterns))
+	for _, whenPattern := range whenPatterns {
+		whenCondition := Condition{AllOf: make([]PatternExpression, 0, len(whenPattern))}
+		for _, when := range whenPattern {
+			whenCondition.AllOf = append(whenCondition.AllOf, patternExpresionFromWhen(when))
+		}
+		whenConditions = append(whenConditions, whenCondition)
 	}
 	return whenConditions, nil
 }
@@ -252,7 +322,7 @@ func patternExpresionFromHostname(hostname gatewayapiv1.Hostname) PatternExpress
 	}
 }
 
-func patternExpresionFromWhen(when kuadrantv1beta2.WhenCondition) PatternExpression {
+func patternExpresionFromWhen(when kuadrantv1beta2.PatternExpression) PatternExpression {
 	return PatternExpression{
 		Selector: when.Selector,
 		Operator: PatternOperator(when.Operator),
@@ -269,7 +339,18 @@ func dataFromLimt(limitIdentifier string, limit *kuadrantv1beta2.Limit) (data []
 	data = append(data, DataItem{Static: &StaticSpec{Key: limitIdentifier, Value: "1"}})
 
 	for _, counter := range limit.Counters {
-		data = append(data, DataItem{Selector: &SelectorSpec{Selector: counter}})
+		data = append(data, DataItem{Selector: &SelectorSpec{
+			Selector: counter.Selector,
+			Key:      counter.Key,
+			Default:  counter.Default,
+		}})
+	}
+
+	if limit.Cost != nil {
+		data = append(data, DataItem{HitsAddend: &HitsAddendSpec{
+			Value:    limit.Cost.Value,
+			Selector: limit.Cost.Selector,
+		}})
 	}
 
 	return data
@@ -295,7 +376,8 @@ func ConfigFromStruct(structure *_struct.Struct) (*Config, error) {
 
 type WasmRulesByDomain map[string][]Rule
 
-func ConfigFromGateway(ctx context.Context, cl client.Client, gw *gatewayapiv1.Gateway) (*Config, error) {
+// ConfigFromGateway computes the wasm config of a gateway out of a topology of gateways, routes and policies
+func ConfigFromGateway(ctx context.Context, topology *kuadrantgatewayapi.Topology, gw *gatewayapiv1.Gateway) (*Config, error) {
 	logger, err := logr.FromContext(ctx)
 	if err != nil {
 		return nil, err
@@ -306,9 +388,9 @@ func ConfigFromGateway(ctx context.Context, cl client.Client, gw *gatewayapiv1.G
 		RateLimitPolicies: make([]RateLimitPolicy, 0),
 	}
 
-	t, err := TopologyIndexesFromGateway(ctx, cl, gw)
-	if err != nil {
-		return nil, err
+	t := TopologyIndexesFromTopology(topology)
+	if t == nil {
+		return nil, errors.New("cannot compute wasm config from nil topology")
 	}
 
 	rateLimitPolicies := t.PoliciesFromGateway(gw)
@@ -316,6 +398,7 @@ func ConfigFromGateway(ctx context.Context, cl client.Client, gw *gatewayapiv1.G
 	logger.V(1).Info("ConfigFromGateway", "#RLPS", len(rateLimitPolicies))
 
 	// Sort RLPs for consistent comparison with existing objects
+	// The list is a copy, so sorting it does not alter the indexes shared by the readers of the topology
 	sort.Sort(kuadrantgatewayapi.PolicyByCreationTimestamp(rateLimitPolicies))
 
 	for _, policy := range rateLimitPolicies {
@@ -377,7 +460,7 @@ func wasmRateLimitPolicy(ctx context.Context, t *kuadrantgatewayapi.TopologyInde
 
 	return &RateLimitPolicy{
 		Name:      client.ObjectKeyFromObject(rlp).String(),
-		Domain:    LimitsNamespaceFromRLP(rlp),
+		Domain:    LimitsNamespaceFromRLPAndGateway(rlp, client.ObjectKeyFromObject(gw)),
 		Hostnames: utils.HostnamesToStrings(hostnames), // we might be listing more hostnames than needed due to route selectors hostnames possibly being more restrictive
 		Service:   common.KuadrantRateLimitClusterName,
 		Rules:     rules,
@@ -396,16 +479,16 @@ func routeFromRLP(ctx context.Context, t *kuadrantgatewayapi.TopologyIndexes, rl
 		// The policy is targeting a gateway
 		// This gateway policy will be enforced into all HTTPRoutes that do not have a policy attached to it
 
-		// Build imaginary route with all the routes not having a RLP targeting it
-		untargetedRoutes := t.GetUntargetedRoutes(gw)
-
-		if len(untargetedRoutes) == 0 {
-			// For policies targeting a gateway, when no httproutes is attached to the gateway, skip wasm config
+		if t.IsPolicyOverridden(rlp) {
+			// For policies targeting a gateway, when no untargeted httproutes are attached to the gateway, skip wasm config
 			// test wasm config when no http routes attached to the gateway
 			logger.V(1).Info("no untargeted httproutes attached to the targeted gateway, skipping wasm config for the gateway rlp", "ratelimitpolicy", client.ObjectKeyFromObject(rlp))
 			return nil, nil
 		}
 
+		// Build imaginary route with all the routes not having a RLP targeting it
+		untargetedRoutes := t.GetUntargetedRoutes(gw)
+
 		untargetedRules := make([]gatewayapiv1.HTTPRouteRule, 0)
 		for idx := range untargetedRoutes {
 			untargetedRules = append(untargetedRules, untargetedRoutes[idx].Spec.Rules...)
@@ -3,14 +3,21 @@
 package wasm
 
 import (
+	"context"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 
 	kuadrantv1beta2 "github.com/kuadrant/kuadrant-operator/api/v1beta2"
-	"github.com/kuadrant/kuadrant-operator/pkg/rlptools/wasm"
+	kuadrantgatewayapi "github.com/kuadrant/kuadrant-operator/pkg/library/gatewayapi"
+	"github.com/kuadrant/kuadrant-operator/pkg/log"
 )
 
 // TODO(eastizle): missing WASMPluginMutator tests
@@ -69,7 +76,7 @@ func TestWasmRules(t *testing.T) {
 		name          string
 		rlp           *kuadrantv1beta2.RateLimitPolicy
 		route         *gatewayapiv1.HTTPRoute
-		expectedRules []wasm.Rule
+		expectedRules []Rule
 	}{
 		{
 			name: "minimal RLP",
@@ -79,27 +86,27 @@ func TestWasmRules(t *testing.T) {
 				},
 			}),
 			route: httpRoute,
-			expectedRules: []wasm.Rule{
+			expectedRules: []Rule{
 				{
-					Conditions: []wasm.Condition{
+					Conditions: []Condition{
 						{
-							AllOf: []wasm.PatternExpression{
+							AllOf: []PatternExpression{
 								{
 									Selector: "request.url_path",
-									Operator: wasm.PatternOperator(kuadrantv1beta2.StartsWithOperator),
+									Operator: PatternOperator(kuadrantv1beta2.StartsWithOperator),
 									Value:    "/toy",
 								},
 								{
 									Selector: "request.method",
-									Operator: wasm.PatternOperator(kuadrantv1beta2.EqualOperator),
+									Operator: PatternOperator(kuadrantv1beta2.EqualOperator),
 									Value:    "GET",
 								},
 							},
 						},
 					},
-					Data: []wasm.DataItem{
+					Data: []DataItem{
 						{
-							Static: &wasm.StaticSpec{
+							Static: &StaticSpec{
 								Key:   "limit.50rps__770adfd9",
 								Value: "1",
 							},
@@ -124,32 +131,32 @@ func TestWasmRules(t *testing.T) {
 				},
 			}),
 			route: httpRoute,
-			expectedRules: []wasm.Rule{
+			expectedRules: []Rule{
 				{
-					Conditions: []wasm.Condition{
+					Conditions: []Condition{
 						{
-							AllOf: []wasm.PatternExpression{
+							AllOf: []PatternExpression{
 								{
 									Selector: "request.url_path",
-									Operator: wasm.PatternOperator(kuadrantv1beta2.StartsWithOperator),
+									Operator: PatternOperator(kuadrantv1beta2.StartsWithOperator),
 									Value:    "/toy",
 								},
 								{
 									Selector: "request.method",
-									Operator: wasm.PatternOperator(kuadrantv1beta2.EqualOperator),
+									Operator: PatternOperator(kuadrantv1beta2.EqualOperator),
 									Value:    "GET",
 								},
 								{
 									Selector: "request.host",
-									Operator: wasm.PatternOperator(kuadrantv1beta2.EndsWithOperator),
+									Operator: PatternOperator(kuadrantv1beta2.EndsWithOperator),
 									Value:    ".example.com",
 								},
 							},
 						},
 					},
-					Data: []wasm.DataItem{
+					Data: []DataItem{
 						{
-							Static: &wasm.StaticSpec{
+							Static: &StaticSpec{
 								Key:   "limit.50rps_for_selected_hostnames__5af2c820",
 								Value: "1",
 							},
@@ -179,27 +186,27 @@ func TestWasmRules(t *testing.T) {
 				},
 			}),
 			route: httpRoute,
-			expectedRules: []wasm.Rule{
+			expectedRules: []Rule{
 				{
-					Conditions: []wasm.Condition{
+					Conditions: []Condition{
 						{
-							AllOf: []wasm.PatternExpression{
+							AllOf: []PatternExpression{
 								{
 									Selector: "request.url_path",
-									Operator: wasm.PatternOperator(kuadrantv1beta2.StartsWithOperator),
+									Operator: PatternOperator(kuadrantv1beta2.StartsWithOperator),
 									Value:    "/toy",
 								},
 								{
 									Selector: "request.method",
-									Operator: wasm.PatternOperator(kuadrantv1beta2.EqualOperator),
+									Operator: PatternOperator(kuadrantv1beta2.EqualOperator),
 									Value:    "GET",
 								},
 							},
 						},
 					},
-					Data: []wasm.DataItem{
+					Data: []DataItem{
 						{
-							Static: &wasm.StaticSpec{
+							Static: &StaticSpec{
 								Key:   "limit.50rps_for_selected_route__b6640119",
 								Value: "1",
 							},
@@ -228,27 +235,27 @@ func TestWasmRules(t *testing.T) {
 				},
 			}),
 			route: httpRoute,
-			expectedRules: []wasm.Rule{
+			expectedRules: []Rule{
 				{
-					Conditions: []wasm.Condition{
+					Conditions: []Condition{
 						{
-							AllOf: []wasm.PatternExpression{
+							AllOf: []PatternExpression{
 								{
 									Selector: "request.url_path",
-									Operator: wasm.PatternOperator(kuadrantv1beta2.StartsWithOperator),
+									Operator: PatternOperator(kuadrantv1beta2.StartsWithOperator),
 									Value:    "/toy",
 								},
 								{
 									Selector: "request.method",
-									Operator: wasm.PatternOperator(kuadrantv1beta2.EqualOperator),
+									Operator: PatternOperator(kuadrantv1beta2.EqualOperator),
 									Value:    "GET",
 								},
 							},
 						},
 					},
-					Data: []wasm.DataItem{
+					Data: []DataItem{
 						{
-							Static: &wasm.StaticSpec{
+							Static: &StaticSpec{
 								Key:   "limit.50rps_for_selected_path__4088dcf9",
 								Value: "1",
 							},
@@ -274,7 +281,7 @@ func TestWasmRules(t *testing.T) {
 				},
 			}),
 			route:         httpRoute,
-			expectedRules: []wasm.Rule{},
+			expectedRules: []Rule{},
 		},
 		{
 			name: "HTTPRouteRules without rule matches",
@@ -284,12 +291,12 @@ func TestWasmRules(t *testing.T) {
 				},
 			}),
 			route: catchAllHTTPRoute,
-			expectedRules: []wasm.Rule{
+			expectedRules: []Rule{
 				{
 					Conditions: nil,
-					Data: []wasm.DataItem{
+					Data: []DataItem{
 						{
-							Static: &wasm.StaticSpec{
+							Static: &StaticSpec{
 								Key:   "limit.50rps__770adfd9",
 								Value: "1",
 							},
@@ -303,22 +310,22 @@ func TestWasmRules(t *testing.T) {
 			rlp: rlp("my-rlp", map[string]kuadrantv1beta2.Limit{
 				"50rps-per-username": {
 					Rates:    []kuadrantv1beta2.Rate{counter50rps},
-					Counters: []kuadrantv1beta2.ContextSelector{"auth.identity.username"},
+					Counters: []kuadrantv1beta2.Counter{{Selector: "auth.identity.username"}},
 				},
 			}),
 			route: catchAllHTTPRoute,
-			expectedRules: []wasm.Rule{
+			expectedRules: []Rule{
 				{
 					Conditions: nil,
-					Data: []wasm.DataItem{
+					Data: []DataItem{
 						{
-							Static: &wasm.StaticSpec{
+							Static: &StaticSpec{
 								Key:   "limit.50rps_per_username__f5bebfb8",
 								Value: "1",
 							},
 						},
 						{
-							Selector: &wasm.SelectorSpec{
+							Selector: &SelectorSpec{
 								Selector: "auth.identity.username",
 							},
 						},
@@ -326,14 +333,396 @@ func TestWasmRules(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "RLP with limit in report mode",
+			rlp: rlp("my-rlp", map[string]kuadrantv1beta2.Limit{
+				"50rps-report-only": {
+					Rates: []kuadrantv1beta2.Rate{counter50rps},
+					Mode:  kuadrantv1beta2.ReportLimitMode,
+				},
+			}),
+			route: catchAllHTTPRoute,
+			expectedRules: []Rule{
+				{
+					Conditions: nil,
+					Data: []DataItem{
+						{
+							Static: &StaticSpec{
+								Key:   "limit.50rps_report_only__127f1ea3",
+								Value: "1",
+							},
+						},
+					},
+					Mode: RuleModeReport,
+				},
+			},
+		},
+		{
+			name: "RLP with custom rate limited response",
+			rlp: rlp("my-rlp", map[string]kuadrantv1beta2.Limit{
+				"50rps-custom-response": {
+					Rates: []kuadrantv1beta2.Rate{counter50rps},
+					Response: &kuadrantv1beta2.RateLimitedResponse{
+						Code:    503,
+						Headers: map[string]string{"retry-after": "{{ratelimit.reset}}"},
+						Body:    "slow down",
+					},
+				},
+			}),
+			route: catchAllHTTPRoute,
+			expectedRules: []Rule{
+				{
+					Conditions: nil,
+					Data: []DataItem{
+						{
+							Static: &StaticSpec{
+								Key:   "limit.50rps_custom_response__c842d6bf",
+								Value: "1",
+							},
+						},
+					},
+					Response: &Response{
+						Code:    503,
+						Headers: map[string]string{"retry-after": "{{ratelimit.reset}}"},
+						Body:    "slow down",
+					},
+				},
+			},
+		},
+		{
+			name: "RLP with default rate limited response",
+			rlp: func() *kuadrantv1beta2.RateLimitPolicy {
+				policy := rlp("my-rlp", map[string]kuadrantv1beta2.Limit{
+					"50rps-default-response": {
+						Rates: []kuadrantv1beta2.Rate{counter50rps},
+					},
+				})
+				policy.Spec.Response = &kuadrantv1beta2.RateLimitedResponse{Code: 429, Body: "too many requests"}
+				return policy
+			}(),
+			route: catchAllHTTPRoute,
+			expectedRules: []Rule{
+				{
+					Conditions: nil,
+					Data: []DataItem{
+						{
+							Static: &StaticSpec{
+								Key:   "limit.50rps_default_response__104b0bd5",
+								Value: "1",
+							},
+						},
+					},
+					Response: &Response{
+						Code: 429,
+						Body: "too many requests",
+					},
+				},
+			},
+		},
+		{
+			name: "RLP with static cost",
+			rlp: rlp("my-rlp", map[string]kuadrantv1beta2.Limit{
+				"50rps-weighted": {
+					Rates: []kuadrantv1beta2.Rate{counter50rps},
+					Cost:  &kuadrantv1beta2.Cost{Value: &[]int{5}[0]},
+				},
+			}),
+			route: catchAllHTTPRoute,
+			expectedRules: []Rule{
+				{
+					Conditions: nil,
+					Data: []DataItem{
+						{
+							Static: &StaticSpec{
+								Key:   "limit.50rps_weighted__ada5b4f4",
+								Value: "1",
+							},
+						},
+						{
+							HitsAddend: &HitsAddendSpec{
+								Value: &[]int{5}[0],
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "RLP with cost resolved from the context",
+			rlp: rlp("my-rlp", map[string]kuadrantv1beta2.Limit{
+				"50rps-by-payload-size": {
+					Rates:    []kuadrantv1beta2.Rate{counter50rps},
+					Counters: []kuadrantv1beta2.Counter{{Selector: "auth.identity.username"}},
+					Cost:     &kuadrantv1beta2.Cost{Selector: "request.size"},
+				},
+			}),
+			route: catchAllHTTPRoute,
+			expectedRules: []Rule{
+				{
+					Conditions: nil,
+					Data: []DataItem{
+						{
+							Static: &StaticSpec{
+								Key:   "limit.50rps_by_payload_size__87b535c2",
+								Value: "1",
+							},
+						},
+						{
+							Selector: &SelectorSpec{
+								Selector: "auth.identity.username",
+							},
+						},
+						{
+							HitsAddend: &HitsAddendSpec{
+								Selector: "request.size",
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			computedRules := WasmRules(tc.rlp, tc.route)
+			computedRules := wasmRules(tc.rlp, tc.route)
 			if diff := cmp.Diff(tc.expectedRules, computedRules); diff != "" {
 				t.Errorf("unexpected wasm rules (-want +got):\n%s", diff)
 			}
 		})
 	}
 }
+
+func TestConditionsFromLimit(t *testing.T) {
+	route := &gatewayapiv1.HTTPRoute{
+		Spec: gatewayapiv1.HTTPRouteSpec{
+			Rules: []gatewayapiv1.HTTPRouteRule{
+				{
+					Matches: []gatewayapiv1.HTTPRouteMatch{
+						{Method: &[]gatewayapiv1.HTTPMethod{"GET"}[0]},
+						{Method: &[]gatewayapiv1.HTTPMethod{"POST"}[0]},
+					},
+				},
+			},
+		},
+	}
+	catchAllRoute := &gatewayapiv1.HTTPRoute{
+		Spec: gatewayapiv1.HTTPRouteSpec{
+			Hostnames: []gatewayapiv1.Hostname{"*"},
+		},
+	}
+
+	tenant := func(value string) kuadrantv1beta2.WhenPattern {
+		return kuadrantv1beta2.WhenPattern{Selector: "auth.identity.tenant", Operator: kuadrantv1beta2.EqualOperator, Value: value}
+	}
+	group := func(value string) kuadrantv1beta2.WhenPattern {
+		return kuadrantv1beta2.WhenPattern{Selector: "auth.identity.group", Operator: kuadrantv1beta2.EqualOperator, Value: value}
+	}
+	method := func(value string) PatternExpression {
+		return PatternExpression{Selector: "request.method", Operator: PatternOperator(kuadrantv1beta2.EqualOperator), Value: value}
+	}
+	tenantExpr := func(value string) PatternExpression {
+		return PatternExpression{Selector: "auth.identity.tenant", Operator: PatternOperator(kuadrantv1beta2.EqualOperator), Value: value}
+	}
+	groupExpr := func(value string) PatternExpression {
+		return PatternExpression{Selector: "auth.identity.group", Operator: PatternOperator(kuadrantv1beta2.EqualOperator), Value: value}
+	}
+	tenantsAnyOf := kuadrantv1beta2.WhenCondition{AnyOf: []kuadrantv1beta2.WhenPattern{tenant("a"), tenant("b")}}
+	groupsAnyOf := kuadrantv1beta2.WhenCondition{AnyOf: []kuadrantv1beta2.WhenPattern{group("admins"), group("users")}}
+
+	tooManyAlternatives := make([]kuadrantv1beta2.WhenCondition, 0, 7)
+	for i := 0; i < 7; i++ {
+		tooManyAlternatives = append(tooManyAlternatives, tenantsAnyOf)
+	}
+
+	testCases := []struct {
+		name               string
+		when               []kuadrantv1beta2.WhenCondition
+		route              *gatewayapiv1.HTTPRoute
+		expectedConditions []Condition
+		expectErr          bool
+	}{
+		{
+			name:  "route conditions times anyOf alternatives",
+			when:  []kuadrantv1beta2.WhenCondition{tenantsAnyOf},
+			route: route,
+			expectedConditions: []Condition{
+				{AllOf: []PatternExpression{method("GET"), tenantExpr("a")}},
+				{AllOf: []PatternExpression{method("GET"), tenantExpr("b")}},
+				{AllOf: []PatternExpression{method("POST"), tenantExpr("a")}},
+				{AllOf: []PatternExpression{method("POST"), tenantExpr("b")}},
+			},
+		},
+		{
+			name:  "cross product of anyOf groups",
+			when:  []kuadrantv1beta2.WhenCondition{tenantsAnyOf, groupsAnyOf},
+			route: catchAllRoute,
+			expectedConditions: []Condition{
+				{AllOf: []PatternExpression{tenantExpr("a"), groupExpr("admins")}},
+				{AllOf: []PatternExpression{tenantExpr("a"), groupExpr("users")}},
+				{AllOf: []PatternExpression{tenantExpr("b"), groupExpr("admins")}},
+				{AllOf: []PatternExpression{tenantExpr("b"), groupExpr("users")}},
+			},
+		},
+		{
+			name:  "route conditions times cross product of anyOf groups",
+			when:  []kuadrantv1beta2.WhenCondition{tenantsAnyOf, groupsAnyOf},
+			route: route,
+			expectedConditions: []Condition{
+				{AllOf: []PatternExpression{method("GET"), tenantExpr("a"), groupExpr("admins")}},
+				{AllOf: []PatternExpression{method("GET"), tenantExpr("a"), groupExpr("users")}},
+				{AllOf: []PatternExpression{method("GET"), tenantExpr("b"), groupExpr("admins")}},
+				{AllOf: []PatternExpression{method("GET"), tenantExpr("b"), groupExpr("users")}},
+				{AllOf: []PatternExpression{method("POST"), tenantExpr("a"), groupExpr("admins")}},
+				{AllOf: []PatternExpression{method("POST"), tenantExpr("a"), groupExpr("users")}},
+				{AllOf: []PatternExpression{method("POST"), tenantExpr("b"), groupExpr("admins")}},
+				{AllOf: []PatternExpression{method("POST"), tenantExpr("b"), groupExpr("users")}},
+			},
+		},
+		{
+			name:      "too many alternatives",
+			when:      tooManyAlternatives,
+			route:     catchAllRoute,
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conditions, err := conditionsFromLimit(&kuadrantv1beta2.Limit{When: tc.when}, nil, tc.route)
+			if tc.expectErr {
+				if err == nil {
+					t.Errorf("expected error, got conditions %v", conditions)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedConditions, conditions); diff != "" {
+				t.Errorf("unexpected conditions (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// Run with -race to detect concurrent readers of the same topology snapshot modifying its shared indexes
+func TestConfigFromGateway_ConcurrentReaders(t *testing.T) {
+	gw := &gatewayapiv1.Gateway{
+		TypeMeta:   metav1.TypeMeta{APIVersion: gatewayapiv1.GroupVersion.String(), Kind: "Gateway"},
+		ObjectMeta: metav1.ObjectMeta{Name: "gw", Namespace: "my-app"},
+		Spec: gatewayapiv1.GatewaySpec{
+			Listeners: []gatewayapiv1.Listener{{Hostname: ptr.To(gatewayapiv1.Hostname("*.example.com"))}},
+		},
+		Status: gatewayapiv1.GatewayStatus{
+			Conditions: []metav1.Condition{{Type: string(gatewayapiv1.GatewayConditionProgrammed), Status: metav1.ConditionTrue}},
+		},
+	}
+
+	route := func(name, path string) *gatewayapiv1.HTTPRoute {
+		parentRef := gatewayapiv1.ParentReference{
+			Group:     ptr.To(gatewayapiv1.Group(gatewayapiv1.GroupName)),
+			Kind:      ptr.To(gatewayapiv1.Kind("Gateway")),
+			Namespace: ptr.To(gatewayapiv1.Namespace(gw.Namespace)),
+			Name:      gatewayapiv1.ObjectName(gw.Name),
+		}
+		return &gatewayapiv1.HTTPRoute{
+			TypeMeta:   metav1.TypeMeta{APIVersion: gatewayapiv1.GroupVersion.String(), Kind: "HTTPRoute"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "my-app"},
+			Spec: gatewayapiv1.HTTPRouteSpec{
+				CommonRouteSpec: gatewayapiv1.CommonRouteSpec{ParentRefs: []gatewayapiv1.ParentReference{parentRef}},
+				Hostnames:       []gatewayapiv1.Hostname{"api.example.com"},
+				Rules: []gatewayapiv1.HTTPRouteRule{{
+					Matches: []gatewayapiv1.HTTPRouteMatch{{
+						Path: &gatewayapiv1.HTTPPathMatch{Type: ptr.To(gatewayapiv1.PathMatchPathPrefix), Value: ptr.To(path)},
+					}},
+				}},
+			},
+			Status: gatewayapiv1.HTTPRouteStatus{
+				RouteStatus: gatewayapiv1.RouteStatus{
+					Parents: []gatewayapiv1.RouteParentStatus{{
+						ParentRef:  parentRef,
+						Conditions: []metav1.Condition{{Type: "Accepted", Status: metav1.ConditionTrue}},
+					}},
+				},
+			},
+		}
+	}
+
+	rlp := func(name, kind, targetName string, created time.Time) *kuadrantv1beta2.RateLimitPolicy {
+		return &kuadrantv1beta2.RateLimitPolicy{
+			TypeMeta:   metav1.TypeMeta{APIVersion: kuadrantv1beta2.GroupVersion.String(), Kind: "RateLimitPolicy"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "my-app", CreationTimestamp: metav1.NewTime(created)},
+			Spec: kuadrantv1beta2.RateLimitPolicySpec{
+				TargetRef: gatewayapiv1alpha2.PolicyTargetReference{
+					Group: gatewayapiv1.GroupName,
+					Kind:  gatewayapiv1.Kind(kind),
+					Name:  gatewayapiv1.ObjectName(targetName),
+				},
+				RateLimitPolicyCommonSpec: kuadrantv1beta2.RateLimitPolicyCommonSpec{
+					Limits: map[string]kuadrantv1beta2.Limit{
+						"50rps": {Rates: []kuadrantv1beta2.Rate{{Limit: 50, Duration: 1, Unit: kuadrantv1beta2.TimeUnit("second")}}},
+					},
+				},
+			},
+		}
+	}
+
+	now := time.Now()
+	// the gateway policy is indexed first, but it is the newest one
+	policies := []kuadrantgatewayapi.Policy{
+		rlp("gw-rlp", "Gateway", "gw", now),
+		rlp("toys-rlp", "HTTPRoute", "toys", now.Add(-2*time.Minute)),
+		rlp("cars-rlp", "HTTPRoute", "cars", now.Add(-time.Minute)),
+	}
+
+	topology, err := kuadrantgatewayapi.NewTopology(
+		kuadrantgatewayapi.WithLogger(log.NewLogger()),
+		kuadrantgatewayapi.WithGateways([]*gatewayapiv1.Gateway{gw}),
+		kuadrantgatewayapi.WithRoutes([]*gatewayapiv1.HTTPRoute{route("toys", "/toys"), route("cars", "/cars"), route("other", "/other")}),
+		kuadrantgatewayapi.WithPolicies(policies),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := logr.NewContext(context.Background(), log.NewLogger())
+
+	const numReaders = 10
+	configs := make([]*Config, numReaders)
+	errs := make([]error, numReaders)
+	var wg sync.WaitGroup
+	for i := 0; i < numReaders; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			configs[i], errs[i] = ConfigFromGateway(ctx, topology, gw)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < numReaders; i++ {
+		if errs[i] != nil {
+			t.Fatalf("unexpected error: %v", errs[i])
+		}
+		names := make([]string, 0, len(configs[i].RateLimitPolicies))
+		for _, policy := range configs[i].RateLimitPolicies {
+			names = append(names, policy.Name)
+		}
+		if diff := cmp.Diff([]string{"my-app/toys-rlp", "my-app/cars-rlp", "my-app/gw-rlp"}, names); diff != "" {
+			t.Errorf("unexpected order of policies (-want +got):\n%s", diff)
+		}
+	}
+
+	// the shared index keeps the order in which it was built
+	indexed := TopologyIndexesFromTopology(topology).PoliciesFromGateway(gw)
+	if indexed[0].GetName() != "gw-rlp" {
+		t.Errorf("the shared index of policies was modified: %v", indexed)
+	}
+
+	// the indexes of a filtered view of the topology are not the ones memoized in the topology
+	view := topology.WithPolicyFilter(func(p kuadrantgatewayapi.Policy) bool { return p.GetName() == "toys-rlp" })
+	if indexed := TopologyIndexesFromTopology(view).PoliciesFromGateway(gw); len(indexed) != 1 || indexed[0].GetName() != "toys-rlp" {
+		t.Errorf("unexpected policies of the filtered view: %v", indexed)
+	}
+}
@@ -0,0 +1,171 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	authorinoapi "github.com/kuadrant/authorino/api/v1beta2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kuadrantv1beta2 "github.com/kuadrant/kuadrant-operator/api/v1beta2"
+)
+
+// +kubebuilder:webhook:path=/validate-kuadrant-io-v1beta2-authpolicy,mutating=false,failurePolicy=fail,sideEffects=None,groups=kuadrant.io,resources=authpolicies,verbs=create;update,versions=v1beta2,name=vauthpolicy.kuadrant.io,admissionReviewVersions=v1
+
+// AuthPolicyValidator rejects auth policies that would otherwise only be reported as not accepted after being
+// applied, i.e. invalid specs, route selectors that do not match any rule of the targeted HTTPRoute,
+// invalid regular expressions and references to undefined named patterns.
+type AuthPolicyValidator struct {
+	reader client.Reader
+}
+
+var _ admission.CustomValidator = &AuthPolicyValidator{}
+
+func NewAuthPolicyValidator(reader client.Reader) *AuthPolicyValidator {
+	return &AuthPolicyValidator{reader: reader}
+}
+
+// SetupWithManager registers the validating webhook of the auth policies in the webhook server of the manager
+func (v *AuthPolicyValidator) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&kuadrantv1beta2.AuthPolicy{}).
+		WithValidator(v).
+		Complete()
+}
+
+// ValidateCreate implements admission.CustomValidator
+func (v *AuthPolicyValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return v.validate(ctx, obj)
+}
+
+// ValidateUpdate implements admission.CustomValidator
+func (v *AuthPolicyValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldPolicy, oldOK := oldObj.(*kuadrantv1beta2.AuthPolicy)
+	newPolicy, newOK := newObj.(*kuadrantv1beta2.AuthPolicy)
+	if oldOK && newOK && skipUpdateValidation(oldPolicy, newPolicy, oldPolicy.Spec, newPolicy.Spec) {
+		return nil, nil
+	}
+	return v.validate(ctx, newObj)
+}
+
+// ValidateDelete implements admission.CustomValidator
+func (v *AuthPolicyValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *AuthPolicyValidator) validate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	ap, ok := obj.(*kuadrantv1beta2.AuthPolicy)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected an AuthPolicy but got a %T", obj))
+	}
+
+	if err := ap.Validate(); err != nil {
+		return nil, invalid(ap, "AuthPolicy", field.ErrorList{field.Invalid(field.NewPath("spec"), ap.GetName(), err.Error())})
+	}
+
+	target, warnings, err := fetchTarget(ctx, v.reader, ap)
+	if err != nil {
+		return nil, err
+	}
+
+	return warnings, invalid(ap, "AuthPolicy", validateAuthPolicySpec(ap, target))
+}
+
+func validateAuthPolicySpec(ap *kuadrantv1beta2.AuthPolicy, target client.Object) field.ErrorList {
+	path := field.NewPath("spec")
+	if ap.Spec.Defaults != nil {
+		path = path.Child("defaults")
+	}
+	spec := ap.Spec.CommonSpec()
+
+	var errs field.ErrorList
+
+	errs = append(errs, validateRouteSelectors(path.Child("routeSelectors"), spec.RouteSelectors, target)...)
+
+	for _, name := range sortedKeys(spec.NamedPatterns) {
+		for idx, pattern := range spec.NamedPatterns[name] {
+			if err := validatePattern(path.Child("patterns").Key(name).Index(idx).Child("value"), string(pattern.Operator), pattern.Value); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	errs = append(errs, validatePatternExpressionsOrRefs(path.Child("when"), spec.Conditions, spec.NamedPatterns)...)
+
+	if spec.AuthScheme == nil {
+		return errs
+	}
+
+	rulesPath := path.Child("rules")
+	validateRule := func(rulePath *field.Path, routeSelectors []kuadrantv1beta2.RouteSelector, conditions []authorinoapi.PatternExpressionOrRef) {
+		errs = append(errs, validateRouteSelectors(rulePath.Child("routeSelectors"), routeSelectors, target)...)
+		errs = append(errs, validatePatternExpressionsOrRefs(rulePath.Child("when"), conditions, spec.NamedPatterns)...)
+	}
+
+	for _, name := range sortedKeys(spec.AuthScheme.Authentication) {
+		rule := spec.AuthScheme.Authentication[name]
+		validateRule(rulesPath.Child("authentication").Key(name), rule.RouteSelectors, rule.Conditions)
+	}
+	for _, name := range sortedKeys(spec.AuthScheme.Metadata) {
+		rule := spec.AuthScheme.Metadata[name]
+		validateRule(rulesPath.Child("metadata").Key(name), rule.RouteSelectors, rule.Conditions)
+	}
+	for _, name := range sortedKeys(spec.AuthScheme.Authorization) {
+		rule := spec.AuthScheme.Authorization[name]
+		rulePath := rulesPath.Child("authorization").Key(name)
+		validateRule(rulePath, rule.RouteSelectors, rule.Conditions)
+		if rule.PatternMatching != nil {
+			errs = append(errs, validatePatternExpressionsOrRefs(rulePath.Child("patternMatching", "patterns"), rule.PatternMatching.Patterns, spec.NamedPatterns)...)
+		}
+	}
+	if response := spec.AuthScheme.Response; response != nil {
+		successPath := rulesPath.Child("response", "success")
+		for _, name := range sortedKeys(response.Success.Headers) {
+			rule := response.Success.Headers[name]
+			validateRule(successPath.Child("headers").Key(name), rule.RouteSelectors, rule.Conditions)
+		}
+		for _, name := range sortedKeys(response.Success.DynamicMetadata) {
+			rule := response.Success.DynamicMetadata[name]
+			validateRule(successPath.Child("dynamicMetadata").Key(name), rule.RouteSelectors, rule.Conditions)
+		}
+	}
+	for _, name := range sortedKeys(spec.AuthScheme.Callbacks) {
+		rule := spec.AuthScheme.Callbacks[name]
+		validateRule(rulesPath.Child("callbacks").Key(name), rule.RouteSelectors, rule.Conditions)
+	}
+
+	return errs
+}
+
+// validatePatternExpressionsOrRefs checks the regular expressions of the pattern expressions and that the referred
+// named patterns exist, including the ones nested in "all" and "any" expressions
+func validatePatternExpressionsOrRefs(path *field.Path, patterns []authorinoapi.PatternExpressionOrRef, namedPatterns map[string]authorinoapi.PatternExpressions) field.ErrorList {
+	var errs field.ErrorList
+	for idx, pattern := range patterns {
+		patternPath := path.Index(idx)
+		if name := pattern.PatternRef.Name; name != "" {
+			if _, ok := namedPatterns[name]; !ok {
+				errs = append(errs, field.NotFound(patternPath.Child("patternRef"), name))
+			}
+		}
+		if err := validatePattern(patternPath.Child("value"), string(pattern.Operator), pattern.Value); err != nil {
+			errs = append(errs, err)
+		}
+		errs = append(errs, validatePatternExpressionsOrRefs(patternPath.Child("all"), unwrapPatterns(pattern.All), namedPatterns)...)
+		errs = append(errs, validatePatternExpressionsOrRefs(patternPath.Child("any"), unwrapPatterns(pattern.Any), namedPatterns)...)
+	}
+	return errs
+}
+
+func unwrapPatterns(patterns []authorinoapi.UnstructuredPatternExpressionOrRef) []authorinoapi.PatternExpressionOrRef {
+	unwrapped := make([]authorinoapi.PatternExpressionOrRef, 0, len(patterns))
+	for _, pattern := range patterns {
+		unwrapped = append(unwrapped, pattern.PatternExpressionOrRef)
+	}
+	return unwrapped
+}
@@ -0,0 +1,204 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kuadrantv1beta2 "github.com/kuadrant/kuadrant-operator/api/v1beta2"
+)
+
+// +kubebuilder:webhook:path=/validate-kuadrant-io-v1beta2-ratelimitpolicy,mutating=false,failurePolicy=fail,sideEffects=None,groups=kuadrant.io,resources=ratelimitpolicies,verbs=create;update,versions=v1beta2,name=vratelimitpolicy.kuadrant.io,admissionReviewVersions=v1
+
+// RateLimitPolicyValidator rejects rate limit policies that would otherwise only be reported as not accepted
+// after being applied, i.e. invalid specs, route selectors that do not match any rule of the targeted HTTPRoute,
+// invalid regular expressions and unknown selectors.
+type RateLimitPolicyValidator struct {
+	reader client.Reader
+}
+
+var _ admission.CustomValidator = &RateLimitPolicyValidator{}
+
+func NewRateLimitPolicyValidator(reader client.Reader) *RateLimitPolicyValidator {
+	return &RateLimitPolicyValidator{reader: reader}
+}
+
+// SetupWithManager registers the validating webhook of the rate limit policies in the webhook server of the manager
+func (v *RateLimitPolicyValidator) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&kuadrantv1beta2.RateLimitPolicy{}).
+		WithValidator(v).
+		Complete()
+}
+
+// ValidateCreate implements admission.CustomValidator
+func (v *RateLimitPolicyValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return v.validate(ctx, obj)
+}
+
+// ValidateUpdate implements admission.CustomValidator
+func (v *RateLimitPolicyValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldPolicy, oldOK := oldObj.(*kuadrantv1beta2.RateLimitPolicy)
+	newPolicy, newOK := newObj.(*kuadrantv1beta2.RateLimitPolicy)
+	if oldOK && newOK && skipUpdateValidation(oldPolicy, newPolicy, oldPolicy.Spec, newPolicy.Spec) {
+		return nil, nil
+	}
+	return v.validate(ctx, newObj)
+}
+
+// ValidateDelete implements admission.CustomValidator
+func (v *RateLimitPolicyValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *RateLimitPolicyValidator) validate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	rlp, ok := obj.(*kuadrantv1beta2.RateLimitPolicy)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected a RateLimitPolicy but got a %T", obj))
+	}
+
+	if err := rlp.Validate(); err != nil {
+		return nil, invalid(rlp, "RateLimitPolicy", field.ErrorList{field.Invalid(field.NewPath("spec"), rlp.GetName(), err.Error())})
+	}
+
+	target, warnings, err := fetchTarget(ctx, v.reader, rlp)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := append(validateCounterFields(ctx), validateRateLimitPolicySpec(rlp, target)...)
+	return warnings, invalid(rlp, "RateLimitPolicy", errs)
+}
+
+// validateCounterFields rejects unknown fields in the object form of the counters. The schema of the counters is
+// untyped, so the API server preserves unknown fields and the decoding of the policy drops them, hence the check is
+// done on the raw object of the admission request.
+func validateCounterFields(ctx context.Context) field.ErrorList {
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil || len(req.Object.Raw) == 0 {
+		return nil
+	}
+	obj := map[string]any{}
+	if err := json.Unmarshal(req.Object.Raw, &obj); err != nil {
+		return nil
+	}
+
+	var errs field.ErrorList
+	for _, fields := range [][]string{{"spec"}, {"spec", "defaults"}} {
+		path := field.NewPath(fields[0], fields[1:]...)
+		limits, _, _ := unstructured.NestedMap(obj, append(slices.Clone(fields), "limits")...)
+		for _, name := range sortedKeys(limits) {
+			limit, _ := limits[name].(map[string]any)
+			counters, _, _ := unstructured.NestedSlice(limit, "counters")
+			errs = append(errs, validateCounterListFields(path.Child("limits").Key(name).Child("counters"), counters)...)
+		}
+		counters, _, _ := unstructured.NestedSlice(obj, append(slices.Clone(fields), "plans", "counters")...)
+		errs = append(errs, validateCounterListFields(path.Child("plans", "counters"), counters)...)
+	}
+	return errs
+}
+
+func validateCounterListFields(path *field.Path, counters []any) field.ErrorList {
+	var errs field.ErrorList
+	for idx, counter := range counters {
+		counterFields, ok := counter.(map[string]any)
+		if !ok {
+			continue
+		}
+		for _, name := range sortedKeys(counterFields) {
+			if !slices.Contains(kuadrantv1beta2.CounterFields, name) {
+				errs = append(errs, field.NotSupported(path.Index(idx), name, kuadrantv1beta2.CounterFields))
+			}
+		}
+	}
+	return errs
+}
+
+func validateRateLimitPolicySpec(rlp *kuadrantv1beta2.RateLimitPolicy, target client.Object) field.ErrorList {
+	path := field.NewPath("spec")
+	if rlp.Spec.Defaults != nil {
+		path = path.Child("defaults")
+	}
+	limitsPath := path.Child("limits")
+
+	limits := rlp.Spec.CommonSpec().Limits
+
+	var errs field.ErrorList
+	for _, name := range sortedKeys(limits) {
+		limit := limits[name]
+		limitPath := limitsPath.Key(name)
+
+		errs = append(errs, validateRouteSelectors(limitPath.Child("routeSelectors"), limit.RouteSelectors, target)...)
+
+		for idx, condition := range limit.When {
+			conditionPath := limitPath.Child("when").Index(idx)
+			if condition.Selector != "" {
+				errs = append(errs, validateWhenPattern(conditionPath, condition.Selector, condition.Operator, condition.Value)...)
+			}
+			for i, pattern := range condition.AnyOf {
+				if pattern.Selector != "" {
+					errs = append(errs, validateWhenPattern(conditionPath.Child("anyOf").Index(i), pattern.Selector, pattern.Operator, pattern.Value)...)
+				}
+			}
+			for i, pattern := range condition.AllOf {
+				if pattern.Selector != "" {
+					errs = append(errs, validateWhenPattern(conditionPath.Child("allOf").Index(i), pattern.Selector, pattern.Operator, pattern.Value)...)
+				}
+			}
+		}
+
+		for idx, counter := range limit.Counters {
+			if err := validateWellKnownSelector(limitPath.Child("counters").Index(idx).Child("selector"), string(counter.Selector)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		if limit.Cost != nil && limit.Cost.Selector != "" {
+			if err := validateWellKnownSelector(limitPath.Child("cost", "selector"), string(limit.Cost.Selector)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	namedPatterns := rlp.Spec.CommonSpec().NamedPatterns
+	for _, name := range sortedKeys(namedPatterns) {
+		for idx, expression := range namedPatterns[name] {
+			errs = append(errs, validateWhenPattern(path.Child("patterns").Key(name).Index(idx), expression.Selector, expression.Operator, expression.Value)...)
+		}
+	}
+
+	if plans := rlp.Spec.CommonSpec().Plans; plans != nil {
+		plansPath := path.Child("plans")
+		if err := validateWellKnownSelector(plansPath.Child("selector"), string(plans.Selector)); err != nil {
+			errs = append(errs, err)
+		}
+		for idx, counter := range plans.Counters {
+			if err := validateWellKnownSelector(plansPath.Child("counters").Index(idx).Child("selector"), string(counter.Selector)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateWhenPattern validates the selector and the value of a pattern expression of a 'when' condition
+func validateWhenPattern(path *field.Path, selector kuadrantv1beta2.ContextSelector, operator kuadrantv1beta2.WhenConditionOperator, value string) field.ErrorList {
+	var errs field.ErrorList
+	if err := validateWellKnownSelector(path.Child("selector"), string(selector)); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validatePattern(path.Child("value"), string(operator), value); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
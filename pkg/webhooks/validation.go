@@ -0,0 +1,122 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	kuadrantv1beta2 "github.com/kuadrant/kuadrant-operator/api/v1beta2"
+	"github.com/kuadrant/kuadrant-operator/pkg/library/kuadrant"
+)
+
+// WellKnownSelectorRoots are the roots of the well-known attributes the data plane can resolve.
+// See https://github.com/Kuadrant/architecture/blob/main/rfcs/0002-well-known-attributes.md
+var WellKnownSelectorRoots = []string{
+	"request",
+	"response",
+	"connection",
+	"upstream",
+	"source",
+	"destination",
+	"metadata",
+	"filter_state",
+	"xds",
+	"auth",
+	"ratelimit",
+}
+
+// skipUpdateValidation tells whether the update of a policy does not need to be validated, i.e. the policy is being
+// deleted, or neither its generation nor its spec changed, e.g. updates of the status, the labels or the finalizers,
+// so policies that became invalid, for example because of a change of their target, can still be updated and deleted
+func skipUpdateValidation(oldObj, newObj client.Object, oldSpec, newSpec any) bool {
+	if newObj.GetDeletionTimestamp() != nil {
+		return true
+	}
+	return oldObj.GetGeneration() == newObj.GetGeneration() && equality.Semantic.DeepEqual(oldSpec, newSpec)
+}
+
+// fetchTarget fetches the HTTPRoute targeted by the policy, regardless of whether it has been accepted by a gateway,
+// since only its rules are needed to validate the route selectors. It returns nil for other kinds of targets, and
+// nil and a warning if the HTTPRoute does not exist yet, since the policy can be created before its target.
+func fetchTarget(ctx context.Context, reader client.Reader, policy kuadrant.Policy) (client.Object, admission.Warnings, error) {
+	targetRef := policy.GetTargetRef()
+	if targetRef.Kind != "HTTPRoute" {
+		return nil, nil, nil
+	}
+
+	key := client.ObjectKey{Name: string(targetRef.Name), Namespace: policy.GetNamespace()}
+	if targetRef.Namespace != nil {
+		key.Namespace = string(*targetRef.Namespace)
+	}
+
+	route := &gatewayapiv1.HTTPRoute{}
+	if err := reader.Get(ctx, key, route); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, admission.Warnings{fmt.Sprintf("target HTTPRoute %s not found, the route selectors of the policy cannot be validated", key)}, nil
+		}
+		return nil, nil, err
+	}
+	return route, nil, nil
+}
+
+// validateRouteSelectors checks that the route selectors select at least one rule of the targeted HTTPRoute
+func validateRouteSelectors(path *field.Path, routeSelectors []kuadrantv1beta2.RouteSelector, target client.Object) field.ErrorList {
+	route, ok := target.(*gatewayapiv1.HTTPRoute)
+	if !ok || len(routeSelectors) == 0 {
+		return nil
+	}
+	for idx := range routeSelectors {
+		if len(routeSelectors[idx].SelectRules(route)) > 0 {
+			return nil
+		}
+	}
+	return field.ErrorList{field.Invalid(path, routeSelectors, fmt.Sprintf("cannot match any route rules of HTTPRoute %s", client.ObjectKeyFromObject(route)))}
+}
+
+// validateWellKnownSelector checks that the selector refers to a well-known attribute
+func validateWellKnownSelector(path *field.Path, selector string) *field.Error {
+	root, _, _ := strings.Cut(selector, ".")
+	for _, wellKnownRoot := range WellKnownSelectorRoots {
+		if root == wellKnownRoot {
+			return nil
+		}
+	}
+	return field.Invalid(path, selector, fmt.Sprintf("unknown selector, must be a well-known attribute starting with one of: %s", strings.Join(WellKnownSelectorRoots, ", ")))
+}
+
+// validatePattern checks that the values of the "matches" operator are valid regular expressions
+func validatePattern(path *field.Path, operator, value string) *field.Error {
+	if operator != string(kuadrantv1beta2.MatchesOperator) {
+		return nil
+	}
+	if _, err := regexp.Compile(value); err != nil {
+		return field.Invalid(path, value, fmt.Sprintf("invalid regular expression: %v", err))
+	}
+	return nil
+}
+
+// invalid wraps the validation errors of the policy into an admission error
+func invalid(policy client.Object, kind string, errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(kuadrantv1beta2.GroupVersion.WithKind(kind).GroupKind(), policy.GetName(), errs)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,304 @@
+//go:build unit
+
+package webhooks
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	authorinoapi "github.com/kuadrant/authorino/api/v1beta2"
+	"gotest.tools/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	kuadrantv1beta2 "github.com/kuadrant/kuadrant-operator/api/v1beta2"
+)
+
+func testHTTPRoute() *gatewayapiv1.HTTPRoute {
+	return &gatewayapiv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "toystore", Namespace: "testNS"},
+		Spec: gatewayapiv1.HTTPRouteSpec{
+			Hostnames: []gatewayapiv1.Hostname{"api.toystore.io"},
+			Rules: []gatewayapiv1.HTTPRouteRule{
+				{
+					Matches: []gatewayapiv1.HTTPRouteMatch{
+						{
+							Path: &gatewayapiv1.HTTPPathMatch{
+								Type:  ptr.To(gatewayapiv1.PathMatchPathPrefix),
+								Value: ptr.To("/toys"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func testTargetRef() gatewayapiv1alpha2.PolicyTargetReference {
+	return gatewayapiv1alpha2.PolicyTargetReference{
+		Group: gatewayapiv1.GroupName,
+		Kind:  "HTTPRoute",
+		Name:  "toystore",
+	}
+}
+
+func testRouteSelector(path string) kuadrantv1beta2.RouteSelector {
+	return kuadrantv1beta2.RouteSelector{
+		Matches: []gatewayapiv1.HTTPRouteMatch{
+			{
+				Path: &gatewayapiv1.HTTPPathMatch{
+					Type:  ptr.To(gatewayapiv1.PathMatchPathPrefix),
+					Value: ptr.To(path),
+				},
+			},
+		},
+	}
+}
+
+func testRLP(limit kuadrantv1beta2.Limit) *kuadrantv1beta2.RateLimitPolicy {
+	return &kuadrantv1beta2.RateLimitPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "rlp", Namespace: "testNS"},
+		Spec: kuadrantv1beta2.RateLimitPolicySpec{
+			TargetRef: testTargetRef(),
+			RateLimitPolicyCommonSpec: kuadrantv1beta2.RateLimitPolicyCommonSpec{
+				Limits: map[string]kuadrantv1beta2.Limit{"l1": limit},
+			},
+		},
+	}
+}
+
+func testAuthPolicy(spec kuadrantv1beta2.AuthPolicyCommonSpec) *kuadrantv1beta2.AuthPolicy {
+	return &kuadrantv1beta2.AuthPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "ap", Namespace: "testNS"},
+		Spec: kuadrantv1beta2.AuthPolicySpec{
+			TargetRef:            testTargetRef(),
+			AuthPolicyCommonSpec: spec,
+		},
+	}
+}
+
+func testReader(t *testing.T, objs ...runtime.Object) *fake.ClientBuilder {
+	scheme := runtime.NewScheme()
+	assert.NilError(t, kuadrantv1beta2.AddToScheme(scheme))
+	assert.NilError(t, gatewayapiv1.Install(scheme))
+	return fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...)
+}
+
+func TestRateLimitPolicyValidator(t *testing.T) {
+	ctx := context.Background()
+	rate := kuadrantv1beta2.Rate{Limit: 5, Duration: 10, Unit: kuadrantv1beta2.TimeUnit("second")}
+	validator := NewRateLimitPolicyValidator(testReader(t, testHTTPRoute()).Build())
+
+	testCases := []struct {
+		name        string
+		limit       kuadrantv1beta2.Limit
+		expectedErr string
+	}{
+		{
+			name: "valid limit",
+			limit: kuadrantv1beta2.Limit{
+				RouteSelectors: []kuadrantv1beta2.RouteSelector{testRouteSelector("/toys")},
+				When:           []kuadrantv1beta2.WhenCondition{{Selector: "request.path", Operator: kuadrantv1beta2.MatchesOperator, Value: "^/toys/[0-9]+$"}},
+				Counters:       []kuadrantv1beta2.Counter{{Selector: "auth.identity.username"}},
+				Rates:          []kuadrantv1beta2.Rate{rate},
+			},
+		},
+		{
+			name: "route selectors that match no rule",
+			limit: kuadrantv1beta2.Limit{
+				RouteSelectors: []kuadrantv1beta2.RouteSelector{testRouteSelector("/cars")},
+				Rates:          []kuadrantv1beta2.Rate{rate},
+			},
+			expectedErr: "spec.limits[l1].routeSelectors",
+		},
+		{
+			name: "invalid regular expression",
+			limit: kuadrantv1beta2.Limit{
+				When:  []kuadrantv1beta2.WhenCondition{{Selector: "request.path", Operator: kuadrantv1beta2.MatchesOperator, Value: "^/toys/(["}},
+				Rates: []kuadrantv1beta2.Rate{rate},
+			},
+			expectedErr: "spec.limits[l1].when[0].value",
+		},
+		{
+			name: "unknown selector",
+			limit: kuadrantv1beta2.Limit{
+				Counters: []kuadrantv1beta2.Counter{{Selector: "identity.username"}},
+				Rates:    []kuadrantv1beta2.Rate{rate},
+			},
+			expectedErr: "spec.limits[l1].counters[0].selector",
+		},
+		{
+			name: "invalid regular expression in anyOf",
+			limit: kuadrantv1beta2.Limit{
+				When: []kuadrantv1beta2.WhenCondition{{AnyOf: []kuadrantv1beta2.WhenPattern{
+					{Selector: "request.method", Operator: kuadrantv1beta2.EqualOperator, Value: "GET"},
+					{Selector: "request.path", Operator: kuadrantv1beta2.MatchesOperator, Value: "^/toys/(["},
+				}}},
+				Rates: []kuadrantv1beta2.Rate{rate},
+			},
+			expectedErr: "spec.limits[l1].when[0].anyOf[1].value",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(subT *testing.T) {
+			_, err := validator.ValidateCreate(ctx, testRLP(tc.limit))
+			if tc.expectedErr == "" {
+				assert.NilError(subT, err)
+				return
+			}
+			assert.Assert(subT, err != nil && strings.Contains(err.Error(), tc.expectedErr), "unexpected error: %v", err)
+		})
+	}
+
+	t.Run("target not found", func(subT *testing.T) {
+		validator := NewRateLimitPolicyValidator(testReader(subT).Build())
+		warnings, err := validator.ValidateCreate(ctx, testRLP(kuadrantv1beta2.Limit{
+			RouteSelectors: []kuadrantv1beta2.RouteSelector{testRouteSelector("/cars")},
+			Rates:          []kuadrantv1beta2.Rate{rate},
+		}))
+		assert.NilError(subT, err)
+		assert.Equal(subT, len(warnings), 1)
+	})
+
+	t.Run("unknown selector in named pattern", func(subT *testing.T) {
+		rlp := testRLP(kuadrantv1beta2.Limit{
+			When:  []kuadrantv1beta2.WhenCondition{{PatternRef: "admins"}},
+			Rates: []kuadrantv1beta2.Rate{rate},
+		})
+		rlp.Spec.NamedPatterns = map[string]kuadrantv1beta2.PatternExpressions{
+			"admins": {{Selector: "identity.group", Operator: kuadrantv1beta2.EqualOperator, Value: "admins"}},
+		}
+		_, err := validator.ValidateCreate(ctx, rlp)
+		assert.Assert(subT, err != nil && strings.Contains(err.Error(), "spec.patterns[admins][0].selector"), "unexpected error: %v", err)
+	})
+
+	t.Run("unknown plans selector", func(subT *testing.T) {
+		rlp := testRLP(kuadrantv1beta2.Limit{Rates: []kuadrantv1beta2.Rate{rate}})
+		rlp.Spec.Plans = &kuadrantv1beta2.Plans{
+			Selector: "identity.plan",
+			Tiers:    map[string]kuadrantv1beta2.Plan{"free": {Rates: []kuadrantv1beta2.Rate{rate}}},
+		}
+		_, err := validator.ValidateCreate(ctx, rlp)
+		assert.Assert(subT, err != nil && strings.Contains(err.Error(), "spec.plans.selector"), "unexpected error: %v", err)
+	})
+
+	t.Run("unknown counter fields", func(subT *testing.T) {
+		rlp := testRLP(kuadrantv1beta2.Limit{Rates: []kuadrantv1beta2.Rate{rate}})
+		raw := []byte(`{"spec":{"defaults":{"limits":{"l1":{"counters":["request.path",{"selector":"auth.identity.username","defualt":"anonymous"}]}}}}}`)
+		reqCtx := admission.NewContextWithRequest(ctx, admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{Object: runtime.RawExtension{Raw: raw}}})
+		_, err := validator.ValidateCreate(reqCtx, rlp)
+		assert.Assert(subT, err != nil && strings.Contains(err.Error(), `spec.defaults.limits[l1].counters[1]: Unsupported value: "defualt"`), "unexpected error: %v", err)
+
+		raw = []byte(`{"spec":{"limits":{"l1":{"counters":["request.path",{"selector":"auth.identity.username","default":"anonymous"}]}}}}`)
+		reqCtx = admission.NewContextWithRequest(ctx, admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{Object: runtime.RawExtension{Raw: raw}}})
+		_, err = validator.ValidateCreate(reqCtx, rlp)
+		assert.NilError(subT, err)
+	})
+
+	t.Run("update", func(subT *testing.T) {
+		oldRLP := testRLP(kuadrantv1beta2.Limit{
+			RouteSelectors: []kuadrantv1beta2.RouteSelector{testRouteSelector("/cars")},
+			Rates:          []kuadrantv1beta2.Rate{rate},
+		})
+		oldRLP.Generation = 1
+
+		// spec unchanged, e.g. a finalizer added to a policy whose target changed
+		newRLP := oldRLP.DeepCopy()
+		newRLP.Finalizers = []string{"kuadrant.io/finalizer"}
+		_, err := validator.ValidateUpdate(ctx, oldRLP, newRLP)
+		assert.NilError(subT, err)
+
+		// policy being deleted
+		newRLP = oldRLP.DeepCopy()
+		newRLP.Generation = 2
+		newRLP.DeletionTimestamp = ptr.To(metav1.Now())
+		newRLP.Finalizers = nil
+		_, err = validator.ValidateUpdate(ctx, oldRLP, newRLP)
+		assert.NilError(subT, err)
+
+		// spec changed
+		newRLP = oldRLP.DeepCopy()
+		newRLP.Generation = 2
+		newRLP.Spec.Limits["l1"] = kuadrantv1beta2.Limit{
+			RouteSelectors: []kuadrantv1beta2.RouteSelector{testRouteSelector("/bikes")},
+			Rates:          []kuadrantv1beta2.Rate{rate},
+		}
+		_, err = validator.ValidateUpdate(ctx, oldRLP, newRLP)
+		assert.Assert(subT, err != nil, "expected an error")
+	})
+}
+
+func TestAuthPolicyValidator(t *testing.T) {
+	ctx := context.Background()
+	validator := NewAuthPolicyValidator(testReader(t, testHTTPRoute()).Build())
+
+	testCases := []struct {
+		name        string
+		spec        kuadrantv1beta2.AuthPolicyCommonSpec
+		expectedErr string
+	}{
+		{
+			name: "valid policy",
+			spec: kuadrantv1beta2.AuthPolicyCommonSpec{
+				RouteSelectors: []kuadrantv1beta2.RouteSelector{testRouteSelector("/toys")},
+				NamedPatterns: map[string]authorinoapi.PatternExpressions{
+					"admin-path": {{Selector: "context.request.http.path", Operator: "matches", Value: "^/admin"}},
+				},
+				Conditions: []authorinoapi.PatternExpressionOrRef{{PatternRef: authorinoapi.PatternRef{Name: "admin-path"}}},
+			},
+		},
+		{
+			name: "route selectors that match no rule",
+			spec: kuadrantv1beta2.AuthPolicyCommonSpec{
+				AuthScheme: &kuadrantv1beta2.AuthSchemeSpec{
+					Authentication: map[string]kuadrantv1beta2.AuthenticationSpec{
+						"api-key": {CommonAuthRuleSpec: kuadrantv1beta2.CommonAuthRuleSpec{
+							RouteSelectors: []kuadrantv1beta2.RouteSelector{testRouteSelector("/cars")},
+						}},
+					},
+				},
+			},
+			expectedErr: "spec.rules.authentication[api-key].routeSelectors",
+		},
+		{
+			name: "invalid regular expression in nested pattern",
+			spec: kuadrantv1beta2.AuthPolicyCommonSpec{
+				Conditions: []authorinoapi.PatternExpressionOrRef{{
+					Any: []authorinoapi.UnstructuredPatternExpressionOrRef{
+						{PatternExpressionOrRef: authorinoapi.PatternExpressionOrRef{
+							PatternExpression: authorinoapi.PatternExpression{Selector: "context.request.http.path", Operator: "matches", Value: "(["},
+						}},
+					},
+				}},
+			},
+			expectedErr: "spec.when[0].any[0].value",
+		},
+		{
+			name: "undefined named pattern",
+			spec: kuadrantv1beta2.AuthPolicyCommonSpec{
+				Conditions: []authorinoapi.PatternExpressionOrRef{{PatternRef: authorinoapi.PatternRef{Name: "undefined"}}},
+			},
+			expectedErr: "spec.when[0].patternRef",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(subT *testing.T) {
+			_, err := validator.ValidateCreate(ctx, testAuthPolicy(tc.spec))
+			if tc.expectedErr == "" {
+				assert.NilError(subT, err)
+				return
+			}
+			assert.Assert(subT, err != nil && strings.Contains(err.Error(), tc.expectedErr), "unexpected error: %v", err)
+		})
+	}
+}